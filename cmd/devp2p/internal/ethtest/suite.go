@@ -17,18 +17,27 @@
 package ethtest
 
 import (
+	"crypto/rand"
 	"fmt"
 	"net"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/crypto"
 	"github.com/ledgerwatch/turbo-geth/internal/utesting"
 	"github.com/ledgerwatch/turbo-geth/p2p/enode"
 	"github.com/ledgerwatch/turbo-geth/p2p/rlpx"
+	"github.com/ledgerwatch/turbo-geth/rlp"
 	"github.com/stretchr/testify/assert"
 )
 
+const (
+	maxMessageSize = 10 * 1024 * 1024 // Maximum size of the eth protocol messages
+	floodRequests  = 2000             // Number of the requests sent by the flooding peer
+)
+
 var pretty = spew.ConfigState{
 	Indent:                  "  ",
 	DisableCapacities:       true,
@@ -66,6 +75,13 @@ func (s *Suite) AllTests() []utesting.Test {
 		{Name: "GetBlockHeaders", Fn: s.TestGetBlockHeaders},
 		{Name: "Broadcast", Fn: s.TestBroadcast},
 		{Name: "GetBlockBodies", Fn: s.TestGetBlockBodies},
+		{Name: "GetReceipts", Fn: s.TestGetReceipts},
+		{Name: "TransactionAnnouncement", Fn: s.TestTransactionAnnouncement},
+		{Name: "GetPooledTransactions", Fn: s.TestGetPooledTransactions},
+		{Name: "ForkIDMismatch", Fn: s.TestForkIDMismatch},
+		{Name: "MalformedMessage", Fn: s.TestMalformedMessage},
+		{Name: "OversizedMessage", Fn: s.TestOversizedMessage},
+		{Name: "Flooding", Fn: s.TestFlooding},
 	}
 }
 
@@ -80,7 +96,7 @@ func (s *Suite) TestStatus(t *utesting.T) {
 	// get protoHandshake
 	conn.handshake(t)
 	// get status
-	switch msg := conn.statusExchange(t, s.chain).(type) {
+	switch msg := conn.statusExchange(t, s.chain, nil).(type) {
 	case *Status:
 		t.Logf("got status message: %s", pretty.Sdump(msg))
 	default:
@@ -97,7 +113,7 @@ func (s *Suite) TestGetBlockHeaders(t *utesting.T) {
 	}
 
 	conn.handshake(t)
-	conn.statusExchange(t, s.chain)
+	conn.statusExchange(t, s.chain, nil)
 
 	// get block headers
 	req := &GetBlockHeaders{
@@ -136,7 +152,7 @@ func (s *Suite) TestGetBlockBodies(t *utesting.T) {
 	}
 
 	conn.handshake(t)
-	conn.statusExchange(t, s.chain)
+	conn.statusExchange(t, s.chain, nil)
 	// create block bodies request
 	req := &GetBlockBodies{s.chain.blocks[54].Hash(), s.chain.blocks[75].Hash()}
 	if err := conn.Write(req); err != nil {
@@ -169,8 +185,8 @@ func (s *Suite) TestBroadcast(t *utesting.T) {
 	sendConn.handshake(t)
 	receiveConn.handshake(t)
 
-	sendConn.statusExchange(t, s.chain)
-	receiveConn.statusExchange(t, s.chain)
+	sendConn.statusExchange(t, s.chain, nil)
+	receiveConn.statusExchange(t, s.chain, nil)
 
 	// sendConn sends the block announcement
 	blockAnnouncement := &NewBlock{
@@ -211,6 +227,231 @@ func (s *Suite) TestBroadcast(t *utesting.T) {
 	}
 }
 
+// TestGetReceipts tests whether the given node can respond to
+// a `GetReceipts` request and that the receipts match the blocks.
+func (s *Suite) TestGetReceipts(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	blocks := []*types.Block{s.chain.blocks[54], s.chain.blocks[75]}
+	req := &GetReceipts{blocks[0].Hash(), blocks[1].Hash()}
+	if err := conn.Write(req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+
+	timeout := 20 * time.Second
+	switch msg := conn.ReadAndServe(s.chain, timeout).(type) {
+	case *Receipts:
+		receipts := *msg
+		if len(receipts) != len(blocks) {
+			t.Fatalf("wrong number of receipt lists: have %d, want %d", len(receipts), len(blocks))
+		}
+		for i, block := range blocks {
+			if root := types.DeriveSha(types.Receipts(receipts[i])); root != block.ReceiptHash() {
+				t.Fatalf("wrong receipts of block %d: root %x, want %x", block.NumberU64(), root, block.ReceiptHash())
+			}
+		}
+	default:
+		t.Fatalf("unexpected: %s", pretty.Sdump(msg))
+	}
+}
+
+// TestTransactionAnnouncement tests whether the given node requests
+// the transactions announced with `NewPooledTransactionHashes` (eth/65).
+func (s *Suite) TestTransactionAnnouncement(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	if conn.ethProtocolVersion < 65 {
+		t.Logf("transaction announcements are not supported by eth/%d", conn.ethProtocolVersion)
+		return
+	}
+	announced := make(map[common.Hash]bool)
+	hashes := make(NewPooledTransactionHashes, 16)
+	for i := range hashes {
+		hashes[i] = randomHash()
+		announced[hashes[i]] = true
+	}
+	if err := conn.Write(hashes); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+
+	timeout := 20 * time.Second
+	switch msg := conn.ReadAndServe(s.chain, timeout).(type) {
+	case *GetPooledTransactions:
+		if len(*msg) == 0 {
+			t.Fatalf("empty request of the announced transactions")
+		}
+		for _, hash := range *msg {
+			if !announced[hash] {
+				t.Fatalf("requested transaction %x was not announced", hash)
+			}
+		}
+	default:
+		t.Fatalf("unexpected: %s", pretty.Sdump(msg))
+	}
+}
+
+// TestGetPooledTransactions tests whether the given node responds to
+// a `GetPooledTransactions` request for unknown transactions (eth/65)
+// without any of them.
+func (s *Suite) TestGetPooledTransactions(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	if conn.ethProtocolVersion < 65 {
+		t.Logf("pooled transactions are not supported by eth/%d", conn.ethProtocolVersion)
+		return
+	}
+	req := &GetPooledTransactions{randomHash(), randomHash()}
+	if err := conn.Write(req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+
+	timeout := 20 * time.Second
+	switch msg := conn.ReadAndServe(s.chain, timeout).(type) {
+	case *PooledTransactions:
+		if len(*msg) != 0 {
+			t.Fatalf("unknown transactions in the reply: %s", pretty.Sdump(msg))
+		}
+	default:
+		t.Fatalf("unexpected: %s", pretty.Sdump(msg))
+	}
+}
+
+// TestForkIDMismatch tests whether the given node disconnects the peer
+// announcing the fork ID of an incompatible chain.
+func (s *Suite) TestForkIDMismatch(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	forkID := s.chain.ForkID()
+	forkID.Hash[0] ^= 0xff
+	conn.statusExchange(t, s.chain, &Status{
+		ProtocolVersion: uint32(conn.ethProtocolVersion),
+		NetworkID:       s.chain.chainConfig.ChainID.Uint64(),
+		TD:              s.chain.TD(s.chain.Len()),
+		Head:            s.chain.Head().Hash(),
+		Genesis:         s.chain.blocks[0].Hash(),
+		ForkID:          forkID,
+	})
+	if err := conn.expectDisconnect(20 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMalformedMessage tests whether the given node disconnects the peer
+// sending the request it cannot decode.
+func (s *Suite) TestMalformedMessage(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	// The string instead of the list of the query fields
+	payload, _ := rlp.EncodeToBytes("malformed")
+	if _, err := conn.Conn.Write(uint64((GetBlockHeaders{}).Code()), payload); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	if err := conn.expectDisconnect(20 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOversizedMessage tests whether the given node disconnects the peer
+// sending the message over the size limit of the protocol.
+func (s *Suite) TestOversizedMessage(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	payload := make([]byte, maxMessageSize+1)
+	if _, err := conn.Conn.Write(uint64((Transactions{}).Code()), payload); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	if err := conn.expectDisconnect(20 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFlooding tests whether the given node stays responsive when one
+// of its peers floods it with requests, without reading the replies.
+func (s *Suite) TestFlooding(t *utesting.T) {
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	// The replies are drained, so the node is not blocked on writing them
+	replies := make(chan int)
+	go func() {
+		count := 0
+		for {
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+			switch conn.Read().(type) {
+			case *BlockHeaders:
+				count++
+			case *Ping:
+			default:
+				replies <- count
+				return
+			}
+		}
+	}()
+	req := &GetBlockHeaders{Origin: hashOrNumber{Number: 1}, Amount: 1}
+	for i := 0; i < floodRequests; i++ {
+		if err := conn.Write(req); err != nil {
+			t.Logf("connection dropped after %d requests: %v", i, err)
+			break
+		}
+	}
+	t.Logf("received %d replies to %d requests", <-replies, floodRequests)
+	conn.Close()
+
+	// A fresh connection is still served
+	conn, err = s.dial()
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	conn.handshake(t)
+	conn.statusExchange(t, s.chain, nil)
+	if err := conn.Write(req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	switch msg := conn.ReadAndServe(s.chain, 20*time.Second).(type) {
+	case *BlockHeaders:
+		if len(*msg) != 1 || (*msg)[0].Hash() != s.chain.blocks[1].Hash() {
+			t.Fatalf("wrong headers: %s", pretty.Sdump(msg))
+		}
+	default:
+		t.Fatalf("unexpected: %s", pretty.Sdump(msg))
+	}
+}
+
 // dial attempts to dial the given node and perform a handshake,
 // returning the created Conn if successful.
 func (s *Suite) dial() (*Conn, error) {
@@ -231,3 +472,9 @@ func (s *Suite) dial() (*Conn, error) {
 
 	return &conn, nil
 }
+
+func randomHash() common.Hash {
+	var hash common.Hash
+	rand.Read(hash[:]) //nolint:errcheck
+	return hash
+}
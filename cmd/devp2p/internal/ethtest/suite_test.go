@@ -0,0 +1,91 @@
+package ethtest
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/headers/download"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/internal/utesting"
+)
+
+const (
+	fullchainFile = "testdata/fullchain.rlp.gz"
+	genesisFile   = "testdata/genesis.json"
+)
+
+// writeChain writes the genesis and the blocks of the chain into the database, as the canonical chain
+func writeChain(db ethdb.Database, chain *Chain) error {
+	data, err := ioutil.ReadFile(genesisFile)
+	if err != nil {
+		return err
+	}
+	var genesis core.Genesis
+	if err = json.Unmarshal(data, &genesis); err != nil {
+		return err
+	}
+	if _, _, err = genesis.Commit(db, false /* history */); err != nil {
+		return err
+	}
+	td := new(big.Int).Set(chain.blocks[0].Difficulty())
+	for _, block := range chain.blocks[1:] {
+		if err = rawdb.WriteBlock(context.Background(), db, block); err != nil {
+			return err
+		}
+		td.Add(td, block.Difficulty())
+		if err = rawdb.WriteTd(db, block.Hash(), block.NumberU64(), td); err != nil {
+			return err
+		}
+		if err = rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64()); err != nil {
+			return err
+		}
+	}
+	rawdb.WriteHeadHeaderHash(db, chain.Head().Hash())
+	rawdb.WriteHeadBlockHash(db, chain.Head().Hash())
+	return nil
+}
+
+// TestSentry runs the suite against the sentry and the core serving the test chain in the same process
+func TestSentry(t *testing.T) {
+	chain, err := loadChain(fullchainFile, genesisFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	if err = writeChain(db, chain.Shorten(1000)); err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, err := download.ServeChain(ctx, key, "127.0.0.1:0", t.TempDir(), true /* withTxPool */, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	suite := NewSuite(server.Self(), fullchainFile, genesisFile)
+	var tests []utesting.Test
+	for _, test := range suite.AllTests() {
+		// The sentry does not import and propagate the new blocks, the stages of the core do
+		if test.Name == "Broadcast" {
+			continue
+		}
+		tests = append(tests, test)
+	}
+	results := utesting.RunTests(tests, os.Stdout)
+	if fails := utesting.CountFailures(results); fails > 0 {
+		t.Fatalf("%d of %d tests failed", fails, len(tests))
+	}
+}
@@ -18,9 +18,11 @@ package ethtest
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"net"
 	"reflect"
 	"time"
 
@@ -168,6 +170,36 @@ type BlockBodies []*types.Body
 
 func (bb BlockBodies) Code() int { return 22 }
 
+// GetReceipts represents a GetReceipts request
+type GetReceipts []common.Hash
+
+func (gr GetReceipts) Code() int { return 31 }
+
+// Receipts is the network packet for the receipts of the blocks.
+type Receipts [][]*types.Receipt
+
+func (r Receipts) Code() int { return 32 }
+
+// Transactions is the network packet for the transaction propagation.
+type Transactions []*types.Transaction
+
+func (t Transactions) Code() int { return 18 }
+
+// NewPooledTransactionHashes is the network packet for the transaction announcements (eth/65).
+type NewPooledTransactionHashes []common.Hash
+
+func (nb NewPooledTransactionHashes) Code() int { return 24 }
+
+// GetPooledTransactions represents a request of the announced transactions (eth/65).
+type GetPooledTransactions []common.Hash
+
+func (gpt GetPooledTransactions) Code() int { return 25 }
+
+// PooledTransactions is the network packet for the requested transactions (eth/65).
+type PooledTransactions []*types.Transaction
+
+func (pt PooledTransactions) Code() int { return 26 }
+
 // Conn represents an individual connection with a peer
 type Conn struct {
 	*rlpx.Conn
@@ -178,7 +210,7 @@ type Conn struct {
 func (c *Conn) Read() Message {
 	code, rawData, _, err := c.Conn.Read()
 	if err != nil {
-		return errorf("could not read from connection: %v", err)
+		return errorf("could not read from connection: %w", err)
 	}
 
 	var msg Message
@@ -205,6 +237,18 @@ func (c *Conn) Read() Message {
 		msg = new(NewBlock)
 	case (NewBlockHashes{}).Code():
 		msg = new(NewBlockHashes)
+	case (GetReceipts{}).Code():
+		msg = new(GetReceipts)
+	case (Receipts{}).Code():
+		msg = new(Receipts)
+	case (Transactions{}).Code():
+		msg = new(Transactions)
+	case (NewPooledTransactionHashes{}).Code():
+		msg = new(NewPooledTransactionHashes)
+	case (GetPooledTransactions{}).Code():
+		msg = new(GetPooledTransactions)
+	case (PooledTransactions{}).Code():
+		msg = new(PooledTransactions)
 	default:
 		return errorf("invalid message code: %d", code)
 	}
@@ -304,8 +348,9 @@ func (c *Conn) negotiateEthProtocol(caps []p2p.Cap) {
 }
 
 // statusExchange performs a `Status` message exchange with the given
-// node.
-func (c *Conn) statusExchange(t *utesting.T, chain *Chain) Message {
+// node. Our status is the given one, or the status of the chain if it
+// is nil.
+func (c *Conn) statusExchange(t *utesting.T, chain *Chain, status *Status) Message {
 	defer c.SetDeadline(time.Time{})                //nolint:errcheck
 	c.SetDeadline(time.Now().Add(20 * time.Second)) //nolint:errcheck
 
@@ -341,13 +386,15 @@ loop:
 		t.Fatalf("eth protocol version must be set in Conn")
 	}
 	// write status message to client
-	status := Status{
-		ProtocolVersion: uint32(c.ethProtocolVersion),
-		NetworkID:       chain.chainConfig.ChainID.Uint64(),
-		TD:              chain.TD(chain.Len()),
-		Head:            chain.blocks[chain.Len()-1].Hash(),
-		Genesis:         chain.blocks[0].Hash(),
-		ForkID:          chain.ForkID(),
+	if status == nil {
+		status = &Status{
+			ProtocolVersion: uint32(c.ethProtocolVersion),
+			NetworkID:       chain.chainConfig.ChainID.Uint64(),
+			TD:              chain.TD(chain.Len()),
+			Head:            chain.blocks[chain.Len()-1].Hash(),
+			Genesis:         chain.blocks[0].Hash(),
+			ForkID:          chain.ForkID(),
+		}
 	}
 	if err := c.Write(status); err != nil {
 		t.Fatalf("could not write to connection: %v", err)
@@ -379,3 +426,29 @@ func (c *Conn) waitForBlock(block *types.Block) error {
 		}
 	}
 }
+
+// expectDisconnect waits for the node to drop the connection, either with
+// the `Disconnect` message or by closing it.
+func (c *Conn) expectDisconnect(timeout time.Duration) error {
+	defer c.SetReadDeadline(time.Time{}) //nolint:errcheck
+
+	c.SetReadDeadline(time.Now().Add(timeout)) //nolint:errcheck
+	for {
+		switch msg := c.Read().(type) {
+		case *Disconnect:
+			return nil
+		case *Error:
+			if errors.Unwrap(msg.err) == nil {
+				continue // Message we could not decode, the connection is fine
+			}
+			var netErr net.Error
+			if errors.As(msg, &netErr) && netErr.Timeout() {
+				return fmt.Errorf("not disconnected within %v", timeout)
+			}
+			// The connection is closed
+			return nil
+		case *Ping:
+			c.Write(&Pong{}) //nolint:errcheck
+		}
+	}
+}
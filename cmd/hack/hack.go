@@ -1692,7 +1692,10 @@ func extracHeaders(chaindata string, block uint64) error {
 	defer f.Close()
 	w := bufio.NewWriter(f)
 	defer w.Flush()
-	var hBuffer [headerdownload.HeaderSerLength]byte
+	if err = headerdownload.WriteHeadersFileVersion(w); err != nil {
+		return err
+	}
+	var hBuffer []byte
 	for {
 		hash, err := rawdb.ReadCanonicalHash(db, b)
 		check(err)
@@ -1700,8 +1703,8 @@ func extracHeaders(chaindata string, block uint64) error {
 			break
 		}
 		h := rawdb.ReadHeader(db, hash, b)
-		headerdownload.SerialiseHeader(h, hBuffer[:])
-		if _, err := w.Write(hBuffer[:]); err != nil {
+		hBuffer = headerdownload.AppendHeader(hBuffer[:0], h)
+		if _, err := w.Write(hBuffer); err != nil {
 			return err
 		}
 		b += block
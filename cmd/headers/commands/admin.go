@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var (
+	banDuration time.Duration // Duration of the ban, 0 for the permanent one
+	banReason   string        // Reason of the ban, shown in the list of the bans
+)
+
+func init() {
+	adminCmd.PersistentFlags().StringVar(&sentryAddr, "sentryAddr", "localhost:9091", "sentry address <host>:<port>")
+	banCmd.Flags().DurationVar(&banDuration, "duration", 0, "duration of the ban, permanent if not set")
+	banCmd.Flags().StringVar(&banReason, "reason", "", "reason of the ban")
+	adminCmd.AddCommand(peersCmd, bansCmd, banCmd, unbanCmd)
+	rootCmd.AddCommand(adminCmd)
+}
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Inspect the peers of the running sentry and manage their bans",
+}
+
+var peersCmd = &cobra.Command{
+	Use:   "peers",
+	Short: "List the connected peers with their penalty scores",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(cmd.Context(), func(client proto_sentry.SentryAdminClient) error {
+			reply, err := client.Peers(cmd.Context(), &empty.Empty{})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tADDRESS\tHEIGHT\tSCORE\tSUBNET SCORE\tNAME")
+			for _, peer := range reply.Peers {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%.2f\t%.2f\t%s\n", peer.PeerId, peer.RemoteAddr, peer.Height, peer.Score, peer.SubnetScore, peer.Name)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var bansCmd = &cobra.Command{
+	Use:   "bans",
+	Short: "List the bans in effect",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(cmd.Context(), func(client proto_sentry.SentryAdminClient) error {
+			reply, err := client.Bans(cmd.Context(), &empty.Empty{})
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "TARGET\tUNTIL\tREASON")
+			for _, ban := range reply.Bans {
+				until := "permanent"
+				if ban.Until != 0 {
+					until = time.Unix(int64(ban.Until), 0).Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", ban.Target, until, ban.Reason)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var banCmd = &cobra.Command{
+	Use:   "ban <enode ID|IP|CIDR>",
+	Short: "Ban the peer or the subnet, disconnecting the affected peers",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(cmd.Context(), func(client proto_sentry.SentryAdminClient) error {
+			_, err := client.BanPeer(cmd.Context(), &proto_sentry.BanPeerRequest{
+				Target:   args[0],
+				Duration: uint64(banDuration / time.Second),
+				Reason:   banReason,
+			})
+			return err
+		})
+	},
+}
+
+var unbanCmd = &cobra.Command{
+	Use:   "unban <enode ID|IP|CIDR>",
+	Short: "Lift the ban of the peer or the subnet",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminClient(cmd.Context(), func(client proto_sentry.SentryAdminClient) error {
+			_, err := client.UnbanPeer(cmd.Context(), &proto_sentry.UnbanPeerRequest{Target: args[0]})
+			return err
+		})
+	},
+}
+
+func withAdminClient(ctx context.Context, f func(client proto_sentry.SentryAdminClient) error) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, sentryAddr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("connecting to sentry %s: %w", sentryAddr, err)
+	}
+	defer conn.Close()
+	return f(proto_sentry.NewSentryAdminClient(conn))
+}
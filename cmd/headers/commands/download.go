@@ -2,20 +2,26 @@ package commands
 
 import (
 	"github.com/ledgerwatch/turbo-geth/cmd/headers/download"
+	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
 	"github.com/spf13/cobra"
 )
 
 var (
-	bufferSizeStr string // Size of buffer
-	combined      bool   // Whether downloader also includes sentry
+	bufferSizeStr string   // Size of buffer
+	verifyCaches  int      // Memory budget of the seal verification, in ethash verification caches
+	combined      bool     // Whether downloader also includes sentry
+	withTxPool    bool     // Whether the transactions are gossiped to and from the transaction pool
+	sentryAddrs   []string // Addresses of the sentries the downloader spreads its requests across
 )
 
 func init() {
 	downloadCmd.Flags().StringVar(&filesDir, "filesdir", "", "path to directory where files will be stored")
 	downloadCmd.Flags().StringVar(&bufferSizeStr, "bufferSize", "512M", "size o the buffer")
-	downloadCmd.Flags().StringVar(&sentryAddr, "sentryAddr", "localhost:9091", "sentry address <host>:<port>")
+	downloadCmd.Flags().IntVar(&verifyCaches, "verifyCaches", headerdownload.DefaultVerifyCaches, "memory budget of the parallel seal verification, in ethash verification caches (16MB and more each)")
+	downloadCmd.Flags().StringSliceVar(&sentryAddrs, "sentryAddr", []string{"localhost:9091"}, "comma separated sentry addresses <host>:<port>,<host>:<port>")
 	downloadCmd.Flags().StringVar(&coreAddr, "coreAddr", "localhost:9092", "core address <host>:<port>")
 	downloadCmd.Flags().BoolVar(&combined, "combined", false, "run downloader and sentry in the same process")
+	downloadCmd.Flags().BoolVar(&withTxPool, "txpool", false, "keep the transaction pool, receiving and propagating the transactions via the sentries")
 
 	// Options below are only used in the combined mode
 	downloadCmd.Flags().StringVar(&natSetting, "nat", "any", "NAT port mapping mechanism (any|none|upnp|pmp|extip:<IP>)")
@@ -23,6 +29,7 @@ func init() {
 	downloadCmd.Flags().StringArrayVar(&staticPeers, "staticpeers", []string{}, "static peer list [enode]")
 	downloadCmd.Flags().BoolVar(&discovery, "discovery", true, "discovery mode")
 	downloadCmd.Flags().StringVar(&netRestrict, "netrestrict", "", "CIDR range to accept peers from <CIDR>")
+	downloadCmd.Flags().StringVar(&banFile, "bans", "banned_peers.json", "file to keep the bans of the peers in")
 
 	withChaindata(downloadCmd)
 	withLmdbFlags(downloadCmd)
@@ -36,8 +43,8 @@ var downloadCmd = &cobra.Command{
 		db := openDatabase(chaindata)
 		defer db.Close()
 		if combined {
-			return download.Combined(natSetting, port, staticPeers, discovery, netRestrict, filesDir, bufferSizeStr, db)
+			return download.Combined(natSetting, port, staticPeers, discovery, netRestrict, banFile, filesDir, bufferSizeStr, verifyCaches, withTxPool, db)
 		}
-		return download.Download(filesDir, bufferSizeStr, sentryAddr, coreAddr, db)
+		return download.Download(filesDir, bufferSizeStr, verifyCaches, sentryAddrs, coreAddr, withTxPool, db)
 	},
 }
@@ -11,7 +11,7 @@ var (
 	staticPeers []string // static peers
 	discovery   bool     // enable sentry's discovery mechanism
 	netRestrict string   // CIDR to restrict peering to
-
+	banFile     string   // File keeping the bans of the peers
 )
 
 func init() {
@@ -22,6 +22,7 @@ func init() {
 	sentryCmd.Flags().StringArrayVar(&staticPeers, "staticpeers", []string{}, "static peer list [enode]")
 	sentryCmd.Flags().BoolVar(&discovery, "discovery", true, "discovery mode")
 	sentryCmd.Flags().StringVar(&netRestrict, "netrestrict", "", "CIDR range to accept peers from <CIDR>")
+	sentryCmd.Flags().StringVar(&banFile, "bans", "banned_peers.json", "file to keep the bans of the peers in")
 	rootCmd.AddCommand(sentryCmd)
 }
 
@@ -29,6 +30,6 @@ var sentryCmd = &cobra.Command{
 	Use:   "sentry",
 	Short: "Run p2p sentry for the downloader",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return download.Sentry(natSetting, port, sentryAddr, coreAddr, staticPeers, discovery, netRestrict)
+		return download.Sentry(natSetting, port, sentryAddr, coreAddr, staticPeers, discovery, netRestrict, banFile)
 	},
 }
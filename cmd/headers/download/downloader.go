@@ -1,13 +1,16 @@
 package download
 
 import (
+	"bufio"
 	"context"
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -22,12 +25,20 @@ import (
 	proto_core "github.com/ledgerwatch/turbo-geth/cmd/headers/core"
 	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
 	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/forkid"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/eth"
+	"github.com/ledgerwatch/turbo-geth/eth/fetcher"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/metrics"
+	"github.com/ledgerwatch/turbo-geth/p2p"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 	"github.com/ledgerwatch/turbo-geth/turbo/stages"
@@ -38,16 +49,6 @@ import (
 	"google.golang.org/grpc/keepalive"
 )
 
-type chainReader struct {
-	config *params.ChainConfig
-}
-
-func (cr chainReader) Config() *params.ChainConfig                             { return cr.config }
-func (cr chainReader) CurrentHeader() *types.Header                            { panic("") }
-func (cr chainReader) GetHeader(hash common.Hash, number uint64) *types.Header { panic("") }
-func (cr chainReader) GetHeaderByNumber(number uint64) *types.Header           { panic("") }
-func (cr chainReader) GetHeaderByHash(hash common.Hash) *types.Header          { panic("") }
-
 //nolint:interfacer
 func processSegment(lock *sync.Mutex, hd *headerdownload.HeaderDownload, segment *headerdownload.ChainSegment) {
 	lock.Lock()
@@ -120,7 +121,7 @@ func processSegment(lock *sync.Mutex, hd *headerdownload.HeaderDownload, segment
 	}
 }
 
-func grpcSentryClient(ctx context.Context, sentryAddr string) (proto_sentry.SentryClient, error) {
+func grpcSentryClient(ctx context.Context, sentryAddr string) (proto_sentry.SentryClient, proto_sentry.SentryAdminClient, error) {
 	log.Info("Starting Sentry client", "connecting to sentry", sentryAddr)
 	// CREATING GRPC CLIENT CONNECTION
 	var dialOpts []grpc.DialOption
@@ -136,12 +137,12 @@ func grpcSentryClient(ctx context.Context, sentryAddr string) (proto_sentry.Sent
 
 	conn, err := grpc.DialContext(ctx, sentryAddr, dialOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
+		return nil, nil, fmt.Errorf("creating client connection to sentry P2P: %w", err)
 	}
-	return proto_sentry.NewSentryClient(conn), nil
+	return proto_sentry.NewSentryClient(conn), proto_sentry.NewSentryAdminClient(conn), nil
 }
 
-func grpcControlServer(ctx context.Context, coreAddr string, sentryClient proto_sentry.SentryClient, filesDir string, bufferSizeStr string, db ethdb.Database) (*ControlServerImpl, error) {
+func grpcControlServer(ctx context.Context, coreAddr string, sentries *SentryPool, filesDir string, bufferSizeStr string, verifyCaches int, db ethdb.Database) (*ControlServerImpl, error) {
 	log.Info("Starting Core P2P server", "on", coreAddr)
 
 	listenConfig := net.ListenConfig{
@@ -185,7 +186,7 @@ func grpcControlServer(ctx context.Context, coreAddr string, sentryClient proto_
 	}
 	var controlServer *ControlServerImpl
 
-	if controlServer, err = NewControlServer(db, filesDir, int(bufferSize), sentryClient); err != nil {
+	if controlServer, err = NewControlServer(db, filesDir, int(bufferSize), verifyCaches, sentries); err != nil {
 		return nil, fmt.Errorf("create core P2P server: %w", err)
 	}
 	proto_core.RegisterControlServer(grpcServer, controlServer)
@@ -201,22 +202,39 @@ func grpcControlServer(ctx context.Context, coreAddr string, sentryClient proto_
 	return controlServer, nil
 }
 
-// Download creates and starts standalone downloader
-func Download(filesDir string, bufferSizeStr string, sentryAddr string, coreAddr string, db ethdb.Database) error {
+// Download creates and starts standalone downloader, connected to one or more sentries
+func Download(filesDir string, bufferSizeStr string, verifyCaches int, sentryAddrs []string, coreAddr string, withTxPool bool, db ethdb.Database) error {
 	ctx := rootContext()
 
-	sentryClient, err1 := grpcSentryClient(ctx, sentryAddr)
-	if err1 != nil {
-		return err1
+	if len(sentryAddrs) == 0 {
+		return fmt.Errorf("no sentry addresses given")
+	}
+	clients := make([]proto_sentry.SentryClient, len(sentryAddrs))
+	admins := make([]proto_sentry.SentryAdminClient, len(sentryAddrs))
+	for i, sentryAddr := range sentryAddrs {
+		var err1 error
+		if clients[i], admins[i], err1 = grpcSentryClient(ctx, sentryAddr); err1 != nil {
+			return err1
+		}
 	}
-	controlServer, err2 := grpcControlServer(ctx, coreAddr, sentryClient, filesDir, bufferSizeStr, db)
+	sentries := NewSentryPool(sentryAddrs, clients, admins)
+	go sentries.healthLoop(ctx)
+	controlServer, err2 := grpcControlServer(ctx, coreAddr, sentries, filesDir, bufferSizeStr, verifyCaches, db)
 	if err2 != nil {
 		return err2
 	}
+	if withTxPool {
+		txPool, err := newTxPool(db, controlServer.chainConfig, core.DefaultTxPoolConfig.Journal)
+		if err != nil {
+			return err
+		}
+		defer txPool.Stop()
+		controlServer.StartTxPool(ctx, txPool)
+	}
 	go controlServer.headerLoop(ctx)
 	go controlServer.bodyLoop(ctx, db)
 
-	if err := stages.StageLoop(ctx, db, controlServer.hd, controlServer.bd, controlServer.requestWakeUpBodies); err != nil {
+	if err := stages.StageLoop(ctx, db, controlServer.hd, controlServer.bd, controlServer.engine, controlServer.requestWakeUpBodies); err != nil {
 		log.Error("Stage loop failure", "error", err)
 	}
 
@@ -224,11 +242,15 @@ func Download(filesDir string, bufferSizeStr string, sentryAddr string, coreAddr
 }
 
 // Combined creates and starts sentry and downloader in the same process
-func Combined(natSetting string, port int, staticPeers []string, discovery bool, netRestrict string, filesDir string, bufferSizeStr string, db ethdb.Database) error {
+func Combined(natSetting string, port int, staticPeers []string, discovery bool, netRestrict string, banFile string, filesDir string, bufferSizeStr string, verifyCaches int, withTxPool bool, db ethdb.Database) error {
 	ctx := rootContext()
 
+	scores, err := newPeerScores(banFile)
+	if err != nil {
+		return err
+	}
 	coreClient := &ControlClientDirect{}
-	sentryServer := &SentryServerImpl{}
+	sentryServer := &SentryServerImpl{scores: scores}
 	server, err1 := p2pServer(ctx, coreClient, sentryServer, natSetting, port, staticPeers, discovery, netRestrict)
 	if err1 != nil {
 		return err1
@@ -239,7 +261,8 @@ func Combined(natSetting string, port int, staticPeers []string, discovery bool,
 	if err := bufferSize.UnmarshalText([]byte(bufferSizeStr)); err != nil {
 		return fmt.Errorf("parsing bufferSize %s: %w", bufferSizeStr, err)
 	}
-	controlServer, err2 := NewControlServer(db, filesDir, int(bufferSize), sentryClient)
+	sentries := NewSentryPool([]string{"direct"}, []proto_sentry.SentryClient{sentryClient}, nil)
+	controlServer, err2 := NewControlServer(db, filesDir, int(bufferSize), verifyCaches, sentries)
 	if err2 != nil {
 		return fmt.Errorf("create core P2P server: %w", err2)
 	}
@@ -247,41 +270,135 @@ func Combined(natSetting string, port int, staticPeers []string, discovery bool,
 	if err := server.Start(); err != nil {
 		return fmt.Errorf("could not start server: %w", err)
 	}
+	if withTxPool {
+		txPool, err := newTxPool(db, controlServer.chainConfig, core.DefaultTxPoolConfig.Journal)
+		if err != nil {
+			return err
+		}
+		defer txPool.Stop()
+		controlServer.StartTxPool(ctx, txPool)
+	}
 	go controlServer.headerLoop(ctx)
 	go controlServer.bodyLoop(ctx, db)
 
-	if err := stages.StageLoop(ctx, db, controlServer.hd, controlServer.bd, controlServer.requestWakeUpBodies); err != nil {
+	if err := stages.StageLoop(ctx, db, controlServer.hd, controlServer.bd, controlServer.engine, controlServer.requestWakeUpBodies); err != nil {
 		log.Error("Stage loop failure", "error", err)
 	}
 	return nil
 }
 
+// ServeChain starts the sentry and the core in the same process, serving the chain in the database to the peers
+// connecting to the listen address, without discovery and without syncing. It is used for running the eth protocol
+// conformance tests against the sentry. The returned server is stopped by the caller
+func ServeChain(ctx context.Context, key *ecdsa.PrivateKey, listenAddr string, filesDir string, withTxPool bool, db ethdb.Database) (*p2p.Server, error) {
+	scores, err := newPeerScores(filepath.Join(filesDir, "bans.json"))
+	if err != nil {
+		return nil, err
+	}
+	coreClient := &ControlClientDirect{}
+	sentryServer := &SentryServerImpl{scores: scores}
+	server, err := makeP2PServer(
+		ctx,
+		key,
+		listenAddr,
+		nil, /* dialCandidates */
+		&sentryServer.peerHeightMap,
+		&sentryServer.peerTimeMap,
+		&sentryServer.peerRwMap,
+		&sentryServer.peerVersionMap,
+		&sentryServer.peerMap,
+		&sentryServer.peerTxsMap,
+		sentryServer.scores,
+		[]string{eth.ProtocolName},
+		coreClient,
+	)
+	if err != nil {
+		return nil, err
+	}
+	server.NoDiscovery = true
+	sentryClient := &SentryClientDirect{}
+	sentryClient.SetServer(sentryServer)
+	sentries := NewSentryPool([]string{"direct"}, []proto_sentry.SentryClient{sentryClient}, nil)
+	controlServer, err := NewControlServer(db, filesDir, 0 /* bufferSize */, headerdownload.DefaultVerifyCaches, sentries)
+	if err != nil {
+		return nil, fmt.Errorf("create core P2P server: %w", err)
+	}
+	coreClient.SetServer(controlServer)
+	if withTxPool {
+		txPool, err := newTxPool(db, controlServer.chainConfig, filepath.Join(filesDir, "transactions.rlp"))
+		if err != nil {
+			return nil, err
+		}
+		controlServer.StartTxPool(ctx, txPool)
+		go func() {
+			<-ctx.Done()
+			txPool.Stop()
+		}()
+	}
+	if err := server.Start(); err != nil {
+		return nil, fmt.Errorf("could not start server: %w", err)
+	}
+	return server, nil
+}
+
 type ControlServerImpl struct {
 	proto_core.UnimplementedControlServer
 	lock                 sync.Mutex
 	hd                   *headerdownload.HeaderDownload
 	bd                   *bodydownload.BodyDownload
-	sentryClient         proto_sentry.SentryClient
+	engine               consensus.Engine // Verifies the headers in the Headers stage
+	sentries             *SentryPool
+	db                   ethdb.Database
+	chainConfig          *params.ChainConfig
+	genesisHash          common.Hash
+	forks                []uint64 // Block numbers of the forks, for the fork ID in the handshakes of the sentries
 	requestWakeUpHeaders chan struct{}
 	requestWakeUpBodies  chan struct{}
+	txPool               *core.TxPool       // Nil if the core does not keep the transactions
+	txFetcher            *fetcher.TxFetcher // Fetcher of the announced transactions, nil without the pool
+	txRequestID          uint64             // Last request ID of the pooled transactions requests
 }
 
-func NewControlServer(db ethdb.Database, filesDir string, bufferSize int, sentryClient proto_sentry.SentryClient) (*ControlServerImpl, error) {
-	//config := eth.DefaultConfig.Ethash
-	engine := ethash.New(ethash.Config{
-		CachesInMem:      1,
-		CachesLockMmap:   false,
-		DatasetDir:       "ethash",
-		DatasetsInMem:    1,
-		DatasetsOnDisk:   0,
-		DatasetsLockMmap: false,
-	}, nil, false)
-	cr := chainReader{config: params.MainnetChainConfig}
-	calcDiffFunc := func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int {
-		return engine.CalcDifficulty(cr, childTimestamp, parentTime, parentDifficulty, parentNumber, parentHash, parentUncleHash)
-	}
-	verifySealFunc := func(header *types.Header) error {
-		return engine.VerifySeal(cr, header)
+// NewControlServer creates the core over the database. The seals are verified within the memory budget of the given
+// number of ethash verification caches, see headerdownload.VerifyBudget
+func NewControlServer(db ethdb.Database, filesDir string, bufferSize int, verifyCaches int, sentries *SentryPool) (*ControlServerImpl, error) {
+	if verifyCaches < headerdownload.MinVerifyCaches {
+		return nil, fmt.Errorf("seal verification needs the budget of at least %d caches, got %d", headerdownload.MinVerifyCaches, verifyCaches)
+	}
+	// The chain of the genesis block in the database is served to the peers, the main-net one if there is none yet
+	chainConfig, genesisHash, _, err := core.SetupGenesisBlock(db, nil, false /* history */, false /* overwrite */)
+	if err != nil {
+		return nil, fmt.Errorf("setup genesis block: %w", err)
+	}
+	var engine consensus.Engine
+	var calcDiffFunc headerdownload.CalcDifficultyFunc
+	var verifySealFunc headerdownload.VerifySealFunc
+	verifyThreads, cachesInMem, cachesAhead := headerdownload.VerifyBudget(verifyCaches)
+	if chainConfig.Clique != nil {
+		// The clique difficulties and seals depend on the snapshots, the headers are only verified by the Headers stage.
+		// The snapshots are kept apart from the database, which is locked by the stage while it verifies the headers
+		engine = clique.New(chainConfig.Clique, ethdb.NewMemDatabase())
+	} else {
+		// The seals are verified in parallel, while the caches for the next epochs are generated in the background
+		ethashEngine := ethash.New(ethash.Config{
+			CacheDir:         "ethash",
+			CachesInMem:      cachesInMem,
+			CachesOnDisk:     3,
+			CachesAhead:      cachesAhead,
+			CachesLockMmap:   false,
+			DatasetDir:       "ethash",
+			DatasetsInMem:    1,
+			DatasetsOnDisk:   0,
+			DatasetsLockMmap: false,
+		}, nil, false)
+		cr := stagedsync.NewChainReader(chainConfig, db)
+		calcDiffFunc = func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int {
+			return ethashEngine.CalcDifficulty(cr, childTimestamp, parentTime, parentDifficulty, parentNumber, parentHash, parentUncleHash)
+		}
+		verifySealFunc = func(header *types.Header) error {
+			return ethashEngine.VerifySeal(cr, header)
+		}
+		engine = ethashEngine
 	}
 	hd := headerdownload.NewHeaderDownload(
 		common.Hash{}, /* initialHash */
@@ -294,6 +411,7 @@ func NewControlServer(db ethdb.Database, filesDir string, bufferSize int, sentry
 		3600, /* newAnchor future limit */
 		3600, /* newAnchor past limit */
 	)
+	hd.SetVerifyThreads(verifyThreads)
 	dbRecovered, err := hd.RecoverFromDb(db, uint64(time.Now().Unix()))
 	if err != nil {
 		log.Error("Recovery from DB failed", "error", err)
@@ -307,24 +425,26 @@ func NewControlServer(db ethdb.Database, filesDir string, bufferSize int, sentry
 		// Insert hard-coded headers if present
 		if _, err := os.Stat("hard-coded-headers.dat"); err == nil {
 			if f, err1 := os.Open("hard-coded-headers.dat"); err1 == nil {
-				var hBuffer [headerdownload.HeaderSerLength]byte
-				i := 0
-				for {
-					var h types.Header
-					if _, err2 := io.ReadFull(f, hBuffer[:]); err2 == nil {
-						headerdownload.DeserialiseHeader(&h, hBuffer[:])
-					} else if errors.Is(err2, io.EOF) {
-						break
-					} else {
-						log.Error("Failed to read hard coded header", "i", i, "error", err2)
-						break
-					}
-					if err2 := hd.HardCodedHeader(&h, uint64(time.Now().Unix())); err2 != nil {
-						log.Error("Failed to insert hard coded header", "i", i, "block", h.Number.Uint64(), "error", err2)
-					} else {
-						hd.AddHeaderToBuffer(&h)
+				r := bufio.NewReader(f)
+				if err2 := headerdownload.ReadHeadersFileVersion(r); err2 != nil {
+					log.Error("Failed to read hard coded headers", "error", err2)
+				} else {
+					i := 0
+					for {
+						var h types.Header
+						if err2 := headerdownload.ReadHeader(r, &h); err2 != nil {
+							if !errors.Is(err2, io.EOF) {
+								log.Error("Failed to read hard coded header", "i", i, "error", err2)
+							}
+							break
+						}
+						if err2 := hd.HardCodedHeader(&h, uint64(time.Now().Unix())); err2 != nil {
+							log.Error("Failed to insert hard coded header", "i", i, "block", h.Number.Uint64(), "error", err2)
+						} else {
+							hd.AddHeaderToBuffer(&h)
+						}
+						i++
 					}
-					i++
 				}
 			}
 		}
@@ -334,7 +454,18 @@ func NewControlServer(db ethdb.Database, filesDir string, bufferSize int, sentry
 	if err := bd.UpdateFromDb(db); err != nil {
 		return nil, err
 	}
-	return &ControlServerImpl{hd: hd, bd: bd, sentryClient: sentryClient, requestWakeUpHeaders: make(chan struct{}), requestWakeUpBodies: make(chan struct{})}, nil
+	return &ControlServerImpl{
+		hd:                   hd,
+		bd:                   bd,
+		engine:               engine,
+		sentries:             sentries,
+		db:                   db,
+		chainConfig:          chainConfig,
+		genesisHash:          genesisHash,
+		forks:                forkid.GatherForks(chainConfig),
+		requestWakeUpHeaders: make(chan struct{}),
+		requestWakeUpBodies:  make(chan struct{}),
+	}, nil
 }
 
 func (cs *ControlServerImpl) newBlockHashes(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
@@ -344,7 +475,9 @@ func (cs *ControlServerImpl) newBlockHashes(ctx context.Context, inreq *proto_co
 	}
 	for _, announce := range request {
 		if !cs.hd.HasTip(announce.Hash) {
-			log.Info(fmt.Sprintf("Sending header request {hash: %x, height: %d, length: %d}", announce.Hash, announce.Number, 1))
+			req := &headerdownload.HeaderRequest{Hash: announce.Hash, Number: announce.Number, Length: 1}
+			cs.hd.AddHeaderRequest(req)
+			log.Info(fmt.Sprintf("Sending header request {id: %d, hash: %x, height: %d, length: %d}", req.ID, announce.Hash, announce.Number, 1))
 			bytes, err := rlp.EncodeToBytes(&eth.GetBlockHeadersData{
 				Amount:  1,
 				Reverse: false,
@@ -357,11 +490,12 @@ func (cs *ControlServerImpl) newBlockHashes(ctx context.Context, inreq *proto_co
 			outreq := proto_sentry.SendMessageByMinBlockRequest{
 				MinBlock: announce.Number,
 				Data: &proto_sentry.OutboundMessageData{
-					Id:   proto_sentry.OutboundMessageId_GetBlockHeaders,
-					Data: bytes,
+					Id:        proto_sentry.OutboundMessageId_GetBlockHeaders,
+					Data:      bytes,
+					RequestId: req.ID,
 				},
 			}
-			_, err = cs.sentryClient.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
+			_, err = cs.sentries.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
 			if err != nil {
 				return nil, fmt.Errorf("send header request: %v", err)
 			}
@@ -370,11 +504,46 @@ func (cs *ControlServerImpl) newBlockHashes(ctx context.Context, inreq *proto_co
 	return &empty.Empty{}, nil
 }
 
+// penaltyKind converts the penalty of the header download into the penalty the sentry scores the peer with
+func penaltyKind(penalty headerdownload.Penalty) proto_sentry.PenaltyKind {
+	switch penalty {
+	case headerdownload.BadBlockPenalty:
+		return proto_sentry.PenaltyKind_BadBlock
+	case headerdownload.DuplicateHeaderPenalty:
+		return proto_sentry.PenaltyKind_DuplicateHeader
+	case headerdownload.WrongChildBlockHeightPenalty:
+		return proto_sentry.PenaltyKind_WrongChildBlockHeight
+	case headerdownload.WrongChildDifficultyPenalty:
+		return proto_sentry.PenaltyKind_WrongChildDifficulty
+	case headerdownload.InvalidSealPenalty:
+		return proto_sentry.PenaltyKind_InvalidSeal
+	case headerdownload.TooFarFuturePenalty:
+		return proto_sentry.PenaltyKind_TooFarFuture
+	case headerdownload.TooFarPastPenalty:
+		return proto_sentry.PenaltyKind_TooFarPast
+	case headerdownload.UnrequestedHeadersPenalty:
+		return proto_sentry.PenaltyKind_UnrequestedHeaders
+	default:
+		return proto_sentry.PenaltyKind_Kick
+	}
+}
+
 func (cs *ControlServerImpl) blockHeaders(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
 	var request []*types.Header
 	if err := rlp.DecodeBytes(inreq.Data, &request); err != nil {
 		return nil, fmt.Errorf("decode BlockHeaders: %v", err)
 	}
+	if penalty := cs.hd.MatchHeaderResponse(inreq.RequestId, request); penalty != headerdownload.NoPenalty {
+		log.Warn("Headers do not match the request", "requestID", inreq.RequestId, "penalty", penalty)
+		outreq := proto_sentry.PenalizePeerRequest{
+			PeerId:  inreq.PeerId,
+			Penalty: penaltyKind(penalty),
+		}
+		if _, err1 := cs.sentries.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+			log.Error("Could not send penalty", "err", err1)
+		}
+		return &empty.Empty{}, nil
+	}
 	if segments, penalty, err := cs.hd.SplitIntoSegments(request); err == nil {
 		if penalty == headerdownload.NoPenalty {
 			for _, segment := range segments {
@@ -383,9 +552,9 @@ func (cs *ControlServerImpl) blockHeaders(ctx context.Context, inreq *proto_core
 		} else {
 			outreq := proto_sentry.PenalizePeerRequest{
 				PeerId:  inreq.PeerId,
-				Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
+				Penalty: penaltyKind(penalty),
 			}
-			if _, err1 := cs.sentryClient.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+			if _, err1 := cs.sentries.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
 				log.Error("Could not send penalty", "err", err1)
 			}
 		}
@@ -407,9 +576,9 @@ func (cs *ControlServerImpl) newBlock(ctx context.Context, inreq *proto_core.Inb
 		} else {
 			outreq := proto_sentry.PenalizePeerRequest{
 				PeerId:  inreq.PeerId,
-				Penalty: proto_sentry.PenaltyKind_Kick, // TODO: Extend penalty kinds
+				Penalty: penaltyKind(penalty),
 			}
-			if _, err1 := cs.sentryClient.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
+			if _, err1 := cs.sentries.PenalizePeer(ctx, &outreq, &grpc.EmptyCallOption{}); err1 != nil {
 				log.Error("Could not send penalty", "err", err1)
 			}
 		}
@@ -426,19 +595,15 @@ func (cs *ControlServerImpl) blockBodies(inreq *proto_core.InboundMessage) (*emp
 		return nil, fmt.Errorf("decode BlockBodies: %v", err)
 	}
 	var sb strings.Builder
-	var unrequested int
-	for _, body := range request {
-		if blockNum, ok := cs.bd.DeliverBody(body); ok {
-			if sb.Len() > 0 {
-				fmt.Fprintf(&sb, ",")
-			}
-			fmt.Fprintf(&sb, "%d", blockNum)
-		} else {
-			unrequested++
+	delivered, unrequested := cs.bd.DeliverBodies(inreq.RequestId, request)
+	for _, blockNum := range delivered {
+		if sb.Len() > 0 {
+			fmt.Fprintf(&sb, ",")
 		}
+		fmt.Fprintf(&sb, "%d", blockNum)
 	}
 	cs.bd.FeedDeliveries()
-	//log.Info(fmt.Sprintf("BlockBodies{delivered=%s, unrequestedCount=%d}", sb.String(), unrequested))
+	log.Trace(fmt.Sprintf("BlockBodies{delivered=%s, unrequestedCount=%d}", sb.String(), unrequested), "requestID", inreq.RequestId)
 	return &empty.Empty{}, nil
 }
 
@@ -462,18 +627,50 @@ func (cs *ControlServerImpl) ForwardInboundMessage(ctx context.Context, inreq *p
 		return cs.newBlock(ctx, inreq)
 	case proto_core.InboundMessageId_BlockBodies:
 		return cs.blockBodies(inreq)
+	case proto_core.InboundMessageId_NewPooledTransactionHashes:
+		return cs.newPooledTransactionHashes(inreq)
+	case proto_core.InboundMessageId_GetPooledTransactions:
+		return cs.getPooledTransactions(ctx, inreq)
+	case proto_core.InboundMessageId_Transactions, proto_core.InboundMessageId_PooledTransactions:
+		return cs.transactions(inreq)
+	case proto_core.InboundMessageId_GetBlockHeaders:
+		return cs.getBlockHeaders(ctx, inreq)
+	case proto_core.InboundMessageId_GetBlockBodies:
+		return cs.getBlockBodies(ctx, inreq)
+	case proto_core.InboundMessageId_GetReceipts:
+		return cs.getReceipts(ctx, inreq)
 	default:
 		return nil, fmt.Errorf("not implemented for message Id: %s", inreq.Id)
 	}
 }
 
+// GetStatus reports the head of the chain, which the sentries use in the handshakes with their peers, and the health of the sentries
 func (cs *ControlServerImpl) GetStatus(context.Context, *empty.Empty) (*proto_core.StatusData, error) {
-	return nil, nil
+	headHash := rawdb.ReadHeadHeaderHash(cs.db)
+	headNumber := rawdb.ReadHeaderNumber(cs.db, headHash)
+	if headNumber == nil {
+		return nil, errors.New("head header is not found")
+	}
+	td, err := rawdb.ReadTd(cs.db, headHash, *headNumber)
+	if err != nil {
+		return nil, err
+	}
+	if td == nil {
+		return nil, fmt.Errorf("total difficulty of head header %d is not found", *headNumber)
+	}
+	return &proto_core.StatusData{
+		NetworkId:       cs.chainConfig.ChainID.Uint64(),
+		TotalDifficulty: td.Bytes(),
+		BestHash:        headHash.Bytes(),
+		MaxBlock:        *headNumber,
+		ForkData:        &proto_core.Forks{Genesis: cs.genesisHash.Bytes(), Forks: cs.forks},
+		Sentries:        cs.sentries.Status(),
+	}, nil
 }
 
 func (cs *ControlServerImpl) sendRequests(ctx context.Context, reqs []*headerdownload.HeaderRequest) {
 	for _, req := range reqs {
-		log.Debug(fmt.Sprintf("Sending header request {hash: %x, height: %d, length: %d}", req.Hash, req.Number, req.Length))
+		log.Debug(fmt.Sprintf("Sending header request {id: %d, hash: %x, height: %d, length: %d}", req.ID, req.Hash, req.Number, req.Length))
 		bytes, err := rlp.EncodeToBytes(&eth.GetBlockHeadersData{
 			Amount:  uint64(req.Length),
 			Reverse: true,
@@ -487,11 +684,12 @@ func (cs *ControlServerImpl) sendRequests(ctx context.Context, reqs []*headerdow
 		outreq := proto_sentry.SendMessageByMinBlockRequest{
 			MinBlock: req.Number,
 			Data: &proto_sentry.OutboundMessageData{
-				Id:   proto_sentry.OutboundMessageId_GetBlockHeaders,
-				Data: bytes,
+				Id:        proto_sentry.OutboundMessageId_GetBlockHeaders,
+				Data:      bytes,
+				RequestId: req.ID,
 			},
 		}
-		_, err = cs.sentryClient.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
+		_, err = cs.sentries.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
 		if err != nil {
 			log.Error("Could not send header request", "err", err)
 			continue
@@ -511,11 +709,12 @@ func (cs *ControlServerImpl) sendBodyRequest(ctx context.Context, req *bodydownl
 	outreq := proto_sentry.SendMessageByMinBlockRequest{
 		MinBlock: req.BlockNums[len(req.BlockNums)-1],
 		Data: &proto_sentry.OutboundMessageData{
-			Id:   proto_sentry.OutboundMessageId_GetBlockBodies,
-			Data: bytes,
+			Id:        proto_sentry.OutboundMessageId_GetBlockBodies,
+			Data:      bytes,
+			RequestId: req.ID,
 		},
 	}
-	sentPeers, err1 := cs.sentryClient.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
+	sentPeers, err1 := cs.sentries.SendMessageByMinBlock(ctx, &outreq, &grpc.EmptyCallOption{})
 	if err1 != nil {
 		log.Error("Could not send block bodies request", "err", err1)
 	}
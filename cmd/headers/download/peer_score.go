@@ -0,0 +1,332 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+)
+
+const (
+	scoreHalfLife      = 30 * time.Minute // Penalty scores halve every this period
+	peerBanThreshold   = 100              // Score of the peer which gets it banned
+	subnetBanThreshold = 300              // Score of the subnet which gets it banned
+	tempBanDuration    = time.Hour        // Duration of the automatic bans
+	maxTempBans        = 3                // Number of the automatic bans after which the next one is permanent
+	banRetention       = 24 * time.Hour   // How long the expired bans are kept to count the strikes
+	pruneInterval      = scoreHalfLife    // How often the expired bans and the decayed scores are dropped
+	minScore           = 1                // Scores decayed below this are forgotten
+)
+
+// penaltyScores are the scores added for each kind of penalty. Kicks disconnect the peer
+// without affecting its reputation much
+var penaltyScores = map[proto_sentry.PenaltyKind]float64{
+	proto_sentry.PenaltyKind_Kick:                  10,
+	proto_sentry.PenaltyKind_BadBlock:              100,
+	proto_sentry.PenaltyKind_DuplicateHeader:       20,
+	proto_sentry.PenaltyKind_WrongChildBlockHeight: 100,
+	proto_sentry.PenaltyKind_WrongChildDifficulty:  100,
+	proto_sentry.PenaltyKind_InvalidSeal:           100,
+	proto_sentry.PenaltyKind_TooFarFuture:          50,
+	proto_sentry.PenaltyKind_TooFarPast:            20,
+	proto_sentry.PenaltyKind_UnrequestedHeaders:    20,
+}
+
+// score is the penalty score decaying exponentially over time
+type score struct {
+	value   float64
+	updated time.Time
+}
+
+func (s *score) at(now time.Time) float64 {
+	return s.value * math.Pow(0.5, float64(now.Sub(s.updated))/float64(scoreHalfLife))
+}
+
+func (s *score) add(now time.Time, v float64) float64 {
+	s.value, s.updated = s.at(now)+v, now
+	return s.value
+}
+
+// ban of the peer (by its enode ID) or of the subnet
+type ban struct {
+	Target    string `json:"target"`
+	Until     int64  `json:"until"` // Unix time, meaningless for the permanent bans
+	Permanent bool   `json:"permanent"`
+	Reason    string `json:"reason"`
+	Strikes   int    `json:"strikes"` // Number of the automatic bans so far, expired ones within banRetention included
+}
+
+func (b *ban) active(now time.Time) bool {
+	return b.Permanent || b.Until > now.Unix()
+}
+
+// retained tells whether the ban is still kept, to count the strikes after it has expired
+func (b *ban) retained(now time.Time) bool {
+	return b.Permanent || b.Until > now.Add(-banRetention).Unix()
+}
+
+// peerScores keeps the penalty scores of the peers and their subnets, and the bans, which
+// are persisted in the file to survive the restarts
+type peerScores struct {
+	lock    sync.Mutex
+	peers   map[string]*score // Scores by enode ID
+	subnets map[string]*score // Scores by subnet
+	bans    map[string]*ban   // Bans by enode ID or subnet, including the ones expired within banRetention
+	banFile string
+	now     func() time.Time
+	pruned  time.Time // When the expired bans and the decayed scores were last dropped
+}
+
+func newPeerScores(banFile string) (*peerScores, error) {
+	ps := &peerScores{
+		peers:   make(map[string]*score),
+		subnets: make(map[string]*score),
+		bans:    make(map[string]*ban),
+		banFile: banFile,
+		now:     time.Now,
+	}
+	data, err := ioutil.ReadFile(banFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading bans: %w", err)
+	}
+	var bans []*ban
+	if err = json.Unmarshal(data, &bans); err != nil {
+		return nil, fmt.Errorf("parsing bans from %s: %w", banFile, err)
+	}
+	for _, b := range bans {
+		ps.bans[b.Target] = b
+	}
+	return ps, nil
+}
+
+// prune drops the bans expired longer than banRetention ago and the scores which have decayed, so that
+// the peers changing their enode IDs do not grow them without limit
+func (ps *peerScores) prune(now time.Time) {
+	for target, b := range ps.bans {
+		if !b.retained(now) {
+			delete(ps.bans, target)
+		}
+	}
+	for peerID, s := range ps.peers {
+		if s.at(now) < minScore {
+			delete(ps.peers, peerID)
+		}
+	}
+	for sn, s := range ps.subnets {
+		if s.at(now) < minScore {
+			delete(ps.subnets, sn)
+		}
+	}
+	ps.pruned = now
+}
+
+// subnet returns the /24 subnet for IPv4 addresses and the /64 subnet for IPv6 ones
+func subnet(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// parseBanTarget normalises the target of the manual ban: enode ID, IP address or subnet
+func parseBanTarget(target string) (string, error) {
+	if id, err := enode.ParseID(target); err == nil {
+		return id.String(), nil
+	}
+	if ip := net.ParseIP(target); ip != nil {
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String(), nil
+	}
+	if _, ipNet, err := net.ParseCIDR(target); err == nil {
+		return ipNet.String(), nil
+	}
+	return "", fmt.Errorf("invalid ban target %q, expected enode ID, IP address or subnet", target)
+}
+
+// banned checks whether the peer with the given ID and IP address is banned by its ID or by
+// any of the subnets containing its address
+func (ps *peerScores) banned(peerID string, ip net.IP) bool {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	now := ps.now()
+	for target, b := range ps.bans {
+		if !b.active(now) {
+			continue
+		}
+		if target == peerID {
+			return true
+		}
+		if ip == nil {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(target); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// penalize adds the score of the penalty to the peer and its subnet, banning them once the scores
+// reach the thresholds. Returns whether the peer got banned
+func (ps *peerScores) penalize(peerID string, ip net.IP, kind proto_sentry.PenaltyKind) (bool, error) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	now := ps.now()
+	if now.Sub(ps.pruned) >= pruneInterval {
+		ps.prune(now)
+	}
+	v, ok := penaltyScores[kind]
+	if !ok {
+		v = penaltyScores[proto_sentry.PenaltyKind_Kick]
+	}
+	var banned bool
+	s, ok := ps.peers[peerID]
+	if !ok {
+		s = &score{}
+		ps.peers[peerID] = s
+	}
+	if s.add(now, v) >= peerBanThreshold {
+		ps.autoBan(peerID, now, fmt.Sprintf("score reached %d, last penalty %s", peerBanThreshold, kind))
+		delete(ps.peers, peerID)
+		banned = true
+	}
+	if sn := subnet(ip); sn != "" {
+		s, ok := ps.subnets[sn]
+		if !ok {
+			s = &score{}
+			ps.subnets[sn] = s
+		}
+		if s.add(now, v) >= subnetBanThreshold {
+			ps.autoBan(sn, now, fmt.Sprintf("score reached %d, last penalty %s", subnetBanThreshold, kind))
+			delete(ps.subnets, sn)
+			banned = true
+		}
+	}
+	if !banned {
+		return false, nil
+	}
+	return true, ps.save()
+}
+
+func (ps *peerScores) autoBan(target string, now time.Time, reason string) {
+	b, ok := ps.bans[target]
+	if !ok {
+		b = &ban{Target: target}
+		ps.bans[target] = b
+	}
+	b.Strikes++
+	b.Reason = reason
+	if b.Strikes > maxTempBans {
+		b.Permanent = true
+	} else {
+		b.Until = now.Add(tempBanDuration).Unix()
+	}
+	log.Warn("Banned peer", "target", target, "permanent", b.Permanent, "reason", reason)
+}
+
+// ban bans the target manually. Zero duration means the permanent ban
+func (ps *peerScores) ban(target string, duration time.Duration, reason string) error {
+	target, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	b, ok := ps.bans[target]
+	if !ok {
+		b = &ban{Target: target}
+		ps.bans[target] = b
+	}
+	b.Reason = reason
+	b.Permanent = duration == 0
+	b.Until = ps.now().Add(duration).Unix()
+	return ps.save()
+}
+
+// unban lifts the ban of the target and forgets its score. Returns false if the target was not banned
+func (ps *peerScores) unban(target string) (bool, error) {
+	target, err := parseBanTarget(target)
+	if err != nil {
+		return false, err
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	if _, ok := ps.bans[target]; !ok {
+		return false, nil
+	}
+	delete(ps.bans, target)
+	delete(ps.peers, target)
+	delete(ps.subnets, target)
+	return true, ps.save()
+}
+
+// scores returns the current scores of the peer and its subnet
+func (ps *peerScores) scores(peerID string, ip net.IP) (float64, float64) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	now := ps.now()
+	var peerScore, subnetScore float64
+	if s, ok := ps.peers[peerID]; ok {
+		peerScore = s.at(now)
+	}
+	if s, ok := ps.subnets[subnet(ip)]; ok {
+		subnetScore = s.at(now)
+	}
+	return peerScore, subnetScore
+}
+
+// activeBans returns the bans in effect, sorted by target
+func (ps *peerScores) activeBans() []ban {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	now := ps.now()
+	var bans []ban
+	for _, b := range ps.bans {
+		if b.active(now) {
+			bans = append(bans, *b)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Target < bans[j].Target })
+	return bans
+}
+
+// save writes the bans into the file, replacing it atomically. Expired bans are kept to count the strikes,
+// until they are pruned
+func (ps *peerScores) save() error {
+	bans := make([]*ban, 0, len(ps.bans))
+	for _, b := range ps.bans {
+		bans = append(bans, b)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Target < bans[j].Target })
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := ps.banFile + ".tmp"
+	if err = ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("writing bans: %w", err)
+	}
+	if err = os.Rename(tmpFile, ps.banFile); err != nil {
+		return fmt.Errorf("writing bans: %w", err)
+	}
+	return nil
+}
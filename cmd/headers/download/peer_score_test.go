@@ -0,0 +1,158 @@
+package download
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+)
+
+const (
+	testPeer1 = "0000000000000000000000000000000000000000000000000000000000000001"
+	testPeer2 = "0000000000000000000000000000000000000000000000000000000000000002"
+	testPeer3 = "0000000000000000000000000000000000000000000000000000000000000003"
+)
+
+func TestPeerScores(t *testing.T) {
+	banFile := filepath.Join(t.TempDir(), "bans.json")
+	ps, err := newPeerScores(banFile)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	ps.now = func() time.Time { return now }
+	ip1, ip2 := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+
+	banned, err := ps.penalize(testPeer1, ip1, proto_sentry.PenaltyKind_TooFarFuture)
+	require.NoError(t, err)
+	assert.False(t, banned)
+	score, subnetScore := ps.scores(testPeer1, ip1)
+	assert.Equal(t, 50.0, score)
+	assert.Equal(t, 50.0, subnetScore)
+
+	// Scores decay, so the same penalty after the half-life does not get the peer banned
+	now = now.Add(scoreHalfLife)
+	banned, err = ps.penalize(testPeer1, ip1, proto_sentry.PenaltyKind_TooFarFuture)
+	require.NoError(t, err)
+	assert.False(t, banned)
+	score, _ = ps.scores(testPeer1, ip1)
+	assert.InDelta(t, 75.0, score, 1e-9)
+
+	banned, err = ps.penalize(testPeer1, ip1, proto_sentry.PenaltyKind_InvalidSeal)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	assert.True(t, ps.banned(testPeer1, ip1))
+	assert.False(t, ps.banned(testPeer2, ip2))
+
+	// Other peers from the same subnet push the subnet score over its threshold
+	banned, err = ps.penalize(testPeer2, ip2, proto_sentry.PenaltyKind_BadBlock)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	assert.False(t, ps.banned(testPeer3, net.ParseIP("10.0.0.3")))
+	banned, err = ps.penalize(testPeer3, net.ParseIP("10.0.0.3"), proto_sentry.PenaltyKind_TooFarFuture)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	assert.True(t, ps.banned(testPeer3, net.ParseIP("10.0.0.200")))
+	assert.False(t, ps.banned(testPeer3, net.ParseIP("10.0.1.2")))
+
+	// Bans survive the restart and expire
+	ps, err = newPeerScores(banFile)
+	require.NoError(t, err)
+	ps.now = func() time.Time { return now }
+	assert.True(t, ps.banned(testPeer1, nil))
+	assert.Len(t, ps.activeBans(), 3)
+	now = now.Add(tempBanDuration)
+	assert.False(t, ps.banned(testPeer1, ip1))
+	assert.Empty(t, ps.activeBans())
+}
+
+func TestPeerBanEscalation(t *testing.T) {
+	ps, err := newPeerScores(filepath.Join(t.TempDir(), "bans.json"))
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	ps.now = func() time.Time { return now }
+	for i := 0; i <= maxTempBans; i++ {
+		banned, err := ps.penalize(testPeer1, nil, proto_sentry.PenaltyKind_BadBlock)
+		require.NoError(t, err)
+		assert.True(t, banned)
+		now = now.Add(tempBanDuration)
+	}
+	assert.True(t, ps.banned(testPeer1, nil), "ban after %d temporary bans is permanent", maxTempBans)
+	found, err := ps.unban(testPeer1)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.False(t, ps.banned(testPeer1, nil))
+}
+
+func TestPeerBanPruning(t *testing.T) {
+	banFile := filepath.Join(t.TempDir(), "bans.json")
+	ps, err := newPeerScores(banFile)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	ps.now = func() time.Time { return now }
+	require.NoError(t, ps.ban("10.0.0.0/8", 0, "test"))
+
+	// The peer changing its enode ID after every ban leaves a ban per ID behind
+	for i := 0; i < 10; i++ {
+		banned, err := ps.penalize(fmt.Sprintf("%064x", i+100), nil, proto_sentry.PenaltyKind_BadBlock)
+		require.NoError(t, err)
+		assert.True(t, banned)
+		_, err = ps.penalize(fmt.Sprintf("%064x", i+200), nil, proto_sentry.PenaltyKind_Kick)
+		require.NoError(t, err)
+	}
+	assert.Len(t, ps.bans, 11)
+	assert.Len(t, ps.peers, 10)
+
+	// The expired bans are still counted within the retention window
+	now = now.Add(banRetention)
+	banned, err := ps.penalize(testPeer1, nil, proto_sentry.PenaltyKind_BadBlock)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	assert.Len(t, ps.bans, 12)
+	assert.Equal(t, map[string]*score{}, ps.peers, "decayed scores are dropped")
+
+	// After it, only the permanent and the recent bans are kept, in the file too
+	now = now.Add(tempBanDuration + pruneInterval)
+	banned, err = ps.penalize(testPeer2, nil, proto_sentry.PenaltyKind_BadBlock)
+	require.NoError(t, err)
+	assert.True(t, banned)
+	ps, err = newPeerScores(banFile)
+	require.NoError(t, err)
+	targets := make([]string, 0, len(ps.bans))
+	for target := range ps.bans {
+		targets = append(targets, target)
+	}
+	assert.ElementsMatch(t, []string{"10.0.0.0/8", testPeer1, testPeer2}, targets)
+}
+
+func TestManualBans(t *testing.T) {
+	ps, err := newPeerScores(filepath.Join(t.TempDir(), "bans.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, ps.ban("192.168.1.5", time.Hour, "test"))
+	require.NoError(t, ps.ban("2001:db8::/32", 0, "test"))
+	require.NoError(t, ps.ban("0x"+testPeer2, 0, "test"))
+	require.Error(t, ps.ban("not a target", 0, "test"))
+	assert.True(t, ps.banned(testPeer1, net.ParseIP("192.168.1.5")))
+	assert.False(t, ps.banned(testPeer1, net.ParseIP("192.168.1.6")))
+	assert.True(t, ps.banned(testPeer1, net.ParseIP("2001:db8::1")))
+	assert.True(t, ps.banned(testPeer2, nil))
+
+	bans := ps.activeBans()
+	require.Len(t, bans, 3)
+	assert.Equal(t, testPeer2, bans[0].Target)
+	assert.Equal(t, "192.168.1.5/32", bans[1].Target)
+	assert.Equal(t, "2001:db8::/32", bans[2].Target)
+
+	found, err := ps.unban("192.168.1.5")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.False(t, ps.banned(testPeer1, net.ParseIP("192.168.1.5")))
+	found, err = ps.unban("192.168.1.5")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
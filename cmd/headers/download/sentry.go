@@ -1,22 +1,25 @@
 package download
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
-	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"math/rand"
 	"net"
 	"os"
 	"os/signal"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/c2h5oh/datasize"
+	mapset "github.com/deckarep/golang-set"
 	"github.com/golang/protobuf/ptypes/empty"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
@@ -24,7 +27,6 @@ import (
 	proto_core "github.com/ledgerwatch/turbo-geth/cmd/headers/core"
 	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
 	"github.com/ledgerwatch/turbo-geth/common"
-	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/forkid"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/crypto"
@@ -39,6 +41,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/keepalive"
@@ -89,82 +92,157 @@ type PenaltyMsg struct {
 
 func makeP2PServer(
 	ctx context.Context,
-	natSetting string,
-	port int,
+	serverKey *ecdsa.PrivateKey,
+	listenAddr string,
+	dialCandidates enode.Iterator,
 	peerHeightMap *sync.Map,
 	peerTimeMap *sync.Map,
 	peerRwMap *sync.Map,
+	peerVersionMap *sync.Map,
+	peerMap *sync.Map,
+	peerTxsMap *sync.Map,
+	scores *peerScores,
 	protocols []string,
 	coreClient proto_core.ControlClient,
 ) (*p2p.Server, error) {
-	client := dnsdisc.NewClient(dnsdisc.Config{})
-
-	dns := params.KnownDNSNetwork(params.MainnetGenesisHash, "all")
-	dialCandidates, err := client.NewIterator(dns)
-	if err != nil {
-		return nil, fmt.Errorf("create discovery candidates: %v", err)
-	}
-
-	genesis := core.DefaultGenesisBlock()
-	serverKey := nodeKey()
 	p2pConfig := p2p.Config{}
-	natif, err := nat.Parse(natSetting)
-	if err != nil {
-		return nil, fmt.Errorf("invalid nat option %s: %v", natSetting, err)
-	}
-	p2pConfig.NAT = natif
 	p2pConfig.PrivateKey = serverKey
 	p2pConfig.Name = "header downloader"
 	p2pConfig.Logger = log.New()
 	p2pConfig.MaxPeers = 100
 	p2pConfig.Protocols = []p2p.Protocol{}
-	p2pConfig.NodeDatabase = "downloader_nodes"
-	p2pConfig.ListenAddr = fmt.Sprintf(":%d", port)
-	pMap := map[string]p2p.Protocol{
-		eth.ProtocolName: {
+	p2pConfig.ListenAddr = listenAddr
+	p2pConfig.PeerFilter = func(n *enode.Node) bool {
+		return !scores.banned(n.ID().String(), n.IP())
+	}
+	// The highest version supported by both sides is negotiated for each peer
+	ethProtocol := func(version uint) p2p.Protocol {
+		return p2p.Protocol{
 			Name:           eth.ProtocolName,
-			Version:        eth.ProtocolVersions[0],
-			Length:         eth.ProtocolLengths[eth.ProtocolVersions[0]],
+			Version:        version,
+			Length:         eth.ProtocolLengths[version],
 			DialCandidates: dialCandidates,
 			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
 				peerID := peer.ID().String()
-				log.Info(fmt.Sprintf("[%s] Start with peer", peerID))
+				if scores.banned(peerID, peerIP(peer)) {
+					return p2p.DiscUselessPeer
+				}
+				// The handshake announces the chain of the core
+				status, err := coreClient.GetStatus(ctx, &empty.Empty{}, &grpc.EmptyCallOption{})
+				if err != nil {
+					return fmt.Errorf("get status from core: %w", err)
+				}
+				log.Info(fmt.Sprintf("[%s] Start with peer", peerID), "version", version)
+				peerMap.Store(peerID, peer)
 				peerRwMap.Store(peerID, rw)
+				peerVersionMap.Store(peerID, version)
+				txs := newPeerTxs()
+				peerTxsMap.Store(peerID, txs)
 				if err := runPeer(
 					ctx,
 					peerHeightMap,
 					peerTimeMap,
 					peer,
 					rw,
-					eth.ProtocolVersions[0], // version == eth65
-					eth.ProtocolVersions[1], // minVersion == eth64
-					eth.DefaultConfig.NetworkID,
-					genesis.Difficulty,
-					params.MainnetGenesisHash,
-					params.MainnetChainConfig,
-					0, /* head */
+					version,
+					eth.ETH64, // minVersion
+					status,
 					coreClient,
+					txs,
+					rate.NewLimiter(requestsRate, requestsBurst),
 				); err != nil {
 					log.Info(fmt.Sprintf("[%s] Error while running peer: %v", peerID, err))
 				}
 				peerHeightMap.Delete(peerID)
 				peerTimeMap.Delete(peerID)
 				peerRwMap.Delete(peerID)
+				peerVersionMap.Delete(peerID)
+				peerMap.Delete(peerID)
+				peerTxsMap.Delete(peerID)
 				return nil
 			},
-		},
+		}
+	}
+	pMap := map[string][]p2p.Protocol{
+		eth.ProtocolName: {ethProtocol(eth.ETH66), ethProtocol(eth.ETH65), ethProtocol(eth.ETH64)},
 	}
 
 	for _, protocolName := range protocols {
-		p2pConfig.Protocols = append(p2pConfig.Protocols, pMap[protocolName])
+		p2pConfig.Protocols = append(p2pConfig.Protocols, pMap[protocolName]...)
 	}
 	return &p2p.Server{Config: p2pConfig}, nil
 }
 
+const (
+	maxKnownTxs     = 32768 // Maximum number of the transaction hashes to remember as known to the peer
+	txMessagesRate  = 10    // Number of the transaction messages per second the peer is allowed to send
+	txMessagesBurst = 100   // Number of the transaction messages the peer can send at once
+	requestsRate    = 20    // Number of the block and receipt requests per second the peer is allowed to send
+	requestsBurst   = 100   // Number of the block and receipt requests the peer can send at once
+)
+
+// peerTxs tracks the transactions known to the peer, so they are not sent back to it, and limits
+// the rate of the transaction messages the peer can send
+type peerTxs struct {
+	known   mapset.Set
+	limiter *rate.Limiter
+}
+
+func newPeerTxs() *peerTxs {
+	return &peerTxs{
+		known:   mapset.NewSet(),
+		limiter: rate.NewLimiter(txMessagesRate, txMessagesBurst),
+	}
+}
+
+// mark remembers the transactions as known to the peer, forgetting the oldest ones over the limit
+func (pt *peerTxs) mark(hashes ...common.Hash) {
+	if len(hashes) > maxKnownTxs {
+		hashes = hashes[len(hashes)-maxKnownTxs:]
+	}
+	for pt.known.Cardinality()+len(hashes) > maxKnownTxs {
+		pt.known.Pop()
+	}
+	for _, hash := range hashes {
+		pt.known.Add(hash)
+	}
+}
+
+// unknown returns the hashes not known to the peer, in the same order
+func (pt *peerTxs) unknown(hashes []common.Hash) []common.Hash {
+	var unknown []common.Hash
+	for _, hash := range hashes {
+		if !pt.known.Contains(hash) {
+			unknown = append(unknown, hash)
+		}
+	}
+	return unknown
+}
+
+// peerIP returns the IP address of the peer's connection
+func peerIP(peer *p2p.Peer) net.IP {
+	if addr, ok := peer.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
+}
+
 func errResp(code int, format string, v ...interface{}) error {
 	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
 }
 
+// sendPacket sends the RLP encoded packet to the peer. Since eth/66, requests and
+// responses are wrapped into the envelope carrying the request ID.
+func sendPacket(rw p2p.MsgWriter, version uint, msgcode uint64, requestID uint64, packet []byte) error {
+	if version >= eth.ETH66 && eth.IsRequestOrResponse(msgcode) {
+		var err error
+		if packet, err = eth.EncodePacket66(requestID, packet); err != nil {
+			return err
+		}
+	}
+	return rw.WriteMsg(p2p.Msg{Code: msgcode, Size: uint32(len(packet)), Payload: bytes.NewReader(packet)})
+}
+
 func runPeer(
 	ctx context.Context,
 	peerHeightMap *sync.Map,
@@ -173,23 +251,23 @@ func runPeer(
 	rw p2p.MsgReadWriter,
 	version uint,
 	minVersion uint,
-	networkID uint64,
-	td *big.Int,
-	genesisHash common.Hash,
-	chainConfig *params.ChainConfig,
-	head uint64,
+	ourStatus *proto_core.StatusData,
 	coreClient proto_core.ControlClient,
+	txs *peerTxs,
+	requests *rate.Limiter,
 ) error {
 	peerID := peer.ID().String()
-	forkId := forkid.NewID(chainConfig, genesisHash, head)
+	networkID := ourStatus.NetworkId
+	genesisHash := common.BytesToHash(ourStatus.ForkData.GetGenesis())
+	forks := ourStatus.ForkData.GetForks()
 	// Send handshake message
 	if err := p2p.Send(rw, eth.StatusMsg, &eth.StatusData{
 		ProtocolVersion: uint32(version),
 		NetworkID:       networkID,
-		TD:              td,
-		Head:            genesisHash, // For now we always start unsyched
+		TD:              new(big.Int).SetBytes(ourStatus.TotalDifficulty),
+		Head:            common.BytesToHash(ourStatus.BestHash),
 		Genesis:         genesisHash,
-		ForkID:          forkId,
+		ForkID:          forkid.NewIDFromForks(forks, genesisHash, ourStatus.MaxBlock),
 	}); err != nil {
 		return fmt.Errorf("handshake to peer %s: %v", peerID, err)
 	}
@@ -223,7 +301,7 @@ func runPeer(
 	if status.Genesis != genesisHash {
 		return errResp(eth.ErrGenesisMismatch, "genesis hash does not match: theirs %x, ours %x", status.Genesis, genesisHash)
 	}
-	forkFilter := forkid.NewFilter(chainConfig, genesisHash, head)
+	forkFilter := forkid.NewFilterFromForks(forks, genesisHash, ourStatus.MaxBlock)
 	if err = forkFilter(status.ForkID); err != nil {
 		return errResp(eth.ErrForkIDRejected, "%v", err)
 	}
@@ -240,20 +318,56 @@ func runPeer(
 			msg.Discard()
 			return errResp(eth.ErrMsgTooLarge, "message is too large %d, limit %d", msg.Size, eth.ProtocolMaxMsgSize)
 		}
+		// Since eth/66 the requests and responses are prefixed with the request ID
+		var requestID uint64
+		if version >= eth.ETH66 && eth.IsRequestOrResponse(msg.Code) {
+			var packet eth.Packet66
+			if err = msg.Decode(&packet); err != nil {
+				return errResp(eth.ErrDecode, "decoding eth/66 packet %v: %v", msg, err)
+			}
+			requestID = packet.RequestID
+			msg.Payload, msg.Size = bytes.NewReader(packet.Packet), uint32(len(packet.Packet))
+		}
 		switch msg.Code {
 		case eth.StatusMsg:
 			msg.Discard()
 			// Status messages should never arrive after the handshake
 			return errResp(eth.ErrExtraStatusMsg, "uncontrolled status message")
-		case eth.GetBlockHeadersMsg:
-			var query eth.GetBlockHeadersData
-			if err = msg.Decode(&query); err != nil {
-				return errResp(eth.ErrDecode, "decoding GetBlockHeadersMsg %v: %v", msg, err)
+		case eth.GetBlockHeadersMsg, eth.GetBlockBodiesMsg, eth.GetReceiptsMsg:
+			// The requests are served by the core, the flood of them is dropped before it reaches the core
+			if !requests.Allow() {
+				log.Debug(fmt.Sprintf("[%s] Dropping request over the rate limit", peerID), "code", msg.Code)
+				break
 			}
-			log.Info(fmt.Sprintf("[%s] GetBlockHeaderMsg{hash=%x, number=%d, amount=%d, skip=%d, reverse=%t}", peerID, query.Origin.Hash, query.Origin.Number, query.Amount, query.Skip, query.Reverse))
-			var headers []*types.Header
-			if err = p2p.Send(rw, eth.BlockHeadersMsg, headers); err != nil {
-				return fmt.Errorf("send empty headers reply: %v", err)
+			bytes := make([]byte, msg.Size)
+			if _, err = io.ReadFull(msg.Payload, bytes); err != nil {
+				return fmt.Errorf("%s: reading msg into bytes: %v", peerID, err)
+			}
+			outreq := proto_core.InboundMessage{
+				PeerId:    []byte(peerID),
+				Data:      bytes,
+				RequestId: requestID,
+			}
+			if msg.Code == eth.GetBlockHeadersMsg {
+				var query eth.GetBlockHeadersData
+				if err = rlp.DecodeBytes(bytes, &query); err != nil {
+					return errResp(eth.ErrDecode, "decoding GetBlockHeadersMsg %v: %v", msg, err)
+				}
+				log.Debug(fmt.Sprintf("[%s] GetBlockHeaderMsg{hash=%x, number=%d, amount=%d, skip=%d, reverse=%t}", peerID, query.Origin.Hash, query.Origin.Number, query.Amount, query.Skip, query.Reverse))
+				outreq.Id = proto_core.InboundMessageId_GetBlockHeaders
+			} else {
+				var hashes []common.Hash
+				if err = rlp.DecodeBytes(bytes, &hashes); err != nil {
+					return errResp(eth.ErrDecode, "decoding request %v: %v", msg, err)
+				}
+				log.Debug(fmt.Sprintf("[%s] Block data request", peerID), "code", msg.Code, "hashes", len(hashes))
+				outreq.Id = proto_core.InboundMessageId_GetBlockBodies
+				if msg.Code == eth.GetReceiptsMsg {
+					outreq.Id = proto_core.InboundMessageId_GetReceipts
+				}
+			}
+			if _, err = coreClient.ForwardInboundMessage(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
+				log.Error("Sending request to core P2P failed", "error", err)
 			}
 		case eth.BlockHeadersMsg:
 			bytes := make([]byte, msg.Size)
@@ -275,37 +389,16 @@ func runPeer(
 					hashesStr.WriteString(fmt.Sprintf("%x-%x(%d)", hash[:4], hash[28:], header.Number.Uint64()))
 				}
 			*/
-			log.Info(fmt.Sprintf("[%s] BlockHeadersMsg{%d hashes}", peerID, len(headers)))
+			log.Info(fmt.Sprintf("[%s] BlockHeadersMsg{%d hashes}", peerID, len(headers)), "requestID", requestID)
 			outreq := proto_core.InboundMessage{
-				PeerId: []byte(peerID),
-				Id:     proto_core.InboundMessageId_BlockHeaders,
-				Data:   bytes,
+				PeerId:    []byte(peerID),
+				Id:        proto_core.InboundMessageId_BlockHeaders,
+				Data:      bytes,
+				RequestId: requestID,
 			}
 			if _, err = coreClient.ForwardInboundMessage(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
 				log.Error("Sending block headers to core P2P failed", "error", err)
 			}
-		case eth.GetBlockBodiesMsg:
-			// Decode the retrieval message
-			msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-			if _, err = msgStream.List(); err != nil {
-				return fmt.Errorf("getting list from RLP stream for GetBlockBodiesMsg: %v", err)
-			}
-			// Gather blocks until the fetch or network limits is reached
-			var hash common.Hash
-			var hashesStr strings.Builder
-			for {
-				// Retrieve the hash of the next block
-				if err = msgStream.Decode(&hash); errors.Is(err, rlp.EOL) {
-					break
-				} else if err != nil {
-					return errResp(eth.ErrDecode, "decode hash for GetBlockBodiesMsg %v: %v", msg, err)
-				}
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
-			}
-			log.Info(fmt.Sprintf("[%s] GetBlockBodiesMsg {%s}", peerID, hashesStr.String()))
 		case eth.BlockBodiesMsg:
 			log.Info(fmt.Sprintf("[%s] BlockBodiesMsg", peerID))
 			bytes := make([]byte, msg.Size)
@@ -314,17 +407,16 @@ func runPeer(
 				return fmt.Errorf("%s: reading msg into bytes: %v", peerID, err)
 			}
 			outreq := proto_core.InboundMessage{
-				PeerId: []byte(peerID),
-				Id:     proto_core.InboundMessageId_BlockBodies,
-				Data:   bytes,
+				PeerId:    []byte(peerID),
+				Id:        proto_core.InboundMessageId_BlockBodies,
+				Data:      bytes,
+				RequestId: requestID,
 			}
 			if _, err = coreClient.ForwardInboundMessage(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
 				log.Error("Sending block bodies to core P2P failed", "error", err)
 			}
 		case eth.GetNodeDataMsg:
 			log.Info(fmt.Sprintf("[%s] GetNodeData", peerID))
-		case eth.GetReceiptsMsg:
-			log.Info(fmt.Sprintf("[%s] GetReceiptsMsg", peerID))
 		case eth.ReceiptsMsg:
 			log.Info(fmt.Sprintf("[%s] ReceiptsMsg", peerID))
 		case eth.NewBlockHashesMsg:
@@ -385,37 +477,49 @@ func runPeer(
 			if _, err = coreClient.ForwardInboundMessage(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
 				log.Error("Sending new block to core P2P failed", "error", err)
 			}
-		case eth.NewPooledTransactionHashesMsg:
-			var hashes []common.Hash
-			if err := msg.Decode(&hashes); err != nil {
-				return errResp(eth.ErrDecode, "decode NewPooledTransactionHashesMsg %v: %v", msg, err)
+		case eth.NewPooledTransactionHashesMsg, eth.GetPooledTransactionsMsg, eth.TransactionMsg, eth.PooledTransactionsMsg:
+			if !txs.limiter.Allow() {
+				log.Debug(fmt.Sprintf("[%s] Dropping transaction message over the rate limit", peerID), "code", msg.Code)
+				break
 			}
-			var hashesStr strings.Builder
-			for _, hash := range hashes {
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
-				}
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
+			bytes := make([]byte, msg.Size)
+			if _, err = io.ReadFull(msg.Payload, bytes); err != nil {
+				return fmt.Errorf("%s: reading msg into bytes: %v", peerID, err)
 			}
-			//log.Info(fmt.Sprintf("[%s] NewPooledTransactionHashesMsg {%s}", peerID, hashesStr.String()))
-		case eth.GetPooledTransactionsMsg:
-			//log.Info(fmt.Sprintf("[%s] GetPooledTransactionsMsg", peerID)
-		case eth.TransactionMsg:
-			var txs []*types.Transaction
-			if err := msg.Decode(&txs); err != nil {
-				return errResp(eth.ErrDecode, "decode TransactionMsg %v: %v", msg, err)
+			outreq := proto_core.InboundMessage{
+				PeerId:    []byte(peerID),
+				Data:      bytes,
+				RequestId: requestID,
 			}
-			var hashesStr strings.Builder
-			for _, tx := range txs {
-				if hashesStr.Len() > 0 {
-					hashesStr.WriteString(",")
+			switch msg.Code {
+			case eth.NewPooledTransactionHashesMsg:
+				var hashes []common.Hash
+				if err = rlp.DecodeBytes(bytes, &hashes); err != nil {
+					return errResp(eth.ErrDecode, "decode NewPooledTransactionHashesMsg %v: %v", msg, err)
+				}
+				txs.mark(hashes...)
+				outreq.Id = proto_core.InboundMessageId_NewPooledTransactionHashes
+			case eth.GetPooledTransactionsMsg:
+				outreq.Id = proto_core.InboundMessageId_GetPooledTransactions
+			default:
+				var transactions []*types.Transaction
+				if err = rlp.DecodeBytes(bytes, &transactions); err != nil {
+					return errResp(eth.ErrDecode, "decode TransactionMsg %v: %v", msg, err)
+				}
+				for i, tx := range transactions {
+					if tx == nil {
+						return errResp(eth.ErrDecode, "transaction %d is nil", i)
+					}
+					txs.mark(tx.Hash())
 				}
-				hash := tx.Hash()
-				hashesStr.WriteString(fmt.Sprintf("%x-%x", hash[:4], hash[28:]))
+				outreq.Id = proto_core.InboundMessageId_Transactions
+				if msg.Code == eth.PooledTransactionsMsg {
+					outreq.Id = proto_core.InboundMessageId_PooledTransactions
+				}
+			}
+			if _, err = coreClient.ForwardInboundMessage(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
+				log.Error("Sending transactions to core P2P failed", "error", err)
 			}
-			//log.Info(fmt.Sprintf("[%s] TransactionMsg {%s}", peerID, hashesStr.String()))
-		case eth.PooledTransactionsMsg:
-			//log.Info(fmt.Sprintf("[%s] PooledTransactionsMsg", peerID)
 		default:
 			log.Error(fmt.Sprintf("[%s] Unknown message code: %d", peerID, msg.Code))
 		}
@@ -462,7 +566,11 @@ func grpcControlClient(ctx context.Context, coreAddr string) (proto_core.Control
 	return proto_core.NewControlClient(conn), nil
 }
 
-func grpcSentryServer(ctx context.Context, sentryAddr string) (*SentryServerImpl, error) {
+func grpcSentryServer(ctx context.Context, sentryAddr string, banFile string) (*SentryServerImpl, error) {
+	scores, err := newPeerScores(banFile)
+	if err != nil {
+		return nil, err
+	}
 	// STARTING GRPC SERVER
 	log.Info("Starting Sentry P2P server", "on", sentryAddr)
 	listenConfig := net.ListenConfig{
@@ -499,8 +607,9 @@ func grpcSentryServer(ctx context.Context, sentryAddr string) (*SentryServerImpl
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unaryInterceptors...)),
 	}
 	grpcServer = grpc.NewServer(opts...)
-	sentryServer := &SentryServerImpl{}
+	sentryServer := &SentryServerImpl{scores: scores}
 	proto_sentry.RegisterSentryServer(grpcServer, sentryServer)
+	proto_sentry.RegisterSentryAdminServer(grpcServer, sentryServer)
 	if metrics.Enabled {
 		grpc_prometheus.Register(grpcServer)
 	}
@@ -517,19 +626,36 @@ func p2pServer(ctx context.Context,
 	sentryServer *SentryServerImpl,
 	natSetting string, port int, staticPeers []string, discovery bool, netRestrict string,
 ) (*p2p.Server, error) {
+	client := dnsdisc.NewClient(dnsdisc.Config{})
+	dns := params.KnownDNSNetwork(params.MainnetGenesisHash, "all")
+	dialCandidates, err := client.NewIterator(dns)
+	if err != nil {
+		return nil, fmt.Errorf("create discovery candidates: %v", err)
+	}
 	server, err := makeP2PServer(
 		ctx,
-		natSetting,
-		port,
+		nodeKey(),
+		fmt.Sprintf(":%d", port),
+		dialCandidates,
 		&sentryServer.peerHeightMap,
 		&sentryServer.peerTimeMap,
 		&sentryServer.peerRwMap,
+		&sentryServer.peerVersionMap,
+		&sentryServer.peerMap,
+		&sentryServer.peerTxsMap,
+		sentryServer.scores,
 		[]string{eth.ProtocolName},
 		coreClient,
 	)
 	if err != nil {
 		return nil, err
 	}
+	natif, err := nat.Parse(natSetting)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nat option %s: %v", natSetting, err)
+	}
+	server.NAT = natif
+	server.NodeDatabase = "downloader_nodes"
 
 	enodes := make([]*enode.Node, len(staticPeers))
 	for i, e := range staticPeers {
@@ -547,7 +673,7 @@ func p2pServer(ctx context.Context,
 }
 
 // Sentry creates and runs standalone sentry
-func Sentry(natSetting string, port int, sentryAddr string, coreAddr string, staticPeers []string, discovery bool, netRestrict string) error {
+func Sentry(natSetting string, port int, sentryAddr string, coreAddr string, staticPeers []string, discovery bool, netRestrict string, banFile string) error {
 	ctx := rootContext()
 
 	coreClient, err1 := grpcControlClient(ctx, coreAddr)
@@ -555,7 +681,7 @@ func Sentry(natSetting string, port int, sentryAddr string, coreAddr string, sta
 		return err1
 	}
 
-	sentryServer, err2 := grpcSentryServer(ctx, sentryAddr)
+	sentryServer, err2 := grpcSentryServer(ctx, sentryAddr, banFile)
 	if err2 != nil {
 		return err2
 	}
@@ -576,14 +702,96 @@ func Sentry(natSetting string, port int, sentryAddr string, coreAddr string, sta
 
 type SentryServerImpl struct {
 	proto_sentry.UnimplementedSentryServer
-	peerHeightMap sync.Map
-	peerRwMap     sync.Map
-	peerTimeMap   sync.Map
+	proto_sentry.UnimplementedSentryAdminServer
+	peerHeightMap  sync.Map
+	peerRwMap      sync.Map
+	peerTimeMap    sync.Map
+	peerVersionMap sync.Map
+	peerMap        sync.Map // Connected peers by ID
+	peerTxsMap     sync.Map // Transactions known to the peers, by peer ID
+	scores         *peerScores
 }
 
 func (ss *SentryServerImpl) PenalizePeer(_ context.Context, req *proto_sentry.PenalizePeerRequest) (*empty.Empty, error) {
-	log.Warn("Received penalty", "kind", req.GetPenalty().Descriptor().FullName, "from", fmt.Sprintf("%x", req.GetPeerId()))
-	return nil, nil
+	peerID := string(req.GetPeerId())
+	log.Warn("Received penalty", "kind", req.GetPenalty(), "from", peerID)
+	peerRaw, _ := ss.peerMap.Load(peerID)
+	peer, _ := peerRaw.(*p2p.Peer)
+	var ip net.IP
+	if peer != nil {
+		ip = peerIP(peer)
+	}
+	banned, err := ss.scores.penalize(peerID, ip, req.GetPenalty())
+	if err != nil {
+		log.Error("Could not persist bans", "error", err)
+	}
+	if peer != nil && (banned || req.GetPenalty() == proto_sentry.PenaltyKind_Kick) {
+		peer.Disconnect(p2p.DiscUselessPeer)
+	}
+	return &empty.Empty{}, nil
+}
+
+// Peers lists the connected peers with their scores
+func (ss *SentryServerImpl) Peers(context.Context, *empty.Empty) (*proto_sentry.PeersReply, error) {
+	reply := &proto_sentry.PeersReply{}
+	ss.peerMap.Range(func(key, value interface{}) bool {
+		peerID, peer := key.(string), value.(*p2p.Peer)
+		heightRaw, _ := ss.peerHeightMap.Load(peerID)
+		height, _ := heightRaw.(uint64)
+		score, subnetScore := ss.scores.scores(peerID, peerIP(peer))
+		reply.Peers = append(reply.Peers, &proto_sentry.PeerInfo{
+			PeerId:      []byte(peerID),
+			Name:        peer.Name(),
+			RemoteAddr:  peer.RemoteAddr().String(),
+			Height:      height,
+			Score:       score,
+			SubnetScore: subnetScore,
+		})
+		return true
+	})
+	sort.Slice(reply.Peers, func(i, j int) bool { return bytes.Compare(reply.Peers[i].PeerId, reply.Peers[j].PeerId) < 0 })
+	return reply, nil
+}
+
+// Bans lists the bans in effect
+func (ss *SentryServerImpl) Bans(context.Context, *empty.Empty) (*proto_sentry.BansReply, error) {
+	reply := &proto_sentry.BansReply{}
+	for _, b := range ss.scores.activeBans() {
+		info := &proto_sentry.BanInfo{Target: b.Target, Reason: b.Reason}
+		if !b.Permanent {
+			info.Until = uint64(b.Until)
+		}
+		reply.Bans = append(reply.Bans, info)
+	}
+	return reply, nil
+}
+
+// BanPeer bans the peer or the subnet, disconnecting the affected peers
+func (ss *SentryServerImpl) BanPeer(_ context.Context, req *proto_sentry.BanPeerRequest) (*empty.Empty, error) {
+	if err := ss.scores.ban(req.Target, time.Duration(req.Duration)*time.Second, req.Reason); err != nil {
+		return nil, err
+	}
+	log.Info("Banned peer by the operator", "target", req.Target, "duration", time.Duration(req.Duration)*time.Second, "reason", req.Reason)
+	ss.peerMap.Range(func(key, value interface{}) bool {
+		if peer := value.(*p2p.Peer); ss.scores.banned(key.(string), peerIP(peer)) {
+			peer.Disconnect(p2p.DiscUselessPeer)
+		}
+		return true
+	})
+	return &empty.Empty{}, nil
+}
+
+// UnbanPeer lifts the ban of the peer or the subnet
+func (ss *SentryServerImpl) UnbanPeer(_ context.Context, req *proto_sentry.UnbanPeerRequest) (*empty.Empty, error) {
+	found, err := ss.scores.unban(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s is not banned", req.Target)
+	}
+	log.Info("Unbanned peer by the operator", "target", req.Target)
+	return &empty.Empty{}, nil
 }
 
 func (ss *SentryServerImpl) findPeer(minBlock uint64) (string, bool) {
@@ -607,6 +815,13 @@ func (ss *SentryServerImpl) findPeer(minBlock uint64) (string, bool) {
 	return peerID, found
 }
 
+// peerVersion returns the version of the eth protocol negotiated with the peer
+func (ss *SentryServerImpl) peerVersion(peerID string) uint {
+	versionRaw, _ := ss.peerVersionMap.Load(peerID)
+	version, _ := versionRaw.(uint)
+	return version
+}
+
 func (ss *SentryServerImpl) getBlockHeaders(inreq *proto_sentry.SendMessageByMinBlockRequest) (*proto_sentry.SentPeers, error) {
 	var req eth.GetBlockHeadersData
 	if err := rlp.DecodeBytes(inreq.Data.Data, &req); err != nil {
@@ -617,13 +832,13 @@ func (ss *SentryServerImpl) getBlockHeaders(inreq *proto_sentry.SendMessageByMin
 		log.Debug("Could not find peer for request", "minBlock", inreq.MinBlock)
 		return &proto_sentry.SentPeers{}, nil
 	}
-	log.Info(fmt.Sprintf("Sending req for hash %x, amount %d to peer %s\n", req.Origin.Hash, req.Amount, peerID))
+	log.Info(fmt.Sprintf("Sending req for hash %x, amount %d to peer %s\n", req.Origin.Hash, req.Amount, peerID), "requestID", inreq.Data.RequestId)
 	rwRaw, _ := ss.peerRwMap.Load(peerID)
 	rw, _ := rwRaw.(p2p.MsgReadWriter)
 	if rw == nil {
 		return &proto_sentry.SentPeers{}, fmt.Errorf("find rw for peer %s", peerID)
 	}
-	if err := p2p.Send(rw, eth.GetBlockHeadersMsg, &req); err != nil {
+	if err := sendPacket(rw, ss.peerVersion(peerID), eth.GetBlockHeadersMsg, inreq.Data.RequestId, inreq.Data.Data); err != nil {
 		return &proto_sentry.SentPeers{}, fmt.Errorf("send to peer %s: %v", peerID, err)
 	}
 	ss.peerTimeMap.Store(peerID, time.Now().Unix()+5)
@@ -646,7 +861,7 @@ func (ss *SentryServerImpl) getBlockBodies(inreq *proto_sentry.SendMessageByMinB
 	if rw == nil {
 		return &proto_sentry.SentPeers{}, fmt.Errorf("find rw for peer %s", peerID)
 	}
-	if err := p2p.Send(rw, eth.GetBlockBodiesMsg, &req); err != nil {
+	if err := sendPacket(rw, ss.peerVersion(peerID), eth.GetBlockBodiesMsg, inreq.Data.RequestId, inreq.Data.Data); err != nil {
 		return &proto_sentry.SentPeers{}, fmt.Errorf("send to peer %s: %v", peerID, err)
 	}
 	ss.peerTimeMap.Store(peerID, time.Now().Unix()+5)
@@ -664,14 +879,159 @@ func (ss *SentryServerImpl) SendMessageByMinBlock(_ context.Context, inreq *prot
 	}
 }
 
-func (ss *SentryServerImpl) SendMessageById(context.Context, *proto_sentry.SendMessageByIdRequest) (*proto_sentry.SentPeers, error) {
-	return nil, nil
+// messageCodes are the eth message codes of the messages the core sends via the sentry to the chosen peers
+var messageCodes = map[proto_sentry.OutboundMessageId]uint64{
+	proto_sentry.OutboundMessageId_GetBlockHeaders:            eth.GetBlockHeadersMsg,
+	proto_sentry.OutboundMessageId_GetBlockBodies:             eth.GetBlockBodiesMsg,
+	proto_sentry.OutboundMessageId_GetNodeData:                eth.GetNodeDataMsg,
+	proto_sentry.OutboundMessageId_NewPooledTransactionHashes: eth.NewPooledTransactionHashesMsg,
+	proto_sentry.OutboundMessageId_GetPooledTransactions:      eth.GetPooledTransactionsMsg,
+	proto_sentry.OutboundMessageId_Transactions:               eth.TransactionMsg,
+	proto_sentry.OutboundMessageId_PooledTransactions:         eth.PooledTransactionsMsg,
+	proto_sentry.OutboundMessageId_BlockHeaders:               eth.BlockHeadersMsg,
+	proto_sentry.OutboundMessageId_BlockBodies:                eth.BlockBodiesMsg,
+	proto_sentry.OutboundMessageId_Receipts:                   eth.ReceiptsMsg,
+}
+
+// outboundTxs is the outbound message with the hashes of the transactions it carries or announces,
+// so that the peers are not sent the transactions they already know
+type outboundTxs struct {
+	code   uint64
+	data   *proto_sentry.OutboundMessageData
+	hashes []common.Hash
+	txs    []*types.Transaction // Transactions themselves, nil for the announcements and requests
+}
+
+func parseOutbound(data *proto_sentry.OutboundMessageData) (*outboundTxs, error) {
+	code, ok := messageCodes[data.Id]
+	if !ok {
+		return nil, fmt.Errorf("not implemented for message Id: %s", data.Id)
+	}
+	out := &outboundTxs{code: code, data: data}
+	switch data.Id {
+	case proto_sentry.OutboundMessageId_NewPooledTransactionHashes:
+		if err := rlp.DecodeBytes(data.Data, &out.hashes); err != nil {
+			return nil, fmt.Errorf("parse %s: %v", data.Id, err)
+		}
+	case proto_sentry.OutboundMessageId_Transactions, proto_sentry.OutboundMessageId_PooledTransactions:
+		if err := rlp.DecodeBytes(data.Data, &out.txs); err != nil {
+			return nil, fmt.Errorf("parse %s: %v", data.Id, err)
+		}
+		out.hashes = make([]common.Hash, len(out.txs))
+		for i, tx := range out.txs {
+			out.hashes[i] = tx.Hash()
+		}
+	}
+	return out, nil
+}
+
+// sendTo sends the message to the peer, unless it is not connected. Broadcasts of the transactions and their announcements skip
+// the transactions known to the peer, and are not sent at all if the peer knows all of them. The announcements and requests
+// of the pooled transactions are not sent to the peers below eth/65, which would drop the connection on these codes
+func (ss *SentryServerImpl) sendTo(peerID string, out *outboundTxs, broadcast bool) (bool, error) {
+	rwRaw, _ := ss.peerRwMap.Load(peerID)
+	rw, _ := rwRaw.(p2p.MsgReadWriter)
+	txsRaw, _ := ss.peerTxsMap.Load(peerID)
+	txs, _ := txsRaw.(*peerTxs)
+	if rw == nil || txs == nil {
+		return false, nil // Peer is not connected (any longer)
+	}
+	version := ss.peerVersion(peerID)
+	if version < eth.ETH65 && (out.code == eth.NewPooledTransactionHashesMsg || out.code == eth.GetPooledTransactionsMsg) {
+		return false, nil
+	}
+	data := out.data.Data
+	if broadcast && len(out.hashes) > 0 {
+		unknown := txs.unknown(out.hashes)
+		if len(unknown) == 0 {
+			return false, nil
+		}
+		if len(unknown) < len(out.hashes) {
+			var err error
+			if out.txs == nil {
+				data, err = rlp.EncodeToBytes(unknown)
+			} else {
+				var filtered []*types.Transaction
+				for i, j := 0, 0; i < len(out.txs) && j < len(unknown); i++ {
+					if out.hashes[i] == unknown[j] {
+						filtered = append(filtered, out.txs[i])
+						j++
+					}
+				}
+				data, err = rlp.EncodeToBytes(filtered)
+			}
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := sendPacket(rw, version, out.code, out.data.RequestId, data); err != nil {
+		return false, fmt.Errorf("send to peer %s: %v", peerID, err)
+	}
+	txs.mark(out.hashes...)
+	return true, nil
+}
+
+func (ss *SentryServerImpl) SendMessageById(_ context.Context, inreq *proto_sentry.SendMessageByIdRequest) (*proto_sentry.SentPeers, error) {
+	out, err := parseOutbound(inreq.Data)
+	if err != nil {
+		return &proto_sentry.SentPeers{}, err
+	}
+	sent, err := ss.sendTo(string(inreq.PeerId), out, false /* broadcast */)
+	if err != nil || !sent {
+		return &proto_sentry.SentPeers{}, err
+	}
+	return &proto_sentry.SentPeers{Peers: [][]byte{inreq.PeerId}}, nil
+}
+
+// peerIDs returns the IDs of the connected peers in random order
+func (ss *SentryServerImpl) peerIDs() []string {
+	var peerIDs []string
+	ss.peerRwMap.Range(func(key, _ interface{}) bool {
+		peerIDs = append(peerIDs, key.(string))
+		return true
+	})
+	rand.Shuffle(len(peerIDs), func(i, j int) { peerIDs[i], peerIDs[j] = peerIDs[j], peerIDs[i] })
+	return peerIDs
 }
 
-func (ss *SentryServerImpl) SendMessageToRandomPeers(context.Context, *proto_sentry.SendMessageToRandomPeersRequest) (*proto_sentry.SentPeers, error) {
-	return nil, nil
+func (ss *SentryServerImpl) SendMessageToRandomPeers(_ context.Context, inreq *proto_sentry.SendMessageToRandomPeersRequest) (*proto_sentry.SentPeers, error) {
+	out, err := parseOutbound(inreq.Data)
+	if err != nil {
+		return &proto_sentry.SentPeers{}, err
+	}
+	reply := &proto_sentry.SentPeers{}
+	for _, peerID := range ss.peerIDs() {
+		if uint64(len(reply.Peers)) >= inreq.MaxPeers {
+			break
+		}
+		sent, err := ss.sendTo(peerID, out, true /* broadcast */)
+		if err != nil {
+			log.Debug("Could not send message", "id", inreq.Data.Id, "peer", peerID, "error", err)
+			continue
+		}
+		if sent {
+			reply.Peers = append(reply.Peers, []byte(peerID))
+		}
+	}
+	return reply, nil
 }
 
-func (ss *SentryServerImpl) SendMessageToAll(context.Context, *proto_sentry.OutboundMessageData) (*proto_sentry.SentPeers, error) {
-	return nil, nil
+func (ss *SentryServerImpl) SendMessageToAll(_ context.Context, inreq *proto_sentry.OutboundMessageData) (*proto_sentry.SentPeers, error) {
+	out, err := parseOutbound(inreq)
+	if err != nil {
+		return &proto_sentry.SentPeers{}, err
+	}
+	reply := &proto_sentry.SentPeers{}
+	for _, peerID := range ss.peerIDs() {
+		sent, err := ss.sendTo(peerID, out, true /* broadcast */)
+		if err != nil {
+			log.Debug("Could not send message", "id", inreq.Id, "peer", peerID, "error", err)
+			continue
+		}
+		if sent {
+			reply.Peers = append(reply.Peers, []byte(peerID))
+		}
+	}
+	return reply, nil
 }
@@ -0,0 +1,311 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	proto_core "github.com/ledgerwatch/turbo-geth/cmd/headers/core"
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"google.golang.org/grpc"
+)
+
+const (
+	sentryCheckInterval = 10 * time.Second // How often the health of the sentries is checked
+	sentryCheckTimeout  = 5 * time.Second
+)
+
+var errNoSentries = errors.New("no sentry could send the message")
+
+// pooledSentry is one of the sentries in the pool, together with its health
+type pooledSentry struct {
+	addr      string
+	client    proto_sentry.SentryClient
+	admin     proto_sentry.SentryAdminClient // Used for the health checks, nil if the sentry does not serve it
+	healthy   bool
+	peerCount uint64
+	lastSeen  time.Time
+	lastErr   error
+}
+
+// SentryPool spreads the requests of the core across several sentries, failing over to the other
+// sentries when one of them is unavailable. It implements SentryClient, so it can be used in place
+// of a single sentry
+type SentryPool struct {
+	lock     sync.RWMutex
+	sentries []*pooledSentry
+	peers    map[string]int // Index of the sentry each known peer is connected to
+	next     uint32         // Round-robin counter for choosing the first sentry to try
+}
+
+// NewSentryPool creates the pool of the given sentries. The admin clients are optional, the sentries
+// without them are considered healthy until their calls start failing
+func NewSentryPool(addrs []string, clients []proto_sentry.SentryClient, admins []proto_sentry.SentryAdminClient) *SentryPool {
+	sp := &SentryPool{peers: make(map[string]int)}
+	for i, addr := range addrs {
+		sentry := &pooledSentry{addr: addr, client: clients[i], healthy: true}
+		if admins != nil {
+			sentry.admin = admins[i]
+		}
+		sp.sentries = append(sp.sentries, sentry)
+	}
+	return sp
+}
+
+// order returns the indices of the sentries to try: the healthy ones first, starting from the next one
+// in the round-robin order, then the unhealthy ones, in case they have recovered since the last check
+func (sp *SentryPool) order() []int {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	n := len(sp.sentries)
+	start := int(atomic.AddUint32(&sp.next, 1)) % n
+	order := make([]int, 0, n)
+	var unhealthy []int
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		if sp.sentries[idx].healthy {
+			order = append(order, idx)
+		} else {
+			unhealthy = append(unhealthy, idx)
+		}
+	}
+	return append(order, unhealthy...)
+}
+
+func (sp *SentryPool) client(idx int) proto_sentry.SentryClient {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	return sp.sentries[idx].client
+}
+
+// report records the outcome of the call to the sentry, and the peers the message was sent to. The calls
+// failed because the context of the caller was cancelled or expired say nothing about the sentry
+func (sp *SentryPool) report(ctx context.Context, idx int, sent *proto_sentry.SentPeers, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	sentry := sp.sentries[idx]
+	if err != nil {
+		if sentry.healthy {
+			log.Warn("Sentry failed, failing over to the other sentries", "sentry", sentry.addr, "error", err)
+		}
+		sentry.healthy, sentry.lastErr = false, err
+		return
+	}
+	sentry.healthy, sentry.lastErr, sentry.lastSeen = true, nil, time.Now()
+	for _, peer := range sent.GetPeers() {
+		sp.peers[string(peer)] = idx
+	}
+}
+
+// peerSentry returns the index of the sentry the peer is connected to
+func (sp *SentryPool) peerSentry(peerID []byte) (int, bool) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	idx, ok := sp.peers[string(peerID)]
+	return idx, ok
+}
+
+// peerOrder returns the indices of the sentries to try for the peer: the one the peer is known to be
+// connected to first, then the rest, in case the peer has reconnected through another sentry since
+func (sp *SentryPool) peerOrder(peerID []byte) []int {
+	order := sp.order()
+	idx, ok := sp.peerSentry(peerID)
+	if !ok {
+		return order
+	}
+	peerOrder := append(make([]int, 0, len(order)), idx)
+	for _, i := range order {
+		if i != idx {
+			peerOrder = append(peerOrder, i)
+		}
+	}
+	return peerOrder
+}
+
+// PenalizePeer sends the penalty to the sentry of the peer, or to all the sentries if it is not known or
+// that sentry fails
+func (sp *SentryPool) PenalizePeer(ctx context.Context, in *proto_sentry.PenalizePeerRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	if idx, ok := sp.peerSentry(in.PeerId); ok {
+		_, err := sp.client(idx).PenalizePeer(ctx, in, opts...)
+		sp.report(ctx, idx, nil, err)
+		if err == nil || ctx.Err() != nil {
+			return &empty.Empty{}, err
+		}
+	}
+	var lastErr error
+	for _, idx := range sp.order() {
+		_, err := sp.client(idx).PenalizePeer(ctx, in, opts...)
+		sp.report(ctx, idx, nil, err)
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return &empty.Empty{}, lastErr
+}
+
+// SendMessageByMinBlock sends the message via the first sentry that finds a suitable peer
+func (sp *SentryPool) SendMessageByMinBlock(ctx context.Context, in *proto_sentry.SendMessageByMinBlockRequest, opts ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	var lastErr error
+	responded := false
+	for _, idx := range sp.order() {
+		sent, err := sp.client(idx).SendMessageByMinBlock(ctx, in, opts...)
+		sp.report(ctx, idx, sent, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		responded = true
+		if len(sent.GetPeers()) > 0 {
+			return sent, nil
+		}
+	}
+	if responded {
+		return &proto_sentry.SentPeers{}, nil
+	}
+	return &proto_sentry.SentPeers{}, fmt.Errorf("%w: %v", errNoSentries, lastErr)
+}
+
+// SendMessageById sends the message via the sentry the peer is connected to. If it is not known yet,
+// or that sentry fails, the other sentries are asked in turn until the one with the peer is found
+func (sp *SentryPool) SendMessageById(ctx context.Context, in *proto_sentry.SendMessageByIdRequest, opts ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	var lastErr error
+	for _, idx := range sp.peerOrder(in.PeerId) {
+		sent, err := sp.client(idx).SendMessageById(ctx, in, opts...)
+		sp.report(ctx, idx, sent, err)
+		if err != nil {
+			if ctx.Err() != nil {
+				return &proto_sentry.SentPeers{}, err
+			}
+			lastErr = err
+			continue
+		}
+		if len(sent.GetPeers()) > 0 {
+			return sent, nil
+		}
+	}
+	if lastErr != nil {
+		return &proto_sentry.SentPeers{}, fmt.Errorf("%w: %v", errNoSentries, lastErr)
+	}
+	return &proto_sentry.SentPeers{}, fmt.Errorf("peer %s is not connected to any sentry", in.PeerId)
+}
+
+// SendMessageToRandomPeers sends the message to up to the given number of peers, taking them from
+// the sentries in turn
+func (sp *SentryPool) SendMessageToRandomPeers(ctx context.Context, in *proto_sentry.SendMessageToRandomPeersRequest, opts ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	result := &proto_sentry.SentPeers{}
+	var lastErr error
+	for _, idx := range sp.order() {
+		if uint64(len(result.Peers)) >= in.MaxPeers {
+			break
+		}
+		req := &proto_sentry.SendMessageToRandomPeersRequest{Data: in.Data, MaxPeers: in.MaxPeers - uint64(len(result.Peers))}
+		sent, err := sp.client(idx).SendMessageToRandomPeers(ctx, req, opts...)
+		sp.report(ctx, idx, sent, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result.Peers = append(result.Peers, sent.GetPeers()...)
+	}
+	if len(result.Peers) == 0 && lastErr != nil {
+		return result, fmt.Errorf("%w: %v", errNoSentries, lastErr)
+	}
+	return result, nil
+}
+
+// SendMessageToAll sends the message to all the peers of all the sentries
+func (sp *SentryPool) SendMessageToAll(ctx context.Context, in *proto_sentry.OutboundMessageData, opts ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	result := &proto_sentry.SentPeers{}
+	var lastErr error
+	for _, idx := range sp.order() {
+		sent, err := sp.client(idx).SendMessageToAll(ctx, in, opts...)
+		sp.report(ctx, idx, sent, err)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result.Peers = append(result.Peers, sent.GetPeers()...)
+	}
+	if len(result.Peers) == 0 && lastErr != nil {
+		return result, fmt.Errorf("%w: %v", errNoSentries, lastErr)
+	}
+	return result, nil
+}
+
+// checkHealth asks the sentries with the admin interface for their peers, which tells whether they are
+// alive and where the peers are
+func (sp *SentryPool) checkHealth(ctx context.Context) {
+	sp.lock.RLock()
+	sentries := append([]*pooledSentry{}, sp.sentries...)
+	sp.lock.RUnlock()
+	for idx, sentry := range sentries {
+		if sentry.admin == nil {
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, sentryCheckTimeout)
+		reply, err := sentry.admin.Peers(callCtx, &empty.Empty{})
+		cancel()
+		if err != nil {
+			sp.report(ctx, idx, nil, err)
+			continue
+		}
+		sp.lock.Lock()
+		for peer, i := range sp.peers {
+			if i == idx {
+				delete(sp.peers, peer)
+			}
+		}
+		for _, peer := range reply.Peers {
+			sp.peers[string(peer.PeerId)] = idx
+		}
+		if !sentry.healthy {
+			log.Info("Sentry is back", "sentry", sentry.addr, "peers", len(reply.Peers))
+		}
+		sentry.healthy, sentry.lastErr, sentry.lastSeen = true, nil, time.Now()
+		sentry.peerCount = uint64(len(reply.Peers))
+		sp.lock.Unlock()
+	}
+}
+
+func (sp *SentryPool) healthLoop(ctx context.Context) {
+	ticker := time.NewTicker(sentryCheckInterval)
+	defer ticker.Stop()
+	for {
+		sp.checkHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status reports the health of each sentry
+func (sp *SentryPool) Status() []*proto_core.SentryStatus {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	statuses := make([]*proto_core.SentryStatus, len(sp.sentries))
+	for i, sentry := range sp.sentries {
+		status := &proto_core.SentryStatus{
+			Address:   sentry.addr,
+			Healthy:   sentry.healthy,
+			PeerCount: sentry.peerCount,
+		}
+		if !sentry.lastSeen.IsZero() {
+			status.LastSeen = uint64(sentry.lastSeen.Unix())
+		}
+		if sentry.lastErr != nil {
+			status.LastError = sentry.lastErr.Error()
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
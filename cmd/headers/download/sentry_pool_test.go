@@ -0,0 +1,165 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+)
+
+// testSentry sends every message to its own peers, or fails while it is down
+type testSentry struct {
+	proto_sentry.SentryClient
+	peers     [][]byte
+	down      bool
+	penalized [][]byte
+	calls     int
+}
+
+var errSentryDown = errors.New("sentry is down")
+
+func (ts *testSentry) send() (*proto_sentry.SentPeers, error) {
+	ts.calls++
+	if ts.down {
+		return nil, errSentryDown
+	}
+	return &proto_sentry.SentPeers{Peers: ts.peers}, nil
+}
+
+func (ts *testSentry) SendMessageByMinBlock(context.Context, *proto_sentry.SendMessageByMinBlockRequest, ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	sent, err := ts.send()
+	if err != nil || len(sent.Peers) == 0 {
+		return sent, err
+	}
+	return &proto_sentry.SentPeers{Peers: sent.Peers[:1]}, nil
+}
+
+func (ts *testSentry) SendMessageToAll(context.Context, *proto_sentry.OutboundMessageData, ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	return ts.send()
+}
+
+// SendMessageById only sends to the peers of the sentry, and fails once the context of the caller is done
+func (ts *testSentry) SendMessageById(ctx context.Context, in *proto_sentry.SendMessageByIdRequest, _ ...grpc.CallOption) (*proto_sentry.SentPeers, error) {
+	if ctx.Err() != nil {
+		ts.calls++
+		return nil, ctx.Err()
+	}
+	sent, err := ts.send()
+	if err != nil {
+		return nil, err
+	}
+	for _, peer := range sent.Peers {
+		if string(peer) == string(in.PeerId) {
+			return &proto_sentry.SentPeers{Peers: [][]byte{peer}}, nil
+		}
+	}
+	return &proto_sentry.SentPeers{}, nil
+}
+
+func (ts *testSentry) PenalizePeer(_ context.Context, in *proto_sentry.PenalizePeerRequest, _ ...grpc.CallOption) (*empty.Empty, error) {
+	if _, err := ts.send(); err != nil {
+		return nil, err
+	}
+	ts.penalized = append(ts.penalized, in.PeerId)
+	return &empty.Empty{}, nil
+}
+
+func newTestPool(sentries ...*testSentry) *SentryPool {
+	addrs := make([]string, len(sentries))
+	clients := make([]proto_sentry.SentryClient, len(sentries))
+	for i, s := range sentries {
+		addrs[i] = string(rune('a' + i))
+		clients[i] = s
+	}
+	return NewSentryPool(addrs, clients, nil)
+}
+
+func TestSentryPoolRoundRobin(t *testing.T) {
+	s1 := &testSentry{peers: [][]byte{[]byte("p1")}}
+	s2 := &testSentry{peers: [][]byte{[]byte("p2")}}
+	sp := newTestPool(s1, s2)
+	for i := 0; i < 4; i++ {
+		sent, err := sp.SendMessageByMinBlock(context.Background(), &proto_sentry.SendMessageByMinBlockRequest{})
+		require.NoError(t, err)
+		require.Len(t, sent.Peers, 1)
+	}
+	assert.Equal(t, 2, s1.calls)
+	assert.Equal(t, 2, s2.calls)
+
+	// Penalties go to the sentry the peer is connected to
+	_, err := sp.PenalizePeer(context.Background(), &proto_sentry.PenalizePeerRequest{PeerId: []byte("p2")})
+	require.NoError(t, err)
+	assert.Empty(t, s1.penalized)
+	assert.Equal(t, [][]byte{[]byte("p2")}, s2.penalized)
+
+	sent, err := sp.SendMessageToAll(context.Background(), &proto_sentry.OutboundMessageData{})
+	require.NoError(t, err)
+	assert.Len(t, sent.Peers, 2)
+}
+
+func TestSentryPoolFailover(t *testing.T) {
+	s1 := &testSentry{peers: [][]byte{[]byte("p1")}, down: true}
+	s2 := &testSentry{peers: [][]byte{[]byte("p2")}}
+	s3 := &testSentry{}
+	sp := newTestPool(s1, s2, s3)
+	for i := 0; i < 3; i++ {
+		sent, err := sp.SendMessageByMinBlock(context.Background(), &proto_sentry.SendMessageByMinBlockRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("p2")}, sent.Peers)
+	}
+	// The failed sentry is only tried again after the healthy ones had no peers to offer
+	assert.Equal(t, 1, s1.calls)
+
+	statuses := sp.Status()
+	require.Len(t, statuses, 3)
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, errSentryDown.Error(), statuses[0].LastError)
+	assert.True(t, statuses[1].Healthy)
+	assert.NotZero(t, statuses[1].LastSeen)
+
+	// Once all the sentries are down, the error is returned
+	s2.down = true
+	s3.down = true
+	_, err := sp.SendMessageByMinBlock(context.Background(), &proto_sentry.SendMessageByMinBlockRequest{})
+	require.True(t, errors.Is(err, errNoSentries))
+
+	// The sentry coming back is healthy again after the first successful call
+	s1.down = false
+	sent, err := sp.SendMessageByMinBlock(context.Background(), &proto_sentry.SendMessageByMinBlockRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("p1")}, sent.Peers)
+	assert.True(t, sp.Status()[0].Healthy)
+}
+
+func TestSentryPoolPeerFailover(t *testing.T) {
+	s1 := &testSentry{peers: [][]byte{[]byte("p1")}}
+	s2 := &testSentry{peers: [][]byte{[]byte("p2")}}
+	sp := newTestPool(s1, s2)
+	_, err := sp.SendMessageToAll(context.Background(), &proto_sentry.OutboundMessageData{})
+	require.NoError(t, err)
+
+	// The peer reconnected through the other sentry after its sentry went down
+	s1.down = true
+	s2.peers = append(s2.peers, []byte("p1"))
+	sent, err := sp.SendMessageById(context.Background(), &proto_sentry.SendMessageByIdRequest{PeerId: []byte("p1")})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("p1")}, sent.Peers)
+	assert.False(t, sp.Status()[0].Healthy)
+	idx, ok := sp.peerSentry([]byte("p1"))
+	require.True(t, ok)
+	assert.Equal(t, 1, idx)
+
+	// The cancelled call of the caller does not make the sentry unhealthy
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sp.SendMessageById(ctx, &proto_sentry.SendMessageByIdRequest{PeerId: []byte("p1")})
+	require.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, sp.Status()[1].Healthy)
+	assert.Empty(t, sp.Status()[1].LastError)
+}
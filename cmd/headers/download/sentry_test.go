@@ -0,0 +1,143 @@
+package download
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth"
+	"github.com/ledgerwatch/turbo-geth/p2p"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+func TestSentryTxBroadcast(t *testing.T) {
+	ss := &SentryServerImpl{}
+	rw, remote := p2p.MsgPipe()
+	defer rw.Close()
+	ss.peerRwMap.Store(testPeer1, p2p.MsgReadWriter(rw))
+	ss.peerVersionMap.Store(testPeer1, uint(eth.ETH65))
+	txs := newPeerTxs()
+	ss.peerTxsMap.Store(testPeer1, txs)
+
+	tx1 := types.NewTransaction(0, common.Address{1}, uint256.NewInt().SetUint64(1), 21000, uint256.NewInt().SetUint64(1), nil)
+	tx2 := types.NewTransaction(1, common.Address{1}, uint256.NewInt().SetUint64(1), 21000, uint256.NewInt().SetUint64(1), nil)
+	// The peer announced the first transaction, so only the second one is sent to it
+	txs.mark(tx1.Hash())
+
+	// The pipe only completes the write once the message is decoded on the other end
+	type reply struct {
+		code uint64
+		txs  []*types.Transaction
+	}
+	received := make(chan reply, 1)
+	go func() {
+		msg, err := remote.ReadMsg()
+		if err != nil {
+			return
+		}
+		var txs []*types.Transaction
+		if err = msg.Decode(&txs); err == nil {
+			received <- reply{msg.Code, txs}
+		}
+	}()
+	data, err := rlp.EncodeToBytes(types.Transactions{tx1, tx2})
+	require.NoError(t, err)
+	sent, err := ss.SendMessageToRandomPeers(context.Background(), &proto_sentry.SendMessageToRandomPeersRequest{
+		Data:     &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_Transactions, Data: data},
+		MaxPeers: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(testPeer1)}, sent.Peers)
+	got := <-received
+	assert.Equal(t, uint64(eth.TransactionMsg), got.code)
+	require.Len(t, got.txs, 1)
+	assert.Equal(t, tx2.Hash(), got.txs[0].Hash())
+
+	// Now the peer knows both, so the announcement is not sent at all
+	data, err = rlp.EncodeToBytes([]common.Hash{tx1.Hash(), tx2.Hash()})
+	require.NoError(t, err)
+	sent, err = ss.SendMessageToAll(context.Background(), &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_NewPooledTransactionHashes, Data: data})
+	require.NoError(t, err)
+	assert.Empty(t, sent.Peers)
+
+	// Unknown peers are not an error, the sentry just reports that nothing was sent
+	sent, err = ss.SendMessageById(context.Background(), &proto_sentry.SendMessageByIdRequest{
+		PeerId: []byte(testPeer2),
+		Data:   &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_GetPooledTransactions, Data: data},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, sent.Peers)
+}
+
+func TestSentryTxBroadcastEth64(t *testing.T) {
+	ss := &SentryServerImpl{}
+	codes := make(map[string]chan uint64)
+	for peerID, version := range map[string]uint{testPeer1: eth.ETH65, testPeer2: eth.ETH64} {
+		rw, remote := p2p.MsgPipe()
+		defer rw.Close()
+		ss.peerRwMap.Store(peerID, p2p.MsgReadWriter(rw))
+		ss.peerVersionMap.Store(peerID, version)
+		ss.peerTxsMap.Store(peerID, newPeerTxs())
+		received := make(chan uint64, 10)
+		codes[peerID] = received
+		go func() {
+			for {
+				msg, err := remote.ReadMsg()
+				if err != nil {
+					close(received)
+					return
+				}
+				msg.Discard()
+				received <- msg.Code
+			}
+		}()
+	}
+	tx := types.NewTransaction(0, common.Address{1}, uint256.NewInt().SetUint64(1), 21000, uint256.NewInt().SetUint64(1), nil)
+
+	// The announcement only goes to the eth/65 peer, the eth/64 peer would drop the connection on it
+	hashes, err := rlp.EncodeToBytes([]common.Hash{tx.Hash()})
+	require.NoError(t, err)
+	sent, err := ss.SendMessageToAll(context.Background(), &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_NewPooledTransactionHashes, Data: hashes})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(testPeer1)}, sent.Peers)
+	assert.Equal(t, uint64(eth.NewPooledTransactionHashesMsg), <-codes[testPeer1])
+
+	// The transaction itself then only goes to the eth/64 peer, the other one already knows it
+	data, err := rlp.EncodeToBytes(types.Transactions{tx})
+	require.NoError(t, err)
+	sent, err = ss.SendMessageToAll(context.Background(), &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_Transactions, Data: data})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(testPeer2)}, sent.Peers)
+	assert.Equal(t, uint64(eth.TransactionMsg), <-codes[testPeer2])
+
+	// Neither are the pooled transactions requested from the eth/64 peer
+	sent, err = ss.SendMessageById(context.Background(), &proto_sentry.SendMessageByIdRequest{
+		PeerId: []byte(testPeer2),
+		Data:   &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_GetPooledTransactions, Data: hashes},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, sent.Peers)
+	assert.Empty(t, codes[testPeer1])
+	assert.Empty(t, codes[testPeer2])
+}
+
+func TestPeerTxsLimit(t *testing.T) {
+	txs := newPeerTxs()
+	hashes := make([]common.Hash, maxKnownTxs+10)
+	for i := range hashes {
+		hashes[i] = common.BigToHash(big.NewInt(int64(i)))
+	}
+	txs.mark(hashes...)
+	assert.Equal(t, maxKnownTxs, txs.known.Cardinality())
+	assert.Len(t, txs.unknown(hashes), 10, "oldest hashes are forgotten")
+	txs.mark(common.Hash{0xff})
+	assert.Equal(t, maxKnownTxs, txs.known.Cardinality())
+	assert.Empty(t, txs.unknown([]common.Hash{{0xff}}))
+}
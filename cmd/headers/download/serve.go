@@ -0,0 +1,176 @@
+package download
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	proto_core "github.com/ledgerwatch/turbo-geth/cmd/headers/core"
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"google.golang.org/grpc"
+)
+
+const (
+	softResponseLimit = 2 * 1024 * 1024 // Target maximum size of the replies to the requests of the peers
+	maxHeadersServe   = 1024            // Maximum number of the block headers sent in one reply
+	maxBodiesServe    = 1024            // Maximum number of the block bodies sent in one reply
+	maxReceiptsServe  = 1024            // Maximum number of the block receipts sent in one reply
+)
+
+// canonicalHeaders collects the headers matching the query. Only the canonical chain is traversed, so the
+// non-canonical origin is served alone
+func canonicalHeaders(db ethdb.Database, query *eth.GetBlockHeadersData) ([]rlp.RawValue, error) {
+	number := query.Origin.Number
+	if query.Origin.Hash != (common.Hash{}) {
+		n := rawdb.ReadHeaderNumber(db, query.Origin.Hash)
+		if n == nil {
+			return nil, nil
+		}
+		canonical, err := rawdb.ReadCanonicalHash(db, *n)
+		if err != nil {
+			return nil, err
+		}
+		if canonical != query.Origin.Hash {
+			if header := rawdb.ReadHeaderRLP(db, query.Origin.Hash, *n); len(header) > 0 {
+				return []rlp.RawValue{header}, nil
+			}
+			return nil, nil
+		}
+		number = *n
+	}
+	var (
+		headers []rlp.RawValue
+		bytes   int
+	)
+	for uint64(len(headers)) < query.Amount && len(headers) < maxHeadersServe && bytes < softResponseLimit {
+		hash, err := rawdb.ReadCanonicalHash(db, number)
+		if err != nil {
+			return nil, err
+		}
+		if hash == (common.Hash{}) {
+			break
+		}
+		header := rawdb.ReadHeaderRLP(db, hash, number)
+		if len(header) == 0 {
+			break
+		}
+		headers = append(headers, header)
+		bytes += len(header)
+		if query.Reverse {
+			if number < query.Skip+1 {
+				break
+			}
+			number -= query.Skip + 1
+		} else {
+			next := number + query.Skip + 1
+			if next <= number {
+				break // Overflow of the skip
+			}
+			number = next
+		}
+	}
+	return headers, nil
+}
+
+// reply sends the RLP list of the items to the peer, as the response to its request
+func (cs *ControlServerImpl) reply(ctx context.Context, inreq *proto_core.InboundMessage, id proto_sentry.OutboundMessageId, items []rlp.RawValue) (*empty.Empty, error) {
+	if items == nil {
+		items = []rlp.RawValue{}
+	}
+	bytes, err := rlp.EncodeToBytes(items)
+	if err != nil {
+		return nil, err
+	}
+	outreq := proto_sentry.SendMessageByIdRequest{
+		PeerId: inreq.PeerId,
+		Data: &proto_sentry.OutboundMessageData{
+			Id:        id,
+			Data:      bytes,
+			RequestId: inreq.RequestId,
+		},
+	}
+	if _, err = cs.sentries.SendMessageById(ctx, &outreq, &grpc.EmptyCallOption{}); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+// getBlockHeaders answers the request of the peer with the headers of the canonical chain
+func (cs *ControlServerImpl) getBlockHeaders(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	var query eth.GetBlockHeadersData
+	if err := rlp.DecodeBytes(inreq.Data, &query); err != nil {
+		return nil, fmt.Errorf("decode GetBlockHeaders: %v", err)
+	}
+	headers, err := canonicalHeaders(cs.db, &query)
+	if err != nil {
+		return nil, err
+	}
+	log.Trace("Serving block headers", "requested", query.Amount, "found", len(headers), "requestID", inreq.RequestId)
+	return cs.reply(ctx, inreq, proto_sentry.OutboundMessageId_BlockHeaders, headers)
+}
+
+// getBlockBodies answers the request of the peer with the bodies of the known blocks
+func (cs *ControlServerImpl) getBlockBodies(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(inreq.Data, &hashes); err != nil {
+		return nil, fmt.Errorf("decode GetBlockBodies: %v", err)
+	}
+	var (
+		bytes  int
+		bodies []rlp.RawValue
+	)
+	for _, hash := range hashes {
+		if bytes >= softResponseLimit || len(bodies) >= maxBodiesServe {
+			break
+		}
+		number := rawdb.ReadHeaderNumber(cs.db, hash)
+		if number == nil {
+			continue
+		}
+		body := rawdb.ReadBody(cs.db, hash, *number)
+		if body == nil {
+			continue
+		}
+		encoded, err := rlp.EncodeToBytes(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode body %d: %v", *number, err)
+		}
+		bodies = append(bodies, encoded)
+		bytes += len(encoded)
+	}
+	return cs.reply(ctx, inreq, proto_sentry.OutboundMessageId_BlockBodies, bodies)
+}
+
+// getReceipts answers the request of the peer with the receipts of the canonical blocks
+func (cs *ControlServerImpl) getReceipts(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(inreq.Data, &hashes); err != nil {
+		return nil, fmt.Errorf("decode GetReceipts: %v", err)
+	}
+	var (
+		bytes    int
+		receipts []rlp.RawValue
+	)
+	for _, hash := range hashes {
+		if bytes >= softResponseLimit || len(receipts) >= maxReceiptsServe {
+			break
+		}
+		encoded, err := rawdb.ReadCanonicalReceiptsRLP(cs.db, hash)
+		if err != nil {
+			log.Error("Failed to encode receipts", "hash", hash, "error", err)
+			continue
+		}
+		if encoded == nil {
+			continue
+		}
+		receipts = append(receipts, encoded)
+		bytes += len(encoded)
+	}
+	return cs.reply(ctx, inreq, proto_sentry.OutboundMessageId_Receipts, receipts)
+}
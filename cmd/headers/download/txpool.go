@@ -0,0 +1,202 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	proto_core "github.com/ledgerwatch/turbo-geth/cmd/headers/core"
+	proto_sentry "github.com/ledgerwatch/turbo-geth/cmd/headers/sentry"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/fetcher"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"google.golang.org/grpc"
+)
+
+const (
+	txChanSize       = 4096 // Size of the channel listening to the new transactions of the pool
+	txPropagatePeers = 10   // Number of the peers the new transactions are sent to, the rest only get the announcements
+)
+
+// newTxPool creates the transaction pool and starts it at the current head of the chain. The local transactions
+// are kept in the journal file
+func newTxPool(db ethdb.Database, chainConfig *params.ChainConfig, journal string) (*core.TxPool, error) {
+	objectDb, ok := db.(*ethdb.ObjectDatabase)
+	if !ok {
+		return nil, fmt.Errorf("transaction pool needs object database, got %T", db)
+	}
+	headHash := rawdb.ReadHeadHeaderHash(db)
+	headNumber := rawdb.ReadHeaderNumber(db, headHash)
+	if headNumber == nil {
+		return nil, errors.New("head header is not found")
+	}
+	head := rawdb.ReadHeader(db, headHash, *headNumber)
+	if head == nil {
+		return nil, fmt.Errorf("head header %d is not found", *headNumber)
+	}
+	config := core.DefaultTxPoolConfig
+	config.Journal = journal
+	txPool := core.NewTxPool(config, chainConfig, objectDb, core.NewTxSenderCacher(runtime.NumCPU()))
+	if err := txPool.Start(head.GasLimit, *headNumber); err != nil {
+		return nil, err
+	}
+	return txPool, nil
+}
+
+// StartTxPool makes the core gossip the transactions of the pool through the sentries: the transactions
+// announced and sent by the peers are fetched and added to the pool, and the new transactions of the pool
+// are propagated to the peers. Without the pool, the transaction messages are ignored
+func (cs *ControlServerImpl) StartTxPool(ctx context.Context, txPool *core.TxPool) {
+	cs.txPool = txPool
+	// The fetcher keeps track of the announcements across all the peers of all the sentries, so each
+	// transaction is only requested once, whichever sentries it is announced through
+	cs.txFetcher = fetcher.NewTxFetcher(txPool.Has, txPool.AddRemotes, cs.fetchTxs)
+	cs.txFetcher.Start()
+	txsCh := make(chan core.NewTxsEvent, txChanSize)
+	txsSub := txPool.SubscribeNewTxsEvent(txsCh)
+	go func() {
+		defer cs.txFetcher.Stop()
+		defer txsSub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-txsSub.Err():
+				if err != nil {
+					log.Error("Transaction pool subscription failed", "error", err)
+				}
+				return
+			case event := <-txsCh:
+				cs.broadcastTxs(ctx, event.Txs)
+			}
+		}
+	}()
+}
+
+// fetchTxs requests the announced transactions from the peer, via the sentry the peer is connected to
+func (cs *ControlServerImpl) fetchTxs(peerID string, hashes []common.Hash) error {
+	bytes, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		return err
+	}
+	outreq := proto_sentry.SendMessageByIdRequest{
+		PeerId: []byte(peerID),
+		Data: &proto_sentry.OutboundMessageData{
+			Id:        proto_sentry.OutboundMessageId_GetPooledTransactions,
+			Data:      bytes,
+			RequestId: atomic.AddUint64(&cs.txRequestID, 1),
+		},
+	}
+	_, err = cs.sentries.SendMessageById(context.Background(), &outreq, &grpc.EmptyCallOption{})
+	return err
+}
+
+// broadcastTxs sends the new transactions to a few peers and announces them to the rest. The sentries
+// leave out the transactions their peers already know. The peers below eth/65 are not sent the announcements,
+// so the transactions themselves are sent to all the peers afterwards: the ones that got the announcements
+// already know the transactions and are skipped
+func (cs *ControlServerImpl) broadcastTxs(ctx context.Context, txs types.Transactions) {
+	txsBytes, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		log.Error("Could not encode transactions", "error", err)
+		return
+	}
+	sent, err := cs.sentries.SendMessageToRandomPeers(ctx, &proto_sentry.SendMessageToRandomPeersRequest{
+		Data:     &proto_sentry.OutboundMessageData{Id: proto_sentry.OutboundMessageId_Transactions, Data: txsBytes},
+		MaxPeers: txPropagatePeers,
+	}, &grpc.EmptyCallOption{})
+	if err != nil {
+		log.Warn("Could not propagate transactions", "error", err)
+	}
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	bytes, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		log.Error("Could not encode transaction hashes", "error", err)
+		return
+	}
+	announced, err := cs.sentries.SendMessageToAll(ctx, &proto_sentry.OutboundMessageData{
+		Id:   proto_sentry.OutboundMessageId_NewPooledTransactionHashes,
+		Data: bytes,
+	}, &grpc.EmptyCallOption{})
+	if err != nil {
+		log.Warn("Could not announce transactions", "error", err)
+	}
+	legacy, err := cs.sentries.SendMessageToAll(ctx, &proto_sentry.OutboundMessageData{
+		Id:   proto_sentry.OutboundMessageId_Transactions,
+		Data: txsBytes,
+	}, &grpc.EmptyCallOption{})
+	if err != nil {
+		log.Warn("Could not send transactions to the eth/64 peers", "error", err)
+	}
+	log.Trace("Broadcast transactions", "count", len(txs), "sent", len(sent.GetPeers()), "announced", len(announced.GetPeers()), "legacy", len(legacy.GetPeers()))
+}
+
+func (cs *ControlServerImpl) newPooledTransactionHashes(inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	if cs.txFetcher == nil {
+		return &empty.Empty{}, nil
+	}
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(inreq.Data, &hashes); err != nil {
+		return nil, fmt.Errorf("decode NewPooledTransactionHashes: %v", err)
+	}
+	if err := cs.txFetcher.Notify(string(inreq.PeerId), hashes); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (cs *ControlServerImpl) transactions(inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	if cs.txFetcher == nil {
+		return &empty.Empty{}, nil
+	}
+	var txs []*types.Transaction
+	if err := rlp.DecodeBytes(inreq.Data, &txs); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", inreq.Id, err)
+	}
+	if err := cs.txFetcher.Enqueue(string(inreq.PeerId), txs, inreq.Id == proto_core.InboundMessageId_PooledTransactions); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+// getPooledTransactions answers the request of the peer with the transactions found in the pool. Without
+// the pool, the reply is empty
+func (cs *ControlServerImpl) getPooledTransactions(ctx context.Context, inreq *proto_core.InboundMessage) (*empty.Empty, error) {
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(inreq.Data, &hashes); err != nil {
+		return nil, fmt.Errorf("decode GetPooledTransactions: %v", err)
+	}
+	var (
+		size int
+		txs  []rlp.RawValue
+	)
+	for _, hash := range hashes {
+		if size >= softResponseLimit || cs.txPool == nil {
+			break
+		}
+		tx := cs.txPool.Get(hash)
+		if tx == nil {
+			continue
+		}
+		encoded, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			log.Error("Failed to encode transaction", "error", err)
+			continue
+		}
+		txs = append(txs, encoded)
+		size += len(encoded)
+	}
+	return cs.reply(ctx, inreq, proto_sentry.OutboundMessageId_PooledTransactions, txs)
+}
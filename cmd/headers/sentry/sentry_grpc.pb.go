@@ -240,3 +240,194 @@ var _Sentry_serviceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sentry.proto",
 }
+
+// SentryAdminClient is the client API for SentryAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SentryAdminClient interface {
+	Peers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PeersReply, error)
+	Bans(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*BansReply, error)
+	BanPeer(ctx context.Context, in *BanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UnbanPeer(ctx context.Context, in *UnbanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type sentryAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSentryAdminClient(cc grpc.ClientConnInterface) SentryAdminClient {
+	return &sentryAdminClient{cc}
+}
+
+func (c *sentryAdminClient) Peers(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PeersReply, error) {
+	out := new(PeersReply)
+	err := c.cc.Invoke(ctx, "/sentry.SentryAdmin/Peers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryAdminClient) Bans(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*BansReply, error) {
+	out := new(BansReply)
+	err := c.cc.Invoke(ctx, "/sentry.SentryAdmin/Bans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryAdminClient) BanPeer(ctx context.Context, in *BanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/sentry.SentryAdmin/BanPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentryAdminClient) UnbanPeer(ctx context.Context, in *UnbanPeerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/sentry.SentryAdmin/UnbanPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SentryAdminServer is the server API for SentryAdmin service.
+// All implementations must embed UnimplementedSentryAdminServer
+// for forward compatibility
+type SentryAdminServer interface {
+	Peers(context.Context, *emptypb.Empty) (*PeersReply, error)
+	Bans(context.Context, *emptypb.Empty) (*BansReply, error)
+	BanPeer(context.Context, *BanPeerRequest) (*emptypb.Empty, error)
+	UnbanPeer(context.Context, *UnbanPeerRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSentryAdminServer()
+}
+
+// UnimplementedSentryAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedSentryAdminServer struct {
+}
+
+func (UnimplementedSentryAdminServer) Peers(context.Context, *emptypb.Empty) (*PeersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Peers not implemented")
+}
+func (UnimplementedSentryAdminServer) Bans(context.Context, *emptypb.Empty) (*BansReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Bans not implemented")
+}
+func (UnimplementedSentryAdminServer) BanPeer(context.Context, *BanPeerRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BanPeer not implemented")
+}
+func (UnimplementedSentryAdminServer) UnbanPeer(context.Context, *UnbanPeerRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbanPeer not implemented")
+}
+func (UnimplementedSentryAdminServer) mustEmbedUnimplementedSentryAdminServer() {}
+
+// UnsafeSentryAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SentryAdminServer will
+// result in compilation errors.
+type UnsafeSentryAdminServer interface {
+	mustEmbedUnimplementedSentryAdminServer()
+}
+
+func RegisterSentryAdminServer(s grpc.ServiceRegistrar, srv SentryAdminServer) {
+	s.RegisterService(&_SentryAdmin_serviceDesc, srv)
+}
+
+func _SentryAdmin_Peers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryAdminServer).Peers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sentry.SentryAdmin/Peers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryAdminServer).Peers(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentryAdmin_Bans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryAdminServer).Bans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sentry.SentryAdmin/Bans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryAdminServer).Bans(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentryAdmin_BanPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BanPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryAdminServer).BanPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sentry.SentryAdmin/BanPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryAdminServer).BanPeer(ctx, req.(*BanPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentryAdmin_UnbanPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbanPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentryAdminServer).UnbanPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sentry.SentryAdmin/UnbanPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentryAdminServer).UnbanPeer(ctx, req.(*UnbanPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SentryAdmin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "sentry.SentryAdmin",
+	HandlerType: (*SentryAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Peers",
+			Handler:    _SentryAdmin_Peers_Handler,
+		},
+		{
+			MethodName: "Bans",
+			Handler:    _SentryAdmin_Bans_Handler,
+		},
+		{
+			MethodName: "BanPeer",
+			Handler:    _SentryAdmin_BanPeer_Handler,
+		},
+		{
+			MethodName: "UnbanPeer",
+			Handler:    _SentryAdmin_UnbanPeer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "sentry.proto",
+}
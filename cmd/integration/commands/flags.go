@@ -25,6 +25,8 @@ var (
 	freelistReuse      int
 	migration          string
 	silkwormPath       string
+	parallelExecution  int
+	verifyParallel     bool
 	file               string
 )
 
@@ -101,6 +103,11 @@ func withMigration(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&migration, "migration", "", "action to apply to given migration")
 }
 
+func withParallelExecution(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&parallelExecution, "parallel", 0, "number of workers speculatively executing transactions of a block (experimental), 0 means sequential execution")
+	cmd.Flags().BoolVar(&verifyParallel, "verifyParallel", false, "execute every block sequentially as well and fail if parallel execution gives different results")
+}
+
 func withSilkworm(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&silkwormPath, "silkworm", "", "file path of libsilkworm_tg_api.so")
 	must(cmd.MarkFlagFilename("silkworm"))
@@ -234,6 +234,7 @@ func init() {
 	withUnwind(cmdStageExec)
 	withBatchSize(cmdStageExec)
 	withSilkworm(cmdStageExec)
+	withParallelExecution(cmdStageExec)
 
 	rootCmd.AddCommand(cmdStageExec)
 
@@ -383,11 +384,13 @@ func stageExec(db ethdb.Database, ctx context.Context) error {
 		bc.Config(), cc, bc.GetVMConfig(),
 		ch,
 		stagedsync.ExecuteBlockStageParams{
-			ToBlock:               block, // limit execution to the specified block
-			WriteReceipts:         sm.Receipts,
-			CacheSize:             int(cacheSize),
-			BatchSize:             int(batchSize),
-			SilkwormExecutionFunc: silkwormExecutionFunc(),
+			ToBlock:                 block, // limit execution to the specified block
+			WriteReceipts:           sm.Receipts,
+			CacheSize:               int(cacheSize),
+			BatchSize:               int(batchSize),
+			SilkwormExecutionFunc:   silkwormExecutionFunc(),
+			ParallelExecution:       parallelExecution,
+			VerifyParallelExecution: verifyParallel,
 		})
 }
 
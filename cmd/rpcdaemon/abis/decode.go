@@ -0,0 +1,172 @@
+package abis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ledgerwatch/turbo-geth/accounts/abi"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+)
+
+// Sources of the signatures of the decoded calls and logs
+const (
+	SourceContract = "contract" // ABI uploaded for the contract
+	SourceRegistry = "registry" // ABI uploaded for another contract
+	Source4Byte    = "4byte"    // 4byte database, the arguments are not named
+)
+
+// DecodedCall is the method call with its arguments and, if the output is given, its return values
+type DecodedCall struct {
+	Signature string            `json:"signature"`
+	Name      string            `json:"name"`
+	Inputs    []DecodedArgument `json:"inputs"`
+	Outputs   []DecodedArgument `json:"outputs,omitempty"`
+	Source    string            `json:"source"`
+}
+
+// DecodedLog is the event with its arguments, in the order of the declaration
+type DecodedLog struct {
+	Signature string            `json:"signature"`
+	Name      string            `json:"name"`
+	Inputs    []DecodedArgument `json:"inputs"`
+	Source    string            `json:"source"`
+}
+
+// DecodedArgument is the value of an argument. The integers are decimal strings, the byte arrays are hex strings.
+// The indexed arguments of the dynamic types are only known by their hashes.
+type DecodedArgument struct {
+	Name    string      `json:"name,omitempty"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+func decodeArguments(arguments abi.Arguments, data []byte) ([]DecodedArgument, error) {
+	values, err := arguments.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	return newDecodedArguments(arguments, values), nil
+}
+
+func newDecodedArguments(arguments abi.Arguments, values []interface{}) []DecodedArgument {
+	decoded := make([]DecodedArgument, len(arguments))
+	for i, arg := range arguments {
+		decoded[i] = DecodedArgument{Name: arg.Name, Type: arg.Type.String(), Value: formatValue(values[i])}
+	}
+	return decoded
+}
+
+func decodeEvent(event *abi.Event, topics []common.Hash, data []byte, source string) (*DecodedLog, error) {
+	var indexed int
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed++
+		}
+	}
+	if len(topics) != indexed+1 {
+		return nil, fmt.Errorf("%s: %d indexed arguments expected, %d given", event.Sig, indexed, len(topics)-1)
+	}
+	values, err := event.Inputs.UnpackValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("data does not match %s: %w", event.Sig, err)
+	}
+	decoded := &DecodedLog{Signature: event.Sig, Name: event.RawName, Source: source}
+	topics = topics[1:]
+	for _, arg := range event.Inputs {
+		var value interface{}
+		if arg.Indexed {
+			// The values of the dynamic types are replaced by their hashes, the tuples can not be parsed back at all
+			parsed := make(map[string]interface{}, 1)
+			if err := abi.ParseTopicsIntoMap(parsed, abi.Arguments{arg}, topics[:1]); err == nil {
+				value = parsed[arg.Name]
+			} else {
+				value = topics[0]
+			}
+			topics = topics[1:]
+		} else {
+			value, values = values[0], values[1:]
+		}
+		decoded.Inputs = append(decoded.Inputs, DecodedArgument{
+			Name:    arg.Name,
+			Type:    arg.Type.String(),
+			Indexed: arg.Indexed,
+			Value:   formatValue(value),
+		})
+	}
+	return decoded, nil
+}
+
+// signatureRegexp matches the method signatures of the 4byte database, the same way signer/fourbyte does
+var signatureRegexp = regexp.MustCompile(`^([^\)]+)\(([A-Za-z0-9,\[\]]*)\)`)
+
+// parseSignature converts the method signature into the method of a single-method ABI
+func parseSignature(signature string) (*abi.Method, error) {
+	type fakeArg struct {
+		Type string `json:"type"`
+	}
+	type fakeABI struct {
+		Name   string    `json:"name"`
+		Type   string    `json:"type"`
+		Inputs []fakeArg `json:"inputs"`
+	}
+	groups := signatureRegexp.FindStringSubmatch(signature)
+	if len(groups) != 3 {
+		return nil, fmt.Errorf("invalid signature %q", signature)
+	}
+	arguments := make([]fakeArg, 0)
+	if len(groups[2]) > 0 {
+		for _, arg := range strings.Split(groups[2], ",") {
+			arguments = append(arguments, fakeArg{arg})
+		}
+	}
+	abiJSON, err := json.Marshal([]fakeABI{{groups[1], "function", arguments}})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %q: %w", signature, err)
+	}
+	method := parsed.Methods[groups[1]]
+	return &method, nil
+}
+
+// formatValue converts the unpacked value into its JSON friendly form: the big integers would lose the precision as
+// JSON numbers and the byte arrays would become the arrays of numbers
+func formatValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address, common.Hash:
+		return v
+	case []byte:
+		return hexutil.Bytes(v)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Bytes(b)
+		}
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			values[i] = formatValue(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			fields[rv.Type().Field(i).Name] = formatValue(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return value
+}
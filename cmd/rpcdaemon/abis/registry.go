@@ -0,0 +1,221 @@
+// Package abis keeps the contract ABIs uploaded to the rpcdaemon and decodes the calldata, return data and logs with them
+package abis
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/ledgerwatch/turbo-geth/accounts/abi"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+var bucketsConfig = func(defaultBuckets dbutils.BucketsCfg) dbutils.BucketsCfg {
+	return dbutils.BucketsCfg{
+		dbutils.ABIBucket: {},
+	}
+}
+
+// OpenDB opens the database of the uploaded ABIs. If no path is given an in-memory, temporary database is constructed.
+func OpenDB(path string) (ethdb.KV, error) {
+	if path == "" {
+		return ethdb.NewLMDB().InMem().WithBucketsConfig(bucketsConfig).Open()
+	}
+	return ethdb.NewLMDB().Path(path).MapSize(64 * datasize.MB).WithBucketsConfig(bucketsConfig).Open()
+}
+
+// LoadSelectors reads the 4byte database: a JSON object of the hex encoded method selectors to the method signatures,
+// the format of the database of signer/fourbyte
+func LoadSelectors(path string) (map[string]string, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	selectors := make(map[string]string)
+	if err = json.Unmarshal(blob, &selectors); err != nil {
+		return nil, fmt.Errorf("invalid 4byte database %s: %w", path, err)
+	}
+	return selectors, nil
+}
+
+// Registry decodes the calls and the logs with the ABIs uploaded for the contracts and, for the calls of the contracts
+// without an ABI, with the 4byte database of the method signatures. The ABIs are stored in the ABI bucket and kept
+// in memory.
+type Registry struct {
+	kv        ethdb.KV
+	selectors map[string]string // 4byte database, hex encoded selector -> method signature
+
+	lock sync.RWMutex
+	abis map[common.Address]*abi.ABI
+}
+
+// NewRegistry loads the ABIs stored in the database, the 4byte database may be nil
+func NewRegistry(kv ethdb.KV, selectors map[string]string) (*Registry, error) {
+	r := &Registry{
+		kv:        kv,
+		selectors: selectors,
+		abis:      make(map[common.Address]*abi.ABI),
+	}
+	if err := kv.View(context.Background(), func(tx ethdb.Tx) error {
+		return ethdb.ForEach(tx.Cursor(dbutils.ABIBucket), func(k, v []byte) (bool, error) {
+			parsed, err := abi.JSON(bytes.NewReader(v))
+			if err != nil {
+				return false, fmt.Errorf("invalid ABI of %x: %w", k, err)
+			}
+			r.abis[common.BytesToAddress(k)] = &parsed
+			return true, nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the database of the registry
+func (r *Registry) Close() {
+	r.kv.Close()
+}
+
+// Add stores the JSON ABI of the contract, replacing the previous one
+func (r *Registry) Add(address common.Address, abiJSON []byte) error {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid ABI: %w", err)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if err = r.kv.Update(context.Background(), func(tx ethdb.Tx) error {
+		return tx.Cursor(dbutils.ABIBucket).Put(common.CopyBytes(address[:]), common.CopyBytes(abiJSON))
+	}); err != nil {
+		return err
+	}
+	r.abis[address] = &parsed
+	return nil
+}
+
+// Remove deletes the ABI of the contract, reports whether there was one
+func (r *Registry) Remove(address common.Address) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.abis[address]; !ok {
+		return false, nil
+	}
+	if err := r.kv.Update(context.Background(), func(tx ethdb.Tx) error {
+		return tx.Cursor(dbutils.ABIBucket).Delete(address[:], nil)
+	}); err != nil {
+		return false, err
+	}
+	delete(r.abis, address)
+	return true, nil
+}
+
+// Get returns the JSON ABI of the contract as it was uploaded, nil if there is none
+func (r *Registry) Get(address common.Address) (json.RawMessage, error) {
+	var abiJSON json.RawMessage
+	err := r.kv.View(context.Background(), func(tx ethdb.Tx) error {
+		v, err := tx.GetOne(dbutils.ABIBucket, address[:])
+		if err != nil {
+			return err
+		}
+		abiJSON = common.CopyBytes(v)
+		return nil
+	})
+	return abiJSON, err
+}
+
+// DecodeCall decodes the input of the call to the contract and, if given, its output. The method is looked up in the
+// ABI of the contract, then in the other ABIs and then in the 4byte database, which only decodes the input.
+func (r *Registry) DecodeCall(to common.Address, input, output []byte) (*DecodedCall, error) {
+	if len(input) < 4 {
+		return nil, fmt.Errorf("input too short to carry a method selector: %d bytes", len(input))
+	}
+	r.lock.RLock()
+	method, source := r.method(to, input[:4])
+	r.lock.RUnlock()
+	if method == nil {
+		return r.decodeSelector(input)
+	}
+	decoded := &DecodedCall{Signature: method.Sig, Name: method.RawName, Source: source}
+	var err error
+	if decoded.Inputs, err = decodeArguments(method.Inputs, input[4:]); err != nil {
+		return nil, fmt.Errorf("input does not match %s: %w", method.Sig, err)
+	}
+	if len(output) > 0 {
+		if decoded.Outputs, err = decodeArguments(method.Outputs, output); err != nil {
+			return nil, fmt.Errorf("output does not match %s: %w", method.Sig, err)
+		}
+	}
+	return decoded, nil
+}
+
+// method looks the method up in the ABI of the contract, then in the other ABIs
+func (r *Registry) method(to common.Address, selector []byte) (*abi.Method, string) {
+	if contract, ok := r.abis[to]; ok {
+		if method, err := contract.MethodById(selector); err == nil {
+			return method, SourceContract
+		}
+	}
+	for _, other := range r.abis {
+		if method, err := other.MethodById(selector); err == nil {
+			return method, SourceRegistry
+		}
+	}
+	return nil, ""
+}
+
+// decodeSelector decodes the input with the signature found in the 4byte database. As the signatures of the different
+// methods may collide, the input only matches if it encodes back to itself.
+func (r *Registry) decodeSelector(input []byte) (*DecodedCall, error) {
+	signature, ok := r.selectors[hex.EncodeToString(input[:4])]
+	if !ok {
+		return nil, fmt.Errorf("unknown method %x", input[:4])
+	}
+	method, err := parseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.UnpackValues(input[4:])
+	if err != nil {
+		return nil, fmt.Errorf("input does not match %s: %w", signature, err)
+	}
+	if encoded, err := method.Inputs.PackValues(values); err != nil || !bytes.Equal(encoded, input[4:]) {
+		return nil, fmt.Errorf("input does not match %s", signature)
+	}
+	return &DecodedCall{
+		Signature: method.Sig,
+		Name:      method.RawName,
+		Inputs:    newDecodedArguments(method.Inputs, values),
+		Source:    Source4Byte,
+	}, nil
+}
+
+// DecodeLog decodes the event logged by the contract with the ABI of the contract, then with the other ABIs.
+// Anonymous events are not decoded.
+func (r *Registry) DecodeLog(address common.Address, topics []common.Hash, data []byte) (*DecodedLog, error) {
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("anonymous event")
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if contract, ok := r.abis[address]; ok {
+		if event, err := contract.EventByID(topics[0]); err == nil {
+			return decodeEvent(event, topics, data, SourceContract)
+		}
+	}
+	// The standard events, like the transfers of the tokens, are emitted by many contracts with the same signature
+	for _, other := range r.abis {
+		if event, err := other.EventByID(topics[0]); err == nil {
+			if decoded, err := decodeEvent(event, topics, data, SourceRegistry); err == nil {
+				return decoded, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown event %x", topics[0])
+}
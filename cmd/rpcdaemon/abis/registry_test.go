@@ -0,0 +1,107 @@
+package abis
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/accounts/abi"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+)
+
+const erc20ABI = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}]}
+]`
+
+func TestRegistry(t *testing.T) {
+	kv, err := OpenDB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kv.Close()
+	token, other := common.Address{1}, common.Address{2}
+	from, to := common.Address{3}, common.Address{4}
+	selectors := map[string]string{"40c10f19": "mint(address,uint256)"}
+	registry, err := NewRegistry(kv, selectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = registry.Add(token, []byte("not an abi")); err == nil {
+		t.Errorf("invalid ABI accepted")
+	}
+	if err = registry.Add(token, []byte(erc20ABI)); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	parsed, _ := abi.JSON(strings.NewReader(erc20ABI))
+	input, _ := parsed.Pack("transfer", to, big.NewInt(3))
+	output, _ := parsed.Methods["transfer"].Outputs.Pack(true)
+	call, err := registry.DecodeCall(token, input, output)
+	if err != nil {
+		t.Fatalf("decode call: %v", err)
+	}
+	if call.Signature != "transfer(address,uint256)" || call.Source != SourceContract || len(call.Inputs) != 2 || len(call.Outputs) != 1 {
+		t.Fatalf("wrong call: %+v", call)
+	}
+	if call.Inputs[0].Name != "to" || call.Inputs[0].Value != to || call.Inputs[1].Value != "3" || call.Outputs[0].Value != true {
+		t.Errorf("wrong arguments: %+v, %+v", call.Inputs, call.Outputs)
+	}
+	if call, err = registry.DecodeCall(other, input, nil); err != nil || call.Source != SourceRegistry {
+		t.Errorf("wrong call of the contract without the ABI: %+v, %v", call, err)
+	}
+
+	// The calls of the methods not in the ABIs are decoded with the 4byte database, if they encode back to themselves
+	mint := append(hexutil.MustDecode("0x40c10f19"), common.LeftPadBytes(to[:], 32)...)
+	mint = append(mint, common.LeftPadBytes([]byte{10}, 32)...)
+	if call, err = registry.DecodeCall(token, mint, nil); err != nil || call.Source != Source4Byte || call.Name != "mint" || call.Inputs[1].Value != "10" {
+		t.Errorf("wrong 4byte call: %+v, %v", call, err)
+	}
+	dirty := common.CopyBytes(mint)
+	dirty[4] = 1 // Not a valid address
+	if _, err = registry.DecodeCall(token, dirty, nil); err == nil {
+		t.Errorf("call with the garbage in the arguments decoded")
+	}
+	if _, err = registry.DecodeCall(token, hexutil.MustDecode("0xdeadbeef"), nil); err == nil {
+		t.Errorf("unknown method decoded")
+	}
+
+	// The standard events are decoded for the other contracts too
+	topics := []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), from.Hash(), to.Hash()}
+	data := common.LeftPadBytes([]byte{7}, 32)
+	for _, address := range []common.Address{token, other} {
+		log, err := registry.DecodeLog(address, topics, data)
+		if err != nil {
+			t.Fatalf("decode log: %v", err)
+		}
+		if log.Name != "Transfer" || len(log.Inputs) != 3 || log.Inputs[0].Value != from || !log.Inputs[1].Indexed || log.Inputs[1].Value != to || log.Inputs[2].Value != "7" {
+			t.Errorf("wrong log: %+v", log)
+		}
+	}
+	if _, err = registry.DecodeLog(token, topics[:2], data); err == nil {
+		t.Errorf("log with the missing topic decoded")
+	}
+
+	// The ABIs are loaded back from the database
+	reopened, err := NewRegistry(kv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, err := reopened.Get(token); err != nil || string(stored) != erc20ABI {
+		t.Errorf("wrong stored ABI: %s, %v", stored, err)
+	}
+	if _, err = reopened.DecodeCall(token, input, nil); err != nil {
+		t.Errorf("decode with the loaded ABI: %v", err)
+	}
+	if removed, err := reopened.Remove(token); err != nil || !removed {
+		t.Errorf("remove: %v, %v", removed, err)
+	}
+	if _, err = reopened.DecodeCall(token, input, nil); err == nil {
+		t.Errorf("call decoded with the removed ABI")
+	}
+	if stored, err := reopened.Get(token); err != nil || stored != nil {
+		t.Errorf("removed ABI still stored: %s, %v", stored, err)
+	}
+}
@@ -6,8 +6,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ledgerwatch/turbo-geth/accounts"
+	"github.com/ledgerwatch/turbo-geth/accounts/external"
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/abis"
 	"github.com/ledgerwatch/turbo-geth/cmd/utils"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/graphql"
 	"github.com/ledgerwatch/turbo-geth/internal/debug"
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/node"
@@ -33,7 +38,12 @@ type Flags struct {
 	MaxTraces            uint64
 	TraceType            string
 	WebsocketEnabled     bool
+	GraphQLEnabled       bool
+	GraphiQLEnabled      bool
 	RpcAllowListFilePath string
+	ExternalSigner       string
+	ABIDir               string
+	FourByteFile         string
 }
 
 var rootCmd = &cobra.Command{
@@ -76,7 +86,12 @@ func RootCommand() (*cobra.Command, *Flags) {
 	rootCmd.PersistentFlags().Uint64Var(&cfg.MaxTraces, "trace.maxtraces", 200, "Sets a limit on traces that can be returned in trace_filter")
 	rootCmd.PersistentFlags().StringVar(&cfg.TraceType, "trace.type", "parity", "Specify the type of tracing [geth|parity*] (experimental)")
 	rootCmd.PersistentFlags().BoolVar(&cfg.WebsocketEnabled, "ws", false, "Enable Websockets")
+	rootCmd.PersistentFlags().BoolVar(&cfg.GraphQLEnabled, "graphql", false, "Enable GraphQL (EIP-1767) queries on the HTTP-RPC server, at /graphql")
+	rootCmd.PersistentFlags().BoolVar(&cfg.GraphiQLEnabled, "graphql.ui", false, "Serve GraphiQL, the in-browser GraphQL IDE, at /graphql/ui (requires --graphql)")
 	rootCmd.PersistentFlags().StringVar(&cfg.RpcAllowListFilePath, "rpc.accessList", "", "Specify granular (method-by-method) API allowlist")
+	rootCmd.PersistentFlags().StringVar(&cfg.ABIDir, "abi.dir", "", "path to the database of the ABIs uploaded with tg_addABI, kept in memory if empty")
+	rootCmd.PersistentFlags().StringVar(&cfg.FourByteFile, "abi.4byte", "", "path to the 4byte database (JSON object of the method selectors to the signatures) to decode the calls of the contracts without an uploaded ABI")
+	rootCmd.PersistentFlags().StringVar(&cfg.ExternalSigner, "signer", "", "External signer (url or path to ipc file) for eth_sendTransaction, eth_sign and eth_signTransaction, for example clef")
 
 	if err := rootCmd.MarkPersistentFlagFilename("rpc.accessList", "json"); err != nil {
 		panic(err)
@@ -85,9 +100,12 @@ func RootCommand() (*cobra.Command, *Flags) {
 	return rootCmd, cfg
 }
 
-func OpenDB(cfg Flags) (ethdb.KV, ethdb.Backend, error) {
+// OpenDB opens the database, locally or over the private API. The backend and the transaction pool of the core
+// are only available over the private API
+func OpenDB(cfg Flags) (ethdb.KV, ethdb.Backend, remote.TXPOOLClient, error) {
 	var db ethdb.KV
 	var ethBackend ethdb.Backend
+	var txPool remote.TXPOOLClient
 	var err error
 	// Do not change the order of these checks. Chaindata needs to be checked first, because PrivateApiAddr has default value which is not ""
 	// If PrivateApiAddr is checked first, the Chaindata option will never work
@@ -100,31 +118,67 @@ func OpenDB(cfg Flags) (ethdb.KV, ethdb.Backend, error) {
 		if cfg.SnapshotMode != "" {
 			mode, innerErr := snapshotsync.SnapshotModeFromString(cfg.SnapshotMode)
 			if innerErr != nil {
-				return nil, nil, fmt.Errorf("can't process snapshot-mode err:%w", innerErr)
+				return nil, nil, nil, fmt.Errorf("can't process snapshot-mode err:%w", innerErr)
 			}
 			kv, innerErr := snapshotsync.WrapBySnapshotsFromDir(db, cfg.SnapshotDir, mode)
 			if innerErr != nil {
-				return nil, nil, fmt.Errorf("can't wrap by snapshots err:%w", innerErr)
+				return nil, nil, nil, fmt.Errorf("can't wrap by snapshots err:%w", innerErr)
 			}
 			db = kv
 		}
 	} else if cfg.PrivateApiAddr != "" {
 		db, ethBackend, err = ethdb.NewRemote().Path(cfg.PrivateApiAddr).Open(cfg.TLSCertfile, cfg.TLSKeyFile, cfg.TLSCACert)
 		if err != nil {
-			return nil, nil, fmt.Errorf("could not connect to remoteDb: %w", err)
+			return nil, nil, nil, fmt.Errorf("could not connect to remoteDb: %w", err)
 		}
+		txPool = ethBackend.(*ethdb.RemoteBackend).TxPool()
 	} else {
-		return nil, nil, fmt.Errorf("either remote db or lmdb must be specified")
+		return nil, nil, nil, fmt.Errorf("either remote db or lmdb must be specified")
 	}
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not connect to remoteDb: %w", err)
+		return nil, nil, nil, fmt.Errorf("could not connect to remoteDb: %w", err)
 	}
 
-	return db, ethBackend, err
+	return db, ethBackend, txPool, err
 }
 
-func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API) error {
+// OpenSigner connects to the external signer, the signer is nil if it is not configured
+func OpenSigner(cfg Flags) (accounts.Wallet, error) {
+	if cfg.ExternalSigner == "" {
+		return nil, nil
+	}
+	signer, err := external.NewExternalSigner(cfg.ExternalSigner)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the external signer: %w", err)
+	}
+	return signer, nil
+}
+
+// OpenABIRegistry opens the registry of the ABIs of the tg_decode* commands together with the 4byte database, if given
+func OpenABIRegistry(cfg Flags) (*abis.Registry, error) {
+	var selectors map[string]string
+	if cfg.FourByteFile != "" {
+		var err error
+		if selectors, err = abis.LoadSelectors(cfg.FourByteFile); err != nil {
+			return nil, err
+		}
+	}
+	kv, err := abis.OpenDB(cfg.ABIDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the ABI database: %w", err)
+	}
+	registry, err := abis.NewRegistry(kv, selectors)
+	if err != nil {
+		kv.Close()
+		return nil, err
+	}
+	return registry, nil
+}
+
+// StartRpcServer serves the APIs over HTTP and, if enabled, over websockets, until the context is cancelled.
+// The GraphQL queries are served next to them when the GraphQL handler is given
+func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API, graphQLHandler http.Handler) error {
 	// register apis and create handler stack
 	httpEndpoint := fmt.Sprintf("%s:%d", cfg.HttpListenAddress, cfg.HttpPort)
 
@@ -146,7 +200,24 @@ func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API) error {
 		wsHandler = srv.WebsocketHandler([]string{"*"})
 	}
 
+	var graphQLStack http.Handler
+	if graphQLHandler != nil {
+		graphQLStack = node.NewHTTPHandlerStack(graphQLHandler, cfg.HttpCORSDomain, cfg.HttpVirtualHost)
+	}
+
 	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if graphQLStack != nil {
+			switch r.URL.Path {
+			case "/graphql", "/graphql/":
+				graphQLStack.ServeHTTP(w, r)
+				return
+			case "/graphql/ui":
+				if cfg.GraphiQLEnabled {
+					graphql.GraphiQL{}.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
 		if cfg.WebsocketEnabled && r.Method == "GET" {
 			wsHandler.ServeHTTP(w, r)
 		}
@@ -162,7 +233,7 @@ func StartRpcServer(ctx context.Context, cfg Flags, rpcAPI []rpc.API) error {
 	if cfg.TraceType != "parity" {
 		log.Info("Tracing output type: ", cfg.TraceType)
 	}
-	log.Info("HTTP endpoint opened", "url", httpEndpoint, "ws", cfg.WebsocketEnabled)
+	log.Info("HTTP endpoint opened", "url", httpEndpoint, "ws", cfg.WebsocketEnabled, "graphql", graphQLHandler != nil)
 
 	defer func() {
 		srv.Stop()
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+// CliqueAPI the interface for the clique_ RPC commands
+type CliqueAPI interface {
+	GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error)
+	GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error)
+	GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error)
+	GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error)
+	Status(ctx context.Context, numBlocks *hexutil.Uint64) (*clique.Status, error)
+}
+
+// CliqueAPIImpl data structure to store things needed for clique_ commands
+type CliqueAPIImpl struct {
+	dbReader ethdb.Database
+
+	engineLock sync.Mutex
+	engine     *clique.Clique // Created on the first request, keeps the recent snapshots across the requests
+}
+
+// NewCliqueAPI returns CliqueAPIImpl instance
+func NewCliqueAPI(dbReader ethdb.Database) *CliqueAPIImpl {
+	return &CliqueAPIImpl{
+		dbReader: dbReader,
+	}
+}
+
+// defaultStatusBlocks is the number of the latest blocks clique_status reports on by default
+const defaultStatusBlocks = 64
+
+// GetSnapshot implements clique_getSnapshot. Returns the voting snapshot at the given block, the latest one by default.
+func (api *CliqueAPIImpl) GetSnapshot(ctx context.Context, number *rpc.BlockNumber) (*clique.Snapshot, error) {
+	var snap *clique.Snapshot
+	err := api.withAPI(ctx, func(cliqueAPI *clique.API) (err error) {
+		snap, err = cliqueAPI.GetSnapshot(number)
+		return err
+	})
+	return snap, err
+}
+
+// GetSnapshotAtHash implements clique_getSnapshotAtHash. Returns the voting snapshot at the given block.
+func (api *CliqueAPIImpl) GetSnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error) {
+	var snap *clique.Snapshot
+	err := api.withAPI(ctx, func(cliqueAPI *clique.API) (err error) {
+		snap, err = cliqueAPI.GetSnapshotAtHash(hash)
+		return err
+	})
+	return snap, err
+}
+
+// GetSigners implements clique_getSigners. Returns the authorized signers at the given block, the latest one by default.
+func (api *CliqueAPIImpl) GetSigners(ctx context.Context, number *rpc.BlockNumber) ([]common.Address, error) {
+	var signers []common.Address
+	err := api.withAPI(ctx, func(cliqueAPI *clique.API) (err error) {
+		signers, err = cliqueAPI.GetSigners(number)
+		return err
+	})
+	return signers, err
+}
+
+// GetSignersAtHash implements clique_getSignersAtHash. Returns the authorized signers at the given block.
+func (api *CliqueAPIImpl) GetSignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	var signers []common.Address
+	err := api.withAPI(ctx, func(cliqueAPI *clique.API) (err error) {
+		signers, err = cliqueAPI.GetSignersAtHash(hash)
+		return err
+	})
+	return signers, err
+}
+
+// Status implements clique_status. Returns the number of blocks produced by every signer and the percentage of
+// in-turn blocks over the given number of the latest blocks, 64 by default.
+func (api *CliqueAPIImpl) Status(ctx context.Context, numBlocks *hexutil.Uint64) (*clique.Status, error) {
+	n := uint64(defaultStatusBlocks)
+	if numBlocks != nil {
+		if *numBlocks == 0 {
+			return nil, fmt.Errorf("number of blocks must be positive")
+		}
+		n = uint64(*numBlocks)
+	}
+	var status *clique.Status
+	err := api.withChain(ctx, func(chain *cliqueChainReader, engine *clique.Clique) (err error) {
+		status, err = clique.SigningStatus(chain, engine, n)
+		return err
+	})
+	return status, err
+}
+
+// withAPI runs f with the clique API over a read-only transaction
+func (api *CliqueAPIImpl) withAPI(ctx context.Context, f func(cliqueAPI *clique.API) error) error {
+	return api.withChain(ctx, func(chain *cliqueChainReader, engine *clique.Clique) error {
+		return f(clique.NewAPI(chain, engine))
+	})
+}
+
+// withChain runs f with the headers of a read-only transaction and the engine of the chain
+func (api *CliqueAPIImpl) withChain(ctx context.Context, f func(chain *cliqueChainReader, engine *clique.Clique) error) error {
+	tx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	genesis, err := rawdb.ReadBlockByNumber(tx, 0)
+	if err != nil {
+		return err
+	}
+	if genesis == nil {
+		return fmt.Errorf("genesis block not found")
+	}
+	chainConfig, err := rawdb.ReadChainConfig(tx, genesis.Hash())
+	if err != nil {
+		return err
+	}
+	if chainConfig.Clique == nil {
+		return fmt.Errorf("the chain is not using clique consensus")
+	}
+	return f(&cliqueChainReader{config: chainConfig, db: tx}, api.getEngine(chainConfig.Clique))
+}
+
+func (api *CliqueAPIImpl) getEngine(config *params.CliqueConfig) *clique.Clique {
+	api.engineLock.Lock()
+	defer api.engineLock.Unlock()
+	if api.engine == nil {
+		api.engine = clique.NewReadOnly(config, api.dbReader)
+	}
+	return api.engine
+}
+
+// cliqueChainReader implements consensus.ChainHeaderReader over the headers of the database, the current header
+// is the one of the latest executed block
+type cliqueChainReader struct {
+	config *params.ChainConfig
+	db     ethdb.Database
+}
+
+func (cr *cliqueChainReader) Config() *params.ChainConfig {
+	return cr.config
+}
+
+func (cr *cliqueChainReader) CurrentHeader() *types.Header {
+	number, err := getLatestBlockNumber(cr.db)
+	if err != nil {
+		return nil
+	}
+	return rawdb.ReadHeaderByNumber(cr.db, number)
+}
+
+func (cr *cliqueChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return rawdb.ReadHeader(cr.db, hash, number)
+}
+
+func (cr *cliqueChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	return rawdb.ReadHeaderByNumber(cr.db, number)
+}
+
+func (cr *cliqueChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, err := rawdb.ReadHeaderByHash(cr.db, hash)
+	if err != nil {
+		return nil
+	}
+	return header
+}
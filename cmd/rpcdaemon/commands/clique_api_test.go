@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+// newCliqueTestChain inserts the given number of blocks, sealed in turn by two signers, into a new database
+func newCliqueTestChain(t *testing.T, numBlocks int) (*ethdb.ObjectDatabase, []common.Address, []*types.Block) {
+	const (
+		extraVanity = 32
+		extraSeal   = crypto.SignatureLength
+	)
+	// Two signers, ordered as clique orders them to take turns
+	keys := make([]*ecdsa.PrivateKey, 2)
+	signers := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	for i, key := range keys {
+		signers[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+
+	db := ethdb.NewMemDatabase()
+	config := params.AllCliqueProtocolChanges
+	gspec := &core.Genesis{
+		Config:    config,
+		ExtraData: make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal),
+	}
+	for i, signer := range signers {
+		copy(gspec.ExtraData[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	genesis := gspec.MustCommit(db)
+
+	engine := clique.New(config.Clique, db)
+	blocks, _, err := core.GenerateChain(config, genesis, engine, db, numBlocks, func(i int, block *core.BlockGen) {}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatalf("generate blocks: %v", err)
+	}
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = big.NewInt(2) // In-turn
+		sig, _ := crypto.Sign(clique.SealHash(header).Bytes(), keys[header.Number.Uint64()%uint64(len(keys))])
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		blocks[i] = block.WithSeal(header)
+	}
+	if len(blocks) > 0 {
+		if _, err = stagedsync.InsertBlocksInStages(db, ethdb.DefaultStorageMode, config, &vm.Config{}, engine, blocks, true /* checkRoot */); err != nil {
+			t.Fatalf("insert blocks: %v", err)
+		}
+	}
+	return db, signers, blocks
+}
+
+func TestCliqueAPI(t *testing.T) {
+	const numBlocks = 8
+	db, signers, blocks := newCliqueTestChain(t, numBlocks)
+	defer db.Close()
+	countSnapshots := func() int {
+		count := 0
+		if err := db.Walk(dbutils.CliqueBucket, nil, 0, func(_, _ []byte) (bool, error) {
+			count++
+			return true, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+	stored := countSnapshots()
+
+	ctx := context.Background()
+	api := NewCliqueAPI(ethdb.NewObjectDatabase(db.KV()))
+	latest, err := api.GetSigners(ctx, nil)
+	if err != nil {
+		t.Fatalf("get signers: %v", err)
+	}
+	if len(latest) != len(signers) || latest[0] != signers[0] || latest[1] != signers[1] {
+		t.Errorf("wrong signers: have %x, want %x", latest, signers)
+	}
+	atHash, err := api.GetSignersAtHash(ctx, blocks[2].Hash())
+	if err != nil || len(atHash) != len(signers) {
+		t.Errorf("wrong signers at hash: %x, %v", atHash, err)
+	}
+	number := rpc.BlockNumber(3)
+	snap, err := api.GetSnapshot(ctx, &number)
+	if err != nil {
+		t.Fatalf("get snapshot: %v", err)
+	}
+	if snap.Number != 3 || snap.Hash != blocks[2].Hash() {
+		t.Errorf("wrong snapshot: number %d, hash %x", snap.Number, snap.Hash)
+	}
+	if snap, err = api.GetSnapshotAtHash(ctx, blocks[numBlocks-1].Hash()); err != nil || snap.Number != numBlocks {
+		t.Errorf("wrong snapshot at hash: %v, %v", snap, err)
+	}
+
+	// Every block within the range was produced by the in-turn signer
+	n := hexutil.Uint64(4)
+	status, err := api.Status(ctx, &n)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.NumBlocks != 4 || status.InturnPercent != 100 || status.SigningStatus[signers[0]] != 2 || status.SigningStatus[signers[1]] != 2 {
+		t.Errorf("wrong status: %+v", status)
+	}
+	if status, err = api.Status(ctx, nil); err != nil || status.NumBlocks != numBlocks-1 {
+		t.Errorf("wrong default status: %+v, %v", status, err)
+	}
+	if stored != countSnapshots() {
+		t.Errorf("snapshots stored by the read-only engine: have %d, want %d", countSnapshots(), stored)
+	}
+	n = 0
+	if status, err = api.Status(ctx, &n); err == nil {
+		t.Errorf("status of no blocks: %+v", status)
+	}
+}
+
+func TestCliqueAPIStatusEmpty(t *testing.T) {
+	// Neither the genesis block alone nor with a single block on top has any blocks to report on
+	for _, numBlocks := range []int{0, 1} {
+		db, _, _ := newCliqueTestChain(t, numBlocks)
+		api := NewCliqueAPI(ethdb.NewObjectDatabase(db.KV()))
+		if status, err := api.Status(context.Background(), nil); err == nil || err.Error() != "no blocks to report the status on" {
+			t.Errorf("status with the head at block %d: %+v, %v", numBlocks, status, err)
+		}
+		db.Close()
+	}
+}
@@ -1,23 +1,30 @@
 package commands
 
 import (
+	"net/http"
+
+	"github.com/ledgerwatch/turbo-geth/accounts"
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/abis"
 	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/cli"
 	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/filters"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/rpc"
 )
 
 // APIList describes the list of available RPC apis
-func APIList(db ethdb.KV, eth ethdb.Backend, filters *filters.Filters, cfg cli.Flags, customAPIList []rpc.API) []rpc.API {
+func APIList(db ethdb.KV, eth ethdb.Backend, txPool remote.TXPOOLClient, signer accounts.Wallet, registry *abis.Registry, filters *filters.Filters, cfg cli.Flags, customAPIList []rpc.API) []rpc.API {
 	var defaultAPIList []rpc.API
 
 	dbReader := ethdb.NewObjectDatabase(db)
 
-	ethImpl := NewEthAPI(db, dbReader, eth, cfg.Gascap, filters)
-	tgImpl := NewTgAPI(db, dbReader)
+	ethImpl := NewEthAPI(db, dbReader, eth, txPool, signer, cfg.Gascap, filters)
 	netImpl := NewNetAPIImpl(eth)
+	txPoolImpl := NewTxPoolAPI(txPool)
+	cliqueImpl := NewCliqueAPI(dbReader)
 	debugImpl := NewPrivateDebugAPI(dbReader, cfg.Gascap)
 	traceImpl := NewTraceAPI(dbReader, &cfg)
+	tgImpl := NewTgAPI(db, dbReader, registry, traceImpl)
 	web3Impl := NewWeb3APIImpl()
 	dbImpl := NewDBAPIImpl()   /* deprecated */
 	shhImpl := NewSHHAPIImpl() /* deprecated */
@@ -73,6 +80,20 @@ func APIList(db ethdb.KV, eth ethdb.Backend, filters *filters.Filters, cfg cli.F
 				Service:   SHHAPI(shhImpl),
 				Version:   "1.0",
 			})
+		case "txpool":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "txpool",
+				Public:    true,
+				Service:   TxPoolAPI(txPoolImpl),
+				Version:   "1.0",
+			})
+		case "clique":
+			defaultAPIList = append(defaultAPIList, rpc.API{
+				Namespace: "clique",
+				Public:    true,
+				Service:   CliqueAPI(cliqueImpl),
+				Version:   "1.0",
+			})
 		case "tg":
 			defaultAPIList = append(defaultAPIList, rpc.API{
 				Namespace: "tg",
@@ -85,3 +106,8 @@ func APIList(db ethdb.KV, eth ethdb.Backend, filters *filters.Filters, cfg cli.F
 
 	return append(defaultAPIList, customAPIList...)
 }
+
+// GraphQLHandler returns the handler of the GraphQL queries, resolved by the eth API
+func GraphQLHandler(db ethdb.KV, eth ethdb.Backend, txPool remote.TXPOOLClient, filters *filters.Filters, cfg cli.Flags) (http.Handler, error) {
+	return newGraphQLHandler(NewEthAPI(db, ethdb.NewObjectDatabase(db), eth, txPool, nil /* signer */, cfg.Gascap, filters))
+}
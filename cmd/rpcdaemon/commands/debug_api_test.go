@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/eth"
 	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/rpc"
 )
 
 var debugTraceTransactionTests = []struct {
@@ -79,3 +81,37 @@ func TestTraceTransactionNoRefund(t *testing.T) {
 		}
 	}
 }
+
+func TestProfileBlockRange(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	api := NewPrivateDebugAPI(db, 0)
+	folded := true
+	result, err := api.ProfileBlockRange(context.Background(), rpc.EarliestBlockNumber, rpc.LatestBlockNumber, &GasProfileConfig{Folded: &folded})
+	if err != nil {
+		t.Fatalf("profileBlockRange: %v", err)
+	}
+	var txCount uint64
+	for n := uint64(1); n <= result.Blocks; n++ {
+		block, err := rawdb.ReadBlockByNumber(db, n)
+		if err != nil {
+			t.Fatalf("read block %d: %v", n, err)
+		}
+		txCount += uint64(len(block.Transactions()))
+	}
+	if result.Transactions != txCount {
+		t.Errorf("wrong number of profiled transactions, got %d, expected %d", result.Transactions, txCount)
+	}
+	if len(result.Contracts) == 0 || len(result.Ops) == 0 || result.Folded == "" {
+		t.Errorf("expected non-empty hotspots and folded stacks, got %+v", result)
+	}
+	var gas uint64
+	for _, c := range result.Contracts {
+		gas += c.Gas
+	}
+	if gas != result.Gas {
+		t.Errorf("contract hotspots add up to %d gas, expected %d", gas, result.Gas)
+	}
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/gasprofile"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+	"github.com/ledgerwatch/turbo-geth/turbo/rpchelper"
+)
+
+// maxProfileBlocks limits the number of blocks a single debug_profileBlockRange call can re-execute
+const maxProfileBlocks = 1000
+
+// GasProfileConfig are the optional parameters of debug_profileBlockRange
+type GasProfileConfig struct {
+	Metric *string `json:"metric"` // gas (default), time or count
+	Top    *int    `json:"top"`    // number of hotspots to return, 50 by default
+	Folded *bool   `json:"folded"` // include flamegraph folded stacks in the result
+}
+
+// GasProfileResult is the result of debug_profileBlockRange
+type GasProfileResult struct {
+	Blocks       uint64                       `json:"blocks"`
+	Transactions uint64                       `json:"transactions"`
+	Instructions uint64                       `json:"instructions"`
+	Gas          uint64                       `json:"gas"`
+	Time         uint64                       `json:"timeNs"`
+	Contracts    []gasprofile.ContractHotspot `json:"contracts"`
+	Ops          []gasprofile.OpHotspot       `json:"ops"`
+	Folded       string                       `json:"folded,omitempty"`
+}
+
+// ProfileBlockRange implements debug_profileBlockRange. Re-executes the blocks in the
+// range [fromBlock, toBlock] and returns gas and time spent per contract and instruction
+func (api *PrivateDebugAPIImpl) ProfileBlockRange(ctx context.Context, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber, config *GasProfileConfig) (*GasProfileResult, error) {
+	metric, top, folded := gasprofile.MetricGas, 50, false
+	if config != nil {
+		if config.Metric != nil {
+			var err error
+			if metric, err = gasprofile.ParseMetric(*config.Metric); err != nil {
+				return nil, err
+			}
+		}
+		if config.Top != nil {
+			top = *config.Top
+		}
+		if config.Folded != nil {
+			folded = *config.Folded
+		}
+	}
+
+	tx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	from, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(fromBlock), tx)
+	if err != nil {
+		return nil, err
+	}
+	to, _, err := rpchelper.GetBlockNumber(rpc.BlockNumberOrHashWithNumber(toBlock), tx)
+	if err != nil {
+		return nil, err
+	}
+	if from == 0 {
+		from = 1 // genesis has no transactions to execute
+	}
+	if to < from {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	if to-from+1 > maxProfileBlocks {
+		return nil, fmt.Errorf("block range %d-%d is too large, at most %d blocks can be profiled at once", from, to, maxProfileBlocks)
+	}
+
+	profiler := gasprofile.NewProfiler()
+	chainContext := adapter.NewChainContext(tx)
+	for blockNum := from; blockNum <= to; blockNum++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		hash, err := rawdb.ReadCanonicalHash(tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		block := rawdb.ReadBlock(tx, hash, blockNum)
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", blockNum)
+		}
+		ibs := state.New(state.NewPlainDBState(tx, blockNum-1))
+		signer := types.MakeSigner(chainConfig, block.Number())
+		eipsCtx := chainConfig.WithEIPsFlags(ctx, block.Number())
+		for idx, txn := range block.Transactions() {
+			ibs.Prepare(txn.Hash(), hash, idx)
+			msg, err := txn.AsMessage(signer)
+			if err != nil {
+				return nil, err
+			}
+			evm := vm.NewEVM(core.NewEVMContext(msg, block.Header(), chainContext, nil), ibs, chainConfig, vm.Config{Debug: true, Tracer: profiler})
+			if _, err = core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas()), true /* refunds */); err != nil {
+				return nil, fmt.Errorf("transaction %x failed: %v", txn.Hash(), err)
+			}
+			if err = ibs.FinalizeTx(eipsCtx, state.NewNoopWriter()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	total := profiler.Total()
+	result := &GasProfileResult{
+		Blocks:       to - from + 1,
+		Transactions: profiler.Transactions(),
+		Instructions: total.Count,
+		Gas:          total.Gas,
+		Time:         uint64(total.Time),
+		Contracts:    profiler.ContractHotspots(metric, top),
+		Ops:          profiler.OpHotspots(metric, top),
+	}
+	if folded {
+		var buf bytes.Buffer
+		if err = profiler.WriteFolded(&buf, metric); err != nil {
+			return nil, err
+		}
+		result.Folded = buf.String()
+	}
+	return result, nil
+}
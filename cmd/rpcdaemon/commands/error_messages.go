@@ -8,3 +8,9 @@ const NotAvailableChainData = "the function %s is not available, please use --pr
 
 // NotAvailableDeprecated x
 const NotAvailableDeprecated = "the method has been deprecated: %s"
+
+// NotAvailableSigner x
+const NotAvailableSigner = "the method %s is not available, please use --signer option to connect an external signer"
+
+// NotAvailableABIRegistry x
+const NotAvailableABIRegistry = "the method %s is not available, the ABI registry is only kept by the standalone rpcdaemon"
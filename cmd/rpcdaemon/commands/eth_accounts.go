@@ -6,6 +6,7 @@ import (
 	"math/big"
 
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
 	"github.com/ledgerwatch/turbo-geth/turbo/rpchelper"
 
@@ -14,6 +15,18 @@ import (
 	"github.com/ledgerwatch/turbo-geth/rpc"
 )
 
+// Accounts implements eth_accounts. Returns the accounts of the external signer, none if it is not configured.
+func (api *APIImpl) Accounts(_ context.Context) ([]common.Address, error) {
+	addresses := []common.Address{}
+	if api.signer == nil {
+		return addresses, nil
+	}
+	for _, account := range api.signer.Accounts() {
+		addresses = append(addresses, account.Address)
+	}
+	return addresses, nil
+}
+
 // GetBalance implements eth_getBalance. Returns the balance of an account for a given address.
 func (api *APIImpl) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
 	tx, err1 := api.dbReader.Begin(ctx, ethdb.RO)
@@ -40,6 +53,14 @@ func (api *APIImpl) GetBalance(ctx context.Context, address common.Address, bloc
 
 // GetTransactionCount implements eth_getTransactionCount. Returns the number of transactions sent from an address (the nonce).
 func (api *APIImpl) GetTransactionCount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	// The pending nonce counts the transactions in the pool as well
+	if blockNr, ok := blockNrOrHash.Number(); ok && blockNr == rpc.PendingBlockNumber && api.txPool != nil {
+		reply, err := api.txPool.Nonce(ctx, &remote.NonceRequest{Address: address.Bytes()})
+		if err != nil {
+			return nil, err
+		}
+		return (*hexutil.Uint64)(&reply.Nonce), nil
+	}
 	tx, err1 := api.dbReader.Begin(ctx, ethdb.RO)
 	if err1 != nil {
 		return nil, fmt.Errorf("getTransactionCount cannot open tx: %v", err1)
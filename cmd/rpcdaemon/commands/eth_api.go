@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"sync"
 
+	"github.com/ledgerwatch/turbo-geth/accounts"
 	rpcfilters "github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/filters"
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/hexutil"
@@ -13,6 +14,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/eth/filters"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rpc"
@@ -66,9 +68,9 @@ type EthAPI interface {
 	Call(ctx context.Context, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]ethapi.Account) (hexutil.Bytes, error)
 	EstimateGas(ctx context.Context, args ethapi.CallArgs) (hexutil.Uint64, error)
 	SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error)
-	SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
-	Sign(ctx context.Context, _ common.Address, _ hexutil.Bytes) (hexutil.Bytes, error)
-	SignTransaction(_ context.Context, txObject interface{}) (common.Hash, error)
+	SendTransaction(ctx context.Context, args ethapi.SendTxArgs) (common.Hash, error)
+	Sign(ctx context.Context, address common.Address, data hexutil.Bytes) (hexutil.Bytes, error)
+	SignTransaction(ctx context.Context, args ethapi.SendTxArgs) (*ethapi.SignTransactionResult, error)
 	GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*interface{}, error)
 
 	// Mining related (see ./eth_mining.go)
@@ -128,23 +130,29 @@ func (api *BaseAPI) chainConfigWithGenesis(db ethdb.Database) (*params.ChainConf
 // APIImpl is implementation of the EthAPI interface based on remote Db access
 type APIImpl struct {
 	*BaseAPI
-	db           ethdb.KV
-	ethBackend   ethdb.Backend
-	dbReader     ethdb.Database
-	chainContext core.ChainContext
-	GasCap       uint64
-	filters      *rpcfilters.Filters
+	db            ethdb.KV
+	ethBackend    ethdb.Backend
+	txPool        remote.TXPOOLClient
+	signer        accounts.Wallet // External signer of the eth_send/sign methods, nil if not configured
+	dbReader      ethdb.Database
+	chainContext  core.ChainContext
+	GasCap        uint64
+	filters       *rpcfilters.Filters
+	analysisCache *core.AnalysisCache
 }
 
 // NewEthAPI returns APIImpl instance
-func NewEthAPI(db ethdb.KV, dbReader ethdb.Database, eth ethdb.Backend, gascap uint64, filters *rpcfilters.Filters) *APIImpl {
+func NewEthAPI(db ethdb.KV, dbReader ethdb.Database, eth ethdb.Backend, txPool remote.TXPOOLClient, signer accounts.Wallet, gascap uint64, filters *rpcfilters.Filters) *APIImpl {
 	return &APIImpl{
-		BaseAPI:    &BaseAPI{},
-		db:         db,
-		dbReader:   dbReader,
-		ethBackend: eth,
-		GasCap:     gascap,
-		filters:    filters,
+		BaseAPI:       &BaseAPI{},
+		db:            db,
+		dbReader:      dbReader,
+		ethBackend:    eth,
+		txPool:        txPool,
+		signer:        signer,
+		GasCap:        gascap,
+		filters:       filters,
+		analysisCache: core.NewAnalysisCache(core.DefaultAnalysisCacheSize),
 	}
 }
 
@@ -196,3 +204,8 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 	}
 	return result
 }
+
+// newRPCPendingTransaction returns a pending transaction that will serialize to the RPC representation
+func newRPCPendingTransaction(tx *types.Transaction) *RPCTransaction {
+	return newRPCTransaction(tx, common.Hash{}, 0, 0)
+}
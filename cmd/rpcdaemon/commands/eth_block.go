@@ -3,11 +3,15 @@ package commands
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/hexutil"
 	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/rpc"
 	"github.com/ledgerwatch/turbo-geth/turbo/adapter/ethapi"
 )
@@ -26,17 +30,30 @@ func (api *APIImpl) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber
 	}
 	additionalFields := make(map[string]interface{})
 
-	block, err := rawdb.ReadBlockByNumber(tx, blockNum)
-	if err != nil {
-		return nil, err
-	}
-	if block == nil {
-		return nil, fmt.Errorf("block not found: %d", blockNum)
-	}
-
-	td, err := rawdb.ReadTd(tx, block.Hash(), blockNum)
-	if err != nil {
-		return nil, err
+	var block *types.Block
+	var td *big.Int
+	if number == rpc.PendingBlockNumber && api.txPool != nil {
+		if block, err = api.pendingBlock(ctx, tx); err != nil {
+			return nil, err
+		}
+		// The pending block is not stored, its total difficulty extends the one of the latest block
+		if td, err = rawdb.ReadTd(tx, block.ParentHash(), blockNum); err != nil {
+			return nil, err
+		}
+		if td != nil {
+			td = new(big.Int).Add(td, block.Difficulty())
+		}
+	} else {
+		block, err = rawdb.ReadBlockByNumber(tx, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block not found: %d", blockNum)
+		}
+		if td, err = rawdb.ReadTd(tx, block.Hash(), blockNum); err != nil {
+			return nil, err
+		}
 	}
 	additionalFields["totalDifficulty"] = (*hexutil.Big)(td)
 	response, err := ethapi.RPCMarshalBlock(block, true, fullTx, additionalFields)
@@ -109,7 +126,12 @@ func (api *APIImpl) GetBlockTransactionCountByNumber(ctx context.Context, blockN
 		return nil, err
 	}
 
-	block, err := rawdb.ReadBlockByNumber(tx, blockNum)
+	var block *types.Block
+	if blockNr == rpc.PendingBlockNumber && api.txPool != nil {
+		block, err = api.pendingBlock(ctx, tx)
+	} else {
+		block, err = rawdb.ReadBlockByNumber(tx, blockNum)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -138,3 +160,59 @@ func (api *APIImpl) GetBlockTransactionCountByHash(ctx context.Context, blockHas
 	n := hexutil.Uint(len(block.Transactions()))
 	return &n, nil
 }
+
+// pendingBlock assembles the pending block on top of the latest executed block from the pending transactions
+// of the pool, in the order the miner would include them. The transactions are not executed, so the fields
+// depending on the execution, such as the state root, are the ones of the parent block
+func (api *APIImpl) pendingBlock(ctx context.Context, tx ethdb.Database) (*types.Block, error) {
+	latest, err := getLatestBlockNumber(tx)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := rawdb.ReadBlockByNumber(tx, latest)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("block not found: %d", latest)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := api.txPool.Content(ctx, &remote.ContentRequest{})
+	if err != nil {
+		return nil, err
+	}
+	pending, err := decodeSenderTxs(reply.Pending)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := uint64(time.Now().Unix())
+	if parent.Time() >= timestamp {
+		timestamp = parent.Time() + 1
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		Root:       parent.Root(),
+		Difficulty: parent.Difficulty(),
+		GasLimit:   parent.GasLimit(),
+		Time:       timestamp,
+	}
+	var txs []*types.Transaction
+	gasLeft := header.GasLimit
+	ordered := types.NewTransactionsByPriceAndNonce(types.NewEIP155Signer(chainConfig.ChainID), pending)
+	for txn := ordered.Peek(); txn != nil; txn = ordered.Peek() {
+		if txn.Gas() > gasLeft {
+			// The rest of the transactions of the sender cannot be included without this one
+			ordered.Pop()
+			continue
+		}
+		gasLeft -= txn.Gas()
+		txs = append(txs, txn)
+		ordered.Shift()
+	}
+	return types.NewBlock(header, txs, nil, nil), nil
+}
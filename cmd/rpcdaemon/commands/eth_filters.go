@@ -68,3 +68,38 @@ func (api *APIImpl) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 
 	return rpcSub, nil
 }
+
+// NewPendingTransactions send a notification each time a new transaction becomes pending in the pool.
+func (api *APIImpl) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		txsCh := make(chan []*types.Transaction, 1)
+		id := api.filters.SubscribePendingTxs(txsCh)
+
+		for {
+			select {
+			case txs := <-txsCh:
+				for _, tx := range txs {
+					err := notifier.Notify(rpcSub.ID, tx.Hash())
+					if err != nil {
+						log.Warn("error while notifying subscription", "err", err)
+					}
+				}
+			case <-rpcSub.Err():
+				api.filters.Unsubscribe(id)
+				return
+			case <-notifier.Closed():
+				api.filters.Unsubscribe(id)
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/common/hexutil"
 	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/rpc"
 )
 
@@ -21,10 +22,25 @@ func (api *APIImpl) GetTransactionByHash(ctx context.Context, hash common.Hash)
 
 	// https://infura.io/docs/ethereum/json-rpc/eth-getTransactionByHash
 	txn, blockHash, blockNumber, txIndex := rawdb.ReadTransaction(tx, hash)
-	if txn == nil {
-		return nil, fmt.Errorf("transaction %#x not found", hash)
+	if txn != nil {
+		return newRPCTransaction(txn, blockHash, blockNumber, txIndex), nil
 	}
-	return newRPCTransaction(txn, blockHash, blockNumber, txIndex), nil
+
+	// No finalized transaction, try to retrieve it from the pool
+	if api.txPool != nil {
+		reply, err := api.txPool.Get(ctx, &remote.GetRequest{Hashes: [][]byte{hash.Bytes()}})
+		if err != nil {
+			return nil, err
+		}
+		txs, err := decodeTxs(reply.Txs)
+		if err != nil {
+			return nil, err
+		}
+		if len(txs) == 1 && txs[0] != nil {
+			return newRPCPendingTransaction(txs[0]), nil
+		}
+	}
+	return nil, fmt.Errorf("transaction %#x not found", hash)
 }
 
 // GetTransactionByBlockHashAndIndex implements eth_getTransactionByBlockHashAndIndex. Returns information about a transaction given the block's hash and a transaction index.
@@ -0,0 +1,921 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/filters"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	tggraphql "github.com/ledgerwatch/turbo-geth/graphql"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/rpchelper"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+// newGraphQLHandler returns the handler answering the GraphQL (EIP-1767) queries. The queries are resolved
+// against the database by the eth API, the same way as the JSON-RPC requests
+func newGraphQLHandler(api *APIImpl) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(tggraphql.Schema, &gqlResolver{api: api})
+	if err != nil {
+		return nil, err
+	}
+	return &relay.Handler{Schema: schema}, nil
+}
+
+// gqlAccount is the account at the particular block
+type gqlAccount struct {
+	api           *APIImpl
+	address       common.Address
+	blockNrOrHash rpc.BlockNumberOrHash
+}
+
+func (a *gqlAccount) Address(_ context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *gqlAccount) Balance(ctx context.Context) (hexutil.Big, error) {
+	balance, err := a.api.GetBalance(ctx, a.address, a.blockNrOrHash)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *balance, nil
+}
+
+func (a *gqlAccount) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	nonce, err := a.api.GetTransactionCount(ctx, a.address, a.blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return *nonce, nil
+}
+
+func (a *gqlAccount) Code(ctx context.Context) (hexutil.Bytes, error) {
+	return a.api.GetCode(ctx, a.address, a.blockNrOrHash)
+}
+
+func (a *gqlAccount) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	value, err := a.api.GetStorageAt(ctx, a.address, args.Slot.Hex(), a.blockNrOrHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.HexToHash(value), nil
+}
+
+// gqlLog is the log emitted by the transaction
+type gqlLog struct {
+	api         *APIImpl
+	transaction *gqlTransaction
+	log         *types.Log
+}
+
+func (l *gqlLog) Transaction(_ context.Context) *gqlTransaction {
+	return l.transaction
+}
+
+func (l *gqlLog) Account(_ context.Context, args tggraphql.BlockNumberArgs) *gqlAccount {
+	return &gqlAccount{api: l.api, address: l.log.Address, blockNrOrHash: args.NumberOrLatest()}
+}
+
+func (l *gqlLog) Index(_ context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *gqlLog) Topics(_ context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *gqlLog) Data(_ context.Context) hexutil.Bytes {
+	return l.log.Data
+}
+
+// gqlTransaction is the transaction included in the canonical chain. Only the hash is mandatory, the rest
+// is read when required. The fields are resolved concurrently, hence the lock
+type gqlTransaction struct {
+	api   *APIImpl
+	hash  common.Hash
+	lock  sync.Mutex
+	tx    *types.Transaction
+	block *gqlBlock
+	index uint64
+}
+
+// resolve reads the transaction, nil if it is not found
+func (t *gqlTransaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.tx != nil {
+		return t.tx, nil
+	}
+	dbtx, err := t.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback()
+	tx, blockHash, _, index := rawdb.ReadTransaction(dbtx, t.hash)
+	if tx == nil {
+		// Not included yet, the transaction may be pending, without a block
+		if t.api.txPool == nil {
+			return nil, nil
+		}
+		reply, err := t.api.txPool.Get(ctx, &remote.GetRequest{Hashes: [][]byte{t.hash.Bytes()}})
+		if err != nil {
+			return nil, err
+		}
+		txs, err := decodeTxs(reply.Txs)
+		if err != nil || len(txs) != 1 {
+			return nil, err
+		}
+		t.tx = txs[0]
+		return t.tx, nil
+	}
+	t.tx = tx
+	t.block = &gqlBlock{api: t.api, numberOrHash: rpc.BlockNumberOrHashWithHash(blockHash, false)}
+	t.index = index
+	return t.tx, nil
+}
+
+// receipt returns the receipt of the transaction, from the receipts of its block
+func (t *gqlTransaction) receipt(ctx context.Context) (*types.Receipt, error) {
+	if tx, err := t.resolve(ctx); err != nil || tx == nil || t.block == nil {
+		return nil, err
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || uint64(len(receipts)) <= t.index {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *gqlTransaction) Hash(_ context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *gqlTransaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return tx.Data(), nil
+}
+
+func (t *gqlTransaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *gqlTransaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice().ToBig()), nil
+}
+
+func (t *gqlTransaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value().ToBig()), nil
+}
+
+func (t *gqlTransaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *gqlTransaction) To(ctx context.Context, args tggraphql.BlockNumberArgs) (*gqlAccount, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.To() == nil {
+		return nil, err
+	}
+	return &gqlAccount{api: t.api, address: *tx.To(), blockNrOrHash: args.NumberOrLatest()}, nil
+}
+
+func (t *gqlTransaction) From(ctx context.Context, args tggraphql.BlockNumberArgs) (*gqlAccount, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	from := newRPCTransaction(tx, common.Hash{}, 0, 0).From
+	return &gqlAccount{api: t.api, address: from, blockNrOrHash: args.NumberOrLatest()}, nil
+}
+
+func (t *gqlTransaction) Block(ctx context.Context) (*gqlBlock, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *gqlTransaction) Index(ctx context.Context) (*int32, error) {
+	if tx, err := t.resolve(ctx); err != nil || tx == nil || t.block == nil {
+		return nil, err
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+func (t *gqlTransaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(receipt.Status)
+	return &status, nil
+}
+
+func (t *gqlTransaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := hexutil.Uint64(receipt.GasUsed)
+	return &gasUsed, nil
+}
+
+func (t *gqlTransaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &gasUsed, nil
+}
+
+func (t *gqlTransaction) CreatedContract(ctx context.Context, args tggraphql.BlockNumberArgs) (*gqlAccount, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &gqlAccount{api: t.api, address: receipt.ContractAddress, blockNrOrHash: args.NumberOrLatest()}, nil
+}
+
+func (t *gqlTransaction) Logs(ctx context.Context) (*[]*gqlLog, error) {
+	receipt, err := t.receipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	logs := make([]*gqlLog, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		logs[i] = &gqlLog{api: t.api, transaction: t, log: log}
+	}
+	return &logs, nil
+}
+
+func (t *gqlTransaction) R(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	_, r, _ := tx.RawSignatureValues()
+	return hexutil.Big(*r.ToBig()), nil
+}
+
+func (t *gqlTransaction) S(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	_, _, s := tx.RawSignatureValues()
+	return hexutil.Big(*s.ToBig()), nil
+}
+
+func (t *gqlTransaction) V(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	v, _, _ := tx.RawSignatureValues()
+	return hexutil.Big(*v.ToBig()), nil
+}
+
+// gqlBlock is the block given by number or hash. The header, the body and the receipts are read once, when
+// first required, so the transactions of the block and their receipts are served by the same query
+type gqlBlock struct {
+	api          *APIImpl
+	numberOrHash rpc.BlockNumberOrHash
+	lock         sync.Mutex
+	header       *types.Header
+	block        *types.Block
+	receipts     types.Receipts
+}
+
+// resolveHeader reads the header of the block, nil if it is not found
+func (b *gqlBlock) resolveHeader(ctx context.Context) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.header != nil {
+		return b.header, nil
+	}
+	tx, err := b.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	number, hash, err := rpchelper.GetBlockNumber(b.numberOrHash, tx)
+	if err != nil {
+		return nil, err
+	}
+	b.header = rawdb.ReadHeader(tx, hash, number)
+	return b.header, nil
+}
+
+// resolve reads the block with its transactions and ommers, nil if it is not found
+func (b *gqlBlock) resolve(ctx context.Context) (*types.Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.block != nil {
+		return b.block, nil
+	}
+	tx, err := b.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	b.block = rawdb.ReadBlock(tx, header.Hash(), header.Number.Uint64())
+	return b.block, nil
+}
+
+// resolveReceipts reads the receipts of the block, or re-executes the block if they are not stored
+func (b *gqlBlock) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.receipts != nil {
+		return b.receipts, nil
+	}
+	tx, err := b.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	chainConfig, err := b.api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	if b.receipts, err = getReceipts(ctx, tx, chainConfig, header.Number.Uint64(), header.Hash()); err != nil {
+		return nil, err
+	}
+	return b.receipts, nil
+}
+
+// stateAt is the block, given by hash, to read the state and to execute the calls at
+func (b *gqlBlock) stateAt(ctx context.Context) (rpc.BlockNumberOrHash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return rpc.BlockNumberOrHash{}, err
+	}
+	if header == nil {
+		return b.numberOrHash, nil
+	}
+	return rpc.BlockNumberOrHashWithHash(header.Hash(), false), nil
+}
+
+// transaction returns the transaction of the block at the index
+func (b *gqlBlock) transaction(tx *types.Transaction, index int) *gqlTransaction {
+	return &gqlTransaction{api: b.api, hash: tx.Hash(), tx: tx, block: b, index: uint64(index)}
+}
+
+func (b *gqlBlock) Number(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.Number.Uint64()), nil
+}
+
+func (b *gqlBlock) Hash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+func (b *gqlBlock) Parent(ctx context.Context) (*gqlBlock, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil || header.Number.Sign() == 0 {
+		return nil, err
+	}
+	return &gqlBlock{api: b.api, numberOrHash: rpc.BlockNumberOrHashWithHash(header.ParentHash, false)}, nil
+}
+
+func (b *gqlBlock) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return hexutil.Bytes{}, err
+	}
+	return header.Nonce[:], nil
+}
+
+func (b *gqlBlock) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *gqlBlock) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, nil
+}
+
+func (b *gqlBlock) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *gqlBlock) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+func (b *gqlBlock) Miner(ctx context.Context, args tggraphql.BlockNumberArgs) (*gqlAccount, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	return &gqlAccount{api: b.api, address: header.Coinbase, blockNrOrHash: args.NumberOrLatest()}, nil
+}
+
+func (b *gqlBlock) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return hexutil.Bytes{}, err
+	}
+	return header.Extra, nil
+}
+
+func (b *gqlBlock) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasLimit), nil
+}
+
+func (b *gqlBlock) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasUsed), nil
+}
+
+func (b *gqlBlock) Timestamp(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.Time), nil
+}
+
+func (b *gqlBlock) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return hexutil.Bytes{}, err
+	}
+	return header.Bloom.Bytes(), nil
+}
+
+func (b *gqlBlock) MixHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.MixDigest, nil
+}
+
+func (b *gqlBlock) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Difficulty), nil
+}
+
+func (b *gqlBlock) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return hexutil.Big{}, err
+	}
+	tx, err := b.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	defer tx.Rollback()
+	td, err := rawdb.ReadTd(tx, header.Hash(), header.Number.Uint64())
+	if err != nil || td == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *gqlBlock) OmmerCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Uncles()))
+	return &count, nil
+}
+
+func (b *gqlBlock) Ommers(ctx context.Context) (*[]*gqlBlock, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ommers := make([]*gqlBlock, len(block.Uncles()))
+	for i, uncle := range block.Uncles() {
+		ommers[i] = &gqlBlock{api: b.api, numberOrHash: rpc.BlockNumberOrHashWithHash(uncle.Hash(), false), header: uncle}
+	}
+	return &ommers, nil
+}
+
+func (b *gqlBlock) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*gqlBlock, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	uncles := block.Uncles()
+	if args.Index < 0 || int(args.Index) >= len(uncles) {
+		return nil, nil
+	}
+	uncle := uncles[args.Index]
+	return &gqlBlock{api: b.api, numberOrHash: rpc.BlockNumberOrHashWithHash(uncle.Hash(), false), header: uncle}, nil
+}
+
+func (b *gqlBlock) OmmerHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.UncleHash, nil
+}
+
+func (b *gqlBlock) Transactions(ctx context.Context) (*[]*gqlTransaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := make([]*gqlTransaction, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs[i] = b.transaction(tx, i)
+	}
+	return &txs, nil
+}
+
+func (b *gqlBlock) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*gqlTransaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	return b.transaction(txs[args.Index], int(args.Index)), nil
+}
+
+func (b *gqlBlock) Logs(ctx context.Context, args struct{ Filter tggraphql.BlockFilterCriteria }) ([]*gqlLog, error) {
+	var addresses []common.Address
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	var topics [][]common.Hash
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var logs []*gqlLog
+	for i, receipt := range receipts {
+		matched := filterLogs(receipt.Logs, nil, nil, addresses, topics)
+		if len(matched) == 0 {
+			continue
+		}
+		transaction := b.transaction(block.Transactions()[i], i)
+		for _, log := range matched {
+			logs = append(logs, &gqlLog{api: b.api, transaction: transaction, log: log})
+		}
+	}
+	return logs, nil
+}
+
+func (b *gqlBlock) Account(ctx context.Context, args struct{ Address common.Address }) (*gqlAccount, error) {
+	blockNrOrHash, err := b.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlAccount{api: b.api, address: args.Address, blockNrOrHash: blockNrOrHash}, nil
+}
+
+func (b *gqlBlock) Call(ctx context.Context, args struct{ Data ethapi.CallArgs }) (*gqlCallResult, error) {
+	blockNrOrHash, err := b.stateAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gqlCall(ctx, b.api, args.Data, blockNrOrHash)
+}
+
+func (b *gqlBlock) EstimateGas(ctx context.Context, args struct{ Data ethapi.CallArgs }) (hexutil.Uint64, error) {
+	blockNrOrHash, err := b.stateAt(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return b.api.DoEstimateGas(ctx, args.Data, blockNrOrHash, new(big.Int).SetUint64(b.api.GasCap))
+}
+
+// gqlCallResult is the outcome of the call
+type gqlCallResult struct {
+	data    hexutil.Bytes
+	gasUsed hexutil.Uint64
+	status  hexutil.Uint64 // 1 on success, 0 on failure
+}
+
+func (c *gqlCallResult) Data() hexutil.Bytes {
+	return c.data
+}
+
+func (c *gqlCallResult) GasUsed() hexutil.Uint64 {
+	return c.gasUsed
+}
+
+func (c *gqlCallResult) Status() hexutil.Uint64 {
+	return c.status
+}
+
+// gqlCall executes the call on top of the state of the block, the same way as eth_call does
+func gqlCall(ctx context.Context, api *APIImpl, args ethapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (*gqlCallResult, error) {
+	dbtx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback()
+	chainConfig, err := api.chainConfig(dbtx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := transactions.DoCall(ctx, args, dbtx, blockNrOrHash, nil, api.GasCap, chainConfig, api.analysisCache)
+	if err != nil {
+		return nil, err
+	}
+	status := hexutil.Uint64(1)
+	if result.Failed() {
+		status = 0
+	}
+	return &gqlCallResult{data: result.ReturnData, gasUsed: hexutil.Uint64(result.UsedGas), status: status}, nil
+}
+
+// gqlPending is the pending state. The pending transactions are the ones of the pool, if the daemon is connected
+// to it, but the state is the one of the latest block
+type gqlPending struct {
+	api *APIImpl
+}
+
+// transactions returns the transactions of the pending block
+func (p *gqlPending) transactions(ctx context.Context) ([]*types.Transaction, error) {
+	if p.api.txPool == nil {
+		return nil, nil
+	}
+	tx, err := p.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	block, err := p.api.pendingBlock(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return block.Transactions(), nil
+}
+
+func (p *gqlPending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.transactions(ctx)
+	return int32(len(txs)), err
+}
+
+func (p *gqlPending) Transactions(ctx context.Context) (*[]*gqlTransaction, error) {
+	txs, err := p.transactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*gqlTransaction, len(txs))
+	for i, tx := range txs {
+		result[i] = &gqlTransaction{api: p.api, hash: tx.Hash(), tx: tx}
+	}
+	return &result, nil
+}
+
+func (p *gqlPending) Account(_ context.Context, args struct{ Address common.Address }) *gqlAccount {
+	return &gqlAccount{api: p.api, address: args.Address, blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)}
+}
+
+func (p *gqlPending) Call(ctx context.Context, args struct{ Data ethapi.CallArgs }) (*gqlCallResult, error) {
+	return gqlCall(ctx, p.api, args.Data, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+}
+
+func (p *gqlPending) EstimateGas(ctx context.Context, args struct{ Data ethapi.CallArgs }) (hexutil.Uint64, error) {
+	return p.api.DoEstimateGas(ctx, args.Data, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), new(big.Int).SetUint64(p.api.GasCap))
+}
+
+// gqlSyncState is the progress of the sync, as reported by eth_syncing
+type gqlSyncState struct {
+	currentBlock uint64
+	highestBlock uint64
+}
+
+func (s *gqlSyncState) StartingBlock() hexutil.Uint64 {
+	return 0
+}
+
+func (s *gqlSyncState) CurrentBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.currentBlock)
+}
+
+func (s *gqlSyncState) HighestBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.highestBlock)
+}
+
+func (s *gqlSyncState) PulledStates() *hexutil.Uint64 {
+	return nil
+}
+
+func (s *gqlSyncState) KnownStates() *hexutil.Uint64 {
+	return nil
+}
+
+// gqlResolver is the root of the queries and the mutations
+type gqlResolver struct {
+	api *APIImpl
+}
+
+func (r *gqlResolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*gqlBlock, error) {
+	block := &gqlBlock{api: r.api, numberOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)}
+	if args.Number != nil {
+		block.numberOrHash = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*args.Number))
+	} else if args.Hash != nil {
+		block.numberOrHash = rpc.BlockNumberOrHashWithHash(*args.Hash, false)
+	}
+	header, err := block.resolveHeader(ctx)
+	if err != nil || header == nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *gqlResolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*gqlBlock, error) {
+	var to uint64
+	if args.To != nil {
+		to = uint64(*args.To)
+	} else {
+		tx, err := r.api.dbReader.Begin(ctx, ethdb.RO)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		if to, err = getLatestBlockNumber(tx); err != nil {
+			return nil, err
+		}
+	}
+	if to < uint64(args.From) {
+		return []*gqlBlock{}, nil
+	}
+	blocks := make([]*gqlBlock, 0, to-uint64(args.From)+1)
+	for number := uint64(args.From); number <= to; number++ {
+		blocks = append(blocks, &gqlBlock{api: r.api, numberOrHash: rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number))})
+	}
+	return blocks, nil
+}
+
+func (r *gqlResolver) Pending(_ context.Context) *gqlPending {
+	return &gqlPending{api: r.api}
+}
+
+func (r *gqlResolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*gqlTransaction, error) {
+	transaction := &gqlTransaction{api: r.api, hash: args.Hash}
+	tx, err := transaction.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (r *gqlResolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	return r.api.SendRawTransaction(ctx, args.Data)
+}
+
+func (r *gqlResolver) Logs(ctx context.Context, args struct{ Filter tggraphql.FilterCriteria }) ([]*gqlLog, error) {
+	var crit filters.FilterCriteria
+	if args.Filter.FromBlock != nil {
+		crit.FromBlock = new(big.Int).SetUint64(uint64(*args.Filter.FromBlock))
+	}
+	if args.Filter.ToBlock != nil {
+		crit.ToBlock = new(big.Int).SetUint64(uint64(*args.Filter.ToBlock))
+	}
+	if args.Filter.Addresses != nil {
+		crit.Addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		crit.Topics = *args.Filter.Topics
+	}
+	logs, err := r.api.GetLogs(ctx, crit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*gqlLog, len(logs))
+	for i, log := range logs {
+		result[i] = &gqlLog{api: r.api, transaction: &gqlTransaction{api: r.api, hash: log.TxHash}, log: log}
+	}
+	return result, nil
+}
+
+func (r *gqlResolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.api.GasPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *price, nil
+}
+
+func (r *gqlResolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	version, err := r.api.ProtocolVersion(ctx)
+	return int32(version), err
+}
+
+func (r *gqlResolver) ChainID(ctx context.Context) (hexutil.Big, error) {
+	chainID, err := r.api.ChainId(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*new(big.Int).SetUint64(uint64(chainID))), nil
+}
+
+// Syncing returns nil when the node is not syncing, the same as eth_syncing
+func (r *gqlResolver) Syncing(ctx context.Context) (*gqlSyncState, error) {
+	tx, err := r.api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	highestBlock, err := stages.GetStageProgress(tx, stages.Headers)
+	if err != nil {
+		return nil, err
+	}
+	currentBlock, err := stages.GetStageProgress(tx, stages.Finish)
+	if err != nil {
+		return nil, err
+	}
+	if currentBlock >= highestBlock {
+		return nil, nil
+	}
+	return &gqlSyncState{currentBlock: currentBlock, highestBlock: highestBlock}, nil
+}
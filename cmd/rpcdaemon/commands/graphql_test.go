@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+// queryGraphQL posts the query to the handler and decodes the data of the response
+func queryGraphQL(t *testing.T, handler http.Handler, query string, data interface{}) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		t.Fatalf("encode query: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err = json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("query failed: %v", result.Errors)
+	}
+	if err = json.Unmarshal(result.Data, data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+}
+
+func TestGraphQLBlockWithReceipts(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	api := NewEthAPI(db.(ethdb.HasKV).KV(), db, nil, nil, nil, 5000000, nil)
+	handler, err := newGraphQLHandler(api)
+	if err != nil {
+		t.Fatalf("create handler: %v", err)
+	}
+	type gqlTx struct {
+		Hash              common.Hash
+		Index             int
+		Status            hexutil.Uint64
+		GasUsed           hexutil.Uint64
+		CumulativeGasUsed hexutil.Uint64
+		From              struct{ Address common.Address }
+		Logs              []struct{ Index int }
+	}
+	var result struct {
+		Block struct {
+			Number           hexutil.Uint64
+			Hash             common.Hash
+			TransactionCount int
+			TotalDifficulty  hexutil.Big
+			Transactions     []gqlTx
+		}
+	}
+	// The transactions of the block come with their receipts in the same query
+	queryGraphQL(t, handler, `{ block(number: 7) { number hash transactionCount totalDifficulty
+		transactions { hash index status gasUsed cumulativeGasUsed from { address } logs { index topics } } } }`, &result)
+	block, err := rawdb.ReadBlockByNumber(db, 7)
+	if err != nil {
+		t.Fatalf("read block: %v", err)
+	}
+	receipts, err := getReceipts(context.Background(), db, api.ChainConfig(), block.NumberU64(), block.Hash())
+	if err != nil {
+		t.Fatalf("read receipts: %v", err)
+	}
+	got := result.Block
+	if uint64(got.Number) != block.NumberU64() || got.Hash != block.Hash() {
+		t.Errorf("wrong block, got %d %x, expected %d %x", got.Number, got.Hash, block.NumberU64(), block.Hash())
+	}
+	if got.TransactionCount != len(block.Transactions()) || len(got.Transactions) != len(block.Transactions()) {
+		t.Fatalf("wrong number of transactions, got %d (%d listed), expected %d", got.TransactionCount, len(got.Transactions), len(block.Transactions()))
+	}
+	if got.TotalDifficulty.ToInt().Sign() <= 0 {
+		t.Errorf("expected positive total difficulty, got %v", got.TotalDifficulty.ToInt())
+	}
+	for i, tx := range got.Transactions {
+		if tx.Hash != block.Transactions()[i].Hash() || tx.Index != i {
+			t.Errorf("wrong transaction %d, got %x at %d", i, tx.Hash, tx.Index)
+		}
+		receipt := receipts[i]
+		if uint64(tx.Status) != receipt.Status || uint64(tx.GasUsed) != receipt.GasUsed || uint64(tx.CumulativeGasUsed) != receipt.CumulativeGasUsed {
+			t.Errorf("wrong receipt of transaction %d, got %+v, expected %+v", i, tx, receipt)
+		}
+		if len(tx.Logs) != len(receipt.Logs) {
+			t.Errorf("wrong number of logs of transaction %d, got %d, expected %d", i, len(tx.Logs), len(receipt.Logs))
+		}
+		if tx.From.Address == (common.Address{}) {
+			t.Errorf("missing sender of transaction %d", i)
+		}
+	}
+}
+
+func TestGraphQLAccountAndCall(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	api := NewEthAPI(db.(ethdb.HasKV).KV(), db, nil, nil, nil, 5000000, nil)
+	handler, err := newGraphQLHandler(api)
+	if err != nil {
+		t.Fatalf("create handler: %v", err)
+	}
+	address := common.Address{1}
+	var result struct {
+		Block struct {
+			Account struct {
+				Balance          hexutil.Big
+				TransactionCount hexutil.Uint64
+			}
+			Call struct {
+				Status  hexutil.Uint64
+				GasUsed hexutil.Uint64
+			}
+			EstimateGas hexutil.Uint64
+		}
+	}
+	queryGraphQL(t, handler, `{ block { account(address: "`+address.Hex()+`") { balance transactionCount }
+		call(data: {to: "`+address.Hex()+`"}) { status gasUsed } estimateGas(data: {to: "`+address.Hex()+`"}) } }`, &result)
+	got := result.Block
+	balance, err := api.GetBalance(context.Background(), address, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		t.Fatalf("get balance: %v", err)
+	}
+	if got.Account.Balance.ToInt().Cmp(balance.ToInt()) != 0 || got.Account.Balance.ToInt().Sign() == 0 {
+		t.Errorf("wrong balance, got %v, expected %v", got.Account.Balance.ToInt(), balance.ToInt())
+	}
+	if got.Call.Status != 1 || got.Call.GasUsed != 21000 {
+		t.Errorf("wrong call result %+v", got.Call)
+	}
+	if got.EstimateGas != 21000 {
+		t.Errorf("wrong gas estimate %d", got.EstimateGas)
+	}
+}
@@ -1,11 +1,21 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/accounts"
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/rpc"
 )
 
 // SendRawTransaction implements eth_sendRawTransaction. Creates new message call transaction or a contract creation for previously-signed transactions.
@@ -19,6 +29,150 @@ func (api *APIImpl) SendRawTransaction(_ context.Context, encodedTx hexutil.Byte
 }
 
 // SendTransaction implements eth_sendTransaction. Creates new message call transaction or a contract creation if the data field contains code.
-func (api *APIImpl) SendTransaction(_ context.Context, txObject interface{}) (common.Hash, error) {
-	return common.Hash{0}, fmt.Errorf(NotImplemented, "eth_sendTransaction")
+// The transaction is signed by the external signer, the missing nonce, gas and gas price are filled in from the state.
+func (api *APIImpl) SendTransaction(ctx context.Context, args ethapi.SendTxArgs) (common.Hash, error) {
+	if api.ethBackend == nil {
+		// We're running in --chaindata mode or otherwise cannot get the backend
+		return common.Hash{}, fmt.Errorf(NotAvailableChainData, "eth_sendTransaction")
+	}
+	signed, err := api.signTransaction(ctx, args, "eth_sendTransaction")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	encodedTx, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	res, err := api.ethBackend.AddLocal(encodedTx)
+	return common.BytesToHash(res), err
+}
+
+// SignTransaction implements eth_signTransaction. Signs the transaction with the external signer without submitting it,
+// the missing nonce, gas and gas price are filled in from the state.
+func (api *APIImpl) SignTransaction(ctx context.Context, args ethapi.SendTxArgs) (*ethapi.SignTransactionResult, error) {
+	signed, err := api.signTransaction(ctx, args, "eth_signTransaction")
+	if err != nil {
+		return nil, err
+	}
+	encodedTx, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &ethapi.SignTransactionResult{Raw: encodedTx, Tx: signed}, nil
+}
+
+// Sign implements eth_sign. Calculates an Ethereum specific signature with: sign(keccak256('\\x19Ethereum Signed Message:\\n' + len(message) + message))).
+// The signature is made by the external signer.
+func (api *APIImpl) Sign(_ context.Context, address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if api.signer == nil {
+		return nil, fmt.Errorf(NotAvailableSigner, "eth_sign")
+	}
+	account := accounts.Account{Address: address}
+	if !api.signer.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	signature, err := api.signer.SignText(account, data)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// signTransaction fills in the defaults of the transaction and signs it with the external signer
+func (api *APIImpl) signTransaction(ctx context.Context, args ethapi.SendTxArgs, method string) (*types.Transaction, error) {
+	if api.signer == nil {
+		return nil, fmt.Errorf(NotAvailableSigner, method)
+	}
+	account := accounts.Account{Address: args.From}
+	if !api.signer.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if err := api.setTxDefaults(ctx, &args); err != nil {
+		return nil, err
+	}
+	tx, err := toTransaction(args)
+	if err != nil {
+		return nil, err
+	}
+
+	dbtx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback()
+	chainConfig, err := api.chainConfig(dbtx)
+	if err != nil {
+		return nil, err
+	}
+	return api.signer.SignTx(account, tx, chainConfig.ChainID)
+}
+
+// setTxDefaults fills in the nonce from the pending or the latest state, the gas price from the oracle and the gas from the estimation
+func (api *APIImpl) setTxDefaults(ctx context.Context, args *ethapi.SendTxArgs) error {
+	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
+		return errors.New(`both "data" and "input" are set and not equal. Please use "input" to pass transaction call data`)
+	}
+	if args.Input == nil {
+		args.Input = args.Data
+	}
+	if args.To == nil && (args.Input == nil || len(*args.Input) == 0) {
+		return errors.New(`contract creation without any data provided`)
+	}
+	if args.Value == nil {
+		args.Value = new(hexutil.Big)
+	}
+	if args.GasPrice == nil {
+		price, err := api.GasPrice(ctx)
+		if err != nil {
+			return err
+		}
+		args.GasPrice = price
+	}
+	if args.Nonce == nil {
+		// The pending nonce counts the transactions in the pool, the latest state is all there is without the pool
+		blockNr := rpc.LatestBlockNumber
+		if api.txPool != nil {
+			blockNr = rpc.PendingBlockNumber
+		}
+		nonce, err := api.GetTransactionCount(ctx, args.From, rpc.BlockNumberOrHashWithNumber(blockNr))
+		if err != nil {
+			return err
+		}
+		args.Nonce = nonce
+	}
+	if args.Gas == nil {
+		gas, err := api.EstimateGas(ctx, ethapi.CallArgs{
+			From:     &args.From,
+			To:       args.To,
+			GasPrice: args.GasPrice,
+			Value:    args.Value,
+			Data:     args.Input,
+		})
+		if err != nil {
+			return err
+		}
+		args.Gas = &gas
+	}
+	return nil
+}
+
+// toTransaction creates the unsigned transaction of the arguments with the defaults filled in
+func toTransaction(args ethapi.SendTxArgs) (*types.Transaction, error) {
+	value, overflow := uint256.FromBig(args.Value.ToInt())
+	if overflow {
+		return nil, errors.New("value overflows 256 bits")
+	}
+	gasPrice, overflow := uint256.FromBig(args.GasPrice.ToInt())
+	if overflow {
+		return nil, errors.New("gas price overflows 256 bits")
+	}
+	var input []byte
+	if args.Input != nil {
+		input = *args.Input
+	}
+	if args.To == nil {
+		return types.NewContractCreation(uint64(*args.Nonce), value, uint64(*args.Gas), gasPrice, input), nil
+	}
+	return types.NewTransaction(uint64(*args.Nonce), *args.To, value, uint64(*args.Gas), gasPrice, input), nil
 }
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/accounts"
+	"github.com/ledgerwatch/turbo-geth/accounts/external"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/internal/ethapi"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	signercore "github.com/ledgerwatch/turbo-geth/signer/core"
+)
+
+// testClef serves the account_ methods of clef which the external signer uses, approving every request
+type testClef struct {
+	key    *ecdsa.PrivateKey
+	signer types.Signer
+}
+
+func (c *testClef) Version() string {
+	return "6.0.0"
+}
+
+func (c *testClef) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(c.key.PublicKey)}
+}
+
+func (c *testClef) SignTransaction(args signercore.SendTxArgs) (*ethapi.SignTransactionResult, error) {
+	value, _ := uint256.FromBig(args.Value.ToInt())
+	gasPrice, _ := uint256.FromBig(args.GasPrice.ToInt())
+	var tx *types.Transaction
+	if args.To == nil {
+		tx = types.NewContractCreation(uint64(args.Nonce), value, uint64(args.Gas), gasPrice, *args.Data)
+	} else {
+		tx = types.NewTransaction(uint64(args.Nonce), args.To.Address(), value, uint64(args.Gas), gasPrice, *args.Data)
+	}
+	signed, err := types.SignTx(tx, c.signer, c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &ethapi.SignTransactionResult{Raw: raw, Tx: signed}, nil
+}
+
+func (c *testClef) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	signature, err := crypto.Sign(accounts.TextHash(data), c.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// testBackend collects the transactions submitted to the core
+type testBackend struct {
+	txs []*types.Transaction
+}
+
+func (b *testBackend) AddLocal(encodedTx []byte) ([]byte, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return nil, err
+	}
+	b.txs = append(b.txs, tx)
+	return tx.Hash().Bytes(), nil
+}
+
+func (b *testBackend) Etherbase() (common.Address, error) {
+	return common.Address{}, nil
+}
+
+func (b *testBackend) NetVersion() (uint64, error) {
+	return 1, nil
+}
+
+func (b *testBackend) Subscribe(func(*remote.SubscribeReply)) error {
+	return nil
+}
+
+func TestSendTransaction(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	chainSigner := types.NewEIP155Signer(params.AllEthashProtocolChanges.ChainID)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err = server.RegisterName("account", &testClef{key: key, signer: chainSigner}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	signer, err := external.NewExternalSigner(httpServer.URL)
+	if err != nil {
+		t.Fatalf("connect to the signer: %v", err)
+	}
+
+	// Without the signer there are no accounts to send from
+	noSigner := NewEthAPI(db.(ethdb.HasKV).KV(), db, &testBackend{}, nil, nil, 5000000, nil)
+	if addresses, err := noSigner.Accounts(ctx); err != nil || len(addresses) != 0 {
+		t.Errorf("wrong accounts without the signer: %x, %v", addresses, err)
+	}
+	if _, err = noSigner.SendTransaction(ctx, ethapi.SendTxArgs{From: from, To: &common.Address{1}}); err == nil {
+		t.Errorf("transaction sent without the signer")
+	}
+
+	backend := &testBackend{}
+	api := NewEthAPI(db.(ethdb.HasKV).KV(), db, backend, nil, signer, 5000000, nil)
+	if addresses, err := api.Accounts(ctx); err != nil || len(addresses) != 1 || addresses[0] != from {
+		t.Errorf("wrong accounts: %x, %v", addresses, err)
+	}
+
+	message := hexutil.Bytes("hello")
+	signature, err := api.Sign(ctx, from, message)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] -= 27
+	if pub, err := crypto.SigToPub(accounts.TextHash(message), signature); err != nil || crypto.PubkeyToAddress(*pub) != from {
+		t.Errorf("wrong signature: %x, %v", signature, err)
+	}
+
+	// The nonce, gas and gas price are filled in
+	nonce, err := api.GetTransactionCount(ctx, from, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := api.SendTransaction(ctx, ethapi.SendTxArgs{From: from, To: &common.Address{1}, Value: (*hexutil.Big)(common.Big1)})
+	if err != nil {
+		t.Fatalf("send transaction: %v", err)
+	}
+	if len(backend.txs) != 1 || backend.txs[0].Hash() != hash {
+		t.Fatalf("transaction not submitted: %v", backend.txs)
+	}
+	tx := backend.txs[0]
+	if sender, err := types.Sender(chainSigner, tx); err != nil || sender != from {
+		t.Errorf("wrong sender: %x, %v", sender, err)
+	}
+	if tx.Nonce() != uint64(*nonce) || tx.Gas() != params.TxGas || tx.GasPrice().IsZero() || tx.Value().Uint64() != 1 {
+		t.Errorf("wrong defaults: nonce %d, gas %d, gas price %d", tx.Nonce(), tx.Gas(), tx.GasPrice())
+	}
+
+	// The given fields are kept and the signed transaction is not submitted
+	explicitNonce, gas := hexutil.Uint64(100), hexutil.Uint64(50000)
+	result, err := api.SignTransaction(ctx, ethapi.SendTxArgs{From: from, To: &common.Address{1}, Nonce: &explicitNonce, Gas: &gas})
+	if err != nil {
+		t.Fatalf("sign transaction: %v", err)
+	}
+	decoded := new(types.Transaction)
+	if err = rlp.DecodeBytes(result.Raw, decoded); err != nil || decoded.Hash() != result.Tx.Hash() {
+		t.Errorf("wrong raw transaction: %v", err)
+	}
+	if result.Tx.Nonce() != 100 || result.Tx.Gas() != 50000 || len(backend.txs) != 1 {
+		t.Errorf("wrong signed transaction: nonce %d, gas %d", result.Tx.Nonce(), result.Tx.Gas())
+	}
+
+	if _, err = api.SendTransaction(ctx, ethapi.SendTxArgs{From: common.Address{2}, To: &common.Address{1}}); !errors.Is(err, accounts.ErrUnknownAccount) {
+		t.Errorf("wrong error for the unknown account: %v", err)
+	}
+	if _, err = api.SendTransaction(ctx, ethapi.SendTxArgs{From: from}); err == nil {
+		t.Errorf("contract creation without data accepted")
+	}
+}
@@ -2,11 +2,15 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/abis"
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
 )
 
 // TgAPI TurboGeth specific routines
@@ -26,6 +30,18 @@ type TgAPI interface {
 	// BlockReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
 	// UncleReward(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
 	Issuance(ctx context.Context, blockNr rpc.BlockNumber) (Issuance, error)
+
+	// ABI decoding related (see ./tg_decode.go)
+	AddABI(_ context.Context, address common.Address, abiJSON json.RawMessage) error
+	RemoveABI(_ context.Context, address common.Address) (bool, error)
+	GetABI(_ context.Context, address common.Address) (json.RawMessage, error)
+	DecodeCall(_ context.Context, to common.Address, input hexutil.Bytes, output *hexutil.Bytes) (*abis.DecodedCall, error)
+	DecodeLogs(_ context.Context, logs []*types.Log) ([]*abis.DecodedLog, error)
+	DecodeTransaction(ctx context.Context, hash common.Hash) (*DecodedTransaction, error)
+	DecodeTraces(ctx context.Context, hash common.Hash) ([]*DecodedTrace, error)
+
+	// Replay related (see ./tg_replay.go)
+	ReplayWithOverrides(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, overrides transactions.StateOverrides) ([]*transactions.TxReplay, error)
 }
 
 // TgImpl is implementation of the TgAPI interface
@@ -33,13 +49,17 @@ type TgImpl struct {
 	*BaseAPI
 	db       ethdb.KV
 	dbReader ethdb.Database
+	registry *abis.Registry // ABIs of the tg_decode* commands, nil if not kept
+	traces   *TraceAPIImpl
 }
 
 // NewTgAPI returns TgImpl instance
-func NewTgAPI(db ethdb.KV, dbReader ethdb.Database) *TgImpl {
+func NewTgAPI(db ethdb.KV, dbReader ethdb.Database, registry *abis.Registry, traces *TraceAPIImpl) *TgImpl {
 	return &TgImpl{
 		BaseAPI:  &BaseAPI{},
 		db:       db,
 		dbReader: dbReader,
+		registry: registry,
+		traces:   traces,
 	}
 }
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/abis"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+// DecodedTransaction is the call of the transaction and the events it logged, decoded with the ABIs. The call of
+// a contract creation and the calls and the logs which can not be decoded are nil.
+type DecodedTransaction struct {
+	Hash common.Hash        `json:"hash"`
+	Call *abis.DecodedCall  `json:"call"`
+	Logs []*abis.DecodedLog `json:"logs"`
+}
+
+// DecodedTrace is the call of the trace with its output, decoded with the ABIs
+type DecodedTrace struct {
+	TraceAddress []int             `json:"traceAddress"`
+	Type         string            `json:"type"`
+	Call         *abis.DecodedCall `json:"call"`
+}
+
+// AddABI implements tg_addABI. Uploads the JSON ABI of the contract to decode its calls and logs, replacing the previous one.
+func (api *TgImpl) AddABI(_ context.Context, address common.Address, abiJSON json.RawMessage) error {
+	if api.registry == nil {
+		return fmt.Errorf(NotAvailableABIRegistry, "tg_addABI")
+	}
+	return api.registry.Add(address, abiJSON)
+}
+
+// RemoveABI implements tg_removeABI. Removes the uploaded ABI of the contract, returns whether there was one.
+func (api *TgImpl) RemoveABI(_ context.Context, address common.Address) (bool, error) {
+	if api.registry == nil {
+		return false, fmt.Errorf(NotAvailableABIRegistry, "tg_removeABI")
+	}
+	return api.registry.Remove(address)
+}
+
+// GetABI implements tg_getABI. Returns the uploaded ABI of the contract, null if there is none.
+func (api *TgImpl) GetABI(_ context.Context, address common.Address) (json.RawMessage, error) {
+	if api.registry == nil {
+		return nil, fmt.Errorf(NotAvailableABIRegistry, "tg_getABI")
+	}
+	return api.registry.Get(address)
+}
+
+// DecodeCall implements tg_decodeCall. Decodes the input of the call to the contract and, if given, its output.
+func (api *TgImpl) DecodeCall(_ context.Context, to common.Address, input hexutil.Bytes, output *hexutil.Bytes) (*abis.DecodedCall, error) {
+	if api.registry == nil {
+		return nil, fmt.Errorf(NotAvailableABIRegistry, "tg_decodeCall")
+	}
+	var out []byte
+	if output != nil {
+		out = *output
+	}
+	return api.registry.DecodeCall(to, input, out)
+}
+
+// DecodeLogs implements tg_decodeLogs. Decodes the logs, as returned by eth_getLogs, the logs which can not be decoded are null.
+func (api *TgImpl) DecodeLogs(_ context.Context, logs []*types.Log) ([]*abis.DecodedLog, error) {
+	if api.registry == nil {
+		return nil, fmt.Errorf(NotAvailableABIRegistry, "tg_decodeLogs")
+	}
+	return api.decodeLogs(logs), nil
+}
+
+// DecodeTransaction implements tg_decodeTransaction. Decodes the input of the transaction and the logs of its receipt.
+func (api *TgImpl) DecodeTransaction(ctx context.Context, hash common.Hash) (*DecodedTransaction, error) {
+	if api.registry == nil {
+		return nil, fmt.Errorf(NotAvailableABIRegistry, "tg_decodeTransaction")
+	}
+	tx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txn, blockHash, blockNumber, txIndex := rawdb.ReadTransaction(tx, hash)
+	if txn == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := getReceipts(ctx, tx, chainConfig, blockNumber, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("getReceipts error: %v", err)
+	}
+	if len(receipts) <= int(txIndex) {
+		return nil, fmt.Errorf("block has less receipts than expected: %d <= %d, block: %d", len(receipts), int(txIndex), blockNumber)
+	}
+
+	decoded := &DecodedTransaction{Hash: hash, Logs: api.decodeLogs(receipts[txIndex].Logs)}
+	if txn.To() != nil {
+		decoded.Call, _ = api.registry.DecodeCall(*txn.To(), txn.Data(), nil)
+	}
+	return decoded, nil
+}
+
+// DecodeTraces implements tg_decodeTraces. Decodes the input and the output of the calls traced by trace_transaction,
+// the calls which can not be decoded are left out.
+func (api *TgImpl) DecodeTraces(ctx context.Context, hash common.Hash) ([]*DecodedTrace, error) {
+	if api.registry == nil || api.traces == nil {
+		return nil, fmt.Errorf(NotAvailableABIRegistry, "tg_decodeTraces")
+	}
+	traces, err := api.traces.Transaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTraces(api.registry, traces), nil
+}
+
+func (api *TgImpl) decodeLogs(logs []*types.Log) []*abis.DecodedLog {
+	decoded := make([]*abis.DecodedLog, len(logs))
+	for i, log := range logs {
+		decoded[i], _ = api.registry.DecodeLog(log.Address, log.Topics, log.Data)
+	}
+	return decoded
+}
+
+func decodeTraces(registry *abis.Registry, traces ParityTraces) []*DecodedTrace {
+	decoded := []*DecodedTrace{}
+	for _, trace := range traces {
+		var to common.Address
+		var input []byte
+		switch action := trace.Action.(type) {
+		case *CallTraceAction:
+			to, input = action.To, action.Input
+		case *TraceAction:
+			if !common.IsHexAddress(action.To) {
+				continue
+			}
+			to, input = common.HexToAddress(action.To), action.Input
+		default:
+			continue
+		}
+		var output []byte
+		if result, ok := trace.Result.(*TraceResult); ok && trace.Error == "" {
+			output = result.Output
+		}
+		call, err := registry.DecodeCall(to, input, output)
+		if err != nil {
+			continue
+		}
+		decoded = append(decoded, &DecodedTrace{TraceAddress: trace.TraceAddress, Type: trace.Type, Call: call})
+	}
+	return decoded
+}
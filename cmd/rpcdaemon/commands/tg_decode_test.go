@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/abis"
+	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/commands/contracts"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestDecodeTransaction(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	defer db.Close()
+	kv, err := abis.OpenDB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kv.Close()
+	registry, err := abis.NewRegistry(kv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	api := NewTgAPI(db.(ethdb.HasKV).KV(), db, registry, nil)
+
+	// The transfer of the tokens in the 5th block
+	block, err := rawdb.ReadBlockByNumber(db, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := block.Transactions()[0]
+	token := *transfer.To()
+	decoded, err := api.DecodeTransaction(ctx, transfer.Hash())
+	if err != nil || decoded.Call != nil {
+		t.Errorf("transaction decoded without the ABI: %+v, %v", decoded, err)
+	}
+	if err = api.AddABI(ctx, token, json.RawMessage(contracts.TokenABI)); err != nil {
+		t.Fatalf("add ABI: %v", err)
+	}
+	if decoded, err = api.DecodeTransaction(ctx, transfer.Hash()); err != nil {
+		t.Fatalf("decode transaction: %v", err)
+	}
+	if decoded.Call == nil || decoded.Call.Signature != "transfer(address,uint256)" || decoded.Call.Inputs[1].Value != "3" {
+		t.Errorf("wrong call: %+v", decoded.Call)
+	}
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	if decoded.Call.Inputs[0].Value != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("wrong recipient: %v", decoded.Call.Inputs[0].Value)
+	}
+
+	// The calls of the traces are decoded with their outputs, the other traces are left out
+	output := hexutil.Bytes(common.LeftPadBytes([]byte{1}, 32))
+	traces := ParityTraces{
+		{Type: "call", TraceAddress: []int{}, Action: &CallTraceAction{To: token, Input: transfer.Data()}, Result: &TraceResult{Output: output}},
+		{Type: "call", TraceAddress: []int{0}, Action: &CallTraceAction{To: token, Input: hexutil.MustDecode("0xdeadbeef")}},
+		{Type: "create", TraceAddress: []int{1}, Action: &CreateTraceAction{}},
+	}
+	decodedTraces := decodeTraces(registry, traces)
+	if len(decodedTraces) != 1 || len(decodedTraces[0].Call.Outputs) != 1 || decodedTraces[0].Call.Outputs[0].Value != true {
+		t.Errorf("wrong traces: %+v", decodedTraces)
+	}
+
+	if removed, err := api.RemoveABI(ctx, token); err != nil || !removed {
+		t.Errorf("remove ABI: %v, %v", removed, err)
+	}
+	if stored, err := api.GetABI(ctx, token); err != nil || stored != nil {
+		t.Errorf("removed ABI still stored: %s, %v", stored, err)
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+// maxReplayBlocks is the maximum number of the blocks tg_replayWithOverrides re-executes in one request
+const maxReplayBlocks = 1000
+
+// ReplayWithOverrides implements tg_replayWithOverrides. Re-executes the blocks from fromBlock to toBlock with the
+// code, the balance or the storage of the accounts overridden at the start of fromBlock, returns the outcome and the
+// state diff of every transaction against its canonical execution.
+func (api *TgImpl) ReplayWithOverrides(ctx context.Context, fromBlock, toBlock rpc.BlockNumber, overrides transactions.StateOverrides) ([]*transactions.TxReplay, error) {
+	tx, err := api.dbReader.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	from, err := getBlockNumber(fromBlock, tx)
+	if err != nil {
+		return nil, err
+	}
+	to, err := getBlockNumber(toBlock, tx)
+	if err != nil {
+		return nil, err
+	}
+	if to < from {
+		return nil, fmt.Errorf("toBlock %d is before fromBlock %d", to, from)
+	}
+	if to-from >= maxReplayBlocks {
+		return nil, fmt.Errorf("block range %d-%d is too long, at most %d blocks can be replayed", from, to, maxReplayBlocks)
+	}
+	chainConfig, err := api.chainConfig(tx)
+	if err != nil {
+		return nil, err
+	}
+	var engine consensus.Engine
+	if chainConfig.Clique != nil {
+		engine = clique.NewReadOnly(chainConfig.Clique, tx)
+	} else {
+		engine = ethash.NewFaker()
+	}
+	replays, err := transactions.ReplayWithOverrides(ctx, tx, tx.(ethdb.HasTx).Tx(), chainConfig, adapter.NewChainContext(tx), engine, from, to, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if replays == nil {
+		replays = []*transactions.TxReplay{}
+	}
+	return replays, nil
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+func TestReplayWithOverrides(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	api := NewTgAPI(db.(ethdb.HasKV).KV(), db, nil, nil)
+
+	// The mint of the tokens in the 4th block and their transfer in the 5th block
+	block, err := rawdb.ReadBlockByNumber(db, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := *block.Transactions()[0].To()
+	key2, _ := crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	holder := crypto.PubkeyToAddress(key2.PublicKey)
+
+	replays, err := api.ReplayWithOverrides(ctx, 4, 5, nil)
+	if err != nil {
+		t.Fatalf("replay without overrides: %v", err)
+	}
+	if len(replays) != 2 {
+		t.Fatalf("2 transactions expected, %d replayed", len(replays))
+	}
+	for _, replay := range replays {
+		if replay.Changed || replay.Canonical.Status != 1 {
+			t.Errorf("replay without overrides differs: %+v", replay)
+		}
+	}
+
+	// Without the minter the mint fails, and so does the transfer of the tokens which were never minted
+	minterSlot := common.BigToHash(common.Big2)
+	replays, err = api.ReplayWithOverrides(ctx, 4, 5, transactions.StateOverrides{
+		token: {Storage: map[common.Hash]common.Hash{minterSlot: {}}},
+	})
+	if err != nil {
+		t.Fatalf("replay with storage override: %v", err)
+	}
+	for _, replay := range replays {
+		if !replay.Changed || replay.Canonical.Status != 1 || replay.Replayed.Status != 0 {
+			t.Errorf("wrong outcome: %+v, %+v", replay.Canonical, replay.Replayed)
+		}
+	}
+	totalSupply := replays[0].StateDiff[token].Storage[common.Hash{}]
+	if totalSupply == nil || totalSupply.Canonical != common.BigToHash(big.NewInt(10)) || totalSupply.Replayed != (common.Hash{}) {
+		t.Errorf("wrong total supply diff: %+v", totalSupply)
+	}
+
+	// The holder can not pay for the gas of the transfer anymore
+	replays, err = api.ReplayWithOverrides(ctx, 5, 5, transactions.StateOverrides{
+		holder: {Balance: (*hexutil.Big)(new(big.Int))},
+	})
+	if err != nil {
+		t.Fatalf("replay with balance override: %v", err)
+	}
+	if len(replays) != 1 || !replays[0].Changed || replays[0].Replayed.Error == "" {
+		t.Errorf("transfer applied without the balance: %+v", replays[0].Replayed)
+	}
+
+	if _, err = api.ReplayWithOverrides(ctx, 5, 4, nil); err == nil {
+		t.Errorf("reversed block range accepted")
+	}
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// TxPoolAPI the interface for the txpool_ RPC commands
+type TxPoolAPI interface {
+	Content(ctx context.Context) (map[string]map[string]map[string]*RPCTransaction, error)
+	Status(ctx context.Context) (map[string]hexutil.Uint, error)
+	Inspect(ctx context.Context) (map[string]map[string]map[string]string, error)
+}
+
+// TxPoolAPIImpl data structure to store things needed for txpool_ commands
+type TxPoolAPIImpl struct {
+	pool remote.TXPOOLClient
+}
+
+// NewTxPoolAPI returns TxPoolAPIImpl instance
+func NewTxPoolAPI(pool remote.TXPOOLClient) *TxPoolAPIImpl {
+	return &TxPoolAPIImpl{
+		pool: pool,
+	}
+}
+
+// Content implements txpool_content. Returns the pending and queued transactions, grouped by sender and nonce.
+func (api *TxPoolAPIImpl) Content(ctx context.Context) (map[string]map[string]map[string]*RPCTransaction, error) {
+	pending, queued, err := poolContent(ctx, api.pool, "txpool_content")
+	if err != nil {
+		return nil, err
+	}
+	content := map[string]map[string]map[string]*RPCTransaction{
+		"pending": make(map[string]map[string]*RPCTransaction),
+		"queued":  make(map[string]map[string]*RPCTransaction),
+	}
+	for kind, txs := range map[string]map[common.Address]types.Transactions{"pending": pending, "queued": queued} {
+		for account, accountTxs := range txs {
+			dump := make(map[string]*RPCTransaction)
+			for _, tx := range accountTxs {
+				dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+			}
+			content[kind][account.Hex()] = dump
+		}
+	}
+	return content, nil
+}
+
+// Status implements txpool_status. Returns the number of pending and queued transactions.
+func (api *TxPoolAPIImpl) Status(ctx context.Context) (map[string]hexutil.Uint, error) {
+	if api.pool == nil {
+		return nil, fmt.Errorf(NotAvailableChainData, "txpool_status")
+	}
+	reply, err := api.pool.Status(ctx, &remote.StatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]hexutil.Uint{
+		"pending": hexutil.Uint(reply.Pending),
+		"queued":  hexutil.Uint(reply.Queued),
+	}, nil
+}
+
+// Inspect implements txpool_inspect. Returns a textual summary of the pending and queued transactions, grouped by sender and nonce.
+func (api *TxPoolAPIImpl) Inspect(ctx context.Context) (map[string]map[string]map[string]string, error) {
+	pending, queued, err := poolContent(ctx, api.pool, "txpool_inspect")
+	if err != nil {
+		return nil, err
+	}
+	content := map[string]map[string]map[string]string{
+		"pending": make(map[string]map[string]string),
+		"queued":  make(map[string]map[string]string),
+	}
+	format := func(tx *types.Transaction) string {
+		if to := tx.To(); to != nil {
+			return fmt.Sprintf("%s: %d wei + %d gas × %d wei", to.Hex(), tx.Value().ToBig(), tx.Gas(), tx.GasPrice().ToBig())
+		}
+		return fmt.Sprintf("contract creation: %d wei + %d gas × %d wei", tx.Value().ToBig(), tx.Gas(), tx.GasPrice().ToBig())
+	}
+	for kind, txs := range map[string]map[common.Address]types.Transactions{"pending": pending, "queued": queued} {
+		for account, accountTxs := range txs {
+			dump := make(map[string]string)
+			for _, tx := range accountTxs {
+				dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			}
+			content[kind][account.Hex()] = dump
+		}
+	}
+	return content, nil
+}
+
+// poolContent fetches the pending and queued transactions of the pool, method is the RPC method they are fetched for
+func poolContent(ctx context.Context, pool remote.TXPOOLClient, method string) (pending, queued map[common.Address]types.Transactions, err error) {
+	if pool == nil {
+		return nil, nil, fmt.Errorf(NotAvailableChainData, method)
+	}
+	reply, err := pool.Content(ctx, &remote.ContentRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending, err = decodeSenderTxs(reply.Pending); err != nil {
+		return nil, nil, err
+	}
+	if queued, err = decodeSenderTxs(reply.Queued); err != nil {
+		return nil, nil, err
+	}
+	return pending, queued, nil
+}
+
+func decodeSenderTxs(senderTxs []*remote.SenderTxs) (map[common.Address]types.Transactions, error) {
+	result := make(map[common.Address]types.Transactions, len(senderTxs))
+	for _, s := range senderTxs {
+		txs, err := decodeTxs(s.Txs)
+		if err != nil {
+			return nil, err
+		}
+		result[common.BytesToAddress(s.Sender)] = txs
+	}
+	return result, nil
+}
+
+// decodeTxs decodes the RLP encoded transactions received from the pool, the empty ones are decoded as nil
+func decodeTxs(encoded [][]byte) ([]*types.Transaction, error) {
+	txs := make([]*types.Transaction, len(encoded))
+	for i, data := range encoded {
+		if len(data) == 0 {
+			continue
+		}
+		txs[i] = new(types.Transaction)
+		if err := rlp.DecodeBytes(data, txs[i]); err != nil {
+			return nil, fmt.Errorf("decoding pooled transaction: %w", err)
+		}
+	}
+	return txs, nil
+}
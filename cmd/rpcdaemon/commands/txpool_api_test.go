@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote/remotedbserver"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestTxPoolAPI(t *testing.T) {
+	db, err := createTestDb()
+	if err != nil {
+		t.Fatalf("create test db: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	// Serve the pool of the core over the TXPOOL service, as the private API does
+	config := core.DefaultTxPoolConfig
+	config.Journal = ""
+	config.NoPersist = true
+	txCacher := core.NewTxSenderCacher(runtime.NumCPU())
+	defer txCacher.Close()
+	pool := core.NewTxPool(config, params.AllEthashProtocolChanges, db.(*ethdb.ObjectDatabase), txCacher)
+	latest, err := getLatestBlockNumber(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := rawdb.ReadHeaderByNumber(db, latest)
+	if err = pool.Start(head.GasLimit, head.Number.Uint64()); err != nil {
+		t.Fatalf("start pool: %v", err)
+	}
+	defer pool.Stop()
+
+	conn := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	remote.RegisterTXPOOLServer(grpcServer, remotedbserver.NewTxPoolServer(pool))
+	go grpcServer.Serve(conn) //nolint:errcheck
+	defer grpcServer.Stop()
+	kv, backend := ethdb.NewRemote().InMem(conn).MustOpen()
+	defer kv.Close()
+	txPool := backend.(*ethdb.RemoteBackend).TxPool()
+
+	// One pending and one queued transaction
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	nonce := pool.Nonce(sender)
+	signer := types.NewEIP155Signer(params.AllEthashProtocolChanges.ChainID)
+	var txs []*types.Transaction
+	for _, n := range []uint64{nonce, nonce + 2} {
+		tx, err := types.SignTx(types.NewTransaction(n, common.Address{1}, uint256.NewInt().SetUint64(1), params.TxGas, uint256.NewInt().SetUint64(1), nil), signer, key)
+		if err != nil {
+			t.Fatalf("sign transaction: %v", err)
+		}
+		if err = pool.AddLocal(tx); err != nil {
+			t.Fatalf("add transaction: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+
+	txPoolAPI := NewTxPoolAPI(txPool)
+	status, err := txPoolAPI.Status(ctx)
+	if err != nil || status["pending"] != 1 || status["queued"] != 1 {
+		t.Fatalf("wrong status: %v, %v", status, err)
+	}
+	content, err := txPoolAPI.Content(ctx)
+	if err != nil {
+		t.Fatalf("content: %v", err)
+	}
+	if tx := content["pending"][sender.Hex()][fmt.Sprintf("%d", nonce)]; tx == nil || tx.Hash != txs[0].Hash() {
+		t.Errorf("wrong pending transaction: %v", tx)
+	}
+	if tx := content["queued"][sender.Hex()][fmt.Sprintf("%d", nonce+2)]; tx == nil || tx.Hash != txs[1].Hash() {
+		t.Errorf("wrong queued transaction: %v", tx)
+	}
+
+	api := NewEthAPI(db.(ethdb.HasKV).KV(), db, nil, txPool, nil, 5000000, nil)
+	tx, err := api.GetTransactionByHash(ctx, txs[0].Hash())
+	if err != nil || tx.Hash != txs[0].Hash() || tx.BlockHash != nil {
+		t.Errorf("wrong pending transaction by hash: %v, %v", tx, err)
+	}
+	count, err := api.GetTransactionCount(ctx, sender, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber))
+	if err != nil || uint64(*count) != nonce+1 {
+		t.Errorf("wrong pending nonce: %v, %v", count, err)
+	}
+	block, err := api.GetBlockByNumber(ctx, rpc.PendingBlockNumber, false)
+	if err != nil {
+		t.Fatalf("pending block: %v", err)
+	}
+	if hashes, ok := block["transactions"].([]interface{}); !ok || len(hashes) != 1 || hashes[0] != txs[0].Hash() {
+		t.Errorf("wrong transactions of the pending block: %v", block["transactions"])
+	}
+}
@@ -1,9 +1,11 @@
 package filters
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -12,18 +14,20 @@ import (
 	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
 	"github.com/ledgerwatch/turbo-geth/ethdb/remote/remotedbserver"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
 )
 
 type Filters struct {
 	mu sync.RWMutex
 
-	headsSubs map[string]chan *types.Header
+	headsSubs      map[string]chan *types.Header
+	pendingTxsSubs map[string]chan []*types.Transaction
 }
 
-func New(ethBackend ethdb.Backend) *Filters {
+func New(ethBackend ethdb.Backend, txPool remote.TXPOOLClient) *Filters {
 	log.Info("rpc filters: subscribing to tg events")
 
-	ff := &Filters{headsSubs: make(map[string]chan *types.Header)}
+	ff := &Filters{headsSubs: make(map[string]chan *types.Header), pendingTxsSubs: make(map[string]chan []*types.Transaction)}
 
 	go func() {
 		var err error
@@ -36,9 +40,40 @@ func New(ethBackend ethdb.Backend) *Filters {
 		}
 	}()
 
+	if txPool != nil {
+		go func() {
+			var err error
+			for i := 0; i < 10; i++ {
+				err = ff.subscribeToPendingTxs(txPool)
+				if err != nil {
+					log.Warn("rpc filters: error subscribing to pending transactions", "err", err)
+					time.Sleep(time.Second)
+				}
+			}
+		}()
+	}
+
 	return ff
 }
 
+func (ff *Filters) subscribeToPendingTxs(txPool remote.TXPOOLClient) error {
+	stream, err := txPool.OnPending(context.Background(), &remote.OnPendingRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		reply, err := stream.Recv()
+		if err == io.EOF {
+			log.Info("rpcdaemon: the pending transactions stream was closed")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		ff.OnNewTxs(reply)
+	}
+}
+
 func (ff *Filters) SubscribeNewHeads(out chan *types.Header) string {
 	ff.mu.Lock()
 	defer ff.mu.Unlock()
@@ -47,10 +82,19 @@ func (ff *Filters) SubscribeNewHeads(out chan *types.Header) string {
 	return id
 }
 
+func (ff *Filters) SubscribePendingTxs(out chan []*types.Transaction) string {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	id := generateSubscriptionID()
+	ff.pendingTxsSubs[id] = out
+	return id
+}
+
 func (ff *Filters) Unsubscribe(id string) {
 	ff.mu.Lock()
 	defer ff.mu.Unlock()
 	delete(ff.headsSubs, id)
+	delete(ff.pendingTxsSubs, id)
 }
 
 func (ff *Filters) OnNewEvent(event *remote.SubscribeReply) {
@@ -75,6 +119,25 @@ func (ff *Filters) OnNewEvent(event *remote.SubscribeReply) {
 	}
 }
 
+func (ff *Filters) OnNewTxs(reply *remote.OnPendingReply) {
+	txs := make([]*types.Transaction, 0, len(reply.Txs))
+	for _, data := range reply.Txs {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(data, tx); err != nil {
+			// ignoring what we can't decode
+			log.Warn("rpc filters, unprocessable pending transaction", "err", err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+	for _, v := range ff.pendingTxsSubs {
+		v <- txs
+	}
+}
+
 func generateSubscriptionID() string {
 	var id [32]byte
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net/http"
 	"os"
 
 	"github.com/ledgerwatch/turbo-geth/cmd/rpcdaemon/cli"
@@ -16,21 +17,42 @@ func main() {
 	raiseFdLimit()
 	cmd, cfg := cli.RootCommand()
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		db, backend, err := cli.OpenDB(*cfg)
+		db, backend, txPool, err := cli.OpenDB(*cfg)
 		if err != nil {
 			log.Error("Could not connect to DB", "error", err)
 			return nil
 		}
 		defer db.Close()
 
+		signer, err := cli.OpenSigner(*cfg)
+		if err != nil {
+			log.Error("Could not connect to the signer", "error", err)
+			return nil
+		}
+
+		registry, err := cli.OpenABIRegistry(*cfg)
+		if err != nil {
+			log.Error("Could not open the ABI registry", "error", err)
+			return nil
+		}
+		defer registry.Close()
+
 		var ff *filters.Filters
 		if backend != nil {
-			ff = filters.New(backend)
+			ff = filters.New(backend, txPool)
 		} else {
 			log.Info("filters are not supported in chaindata mode")
 		}
 
-		return cli.StartRpcServer(cmd.Context(), *cfg, commands.APIList(db, backend, ff, *cfg, nil))
+		var graphQLHandler http.Handler
+		if cfg.GraphQLEnabled {
+			if graphQLHandler, err = commands.GraphQLHandler(db, backend, txPool, ff, *cfg); err != nil {
+				log.Error("Could not create GraphQL handler", "error", err)
+				return nil
+			}
+		}
+
+		return cli.StartRpcServer(cmd.Context(), *cfg, commands.APIList(db, backend, txPool, signer, registry, ff, *cfg, nil), graphQLHandler)
 	}
 
 	if err := cmd.ExecuteContext(utils.RootContext()); err != nil {
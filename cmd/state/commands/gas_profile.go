@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"github.com/ledgerwatch/turbo-geth/cmd/state/stateless"
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileOutput string
+	profileMetric string
+	profileTop    int
+)
+
+func init() {
+	withBlock(gasProfile)
+	withChaindata(gasProfile)
+	gasProfile.Flags().Uint64Var(&numBlocks, "numBlocks", 1, "number of blocks to run the operation on")
+	gasProfile.Flags().StringVar(&profileOutput, "output", "gasprofile", "prefix of the output files (.folded, .pb.gz and .txt are appended)")
+	gasProfile.Flags().StringVar(&profileMetric, "metric", "gas", "weight used for folded stacks and hotspot ordering: gas, time or count")
+	gasProfile.Flags().IntVar(&profileTop, "top", 50, "number of contracts and instructions to include in the hotspot report")
+
+	rootCmd.AddCommand(gasProfile)
+}
+
+var gasProfile = &cobra.Command{
+	Use:   "gasProfile",
+	Short: "Re-executes historical blocks and reports gas and time spent per contract, pc and opcode",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return stateless.GasProfile(rootContext(), genesis, block, chaindata, numBlocks, profileOutput, profileMetric, profileTop)
+	},
+}
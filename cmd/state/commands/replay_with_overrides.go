@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"github.com/ledgerwatch/turbo-geth/cmd/state/stateless"
+	"github.com/spf13/cobra"
+)
+
+var (
+	overridesFile string
+	replayOutput  string
+)
+
+func init() {
+	withBlock(replayWithOverridesCmd)
+	withChaindata(replayWithOverridesCmd)
+	replayWithOverridesCmd.Flags().Uint64Var(&numBlocks, "numBlocks", 1, "number of blocks to run the operation on")
+	replayWithOverridesCmd.Flags().StringVar(&overridesFile, "overrides", "", "path to the JSON file with the code, balance and storage overrides of the accounts, same as the overrides of tg_replayWithOverrides")
+	must(replayWithOverridesCmd.MarkFlagFilename("overrides", "json"))
+	must(replayWithOverridesCmd.MarkFlagRequired("overrides"))
+	replayWithOverridesCmd.Flags().StringVar(&replayOutput, "output", "", "path where to write the outcomes of the transactions changed by the overrides, stdout if omitted")
+	rootCmd.AddCommand(replayWithOverridesCmd)
+}
+
+var replayWithOverridesCmd = &cobra.Command{
+	Use:   "replayWithOverrides",
+	Short: "Re-executes historical blocks with the code, balance or storage of the accounts overridden and reports the transactions whose outcome or state diff changed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return stateless.ReplayWithOverrides(genesis, block, numBlocks, chaindata, overridesFile, replayOutput)
+	},
+}
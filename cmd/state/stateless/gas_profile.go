@@ -0,0 +1,96 @@
+package stateless
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/gasprofile"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// GasProfile re-executes historical blocks in read-only mode, accumulating gas and time
+// per (code hash, pc, opcode), and writes the result as folded stacks (<output>.folded),
+// a pprof profile (<output>.pb.gz) and a hotspot summary (<output>.txt)
+func GasProfile(ctx context.Context, genesis *core.Genesis, blockNum uint64, chaindata string, numBlocks uint64,
+	output string, metricName string, top int) error {
+	metric, err := gasprofile.ParseMetric(metricName)
+	if err != nil {
+		return err
+	}
+	chainDb := ethdb.MustOpen(chaindata)
+	defer chainDb.Close()
+	historyTx, err := chainDb.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return err
+	}
+	defer historyTx.Rollback()
+
+	profiler := gasprofile.NewProfiler()
+	chainConfig := genesis.Config
+	vmConfig := vm.Config{Tracer: profiler, Debug: true}
+	txCacher := core.NewTxSenderCacher(runtime.NumCPU())
+	bc, err := core.NewBlockChain(chainDb, nil, chainConfig, ethash.NewFaker(), vmConfig, nil, txCacher)
+	if err != nil {
+		return err
+	}
+	defer bc.Stop()
+	noOpWriter := state.NewNoopWriter()
+
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	start := time.Now()
+	firstBlock := blockNum
+	for interrupted := false; !interrupted && blockNum < firstBlock+numBlocks; {
+		select {
+		case <-ctx.Done():
+			log.Info("Interrupted, writing the profile collected so far", "block", blockNum)
+			interrupted = true
+			continue
+		case <-logEvery.C:
+			log.Info("Profiling", "block", blockNum, "txs", profiler.Transactions(), "elapsed", time.Since(start))
+		default:
+		}
+		block := bc.GetBlockByNumber(blockNum)
+		if block == nil {
+			break
+		}
+		ibs := state.New(state.NewPlainDBState(historyTx, block.NumberU64()-1))
+		if _, err = runBlock(ibs, noOpWriter, noOpWriter, chainConfig, bc, block, vmConfig); err != nil {
+			return fmt.Errorf("block %d: %w", blockNum, err)
+		}
+		blockNum++
+	}
+	log.Info("Profiling done", "blocks", blockNum-firstBlock, "txs", profiler.Transactions(), "elapsed", time.Since(start))
+
+	if err = writeProfileFile(output+".folded", func(f *os.File) error { return profiler.WriteFolded(f, metric) }); err != nil {
+		return err
+	}
+	if err = writeProfileFile(output+".pb.gz", func(f *os.File) error { return profiler.WritePprof(f) }); err != nil {
+		return err
+	}
+	if err = writeProfileFile(output+".txt", func(f *os.File) error { return profiler.WriteSummary(f, metric, top) }); err != nil {
+		return err
+	}
+	return profiler.WriteSummary(os.Stdout, metric, top)
+}
+
+func writeProfileFile(name string, write func(f *os.File) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = write(f); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	log.Info("Written", "file", name)
+	return nil
+}
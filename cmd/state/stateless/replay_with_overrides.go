@@ -0,0 +1,84 @@
+package stateless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+	"github.com/ledgerwatch/turbo-geth/turbo/transactions"
+)
+
+// ReplayWithOverrides re-executes numBlocks historical blocks starting from blockNum with the overrides
+// read from overridesFile applied to the state, and writes the transactions whose outcome or state diff
+// changed, as JSON, into outputFile or stdout.
+func ReplayWithOverrides(genesis *core.Genesis, blockNum uint64, numBlocks uint64, chaindata string, overridesFile string, outputFile string) error {
+	if numBlocks == 0 {
+		return fmt.Errorf("numBlocks must be positive")
+	}
+	overridesJSON, err := ioutil.ReadFile(overridesFile)
+	if err != nil {
+		return err
+	}
+	var overrides transactions.StateOverrides
+	if err = json.Unmarshal(overridesJSON, &overrides); err != nil {
+		return fmt.Errorf("parsing overrides %s: %v", overridesFile, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	chainDb := ethdb.MustOpen(chaindata)
+	defer chainDb.Close()
+	historyTx, err := chainDb.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return err
+	}
+	defer historyTx.Rollback()
+	chainConfig := genesis.Config
+	var engine consensus.Engine
+	if chainConfig.Clique != nil {
+		engine = clique.NewReadOnly(chainConfig.Clique, historyTx)
+	} else {
+		engine = ethash.NewFaker()
+	}
+
+	replays, err := transactions.ReplayWithOverrides(ctx, historyTx, historyTx.(ethdb.HasTx).Tx(), chainConfig, adapter.NewChainContext(historyTx), engine, blockNum, blockNum+numBlocks-1, overrides)
+	if err != nil {
+		return err
+	}
+	changed := []*transactions.TxReplay{}
+	for _, replay := range replays {
+		if replay.Changed {
+			changed = append(changed, replay)
+		}
+	}
+	log.Info("Replayed with overrides", "blocks", fmt.Sprintf("%d-%d", blockNum, blockNum+numBlocks-1), "transactions", len(replays), "changed", len(changed))
+
+	out := os.Stdout
+	if outputFile != "" {
+		if out, err = os.Create(outputFile); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(changed)
+}
@@ -125,7 +125,7 @@ func tester(cliCtx *cli.Context) error {
 	tp1.forkBase = forkBase
 	tp1.forkHeight = forkHeight
 	tp1.forkFeeder = forkGen
-	tp1.protocolVersion = uint32(eth.ProtocolVersions[0])
+	tp1.protocolVersion = uint32(eth.ETH65)
 	tp1.networkId = 1 // Mainnet
 	tp1.genesisBlockHash = forkGen.Genesis().Hash()
 	server1 := makeP2PServer(ctx, tp1, []string{eth.ProtocolName, eth.DebugName})
@@ -137,7 +137,7 @@ func tester(cliCtx *cli.Context) error {
 
 	tp2 := NewTesterProtocol("tp2", false, false)
 	tp2.blockFeeder = blockGen
-	tp2.protocolVersion = uint32(eth.ProtocolVersions[0])
+	tp2.protocolVersion = uint32(eth.ETH65)
 	tp2.networkId = 1 // Mainnet
 	tp2.genesisBlockHash = blockGen.Genesis().Hash()
 	server2 := makeP2PServer(ctx, tp2, []string{eth.ProtocolName})
@@ -179,8 +179,8 @@ func makeP2PServer(ctx context.Context, tp *TesterProtocol, protocols []string)
 	pMap := map[string]p2p.Protocol{
 		eth.ProtocolName: {
 			Name:    eth.ProtocolName,
-			Version: eth.ProtocolVersions[0],
-			Length:  eth.ProtocolLengths[eth.ProtocolVersions[0]],
+			Version: eth.ETH65,
+			Length:  eth.ProtocolLengths[eth.ETH65],
 			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
 				return tp.protocolRun(ctx, peer, rw)
 			},
@@ -267,11 +267,25 @@ var (
 		Usage: "Enable the DBG (debug) protocol",
 	}
 	// Ethash settings
+	EthashCacheDirFlag = DirectoryFlag{
+		Name:  "ethash.cachedir",
+		Usage: "Directory to store the ethash verification caches (default = inside the datadir)",
+	}
 	EthashCachesInMemoryFlag = cli.IntFlag{
 		Name:  "ethash.cachesinmem",
 		Usage: "Number of recent ethash caches to keep in memory (16MB each)",
 		Value: eth.DefaultConfig.Ethash.CachesInMem,
 	}
+	EthashCachesOnDiskFlag = cli.IntFlag{
+		Name:  "ethash.cachesondisk",
+		Usage: "Number of recent ethash caches to keep on disk (16MB each)",
+		Value: eth.DefaultConfig.Ethash.CachesOnDisk,
+	}
+	EthashCachesAheadFlag = cli.IntFlag{
+		Name:  "ethash.cachesahead",
+		Usage: "Number of ethash caches of the upcoming epochs to generate in the background (16MB each)",
+		Value: eth.DefaultConfig.Ethash.CachesAhead,
+	}
 	EthashCachesLockMmapFlag = cli.BoolFlag{
 		Name:  "ethash.cacheslockmmap",
 		Usage: "Lock memory maps of recent ethash caches",
@@ -314,6 +328,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolNoPersistFlag = cli.BoolFlag{
+		Name:  "txpool.nopersist",
+		Usage: "Disables keeping the pending and queued transactions in the database across restarts",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -349,6 +367,23 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolPolicyFlag = cli.StringFlag{
+		Name:  "txpool.policy",
+		Usage: "Replacement, eviction and ordering rules of the pool (price, fifo)",
+		Value: eth.DefaultConfig.TxPool.Policy,
+	}
+	TxPoolAllowSendersFlag = cli.StringFlag{
+		Name:  "txpool.allowsenders",
+		Usage: "Comma separated accounts, the only ones whose transactions are accepted into the pool",
+	}
+	TxPoolDenySendersFlag = cli.StringFlag{
+		Name:  "txpool.denysenders",
+		Usage: "Comma separated accounts whose transactions are never accepted into the pool",
+	}
+	TxPoolSenderPriceLimitsFlag = cli.StringFlag{
+		Name:  "txpool.senderpricelimits",
+		Usage: "Comma separated account=price pairs, minimum gas price to enforce for the transactions of the account",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -1303,6 +1338,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolNoPersistFlag.Name) {
+		cfg.NoPersist = ctx.GlobalBool(TxPoolNoPersistFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -1324,15 +1362,63 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPolicyFlag.Name) {
+		cfg.Policy = ctx.GlobalString(TxPoolPolicyFlag.Name)
+		if cfg.Policy != core.PricePolicyName && cfg.Policy != core.FIFOPolicyName {
+			Fatalf("Invalid --%s: %s", TxPoolPolicyFlag.Name, cfg.Policy)
+		}
+	}
+	if ctx.GlobalIsSet(TxPoolAllowSendersFlag.Name) {
+		cfg.AllowSenders = splitAccounts(TxPoolAllowSendersFlag.Name, ctx.GlobalString(TxPoolAllowSendersFlag.Name))
+	}
+	if ctx.GlobalIsSet(TxPoolDenySendersFlag.Name) {
+		cfg.DenySenders = splitAccounts(TxPoolDenySendersFlag.Name, ctx.GlobalString(TxPoolDenySendersFlag.Name))
+	}
+	if ctx.GlobalIsSet(TxPoolSenderPriceLimitsFlag.Name) {
+		cfg.SenderPriceLimits = make(map[common.Address]uint64)
+		for _, pair := range strings.Split(ctx.GlobalString(TxPoolSenderPriceLimitsFlag.Name), ",") {
+			parts := strings.Split(strings.TrimSpace(pair), "=")
+			if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+				Fatalf("Invalid account price limit in --%s: %s", TxPoolSenderPriceLimitsFlag.Name, pair)
+			}
+			price, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				Fatalf("Invalid price in --%s: %s", TxPoolSenderPriceLimitsFlag.Name, pair)
+			}
+			cfg.SenderPriceLimits[common.HexToAddress(parts[0])] = price
+		}
+	}
+}
+
+// splitAccounts parses the comma separated accounts given in the flag
+func splitAccounts(flag string, value string) []common.Address {
+	var accounts []common.Address
+	for _, account := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+			Fatalf("Invalid account in --%s: %s", flag, trimmed)
+		} else {
+			accounts = append(accounts, common.HexToAddress(trimmed))
+		}
+	}
+	return accounts
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(EthashDatasetDirFlag.Name) {
 		cfg.Ethash.DatasetDir = ctx.GlobalString(EthashDatasetDirFlag.Name)
 	}
+	if ctx.GlobalIsSet(EthashCacheDirFlag.Name) {
+		cfg.Ethash.CacheDir = ctx.GlobalString(EthashCacheDirFlag.Name)
+	}
 	if ctx.GlobalIsSet(EthashCachesInMemoryFlag.Name) {
 		cfg.Ethash.CachesInMem = ctx.GlobalInt(EthashCachesInMemoryFlag.Name)
 	}
+	if ctx.GlobalIsSet(EthashCachesOnDiskFlag.Name) {
+		cfg.Ethash.CachesOnDisk = ctx.GlobalInt(EthashCachesOnDiskFlag.Name)
+	}
+	if ctx.GlobalIsSet(EthashCachesAheadFlag.Name) {
+		cfg.Ethash.CachesAhead = ctx.GlobalInt(EthashCachesAheadFlag.Name)
+	}
 	if ctx.GlobalIsSet(EthashCachesLockMmapFlag.Name) {
 		cfg.Ethash.CachesLockMmap = ctx.GlobalBool(EthashCachesLockMmapFlag.Name)
 	}
@@ -1751,9 +1837,13 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readOnly bool) (chainConfig *
 	} else {
 		engine = ethash.NewFaker()
 		if !ctx.GlobalBool(FakePoWFlag.Name) {
+			cacheDir, _ := stack.ResolvePath(eth.DefaultConfig.Ethash.CacheDir)
 			datasetDir, _ := stack.ResolvePath(eth.DefaultConfig.Ethash.DatasetDir)
 			engine = ethash.New(ethash.Config{
+				CacheDir:         cacheDir,
 				CachesInMem:      eth.DefaultConfig.Ethash.CachesInMem,
+				CachesOnDisk:     eth.DefaultConfig.Ethash.CachesOnDisk,
+				CachesAhead:      eth.DefaultConfig.Ethash.CachesAhead,
 				CachesLockMmap:   eth.DefaultConfig.Ethash.CachesLockMmap,
 				DatasetDir:       datasetDir,
 				DatasetsInMem:    eth.DefaultConfig.Ethash.DatasetsInMem,
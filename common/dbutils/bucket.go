@@ -76,6 +76,15 @@ var (
 	//value - contract code
 	CodeBucket = "CODE"
 
+	//key - contract code hash
+	//value - result of JUMPDEST analysis of the code (see core.AnalysisCache)
+	CodeAnalysisBucket = "CODE_ANALYSIS"
+
+	//key - sender address + nonce
+	//value - transaction with its arrival time and local flag (see core.TxPool.Save)
+	TxPoolPendingBucket = "TXPOOL_PENDING"
+	TxPoolQueueBucket   = "TXPOOL_QUEUE"
+
 	//key - addressHash+incarnation
 	//value - code hash
 	ContractCodeBucket = "contractCode"
@@ -147,6 +156,10 @@ var (
 	// this bucket stored in separated database
 	InodesBucket = "inodes"
 
+	//key - contract address
+	//value - JSON ABI of the contract uploaded with tg_addABI, stored in separated database of the rpcdaemon
+	ABIBucket = "ABI"
+
 	// Transaction senders - stored separately from the block bodies
 	Senders = "txSenders"
 
@@ -239,6 +252,9 @@ var Buckets = []string{
 	Log,
 	Sequence,
 	EthTx,
+	CodeAnalysisBucket,
+	TxPoolPendingBucket,
+	TxPoolQueueBucket,
 }
 
 // DeprecatedBuckets - list of buckets which can be programmatically deleted - for example after migration
@@ -17,6 +17,7 @@
 package clique
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/ledgerwatch/turbo-geth/common"
@@ -32,6 +33,11 @@ type API struct {
 	clique *Clique
 }
 
+// NewAPI creates the API of the engine over the given chain.
+func NewAPI(chain consensus.ChainHeaderReader, clique *Clique) *API {
+	return &API{chain: chain, clique: clique}
+}
+
 // GetSnapshot retrieves the state snapshot at a given block.
 func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
 	// Retrieve the requested block number (or current if none requested)
@@ -120,24 +126,36 @@ func (api *API) Discard(address common.Address) {
 	delete(api.clique.proposals, address)
 }
 
-type status struct {
+// Status is the block production of the signers over a range of blocks.
+type Status struct {
 	InturnPercent float64                `json:"inturnPercent"`
 	SigningStatus map[common.Address]int `json:"sealerActivity"`
 	NumBlocks     uint64                 `json:"numBlocks"`
 }
 
-// Status returns the status of the last N blocks,
+// errNoStatusBlocks is returned if the status is asked for no blocks, or the chain has no signed blocks
+// before its head to report on.
+var errNoStatusBlocks = errors.New("no blocks to report the status on")
+
+// Status returns the status of the last 64 blocks,
 // - the number of active signers,
 // - the number of signers,
 // - the percentage of in-turn blocks
-func (api *API) Status() (*status, error) {
+func (api *API) Status() (*Status, error) {
+	return SigningStatus(api.chain, api.clique, 64)
+}
+
+// SigningStatus returns the status of the last numBlocks blocks of the chain, see API.Status.
+func SigningStatus(chain consensus.ChainHeaderReader, c *Clique, numBlocks uint64) (*Status, error) {
 	var (
-		numBlocks = uint64(64)
-		header    = api.chain.CurrentHeader()
-		diff      = uint64(0)
-		optimals  = 0
+		header   = chain.CurrentHeader()
+		diff     = uint64(0)
+		optimals = 0
 	)
-	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -146,16 +164,22 @@ func (api *API) Status() (*status, error) {
 		end     = header.Number.Uint64()
 		start   = end - numBlocks
 	)
-	if numBlocks > end {
-		start = 1
-		numBlocks = end - start
+	// The genesis block is not signed, so the range starts at the first block at most
+	if numBlocks >= end {
+		start, numBlocks = 1, 0
+		if end > start {
+			numBlocks = end - start
+		}
+	}
+	if numBlocks == 0 {
+		return nil, errNoStatusBlocks
 	}
 	signStatus := make(map[common.Address]int)
 	for _, s := range signers {
 		signStatus[s] = 0
 	}
 	for n := start; n < end; n++ {
-		h := api.chain.GetHeaderByNumber(n)
+		h := chain.GetHeaderByNumber(n)
 		if h == nil {
 			return nil, fmt.Errorf("missing block %d", n)
 		}
@@ -163,13 +187,13 @@ func (api *API) Status() (*status, error) {
 			optimals++
 		}
 		diff += h.Difficulty.Uint64()
-		sealer, err := api.clique.Author(h)
+		sealer, err := c.Author(h)
 		if err != nil {
 			return nil, err
 		}
 		signStatus[sealer]++
 	}
-	return &status{
+	return &Status{
 		InturnPercent: float64(100*optimals) / float64(numBlocks),
 		SigningStatus: signStatus,
 		NumBlocks:     numBlocks,
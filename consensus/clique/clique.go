@@ -168,8 +168,9 @@ func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, er
 // Clique is the proof-of-authority consensus engine proposed to support the
 // Ethereum testnet following the Ropsten attacks.
 type Clique struct {
-	config *params.CliqueConfig // Consensus engine configuration parameters
-	db     ethdb.Database       // Database to store and retrieve snapshot checkpoints
+	config   *params.CliqueConfig // Consensus engine configuration parameters
+	db       ethdb.Database       // Database to store and retrieve snapshot checkpoints
+	readonly bool                 // Whether the snapshot checkpoints are only retrieved, never stored
 
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
@@ -205,6 +206,15 @@ func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
 	}
 }
 
+// NewReadOnly creates a Clique engine which retrieves the snapshot checkpoints
+// from the database, but never stores them. The snapshots it reconstructs are only
+// kept in memory. It serves the readers of the database of a node, such as rpcdaemon.
+func NewReadOnly(config *params.CliqueConfig, db ethdb.Database) *Clique {
+	c := New(config, db)
+	c.readonly = true
+	return c
+}
+
 // Author implements consensus.Engine, returning the Ethereum address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
@@ -384,6 +394,9 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 					copy(signers[i][:], checkpoint.Extra[extraVanity+i*common.AddressLength:])
 				}
 				snap = newSnapshot(c.config, c.signatures, number, hash, signers)
+				if c.readonly {
+					break
+				}
 				if err := snap.store(c.db); err != nil {
 					return nil, err
 				}
@@ -421,7 +434,7 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 	c.recents.Add(snap.Hash, snap)
 
 	// If we've generated a new checkpoint snapshot, save to disk
-	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 && !c.readonly {
 		if err = snap.store(c.db); err != nil {
 			return nil, err
 		}
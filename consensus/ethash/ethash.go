@@ -20,6 +20,7 @@ package ethash
 import (
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"math"
 	"math/big"
 	"math/rand"
@@ -43,24 +44,33 @@ import (
 	"github.com/hashicorp/golang-lru/simplelru"
 )
 
-const doNotStoreCachesOnDisk = ""
-
-var ErrInvalidDumpMagic = errors.New("invalid dump magic")
+var (
+	ErrInvalidDumpMagic    = errors.New("invalid dump magic")
+	ErrInvalidDumpSize     = errors.New("invalid dump size")
+	ErrInvalidDumpChecksum = errors.New("invalid dump checksum")
+)
 
 var (
 	// two256 is a big integer representing 2^256
 	two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
 
 	// sharedEthash is a full instance that can be shared between multiple users.
-	sharedEthash = New(Config{3, false, "", 1, 0, false, ModeNormal, nil}, nil, false)
+	sharedEthash = New(Config{CachesInMem: 3, DatasetsInMem: 1, PowMode: ModeNormal}, nil, false)
 
 	// algorithmRevision is the data structure version used for file naming.
-	algorithmRevision = 23
+	// Revision 24 added the checksum of the dump content to the dump header.
+	algorithmRevision = 24
 
 	// dumpMagic is a dataset dump header to sanity check a data dump.
 	dumpMagic = []uint32{0xbaddcafe, 0xfee1dead}
+
+	// dumpChecksumTable is used for the checksum of the dump content, which follows the magic in the header.
+	dumpChecksumTable = crc32.MakeTable(crc32.Castagnoli)
 )
 
+// dumpHeaderSize is the size in bytes of the dump magic and the checksum preceding the dump content.
+var dumpHeaderSize = (len(dumpMagic) + 1) * 4
+
 // isLittleEndian returns whether the local system is running in little or big
 // endian byte order.
 func isLittleEndian() bool {
@@ -68,8 +78,9 @@ func isLittleEndian() bool {
 	return *(*byte)(unsafe.Pointer(&n)) == 0x04
 }
 
-// memoryMap tries to memory map a file of uint32s for read only access.
-func memoryMap(path string, lock bool) (*os.File, mmap.MMap, []uint32, error) {
+// memoryMap tries to memory map a file of uint32s for read only access. The file
+// must hold the given number of bytes after the header and match its checksum.
+func memoryMap(path string, size uint64, lock bool) (*os.File, mmap.MMap, []uint32, error) {
 	file, err := os.OpenFile(path, os.O_RDONLY, 0644)
 	if err != nil {
 		return nil, nil, nil, err
@@ -79,6 +90,11 @@ func memoryMap(path string, lock bool) (*os.File, mmap.MMap, []uint32, error) {
 		file.Close()
 		return nil, nil, nil, err
 	}
+	if uint64(len(mem)) != uint64(dumpHeaderSize)+size {
+		mem.Unmap()
+		file.Close()
+		return nil, nil, nil, ErrInvalidDumpSize
+	}
 	for i, magic := range dumpMagic {
 		if buffer[i] != magic {
 			mem.Unmap()
@@ -86,6 +102,11 @@ func memoryMap(path string, lock bool) (*os.File, mmap.MMap, []uint32, error) {
 			return nil, nil, nil, ErrInvalidDumpMagic
 		}
 	}
+	if crc32.Checksum(mem[dumpHeaderSize:], dumpChecksumTable) != buffer[len(dumpMagic)] {
+		mem.Unmap()
+		file.Close()
+		return nil, nil, nil, ErrInvalidDumpChecksum
+	}
 	if lock {
 		if err2 := mem.Lock(); err2 != nil {
 			mem.Unmap() //nolint:errcheck
@@ -93,7 +114,7 @@ func memoryMap(path string, lock bool) (*os.File, mmap.MMap, []uint32, error) {
 			return nil, nil, nil, err2
 		}
 	}
-	return file, mem, buffer[len(dumpMagic):], err
+	return file, mem, buffer[dumpHeaderSize/4:], err
 }
 
 // memoryMapFile tries to memory map an already opened file descriptor.
@@ -117,7 +138,7 @@ func memoryMapFile(file *os.File, write bool) (mmap.MMap, []uint32, error) {
 
 // memoryMapAndGenerate tries to memory map a temporary file of uint32s for write
 // access, fill it with the data from a generator and then move it into the final
-// path requested.
+// path requested. The checksum of the data is written into the header.
 func memoryMapAndGenerate(path string, size uint64, lock bool, generator func(buffer []uint32)) (*os.File, mmap.MMap, []uint32, error) {
 	// Ensure the data folder exists
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -130,7 +151,7 @@ func memoryMapAndGenerate(path string, size uint64, lock bool, generator func(bu
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	if err = dump.Truncate(int64(len(dumpMagic))*4 + int64(size)); err != nil {
+	if err = dump.Truncate(int64(dumpHeaderSize) + int64(size)); err != nil {
 		return nil, nil, nil, err
 	}
 	// Memory map the file for writing and fill it with the generator
@@ -141,8 +162,9 @@ func memoryMapAndGenerate(path string, size uint64, lock bool, generator func(bu
 	}
 	copy(buffer, dumpMagic)
 
-	data := buffer[len(dumpMagic):]
+	data := buffer[dumpHeaderSize/4:]
 	generator(data)
+	buffer[len(dumpMagic)] = crc32.Checksum(mem[dumpHeaderSize:], dumpChecksumTable)
 
 	if err := mem.Unmap(); err != nil {
 		return nil, nil, nil, err
@@ -153,59 +175,73 @@ func memoryMapAndGenerate(path string, size uint64, lock bool, generator func(bu
 	if err := os.Rename(temp, path); err != nil {
 		return nil, nil, nil, err
 	}
-	return memoryMap(path, lock)
+	return memoryMap(path, size, lock)
 }
 
 // lru tracks caches or datasets by their last use time, keeping at most N of them.
 type lru struct {
-	what string
-	new  func(epoch uint64) interface{}
-	mu   sync.Mutex
+	what  string
+	new   func(epoch uint64) interface{}
+	ahead int // Number of epochs after the highest seen one to keep the 'future items' for
+	mu    sync.Mutex
 	// Items are kept in a LRU cache, but there is a special case:
-	// We always keep an item for (highest seen epoch) + 1 as the 'future item'.
-	cache      *simplelru.LRU
-	future     uint64
-	futureItem interface{}
+	// We always keep the items for the epochs following the highest seen one as the 'future items'.
+	cache       *simplelru.LRU
+	future      uint64                 // Highest epoch a future item was created for
+	futureItems map[uint64]interface{} // Future items not requested yet, by epoch
 }
 
 // newlru create a new least-recently-used cache for either the verification caches
 // or the mining datasets.
-func newlru(what string, maxItems int, new func(epoch uint64) interface{}) *lru {
+func newlru(what string, maxItems int, ahead int, new func(epoch uint64) interface{}) *lru {
 	if maxItems <= 0 {
 		maxItems = 1
 	}
+	if ahead <= 0 {
+		ahead = 1
+	}
 	cache, _ := simplelru.NewLRU(maxItems, func(key, value interface{}) {
 		log.Trace("Evicted ethash "+what, "epoch", key)
 	})
-	return &lru{what: what, new: new, cache: cache}
+	return &lru{what: what, new: new, ahead: ahead, cache: cache, futureItems: make(map[uint64]interface{})}
 }
 
 // get retrieves or creates an item for the given epoch. The first return value is always
-// non-nil. The second return value is non-nil if lru thinks that an item will be useful in
-// the near future.
-func (lru *lru) get(epoch uint64) (item, future interface{}) {
+// non-nil. The second return value holds the new items which lru thinks will be useful in
+// the near future, in the epoch order.
+func (lru *lru) get(epoch uint64) (item interface{}, futures []interface{}) {
 	lru.mu.Lock()
 	defer lru.mu.Unlock()
 
 	// Get or create the item for the requested epoch.
 	item, ok := lru.cache.Get(epoch)
 	if !ok {
-		if lru.future > 0 && lru.future == epoch {
-			item = lru.futureItem
+		if future, ok := lru.futureItems[epoch]; ok {
+			item = future
 		} else {
 			log.Trace("Requiring new ethash "+lru.what, "epoch", epoch)
 			item = lru.new(epoch)
 		}
 		lru.cache.Add(epoch, item)
 	}
-	// Update the 'future item' if epoch is larger than previously seen.
-	if epoch < maxEpoch-1 && lru.future < epoch+1 {
-		log.Trace("Requiring new future ethash "+lru.what, "epoch", epoch+1)
-		future = lru.new(epoch + 1)
-		lru.future = epoch + 1
-		lru.futureItem = future
+	// Future items up to the requested epoch are either in the LRU cache now or were skipped
+	for futureEpoch := range lru.futureItems {
+		if futureEpoch <= epoch {
+			delete(lru.futureItems, futureEpoch)
+		}
+	}
+	// Create the 'future items' for the epochs after the largest one seen.
+	for futureEpoch := epoch + 1; futureEpoch <= epoch+uint64(lru.ahead) && futureEpoch < maxEpoch; futureEpoch++ {
+		if futureEpoch <= lru.future {
+			continue
+		}
+		log.Trace("Requiring new future ethash "+lru.what, "epoch", futureEpoch)
+		future := lru.new(futureEpoch)
+		lru.future = futureEpoch
+		lru.futureItems[futureEpoch] = future
+		futures = append(futures, future)
 	}
-	return item, future
+	return item, futures
 }
 
 // cache wraps an ethash cache with some metadata to allow easier concurrent use.
@@ -251,7 +287,7 @@ func (c *cache) generate(dir string, limit int, lock bool, test bool) {
 
 		// Try to load the file from disk and memory map it
 		var err error
-		c.dump, c.mmap, c.cache, err = memoryMap(path, lock)
+		c.dump, c.mmap, c.cache, err = memoryMap(path, size, lock)
 		if err == nil {
 			logger.Debug("Loaded old ethash cache from disk")
 			return
@@ -266,11 +302,13 @@ func (c *cache) generate(dir string, limit int, lock bool, test bool) {
 			c.cache = make([]uint32, size/4)
 			generateCache(c.cache, c.epoch, seed)
 		}
-		// Iterate over all previous instances and delete old ones
-		for ep := int(c.epoch) - limit; ep >= 0; ep-- {
+		// Iterate over all previous instances and delete old ones, the ones of the previous revision are obsolete
+		for ep := int(c.epoch); ep >= 0; ep-- {
 			seed := seedHash(uint64(ep)*epochLength + 1)
-			path := filepath.Join(dir, fmt.Sprintf("cache-R%d-%x%s", algorithmRevision, seed[:8], endian))
-			os.Remove(path)
+			if ep <= int(c.epoch)-limit {
+				os.Remove(filepath.Join(dir, fmt.Sprintf("cache-R%d-%x%s", algorithmRevision, seed[:8], endian)))
+			}
+			os.Remove(filepath.Join(dir, fmt.Sprintf("cache-R%d-%x%s", algorithmRevision-1, seed[:8], endian)))
 		}
 	})
 }
@@ -337,7 +375,7 @@ func (d *dataset) generate(dir string, limit int, lock bool, test bool) {
 
 		// Try to load the file from disk and memory map it
 		var err error
-		d.dump, d.mmap, d.dataset, err = memoryMap(path, lock)
+		d.dump, d.mmap, d.dataset, err = memoryMap(path, dsize, lock)
 		if err == nil {
 			logger.Debug("Loaded old ethash dataset from disk")
 			return
@@ -355,11 +393,13 @@ func (d *dataset) generate(dir string, limit int, lock bool, test bool) {
 			d.dataset = make([]uint32, dsize/2)
 			generateDataset(d.dataset, d.epoch, cache)
 		}
-		// Iterate over all previous instances and delete old ones
-		for ep := int(d.epoch) - limit; ep >= 0; ep-- {
+		// Iterate over all previous instances and delete old ones, the ones of the previous revision are obsolete
+		for ep := int(d.epoch); ep >= 0; ep-- {
 			seed := seedHash(uint64(ep)*epochLength + 1)
-			path := filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision, seed[:8], endian))
-			os.Remove(path)
+			if ep <= int(d.epoch)-limit {
+				os.Remove(filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision, seed[:8], endian)))
+			}
+			os.Remove(filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision-1, seed[:8], endian)))
 		}
 	})
 }
@@ -405,7 +445,10 @@ const (
 
 // Config are the configuration parameters of the ethash.
 type Config struct {
+	CacheDir         string // Directory to persist the verification caches in, they are kept in memory only if empty
 	CachesInMem      int
+	CachesOnDisk     int
+	CachesAhead      int // Number of upcoming epochs to generate the verification caches for in the background
 	CachesLockMmap   bool
 	DatasetDir       string
 	DatasetsInMem    int
@@ -451,13 +494,16 @@ func New(config Config, notify []string, noverify bool) *Ethash {
 		config.Log.Warn("One ethash cache must always be in memory", "requested", config.CachesInMem)
 		config.CachesInMem = 1
 	}
+	if config.CacheDir != "" && config.CachesOnDisk > 0 {
+		config.Log.Info("Disk storage enabled for ethash caches", "dir", config.CacheDir, "count", config.CachesOnDisk)
+	}
 	if config.DatasetDir != "" && config.DatasetsOnDisk > 0 {
 		config.Log.Info("Disk storage enabled for ethash DAGs", "dir", config.DatasetDir, "count", config.DatasetsOnDisk)
 	}
 	ethash := &Ethash{
 		config:   config,
-		caches:   newlru("cache", config.CachesInMem, newCache),
-		datasets: newlru("dataset", config.DatasetsInMem, newDataset),
+		caches:   newlru("cache", config.CachesInMem, config.CachesAhead, newCache),
+		datasets: newlru("dataset", config.DatasetsInMem, 1, newDataset),
 		update:   make(chan struct{}),
 		hashrate: metrics.NewMeterForced(),
 	}
@@ -470,8 +516,8 @@ func New(config Config, notify []string, noverify bool) *Ethash {
 func NewTester(notify []string, noverify bool) *Ethash {
 	ethash := &Ethash{
 		config:   Config{PowMode: ModeTest, Log: log.Root()},
-		caches:   newlru("cache", 1, newCache),
-		datasets: newlru("dataset", 1, newDataset),
+		caches:   newlru("cache", 1, 1, newCache),
+		datasets: newlru("dataset", 1, 1, newDataset),
 		update:   make(chan struct{}),
 		hashrate: metrics.NewMeterForced(),
 	}
@@ -553,16 +599,20 @@ func (ethash *Ethash) Close() error {
 // stored on disk, and finally generating one if none can be found.
 func (ethash *Ethash) cache(block uint64) *cache {
 	epoch := block / epochLength
-	currentI, futureI := ethash.caches.get(epoch)
+	currentI, futures := ethash.caches.get(epoch)
 	current := currentI.(*cache)
 
 	// Wait for generation finish.
-	current.generate(doNotStoreCachesOnDisk, 0, ethash.config.CachesLockMmap, ethash.config.PowMode == ModeTest)
+	current.generate(ethash.config.CacheDir, ethash.config.CachesOnDisk, ethash.config.CachesLockMmap, ethash.config.PowMode == ModeTest)
 
-	// If we need a new future cache, now's a good time to regenerate it.
-	if futureI != nil {
-		future := futureI.(*cache)
-		go future.generate(doNotStoreCachesOnDisk, 0, ethash.config.CachesLockMmap, ethash.config.PowMode == ModeTest)
+	// If we need new future caches, now's a good time to regenerate them, one after another.
+	if len(futures) > 0 {
+		go func() {
+			for _, futureI := range futures {
+				future := futureI.(*cache)
+				future.generate(ethash.config.CacheDir, ethash.config.CachesOnDisk, ethash.config.CachesLockMmap, ethash.config.PowMode == ModeTest)
+			}
+		}()
 	}
 	return current
 }
@@ -576,7 +626,7 @@ func (ethash *Ethash) cache(block uint64) *cache {
 func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
 	// Retrieve the requested ethash dataset
 	epoch := block / epochLength
-	currentI, futureI := ethash.datasets.get(epoch)
+	currentI, futures := ethash.datasets.get(epoch)
 	current := currentI.(*dataset)
 
 	// If async is specified, generate everything in a background thread
@@ -584,7 +634,7 @@ func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
 		go func() {
 			current.generate(ethash.config.DatasetDir, ethash.config.DatasetsOnDisk, ethash.config.DatasetsLockMmap, ethash.config.PowMode == ModeTest)
 
-			if futureI != nil {
+			for _, futureI := range futures {
 				future := futureI.(*dataset)
 				future.generate(ethash.config.DatasetDir, ethash.config.DatasetsOnDisk, ethash.config.DatasetsLockMmap, ethash.config.PowMode == ModeTest)
 			}
@@ -593,7 +643,7 @@ func (ethash *Ethash) dataset(block uint64, async bool) *dataset {
 		// Either blocking generation was requested, or already done
 		current.generate(ethash.config.DatasetDir, ethash.config.DatasetsOnDisk, ethash.config.DatasetsLockMmap, ethash.config.PowMode == ModeTest)
 
-		if futureI != nil {
+		for _, futureI := range futures {
 			future := futureI.(*dataset)
 			go future.generate(ethash.config.DatasetDir, ethash.config.DatasetsOnDisk, ethash.config.DatasetsLockMmap, ethash.config.PowMode == ModeTest)
 		}
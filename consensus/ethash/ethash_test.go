@@ -17,10 +17,14 @@
 package ethash
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -58,13 +62,12 @@ func TestTestMode(t *testing.T) {
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/ledgerwatch/turbo-geth/issues/14943
 func TestCacheFileEvict(t *testing.T) {
-	// turbo-geth: all caches are in RAM, no files are actually used there
 	tmpdir, err := ioutil.TempDir("", "ethash-test")
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer os.RemoveAll(tmpdir)
-	e := New(Config{CachesInMem: 3, PowMode: ModeTest}, nil, false)
+	e := New(Config{CachesInMem: 3, CachesOnDisk: 10, CacheDir: tmpdir, PowMode: ModeTest}, nil, false)
 	defer e.Close()
 
 	workers := 8
@@ -92,6 +95,97 @@ func verifyTest(wg *sync.WaitGroup, e *Ethash, workerIndex, epochs int) {
 	}
 }
 
+// Tests that the caches on disk are only used when they are intact, and are
+// regenerated otherwise.
+func TestCacheDumpIntegrity(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "ethash-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	// The cache of the previous revision is replaced
+	var endian string
+	if !isLittleEndian() {
+		endian = ".be"
+	}
+	obsolete := filepath.Join(tmpdir, fmt.Sprintf("cache-R%d-%x%s", algorithmRevision-1, seedHash(epochLength + 1)[:8], endian))
+	if err = ioutil.WriteFile(obsolete, make([]byte, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &cache{epoch: 1}
+	want.generate("", 0, false, true)
+	generated := &cache{epoch: 1}
+	generated.generate(tmpdir, 1, false, true)
+	if !reflect.DeepEqual(generated.cache, want.cache) {
+		t.Fatalf("cache on disk mismatch")
+	}
+	files, err := filepath.Glob(filepath.Join(tmpdir, "cache-*"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("cache files: %v, %v", files, err)
+	}
+	path := files[0]
+	generated.finalizer()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err = ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err = memoryMap(path, 1024, false); !errors.Is(err, ErrInvalidDumpChecksum) {
+		t.Errorf("corrupted cache: have %v, want %v", err, ErrInvalidDumpChecksum)
+	}
+	if err = ioutil.WriteFile(path, data[:len(data)-4], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err = memoryMap(path, 1024, false); !errors.Is(err, ErrInvalidDumpSize) {
+		t.Errorf("truncated cache: have %v, want %v", err, ErrInvalidDumpSize)
+	}
+	regenerated := &cache{epoch: 1}
+	regenerated.generate(tmpdir, 1, false, true)
+	defer regenerated.finalizer()
+	if !reflect.DeepEqual(regenerated.cache, want.cache) {
+		t.Errorf("regenerated cache mismatch")
+	}
+	if _, _, _, err = memoryMap(path, 1024, false); err != nil {
+		t.Errorf("regenerated cache: %v", err)
+	}
+}
+
+// Tests that the items of the upcoming epochs are created ahead, and only once.
+func TestLruAhead(t *testing.T) {
+	l := newlru("cache", 2, 3, newCache)
+	epochs := func(items []interface{}) (epochs []uint64) {
+		for _, item := range items {
+			epochs = append(epochs, item.(*cache).epoch)
+		}
+		return epochs
+	}
+	_, futures := l.get(0)
+	if have := epochs(futures); !reflect.DeepEqual(have, []uint64{1, 2, 3}) {
+		t.Fatalf("future epochs: have %v, want [1 2 3]", have)
+	}
+	future := futures[0]
+	item, futures := l.get(1)
+	if item != future {
+		t.Errorf("future item for epoch 1 not used")
+	}
+	if have := epochs(futures); !reflect.DeepEqual(have, []uint64{4}) {
+		t.Errorf("future epochs: have %v, want [4]", have)
+	}
+	// Going back does not recreate the future items
+	if _, futures = l.get(0); len(futures) != 0 {
+		t.Errorf("future epochs recreated: %v", epochs(futures))
+	}
+	if len(l.futureItems) != 3 {
+		t.Errorf("future items: have %d, want 3", len(l.futureItems))
+	}
+}
+
 func TestRemoteSealer(t *testing.T) {
 	ethash := NewTester(nil, false)
 	defer ethash.Close()
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// DefaultAnalysisCacheSize is the number of contracts whose JUMPDEST analysis is kept in memory
+const DefaultAnalysisCacheSize = 16 * 1024
+
+const (
+	// analysisNoBitmap marks code without JUMPDEST or BEGINSUB bytes in PUSH data, for which no bitmap is stored
+	analysisNoBitmap byte = 0
+	// analysisBitmap marks code for which the bitmap of data locations follows
+	analysisBitmap byte = 1
+)
+
+// AnalysisCache keeps results of JUMPDEST analysis of contract code, keyed by code hash.
+// An in-memory LRU sits in front of the CodeAnalysisBucket, which is filled lazily when
+// a contract is executed for the first time. Since code is immutable for a given code hash,
+// entries never need to be invalidated, including on unwinds.
+type AnalysisCache struct {
+	lru *lru.Cache
+}
+
+// NewAnalysisCache creates a cache holding the analysis of up to size contracts in memory
+func NewAnalysisCache(size int) *AnalysisCache {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+	return &AnalysisCache{lru: cache}
+}
+
+// View returns a vm.JumpDestCache backed by the in-memory cache and the given database.
+// If putter is not nil, analyses that were not found in the database are written into it.
+func (c *AnalysisCache) View(db ethdb.Getter, putter ethdb.Putter) vm.JumpDestCache {
+	return &analysisCacheView{cache: c, db: db, putter: putter}
+}
+
+type analysisCacheView struct {
+	cache  *AnalysisCache
+	db     ethdb.Getter
+	putter ethdb.Putter
+}
+
+func (v *analysisCacheView) Get(codeHash common.Hash) ([]uint64, bool) {
+	if analysis, ok := v.cache.lru.Get(codeHash); ok {
+		return analysis.([]uint64), true
+	}
+	if v.db == nil {
+		return nil, false
+	}
+	enc, err := v.db.Get(dbutils.CodeAnalysisBucket, codeHash[:])
+	if err != nil {
+		if !errors.Is(err, ethdb.ErrKeyNotFound) {
+			log.Debug("Failed to read code analysis", "codeHash", codeHash, "err", err)
+		}
+		return nil, false
+	}
+	analysis, err := decodeAnalysis(enc)
+	if err != nil {
+		log.Warn("Corrupted code analysis, recomputing", "codeHash", codeHash, "err", err)
+		return nil, false
+	}
+	v.cache.lru.Add(codeHash, analysis)
+	return analysis, true
+}
+
+func (v *analysisCacheView) Put(codeHash common.Hash, analysis []uint64) {
+	v.cache.lru.Add(codeHash, analysis)
+	if v.putter == nil {
+		return
+	}
+	if err := v.putter.Put(dbutils.CodeAnalysisBucket, common.CopyBytes(codeHash[:]), encodeAnalysis(analysis)); err != nil {
+		log.Warn("Failed to write code analysis", "codeHash", codeHash, "err", err)
+	}
+}
+
+func encodeAnalysis(analysis []uint64) []byte {
+	if analysis == nil {
+		return []byte{analysisNoBitmap}
+	}
+	enc := make([]byte, 1+8*len(analysis))
+	enc[0] = analysisBitmap
+	for i, w := range analysis {
+		binary.BigEndian.PutUint64(enc[1+8*i:], w)
+	}
+	return enc
+}
+
+func decodeAnalysis(enc []byte) ([]uint64, error) {
+	if len(enc) == 0 {
+		return nil, fmt.Errorf("empty encoding")
+	}
+	switch enc[0] {
+	case analysisNoBitmap:
+		if len(enc) != 1 {
+			return nil, fmt.Errorf("unexpected bitmap of %d bytes", len(enc)-1)
+		}
+		return nil, nil
+	case analysisBitmap:
+		if (len(enc)-1)%8 != 0 || len(enc) == 1 {
+			return nil, fmt.Errorf("invalid bitmap length %d", len(enc)-1)
+		}
+		analysis := make([]uint64, (len(enc)-1)/8)
+		for i := range analysis {
+			analysis[i] = binary.BigEndian.Uint64(enc[1+8*i:])
+		}
+		return analysis, nil
+	default:
+		return nil, fmt.Errorf("unknown analysis type %d", enc[0])
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestAnalysisEncoding(t *testing.T) {
+	for _, analysis := range [][]uint64{nil, {0}, {0x1, 0xffffffffffffffff, 0x8000000000000000}} {
+		decoded, err := decodeAnalysis(encodeAnalysis(analysis))
+		if err != nil {
+			t.Fatalf("decoding %x: %v", analysis, err)
+		}
+		if !reflect.DeepEqual(decoded, analysis) {
+			t.Errorf("expected %x, got %x", analysis, decoded)
+		}
+	}
+	for _, enc := range [][]byte{nil, {analysisNoBitmap, 0}, {analysisBitmap}, {analysisBitmap, 1, 2, 3}, {2}} {
+		if _, err := decodeAnalysis(enc); err == nil {
+			t.Errorf("expected error decoding %x", enc)
+		}
+	}
+}
+
+func TestAnalysisCachePersistence(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	withBitmap, noBitmap := common.Hash{1}, common.Hash{2}
+
+	view := NewAnalysisCache(16).View(db, db)
+	if _, ok := view.Get(withBitmap); ok {
+		t.Fatalf("unexpected analysis in an empty cache")
+	}
+	view.Put(withBitmap, []uint64{0x2})
+	view.Put(noBitmap, nil)
+
+	// A fresh cache has to load the analyses from the database
+	view = NewAnalysisCache(16).View(db, nil)
+	if analysis, ok := view.Get(withBitmap); !ok || !reflect.DeepEqual(analysis, []uint64{0x2}) {
+		t.Errorf("expected persisted bitmap, got %x %t", analysis, ok)
+	}
+	if analysis, ok := view.Get(noBitmap); !ok || analysis != nil {
+		t.Errorf("expected persisted nil analysis, got %x %t", analysis, ok)
+	}
+
+	// Read-only views do not write into the database
+	view.Put(common.Hash{3}, nil)
+	if _, ok := NewAnalysisCache(16).View(db, nil).Get(common.Hash{3}); ok {
+		t.Errorf("read-only view must not persist analyses")
+	}
+}
@@ -2032,6 +2032,11 @@ func (bc *BlockChain) NoHistory() bool {
 	return bc.cacheConfig.NoHistory
 }
 
+// DownloadOnly reports whether the blocks are only downloaded, without the state being kept
+func (bc *BlockChain) DownloadOnly() bool {
+	return bc.cacheConfig.DownloadOnly
+}
+
 func (bc *BlockChain) IsNoHistory(currentBlock *big.Int) bool {
 	if currentBlock == nil {
 		return bc.cacheConfig.NoHistory
@@ -2131,10 +2136,29 @@ func ExecuteBlockEphemerally(
 		}
 	}
 
+	if err := finalizeBlockExecution(chainConfig, vmConfig, engine, block, ibs, stateWriter, receipts, *usedGas); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// finalizeBlockExecution checks the receipts and the gas used by the transactions of the block,
+// applies the block rewards and writes the state changes of the block into stateWriter
+func finalizeBlockExecution(
+	chainConfig *params.ChainConfig,
+	vmConfig *vm.Config,
+	engine consensus.Engine,
+	block *types.Block,
+	ibs *state.IntraBlockState,
+	stateWriter state.WriterWithChangeSets,
+	receipts types.Receipts,
+	usedGas uint64,
+) error {
+	header := block.Header()
 	if chainConfig.IsByzantium(header.Number) && !vmConfig.NoReceipts {
 		receiptSha := types.DeriveSha(receipts)
-		if receiptSha != block.Header().ReceiptHash {
-			return nil, fmt.Errorf("mismatched receipt headers for block %d", block.NumberU64())
+		if receiptSha != header.ReceiptHash {
+			return fmt.Errorf("mismatched receipt headers for block %d", block.NumberU64())
 		}
 	}
 
@@ -2144,22 +2168,22 @@ func ExecuteBlockEphemerally(
 
 		ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
 		if err := ibs.CommitBlock(ctx, stateWriter); err != nil {
-			return nil, fmt.Errorf("committing block %d failed: %v", block.NumberU64(), err)
+			return fmt.Errorf("committing block %d failed: %v", block.NumberU64(), err)
 		}
 
 		if err := stateWriter.WriteChangeSets(); err != nil {
-			return nil, fmt.Errorf("writing changesets for block %d failed: %v", block.NumberU64(), err)
+			return fmt.Errorf("writing changesets for block %d failed: %v", block.NumberU64(), err)
 		}
 	}
-	if *usedGas != header.GasUsed {
-		return nil, fmt.Errorf("gas used by execution: %d, in header: %d", *usedGas, header.GasUsed)
+	if usedGas != header.GasUsed {
+		return fmt.Errorf("gas used by execution: %d, in header: %d", usedGas, header.GasUsed)
 	}
 	if !vmConfig.NoReceipts {
 		bloom := types.CreateBloom(receipts)
 		if bloom != header.Bloom {
-			return nil, fmt.Errorf("bloom computed by execution: %x, in header: %x", bloom, header.Bloom)
+			return fmt.Errorf("bloom computed by execution: %x, in header: %x", bloom, header.Bloom)
 		}
 	}
 
-	return receipts, nil
+	return nil
 }
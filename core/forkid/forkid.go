@@ -55,12 +55,18 @@ type Filter func(id ID) error
 
 // NewID calculates the Ethereum fork ID from the chain config, genesis hash, and head.
 func NewID(config *params.ChainConfig, genesis common.Hash, head uint64) ID {
+	return NewIDFromForks(GatherForks(config), genesis, head)
+}
+
+// NewIDFromForks calculates the Ethereum fork ID from the sorted fork block numbers,
+// as returned by GatherForks, the genesis hash, and head.
+func NewIDFromForks(forks []uint64, genesis common.Hash, head uint64) ID {
 	// Calculate the starting checksum from the genesis hash
 	hash := crc32.ChecksumIEEE(genesis[:])
 
 	// Calculate the current fork checksum and the next fork block
 	var next uint64
-	for _, fork := range GatherForks(config) {
+	for _, fork := range forks {
 		if fork <= head {
 			// Fork already passed, checksum the previous hash and the fork number
 			hash = checksumUpdate(hash, fork)
@@ -84,6 +90,18 @@ func NewFilter(config *params.ChainConfig, genesis common.Hash, head uint64) Fil
 	)
 }
 
+// NewFilterFromForks creates a filter that returns if a fork ID should be rejected or not
+// based on the sorted fork block numbers, as returned by GatherForks, the genesis hash, and head.
+func NewFilterFromForks(forks []uint64, genesis common.Hash, head uint64) Filter {
+	return newFilterFromForks(
+		forks,
+		genesis,
+		func() uint64 {
+			return head
+		},
+	)
+}
+
 // NewStaticFilter creates a filter at block zero.
 func NewStaticFilter(config *params.ChainConfig, genesis common.Hash) Filter {
 	head := func() uint64 { return 0 }
@@ -94,11 +112,12 @@ func NewStaticFilter(config *params.ChainConfig, genesis common.Hash) Filter {
 // instead of a chain. The reason is to allow testing it without having to simulate
 // an entire blockchain.
 func newFilter(config *params.ChainConfig, genesis common.Hash, headfn func() uint64) Filter {
+	return newFilterFromForks(GatherForks(config), genesis, headfn)
+}
+
+func newFilterFromForks(forks []uint64, genesis common.Hash, headfn func() uint64) Filter {
 	// Calculate the all the valid fork hash and fork next combos
-	var (
-		forks = GatherForks(config)
-		sums  = make([][4]byte, len(forks)+1) // 0th is the genesis
-	)
+	sums := make([][4]byte, len(forks)+1) // 0th is the genesis
 	hash := crc32.ChecksumIEEE(genesis[:])
 	sums[0] = checksumToBytes(hash)
 	for i, fork := range forks {
@@ -107,7 +126,7 @@ func newFilter(config *params.ChainConfig, genesis common.Hash, headfn func() ui
 	}
 	// Add two sentries to simplify the fork checks and don't require special
 	// casing the last one.
-	forks = append(forks, math.MaxUint64) // Last fork will never be passed
+	forks = append(forks[:len(forks):len(forks)], math.MaxUint64) // Last fork will never be passed
 
 	// Create a validator that will filter out incompatible chains
 	return func(id ID) error {
@@ -121,6 +121,9 @@ func TestCreation(t *testing.T) {
 			if have := NewID(tt.config, tt.genesis, ttt.head); have != ttt.want {
 				t.Errorf("test %d, case %d: fork ID mismatch: have %x, want %x", i, j, have, ttt.want)
 			}
+			if have := NewIDFromForks(GatherForks(tt.config), tt.genesis, ttt.head); have != ttt.want {
+				t.Errorf("test %d, case %d: fork ID from forks mismatch: have %x, want %x", i, j, have, ttt.want)
+			}
 		}
 	}
 }
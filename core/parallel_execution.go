@@ -0,0 +1,655 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/metrics"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+var (
+	parallelTxCommittedMeter   = metrics.NewRegisteredMeter("chain/execution/parallel/committed", nil)
+	parallelTxReexecutedMeter  = metrics.NewRegisteredMeter("chain/execution/parallel/reexecuted", nil)
+	parallelVerificationFailed = metrics.NewRegisteredCounter("chain/execution/parallel/diverged", nil)
+)
+
+// ExecuteBlockParallel is an experimental alternative to ExecuteBlockEphemerally.
+// Transactions of the block are executed speculatively by the given number of workers, each of them
+// against the state at the beginning of the block, recording what it reads and writes.
+// The speculative results are then committed in order. A transaction which has read anything written
+// by the preceding transactions of the block is re-executed on top of them instead, so the receipts,
+// the state and the change sets are the same as the ones of the sequential execution.
+//
+// Reads from stateReader and chainContext are serialised, so they do not have to be thread-safe.
+// Blocks which can not benefit from the parallel execution, and the executions with a tracer,
+// fall back to ExecuteBlockEphemerally.
+func ExecuteBlockParallel(
+	chainConfig *params.ChainConfig,
+	vmConfig *vm.Config,
+	chainContext ChainContext,
+	engine consensus.Engine,
+	block *types.Block,
+	stateReader state.StateReader,
+	stateWriter state.WriterWithChangeSets,
+	workers int,
+) (types.Receipts, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	txs := block.Transactions()
+	daoBlock := chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(block.Number()) == 0
+	if workers < 2 || len(txs) < 2 || vmConfig.Debug || daoBlock {
+		return ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter)
+	}
+	defer blockExecutionTimer.UpdateSince(time.Now())
+
+	header := block.Header()
+	coinbase, _ := chainContext.Engine().Author(header) // Ignore error, we're past header validation
+	var mu sync.Mutex
+	reader := &lockedStateReader{mu: &mu, r: stateReader}
+	chain := &lockedChainContext{mu: &mu, ChainContext: chainContext}
+	cfg := *vmConfig
+	if cfg.JumpDestCache != nil {
+		cfg.JumpDestCache = &lockedJumpDestCache{mu: &mu, cache: cfg.JumpDestCache}
+	}
+
+	// Speculative execution
+	specs := make([]*txSpeculation, len(txs))
+	done := make([]chan struct{}, len(txs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	next := int64(-1)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(txs) {
+					return
+				}
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				specs[i] = speculateTx(chainConfig, chain, coinbase, header, block.Hash(), reader, txs[i], i, cfg)
+				close(done[i])
+			}
+		}()
+	}
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+
+	// Commit in order
+	ibs := state.New(reader)
+	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
+	gp := new(GasPool).AddGas(block.GasLimit())
+	usedGas := new(uint64)
+	written := newWrittenState()
+	var receipts types.Receipts
+	for i, tx := range txs {
+		<-done[i]
+		spec := specs[i]
+		var receipt *types.Receipt
+		if spec.err == nil && tx.Gas() <= gp.Gas() && !written.conflicts(spec, coinbase) {
+			if spec.coinbaseBlind {
+				ibs.MergeTx(spec.ibs, &coinbase)
+				written.add(spec.writes, &coinbase)
+				// Fees are credited to the coinbase on top of the changes of the preceding transactions
+				ibs.AddBalance(coinbase, spec.coinbaseDelta)
+				coinbaseWrites := newStateWriteRecorder()
+				if err := ibs.FinalizeTx(ctx, coinbaseWrites); err != nil {
+					return nil, err
+				}
+				written.add(coinbaseWrites, nil)
+			} else {
+				ibs.MergeTx(spec.ibs, nil)
+				written.add(spec.writes, nil)
+			}
+			if err := gp.SubGas(spec.usedGas); err != nil {
+				return nil, err
+			}
+			*usedGas += spec.usedGas
+			if receipt = spec.receipt; receipt != nil {
+				receipt.CumulativeGasUsed = *usedGas
+			}
+			parallelTxCommittedMeter.Mark(1)
+		} else {
+			if !cfg.NoReceipts {
+				ibs.Prepare(tx.Hash(), block.Hash(), i)
+			}
+			writes := newStateWriteRecorder()
+			var err error
+			if receipt, err = ApplyTransaction(chainConfig, chain, nil, gp, ibs, writes, header, tx, usedGas, cfg); err != nil {
+				return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+			}
+			written.add(writes, nil)
+			parallelTxReexecutedMeter.Mark(1)
+		}
+		if !cfg.NoReceipts {
+			receipts = append(receipts, receipt)
+		}
+	}
+
+	if err := finalizeBlockExecution(chainConfig, vmConfig, engine, block, ibs, stateWriter, receipts, *usedGas); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// VerifyParallelExecution executes the block both sequentially and with ExecuteBlockParallel, and fails
+// if the change sets, the written state or the receipts differ. Only the results of the parallel
+// execution are written into stateWriter.
+func VerifyParallelExecution(
+	chainConfig *params.ChainConfig,
+	vmConfig *vm.Config,
+	chainContext ChainContext,
+	engine consensus.Engine,
+	block *types.Block,
+	stateReader state.StateReader,
+	stateWriter state.WriterWithChangeSets,
+	workers int,
+) (types.Receipts, error) {
+	serial := newStateCapture(block.NumberU64())
+	serialReceipts, err := ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, serial)
+	if err != nil {
+		return nil, err
+	}
+	parallel := newStateCapture(block.NumberU64())
+	receipts, err := ExecuteBlockParallel(chainConfig, vmConfig, chainContext, engine, block, stateReader, &teeStateWriter{stateWriter, parallel}, workers)
+	if err != nil {
+		parallelVerificationFailed.Inc(1)
+		return nil, fmt.Errorf("parallel execution of block %d failed, while sequential succeeded: %w", block.NumberU64(), err)
+	}
+	if err = serial.compare(parallel); err == nil && types.DeriveSha(serialReceipts) != types.DeriveSha(receipts) {
+		err = fmt.Errorf("receipts differ")
+	}
+	if err != nil {
+		parallelVerificationFailed.Inc(1)
+		return nil, fmt.Errorf("parallel execution of block %d diverged from sequential: %w", block.NumberU64(), err)
+	}
+	return receipts, nil
+}
+
+// txSpeculation is the result of executing a transaction against the state at the beginning of the block
+type txSpeculation struct {
+	ibs           *state.IntraBlockState
+	reads         *recordingStateReader
+	writes        *stateWriteRecorder
+	receipt       *types.Receipt
+	usedGas       uint64
+	coinbaseBlind bool         // the coinbase account was only credited, never read
+	coinbaseDelta *uint256.Int // amount credited to the coinbase, if coinbaseBlind
+	err           error
+}
+
+func speculateTx(chainConfig *params.ChainConfig, chain ChainContext, coinbase common.Address, header *types.Header, blockHash common.Hash,
+	stateReader state.StateReader, tx *types.Transaction, txIndex int, cfg vm.Config) *txSpeculation {
+	reads := newRecordingStateReader(stateReader)
+	ibs := state.New(reads)
+	tracer := &coinbaseReadTracer{coinbase: coinbase}
+	ibs.SetTracer(tracer)
+	ibs.Prepare(tx.Hash(), blockHash, txIndex)
+	spec := &txSpeculation{ibs: ibs, reads: reads, writes: newStateWriteRecorder()}
+	gp := new(GasPool).AddGas(header.GasLimit)
+	if spec.receipt, spec.err = ApplyTransaction(chainConfig, chain, &coinbase, gp, ibs, spec.writes, header, tx, &spec.usedGas, cfg); spec.err != nil {
+		return spec
+	}
+	ibs.SetTracer(nil)
+	if spec.coinbaseBlind = !tracer.read; spec.coinbaseBlind {
+		var before uint256.Int
+		if acc := reads.accounts[coinbase]; acc != nil {
+			before.Set(&acc.Balance)
+		}
+		spec.coinbaseDelta = new(uint256.Int).Sub(ibs.GetBalance(coinbase), &before)
+	}
+	return spec
+}
+
+// coinbaseReadTracer detects transactions which observe the coinbase account, as opposed to only
+// crediting it. Crediting alone does not make a transaction depend on the fees paid before it.
+type coinbaseReadTracer struct {
+	coinbase common.Address
+	read     bool
+}
+
+func (t *coinbaseReadTracer) CaptureAccountRead(account common.Address) error {
+	if account == t.coinbase {
+		t.read = true
+	}
+	return nil
+}
+
+func (t *coinbaseReadTracer) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
+
+type storageSlot struct {
+	address common.Address
+	key     common.Hash
+}
+
+type storageItem struct {
+	address     common.Address
+	incarnation uint64
+	key         common.Hash
+}
+
+// recordingStateReader remembers which accounts and storage items were read
+type recordingStateReader struct {
+	r        state.StateReader
+	accounts map[common.Address]*accounts.Account // first value read, nil for non-existent accounts
+	storage  map[storageSlot]struct{}
+}
+
+func newRecordingStateReader(r state.StateReader) *recordingStateReader {
+	return &recordingStateReader{
+		r:        r,
+		accounts: make(map[common.Address]*accounts.Account),
+		storage:  make(map[storageSlot]struct{}),
+	}
+}
+
+func (r *recordingStateReader) touch(address common.Address) {
+	if _, ok := r.accounts[address]; !ok {
+		r.accounts[address] = nil
+	}
+}
+
+func (r *recordingStateReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	a, err := r.r.ReadAccountData(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := r.accounts[address]; !ok && a != nil {
+		var cpy accounts.Account
+		cpy.Copy(a)
+		r.accounts[address] = &cpy
+	}
+	r.touch(address)
+	return a, nil
+}
+
+func (r *recordingStateReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.touch(address)
+	r.storage[storageSlot{address, *key}] = struct{}{}
+	return r.r.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *recordingStateReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	r.touch(address)
+	return r.r.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *recordingStateReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	r.touch(address)
+	return r.r.ReadAccountCodeSize(address, incarnation, codeHash)
+}
+
+func (r *recordingStateReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	r.touch(address)
+	return r.r.ReadAccountIncarnation(address)
+}
+
+// stateWriteRecorder is a state.StateWriter remembering the last written value of accounts and storage items
+type stateWriteRecorder struct {
+	accounts  map[common.Address]*accounts.Account // last written value
+	originals map[common.Address]*accounts.Account // value at the beginning of the block
+	deleted   map[common.Address]struct{}
+	created   map[common.Address]struct{}
+	code      map[common.Address]common.Hash
+	storage   map[storageItem]uint256.Int
+}
+
+func newStateWriteRecorder() *stateWriteRecorder {
+	return &stateWriteRecorder{
+		accounts:  make(map[common.Address]*accounts.Account),
+		originals: make(map[common.Address]*accounts.Account),
+		deleted:   make(map[common.Address]struct{}),
+		created:   make(map[common.Address]struct{}),
+		code:      make(map[common.Address]common.Hash),
+		storage:   make(map[storageItem]uint256.Int),
+	}
+}
+
+func (w *stateWriteRecorder) UpdateAccountData(_ context.Context, address common.Address, original, account *accounts.Account) error {
+	var cpy accounts.Account
+	cpy.Copy(account)
+	w.accounts[address] = &cpy
+	if _, ok := w.originals[address]; !ok {
+		var orig accounts.Account
+		orig.Copy(original)
+		w.originals[address] = &orig
+	}
+	return nil
+}
+
+func (w *stateWriteRecorder) UpdateAccountCode(address common.Address, _ uint64, codeHash common.Hash, _ []byte) error {
+	w.code[address] = codeHash
+	return nil
+}
+
+func (w *stateWriteRecorder) DeleteAccount(_ context.Context, address common.Address, _ *accounts.Account) error {
+	w.deleted[address] = struct{}{}
+	delete(w.accounts, address)
+	return nil
+}
+
+func (w *stateWriteRecorder) WriteAccountStorage(_ context.Context, address common.Address, incarnation uint64, key *common.Hash, _, value *uint256.Int) error {
+	w.storage[storageItem{address, incarnation, *key}] = *value
+	return nil
+}
+
+func (w *stateWriteRecorder) CreateContract(address common.Address) error {
+	w.created[address] = struct{}{}
+	return nil
+}
+
+// writtenState tracks what the committed transactions of a block have modified
+type writtenState struct {
+	accounts map[common.Address]struct{}
+	storage  map[storageSlot]struct{}
+	last     map[common.Address]*accounts.Account // last committed value, nil for deleted accounts
+}
+
+func newWrittenState() *writtenState {
+	return &writtenState{
+		accounts: make(map[common.Address]struct{}),
+		storage:  make(map[storageSlot]struct{}),
+		last:     make(map[common.Address]*accounts.Account),
+	}
+}
+
+func (s *writtenState) add(w *stateWriteRecorder, skip *common.Address) {
+	skipped := func(address common.Address) bool {
+		return skip != nil && address == *skip
+	}
+	for address := range w.deleted {
+		if !skipped(address) {
+			s.accounts[address] = struct{}{}
+			s.last[address] = nil
+		}
+	}
+	for address := range w.created {
+		if !skipped(address) {
+			s.accounts[address] = struct{}{}
+		}
+	}
+	for address := range w.code {
+		if !skipped(address) {
+			s.accounts[address] = struct{}{}
+		}
+	}
+	for address, account := range w.accounts {
+		if skipped(address) {
+			continue
+		}
+		prev, ok := s.last[address]
+		if !ok {
+			prev = w.originals[address]
+		}
+		// Storage writes update the account too, without changing it
+		if prev == nil || !prev.Equals(account) {
+			s.accounts[address] = struct{}{}
+		}
+		s.last[address] = account
+	}
+	for item := range w.storage {
+		if !skipped(item.address) {
+			s.storage[storageSlot{item.address, item.key}] = struct{}{}
+		}
+	}
+}
+
+func (s *writtenState) conflicts(spec *txSpeculation, coinbase common.Address) bool {
+	for address := range spec.reads.accounts {
+		if spec.coinbaseBlind && address == coinbase {
+			continue
+		}
+		if _, ok := s.accounts[address]; ok {
+			return true
+		}
+	}
+	for slot := range spec.reads.storage {
+		if _, ok := s.storage[slot]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type lockedStateReader struct {
+	mu *sync.Mutex
+	r  state.StateReader
+}
+
+func (r *lockedStateReader) ReadAccountData(address common.Address) (*accounts.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ReadAccountData(address)
+}
+
+func (r *lockedStateReader) ReadAccountStorage(address common.Address, incarnation uint64, key *common.Hash) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ReadAccountStorage(address, incarnation, key)
+}
+
+func (r *lockedStateReader) ReadAccountCode(address common.Address, incarnation uint64, codeHash common.Hash) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ReadAccountCode(address, incarnation, codeHash)
+}
+
+func (r *lockedStateReader) ReadAccountCodeSize(address common.Address, incarnation uint64, codeHash common.Hash) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ReadAccountCodeSize(address, incarnation, codeHash)
+}
+
+func (r *lockedStateReader) ReadAccountIncarnation(address common.Address) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.ReadAccountIncarnation(address)
+}
+
+type lockedChainContext struct {
+	mu *sync.Mutex
+	ChainContext
+}
+
+func (c *lockedChainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ChainContext.GetHeader(hash, number)
+}
+
+type lockedJumpDestCache struct {
+	mu    *sync.Mutex
+	cache vm.JumpDestCache
+}
+
+func (c *lockedJumpDestCache) Get(codeHash common.Hash) ([]uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Get(codeHash)
+}
+
+func (c *lockedJumpDestCache) Put(codeHash common.Hash, analysis []uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Put(codeHash, analysis)
+}
+
+// stateCapture keeps the change sets and the state written by a block in memory
+type stateCapture struct {
+	*state.ChangeSetWriter
+	writes *stateWriteRecorder
+}
+
+func newStateCapture(blockNumber uint64) *stateCapture {
+	return &stateCapture{
+		ChangeSetWriter: state.NewChangeSetWriterPlain(nil, blockNumber),
+		writes:          newStateWriteRecorder(),
+	}
+}
+
+func (c *stateCapture) UpdateAccountData(ctx context.Context, address common.Address, original, account *accounts.Account) error {
+	if err := c.ChangeSetWriter.UpdateAccountData(ctx, address, original, account); err != nil {
+		return err
+	}
+	return c.writes.UpdateAccountData(ctx, address, original, account)
+}
+
+func (c *stateCapture) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	if err := c.ChangeSetWriter.UpdateAccountCode(address, incarnation, codeHash, code); err != nil {
+		return err
+	}
+	return c.writes.UpdateAccountCode(address, incarnation, codeHash, code)
+}
+
+func (c *stateCapture) DeleteAccount(ctx context.Context, address common.Address, original *accounts.Account) error {
+	if err := c.ChangeSetWriter.DeleteAccount(ctx, address, original); err != nil {
+		return err
+	}
+	return c.writes.DeleteAccount(ctx, address, original)
+}
+
+func (c *stateCapture) WriteAccountStorage(ctx context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	if err := c.ChangeSetWriter.WriteAccountStorage(ctx, address, incarnation, key, original, value); err != nil {
+		return err
+	}
+	return c.writes.WriteAccountStorage(ctx, address, incarnation, key, original, value)
+}
+
+func (c *stateCapture) CreateContract(address common.Address) error {
+	if err := c.ChangeSetWriter.CreateContract(address); err != nil {
+		return err
+	}
+	return c.writes.CreateContract(address)
+}
+
+func (c *stateCapture) WriteChangeSets() error { return nil }
+func (c *stateCapture) WriteHistory() error    { return nil }
+
+func (c *stateCapture) compare(other *stateCapture) error {
+	if err := compareChangeSets("account", c.GetAccountChanges, other.GetAccountChanges); err != nil {
+		return err
+	}
+	if err := compareChangeSets("storage", c.GetStorageChanges, other.GetStorageChanges); err != nil {
+		return err
+	}
+	w1, w2 := c.writes, other.writes
+	if len(w1.accounts) != len(w2.accounts) {
+		return fmt.Errorf("%d accounts updated instead of %d", len(w2.accounts), len(w1.accounts))
+	}
+	for address, account := range w1.accounts {
+		if account2, ok := w2.accounts[address]; !ok || !account.Equals(account2) {
+			return fmt.Errorf("account %x differs", address)
+		}
+	}
+	if len(w1.deleted) != len(w2.deleted) {
+		return fmt.Errorf("%d accounts deleted instead of %d", len(w2.deleted), len(w1.deleted))
+	}
+	for address := range w1.deleted {
+		if _, ok := w2.deleted[address]; !ok {
+			return fmt.Errorf("account %x is not deleted", address)
+		}
+	}
+	if len(w1.code) != len(w2.code) {
+		return fmt.Errorf("code of %d accounts written instead of %d", len(w2.code), len(w1.code))
+	}
+	for address, codeHash := range w1.code {
+		if w2.code[address] != codeHash {
+			return fmt.Errorf("code of %x differs", address)
+		}
+	}
+	if len(w1.storage) != len(w2.storage) {
+		return fmt.Errorf("%d storage items written instead of %d", len(w2.storage), len(w1.storage))
+	}
+	for item, value := range w1.storage {
+		if value2, ok := w2.storage[item]; !ok || value != value2 {
+			return fmt.Errorf("storage item %x of %x (incarnation %d) differs", item.key, item.address, item.incarnation)
+		}
+	}
+	return nil
+}
+
+func compareChangeSets(kind string, get1, get2 func() (*changeset.ChangeSet, error)) error {
+	cs1, err := get1()
+	if err != nil {
+		return err
+	}
+	cs2, err := get2()
+	if err != nil {
+		return err
+	}
+	sort.Sort(cs1)
+	sort.Sort(cs2)
+	if !cs1.Equals(cs2) {
+		return fmt.Errorf("%s change sets differ, sequential:\n%sparallel:\n%s", kind, cs1, cs2)
+	}
+	return nil
+}
+
+// teeStateWriter additionally passes all writes to a stateCapture
+type teeStateWriter struct {
+	state.WriterWithChangeSets
+	capture *stateCapture
+}
+
+func (w *teeStateWriter) UpdateAccountData(ctx context.Context, address common.Address, original, account *accounts.Account) error {
+	if err := w.WriterWithChangeSets.UpdateAccountData(ctx, address, original, account); err != nil {
+		return err
+	}
+	return w.capture.UpdateAccountData(ctx, address, original, account)
+}
+
+func (w *teeStateWriter) UpdateAccountCode(address common.Address, incarnation uint64, codeHash common.Hash, code []byte) error {
+	if err := w.WriterWithChangeSets.UpdateAccountCode(address, incarnation, codeHash, code); err != nil {
+		return err
+	}
+	return w.capture.UpdateAccountCode(address, incarnation, codeHash, code)
+}
+
+func (w *teeStateWriter) DeleteAccount(ctx context.Context, address common.Address, original *accounts.Account) error {
+	if err := w.WriterWithChangeSets.DeleteAccount(ctx, address, original); err != nil {
+		return err
+	}
+	return w.capture.DeleteAccount(ctx, address, original)
+}
+
+func (w *teeStateWriter) WriteAccountStorage(ctx context.Context, address common.Address, incarnation uint64, key *common.Hash, original, value *uint256.Int) error {
+	if err := w.WriterWithChangeSets.WriteAccountStorage(ctx, address, incarnation, key, original, value); err != nil {
+		return err
+	}
+	return w.capture.WriteAccountStorage(ctx, address, incarnation, key, original, value)
+}
+
+func (w *teeStateWriter) CreateContract(address common.Address) error {
+	if err := w.WriterWithChangeSets.CreateContract(address); err != nil {
+		return err
+	}
+	return w.capture.CreateContract(address)
+}
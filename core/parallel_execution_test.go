@@ -0,0 +1,121 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+type parallelTestChain struct {
+	engine consensus.Engine
+}
+
+func (c parallelTestChain) Engine() consensus.Engine                    { return c.engine }
+func (c parallelTestChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+
+func TestParallelExecutionMatchesSequential(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		engine   = ethash.NewFaker()
+		config   = params.TestChainConfig
+		signer   = types.MakeSigner(config, big.NewInt(1))
+		coinbase = common.HexToAddress("0xc0ffee")
+		// increments slot 0 and logs
+		counter = common.HexToAddress("0xc1")
+		// increments the slot of the caller and logs
+		perCaller = common.HexToAddress("0xc2")
+		// stores the balance of the coinbase into slot 1
+		coinbaseReader = common.HexToAddress("0xc3")
+		// self-destructs in favour of the coinbase
+		selfDestructor = common.HexToAddress("0xc4")
+		recipient      = common.HexToAddress("0xdead")
+		keys           = make([]*ecdsa.PrivateKey, 4)
+		addrs          = make([]common.Address, 4)
+		funds          = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	)
+	defer db.Close()
+	alloc := GenesisAlloc{
+		counter:        {Code: common.FromHex("60005460010160005560006000a0"), Balance: big.NewInt(0)},
+		perCaller:      {Code: common.FromHex("3354600101335560006000a0"), Balance: big.NewInt(0)},
+		coinbaseReader: {Code: common.FromHex("4131600155"), Balance: big.NewInt(0)},
+		selfDestructor: {Code: common.FromHex("41ff"), Balance: big.NewInt(1000)},
+	}
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{Balance: funds}
+	}
+	genesis := (&Genesis{Config: config, Alloc: alloc}).MustCommit(db)
+
+	gasPrice := uint256.NewInt().SetUint64(1)
+	blocks, _, err := GenerateChain(config, genesis, engine, db, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(coinbase)
+		send := func(k int, to *common.Address, value uint64, data []byte) {
+			var tx *types.Transaction
+			nonce := b.TxNonce(addrs[k])
+			if to == nil {
+				tx = types.NewContractCreation(nonce, uint256.NewInt().SetUint64(value), 100000, gasPrice, data)
+			} else {
+				tx = types.NewTransaction(nonce, *to, uint256.NewInt().SetUint64(value), 100000, gasPrice, data)
+			}
+			signed, err := types.SignTx(tx, signer, keys[k])
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.AddTx(signed)
+		}
+		send(0, &recipient, 1, nil)
+		send(0, &recipient, 2, nil) // depends on the previous transaction of the sender
+		send(1, &perCaller, 0, nil)
+		send(2, &perCaller, 0, nil) // same contract, independent slot
+		send(3, &counter, 0, nil)
+		send(1, &counter, 0, nil) // same slot
+		send(2, &coinbase, 5, nil)
+		send(3, &coinbaseReader, 0, nil) // observes the fees of all the preceding transactions
+		send(0, nil, 0, common.FromHex("600160005500"))
+		if i == 1 {
+			send(1, &selfDestructor, 0, nil)
+			send(2, &selfDestructor, 7, nil) // resurrects the self-destructed account
+		}
+	}, false /* intermediateHashes */)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := parallelTestChain{engine: engine}
+	for _, block := range blocks {
+		reader := state.NewPlainStateReader(db)
+		writer := state.NewPlainStateWriter(db, db, block.NumberU64())
+		receipts, err := VerifyParallelExecution(config, &vm.Config{}, chain, engine, block, reader, writer, 4)
+		if err != nil {
+			t.Fatalf("block %d: %v", block.NumberU64(), err)
+		}
+		if len(receipts) != len(block.Transactions()) {
+			t.Fatalf("block %d: expected %d receipts, got %d", block.NumberU64(), len(block.Transactions()), len(receipts))
+		}
+		var logIndex uint
+		for i, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				if l.TxIndex != uint(i) || l.Index != logIndex {
+					t.Errorf("block %d: log %d of tx %d has tx index %d and index %d", block.NumberU64(), logIndex, i, l.TxIndex, l.Index)
+				}
+				logIndex++
+			}
+		}
+		if logIndex == 0 {
+			t.Errorf("block %d: no logs", block.NumberU64())
+		}
+	}
+}
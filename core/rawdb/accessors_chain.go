@@ -550,6 +550,42 @@ func ReadReceipts(db ethdb.Database, hash common.Hash, number uint64) types.Rece
 	return receipts
 }
 
+// ReadCanonicalReceiptsRLP returns the consensus encoding of the receipts of the canonical block with given hash,
+// as served to the peers, or nil if the receipts are unknown. Receipts are only kept for the canonical blocks, and
+// are stored without the blooms, so the blooms are recomputed and the result is checked against the receipt root
+// of the header.
+func ReadCanonicalReceiptsRLP(db ethdb.Database, hash common.Hash) (rlp.RawValue, error) {
+	number := ReadHeaderNumber(db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	canonical, err := ReadCanonicalHash(db, *number)
+	if err != nil {
+		return nil, err
+	}
+	if canonical != hash {
+		return nil, nil
+	}
+	header := ReadHeader(db, hash, *number)
+	if header == nil {
+		return nil, nil
+	}
+	receipts := ReadRawReceipts(db, hash, *number)
+	if receipts == nil && header.ReceiptHash != types.EmptyRootHash {
+		return nil, nil
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	if root := types.DeriveSha(receipts); root != header.ReceiptHash {
+		return nil, fmt.Errorf("receipt root mismatch for block %d: have %x, want %x", *number, root, header.ReceiptHash)
+	}
+	if receipts == nil {
+		receipts = types.Receipts{}
+	}
+	return rlp.EncodeToBytes(receipts)
+}
+
 // WriteReceipts stores all the transaction receipts belonging to a block.
 func WriteReceipts(tx DatabaseWriter, number uint64, receipts types.Receipts) error {
 	buf := bytes.NewBuffer(make([]byte, 0, 1024))
@@ -422,6 +422,53 @@ func TestBlockReceiptStorage(t *testing.T) {
 	}
 }
 
+// Tests that the receipts are only served for the canonical blocks, with the blooms recomputed.
+func TestCanonicalReceiptsRLP(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 1,
+		Logs:              []*types.Log{{Address: common.BytesToAddress([]byte{0x11})}},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipts := types.Receipts{receipt}
+	header := &types.Header{Number: big.NewInt(1), Extra: []byte("test header"), ReceiptHash: types.DeriveSha(receipts)}
+	hash := header.Hash()
+	if encoded, err := ReadCanonicalReceiptsRLP(db, hash); err != nil || encoded != nil {
+		t.Fatalf("unknown receipts returned: %x, %v", encoded, err)
+	}
+	WriteHeader(context.Background(), db, header)
+	if err := WriteReceipts(db, 1, receipts); err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err := ReadCanonicalReceiptsRLP(db, hash); err != nil || encoded != nil {
+		t.Fatalf("receipts of non-canonical block returned: %x, %v", encoded, err)
+	}
+	if err := WriteCanonicalHash(db, hash, 1); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := ReadCanonicalReceiptsRLP(db, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := rlp.EncodeToBytes(receipts)
+	if !bytes.Equal(encoded, want) {
+		t.Fatalf("wrong receipts: have %x, want %x", encoded, want)
+	}
+
+	// The stored receipts not matching the header are an error
+	header.ReceiptHash = common.Hash{1}
+	WriteHeader(context.Background(), db, header)
+	if err := WriteCanonicalHash(db, header.Hash(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCanonicalReceiptsRLP(db, header.Hash()); err == nil {
+		t.Fatal("receipt root mismatch not detected")
+	}
+}
+
 func checkReceiptsRLP(have, want types.Receipts) error {
 	if len(have) != len(want) {
 		return fmt.Errorf("receipts sizes mismatch: have %d, want %d", len(have), len(want))
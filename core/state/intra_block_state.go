@@ -910,6 +910,58 @@ func (sdb *IntraBlockState) CommitBlock(ctx context.Context, stateWriter StateWr
 	return nil
 }
 
+// MergeTx moves the effects of a single transaction, executed and finalized on another
+// IntraBlockState created from the same state reader, into this state.
+// The caller must make sure that the transaction has not read anything modified in this state,
+// in which case the result is the same as if the transaction was executed here.
+// Changes of the skip account, if given, are not merged.
+func (sdb *IntraBlockState) MergeTx(other *IntraBlockState, skip *common.Address) {
+	sdb.Lock()
+	defer sdb.Unlock()
+	other.Lock()
+	defer other.Unlock()
+
+	for addr := range other.stateObjectsDirty {
+		if skip != nil && addr == *skip {
+			continue
+		}
+		stateObject, exist := other.stateObjects[addr]
+		if !exist {
+			continue
+		}
+		stateObject.db = sdb
+		prev := sdb.stateObjects[addr]
+		if prev == nil || stateObject.created || stateObject.suicided || stateObject.deleted {
+			// Newly created and removed accounts do not carry anything over from the previous object
+			sdb.setStateObject(stateObject)
+		} else {
+			prev.data.Copy(&stateObject.data)
+			if prev.code == nil {
+				prev.code = stateObject.code
+			}
+			for key, value := range stateObject.originStorage {
+				prev.originStorage[key] = value
+			}
+			for key, value := range stateObject.blockOriginStorage {
+				if _, ok := prev.blockOriginStorage[key]; !ok {
+					prev.blockOriginStorage[key] = value
+				}
+			}
+			for key, value := range stateObject.dirtyStorage {
+				prev.dirtyStorage[key] = value
+			}
+		}
+		sdb.stateObjectsDirty[addr] = struct{}{}
+	}
+	for hash, logs := range other.logs {
+		for _, l := range logs {
+			l.Index = sdb.logSize
+			sdb.logSize++
+		}
+		sdb.logs[hash] = append(sdb.logs[hash], logs...)
+	}
+}
+
 // Prepare sets the current transaction hash and index and block hash which is
 // used when the EVM emits new state logs.
 func (sdb *IntraBlockState) Prepare(thash, bhash common.Hash, ti int) {
@@ -129,10 +129,17 @@ type TxPoolConfig struct {
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	NoPersist bool             // Whether the pool content should not be kept in the database across restarts
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
+	Policy            string                    // Replacement, eviction and ordering rules: "price" (default) or "fifo"
+	AllowSenders      []common.Address          // If not empty, only the transactions of these senders are admitted
+	DenySenders       []common.Address          // Senders whose transactions are never admitted
+	SenderPriceLimits map[common.Address]uint64 // Minimum gas price per sender, applies to the local transactions too
+	CustomPolicy      TxPoolPolicy              `toml:"-"` // Replaces the policy built from the fields above
+
 	AccountSlots uint64 // Number of executable transaction slots guaranteed per account
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
@@ -150,6 +157,7 @@ var DefaultTxPoolConfig = TxPoolConfig{
 
 	PriceLimit: 1,
 	PriceBump:  10,
+	Policy:     PricePolicyName,
 
 	AccountSlots: 16,
 	GlobalSlots:  4096,
@@ -175,6 +183,13 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.Policy == "" {
+		conf.Policy = DefaultTxPoolConfig.Policy
+	}
+	if conf.Policy != PricePolicyName && conf.Policy != FIFOPolicyName {
+		log.Warn("Sanitizing invalid txpool policy", "provided", conf.Policy, "updated", DefaultTxPoolConfig.Policy)
+		conf.Policy = DefaultTxPoolConfig.Policy
+	}
 	if conf.AccountSlots < 1 {
 		log.Warn("Sanitizing invalid txpool account slots", "provided", conf.AccountSlots, "updated", DefaultTxPoolConfig.AccountSlots)
 		conf.AccountSlots = DefaultTxPoolConfig.AccountSlots
@@ -208,6 +223,7 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 type TxPool struct {
 	config       TxPoolConfig
 	chainconfig  *params.ChainConfig
+	policy       TxPoolPolicy
 	chaindb      *ethdb.ObjectDatabase
 	gasPrice     *big.Int
 	txFeed       event.Feed
@@ -259,6 +275,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chaindb *et
 	pool := &TxPool{
 		config:         config,
 		chainconfig:    chainconfig,
+		policy:         newTxPoolPolicy(&config),
 		chaindb:        chaindb,
 		signer:         types.NewEIP155Signer(chainconfig.ChainID),
 		pending:        make(map[common.Address]*txList),
@@ -285,7 +302,7 @@ func (pool *TxPool) Start(gasLimit uint64, headNumber uint64) error {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
-	pool.priced = newTxPricedList(pool.all)
+	pool.priced = newTxPricedList(pool.all, pool.policy)
 	pool.resetHead(gasLimit, headNumber)
 
 	// Start the reorg loop early so it can handle requests generated during journal loading.
@@ -303,6 +320,12 @@ func (pool *TxPool) Start(gasLimit uint64, headNumber uint64) error {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If persistence is enabled, reload the pool content saved before the restart
+	if !pool.config.NoPersist {
+		if err := pool.load(); err != nil {
+			log.Warn("Failed to load transaction pool from the database", "err", err)
+		}
+	}
 
 	pool.wg.Add(1)
 	go pool.loop()
@@ -378,6 +401,11 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if !pool.config.NoPersist {
+				if err := pool.persist(); err != nil {
+					log.Warn("Failed to save transaction pool", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -410,6 +438,11 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if !pool.config.NoPersist {
+		if err := pool.persist(); err != nil {
+			log.Warn("Failed to save transaction pool", "err", err)
+		}
+	}
 
 	pool.isStarted = false
 
@@ -438,7 +471,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
-		pool.removeTxLocked(tx.Hash(), false)
+		pool.removeTxLocked(tx.Hash(), true)
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
@@ -508,6 +541,12 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 	return pending, nil
 }
 
+// Order arranges the given pending transactions for the inclusion into a block
+// according to the pool policy. The transaction lists are reowned by the result.
+func (pool *TxPool) Order(signer types.Signer, pending map[common.Address]types.Transactions) types.TransactionsStream {
+	return pool.policy.Order(signer, pending)
+}
+
 // Locals retrieves the accounts currently considered local by the pool.
 func (pool *TxPool) Locals() []common.Address {
 	pool.mu.Lock()
@@ -558,6 +597,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !local && pool.gasPrice.Cmp(tx.GasPrice().ToBig()) > 0 {
 		return ErrUnderpriced
 	}
+	// Apply the deployment specific admission rules
+	if err := pool.policy.Admit(from, tx, local); err != nil {
+		return err
+	}
 	// Ensure the transaction adheres to nonce ordering
 	if pool.currentState.GetNonce(from) > tx.Nonce() {
 		return ErrNonceTooLow
@@ -621,7 +664,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := list.Add(tx, pool.policy)
 		if !inserted {
 			pendingDiscardMeter.Mark(1)
 			return false, ErrReplaceUnderpriced
@@ -672,7 +715,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.policy)
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardMeter.Mark(1)
@@ -721,7 +764,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
+	inserted, old := list.Add(tx, pool.policy)
 	if !inserted {
 		// An older transaction was better, discard this
 		pool.all.Remove(hash)
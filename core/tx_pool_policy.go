@@ -0,0 +1,158 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+)
+
+var (
+	// ErrSenderNotAllowed is returned if the sender is denied by the pool policy,
+	// or it is not in the allow list of the policy.
+	ErrSenderNotAllowed = errors.New("sender not allowed")
+
+	// ErrSenderUnderpriced is returned if the gas price of a transaction is below
+	// the minimum configured for its sender.
+	ErrSenderUnderpriced = errors.New("transaction underpriced for sender")
+)
+
+// Names of the built-in pool policies, see TxPoolConfig.Policy
+const (
+	PricePolicyName = "price"
+	FIFOPolicyName  = "fifo"
+)
+
+// TxPoolPolicy is the set of rules the pool applies on top of the validity of the transactions. It decides
+// which transactions are admitted, which of them replace the pooled ones, which are evicted when the pool
+// fills up and in which order the pending ones are offered for the inclusion into a block.
+//
+// The methods are called with the pool lock held and must not call back into the pool.
+type TxPoolPolicy interface {
+	// Admit is called for every new transaction which passed the basic validation, an error rejects it.
+	// Local transactions are passed through Admit too.
+	Admit(from common.Address, tx *types.Transaction, local bool) error
+
+	// Replace reports whether tx replaces the pooled old transaction with the same sender and nonce.
+	Replace(old, tx *types.Transaction) bool
+
+	// Compare orders the remote transactions for the eviction when the pool is full, a negative result
+	// means a is evicted before b. A new remote transaction which does not compare above the first one
+	// to evict is rejected instead.
+	Compare(a, b *types.Transaction) int
+
+	// Order arranges the pending transactions for the inclusion into a block. The lists of every sender
+	// are nonce sorted and are owned by the returned stream.
+	Order(signer types.Signer, pending map[common.Address]types.Transactions) types.TransactionsStream
+}
+
+// PricePolicy is the default policy: a replacement has to raise the gas price by the given percentage,
+// the cheapest transactions are evicted first and the pending ones are ordered by price.
+type PricePolicy struct {
+	PriceBump uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+}
+
+func (p *PricePolicy) Admit(common.Address, *types.Transaction, bool) error {
+	return nil
+}
+
+func (p *PricePolicy) Replace(old, tx *types.Transaction) bool {
+	// threshold = oldGP * (100 + priceBump) / 100
+	a := uint256.NewInt().SetUint64(100 + p.PriceBump)
+	a = a.Mul(a, old.GasPrice())
+	b := uint256.NewInt().SetUint64(100)
+	threshold := a.Div(a, b)
+	// Have to ensure that the new gas price is higher than the old gas
+	// price as well as checking the percentage threshold to ensure that
+	// this is accurate for low (Wei-level) gas price replacements
+	return old.GasPriceCmp(tx) < 0 && tx.GasPriceIntCmp(threshold) >= 0
+}
+
+func (p *PricePolicy) Compare(a, b *types.Transaction) int {
+	return a.GasPriceCmp(b)
+}
+
+func (p *PricePolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions) types.TransactionsStream {
+	return types.NewTransactionsByPriceAndNonce(signer, pending)
+}
+
+// FIFOPolicy serves the transactions in the order of their arrival. A replacement only needs to match the
+// gas price of the pooled transaction and, once the pool is full, the new remote transactions are rejected
+// instead of evicting the earlier ones.
+type FIFOPolicy struct{}
+
+func (p *FIFOPolicy) Admit(common.Address, *types.Transaction, bool) error {
+	return nil
+}
+
+func (p *FIFOPolicy) Replace(old, tx *types.Transaction) bool {
+	return old.GasPriceCmp(tx) <= 0
+}
+
+func (p *FIFOPolicy) Compare(a, b *types.Transaction) int {
+	// The later arrival is evicted first
+	switch {
+	case a.Time().After(b.Time()):
+		return -1
+	case a.Time().Before(b.Time()):
+		return 1
+	}
+	return 0
+}
+
+func (p *FIFOPolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions) types.TransactionsStream {
+	return types.NewTransactionsByTimeAndNonce(signer, pending)
+}
+
+// SenderPolicy restricts the senders admitted by the underlying policy, it is meant for the permissioned
+// deployments. The restrictions apply to the local transactions too.
+type SenderPolicy struct {
+	TxPoolPolicy
+
+	Allow       map[common.Address]struct{} // If not empty, only these senders are admitted
+	Deny        map[common.Address]struct{} // These senders are never admitted
+	MinGasPrice map[common.Address]uint64   // Minimum gas price of the transactions of the sender
+}
+
+func (p *SenderPolicy) Admit(from common.Address, tx *types.Transaction, local bool) error {
+	if _, ok := p.Deny[from]; ok {
+		return ErrSenderNotAllowed
+	}
+	if _, ok := p.Allow[from]; len(p.Allow) > 0 && !ok {
+		return ErrSenderNotAllowed
+	}
+	if price, ok := p.MinGasPrice[from]; ok && tx.GasPriceIntCmp(uint256.NewInt().SetUint64(price)) < 0 {
+		return ErrSenderUnderpriced
+	}
+	return p.TxPoolPolicy.Admit(from, tx, local)
+}
+
+// newTxPoolPolicy creates the policy described by the sanitized config, unless the config carries a custom one
+func newTxPoolPolicy(config *TxPoolConfig) TxPoolPolicy {
+	if config.CustomPolicy != nil {
+		return config.CustomPolicy
+	}
+	var policy TxPoolPolicy
+	if config.Policy == FIFOPolicyName {
+		policy = &FIFOPolicy{}
+	} else {
+		policy = &PricePolicy{PriceBump: config.PriceBump}
+	}
+	if len(config.AllowSenders) == 0 && len(config.DenySenders) == 0 && len(config.SenderPriceLimits) == 0 {
+		return policy
+	}
+	senders := &SenderPolicy{
+		TxPoolPolicy: policy,
+		Allow:        make(map[common.Address]struct{}, len(config.AllowSenders)),
+		Deny:         make(map[common.Address]struct{}, len(config.DenySenders)),
+		MinGasPrice:  config.SenderPriceLimits,
+	}
+	for _, addr := range config.AllowSenders {
+		senders.Allow[addr] = struct{}{}
+	}
+	for _, addr := range config.DenySenders {
+		senders.Deny[addr] = struct{}{}
+	}
+	return senders
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// storedTx is the record of a pooled transaction in TxPoolPendingBucket and TxPoolQueueBucket
+type storedTx struct {
+	Tx    *types.Transaction
+	Time  uint64 // Arrival time of the transaction, in nanoseconds since the epoch
+	Local bool
+}
+
+// storedTxKey is the sender address followed by the big endian nonce, so the records of every sender
+// are kept in the nonce order
+func storedTxKey(from common.Address, nonce uint64) []byte {
+	key := make([]byte, common.AddressLength+8)
+	copy(key, from[:])
+	binary.BigEndian.PutUint64(key[common.AddressLength:], nonce)
+	return key
+}
+
+// Save replaces the pool content stored in the database with the current pending and queued transactions.
+// The pool lock is only held while the content is copied, not while it is written. Save does nothing if
+// the persistence is disabled by TxPoolConfig.NoPersist
+func (pool *TxPool) Save(db ethdb.Database) error {
+	if pool.config.NoPersist {
+		return nil
+	}
+	pool.mu.RLock()
+	pending := pool.storedTxs(pool.pending)
+	queued := pool.storedTxs(pool.queue)
+	pool.mu.RUnlock()
+
+	if err := saveTxs(db, dbutils.TxPoolPendingBucket, pending); err != nil {
+		return fmt.Errorf("saving pending transactions: %w", err)
+	}
+	if err := saveTxs(db, dbutils.TxPoolQueueBucket, queued); err != nil {
+		return fmt.Errorf("saving queued transactions: %w", err)
+	}
+	return nil
+}
+
+// persist saves the pool into its own database in a separate transaction
+func (pool *TxPool) persist() error {
+	tx, err := pool.chaindb.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = pool.Save(tx); err != nil {
+		return err
+	}
+	_, err = tx.Commit()
+	return err
+}
+
+// storedTxs encodes the given lists into the records to store, keyed by storedTxKey.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) storedTxs(lists map[common.Address]*txList) map[string][]byte {
+	records := make(map[string][]byte)
+	for addr, list := range lists {
+		local := pool.locals.contains(addr)
+		for _, tx := range list.Flatten() {
+			v, err := rlp.EncodeToBytes(&storedTx{Tx: tx, Time: uint64(tx.Time().UnixNano()), Local: local})
+			if err != nil {
+				log.Warn("Failed to encode pooled transaction", "hash", tx.Hash(), "err", err)
+				continue
+			}
+			records[string(storedTxKey(addr, tx.Nonce()))] = v
+		}
+	}
+	return records
+}
+
+// saveTxs makes the content of the bucket match the given records
+func saveTxs(db ethdb.Database, bucket string, records map[string][]byte) error {
+	var stale [][]byte
+	if err := db.Walk(bucket, nil, 0, func(k, _ []byte) (bool, error) {
+		if _, ok := records[string(k)]; !ok {
+			stale = append(stale, common.CopyBytes(k))
+		}
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err := db.Delete(bucket, k, nil); err != nil {
+			return err
+		}
+	}
+	for k, v := range records {
+		if err := db.Put(bucket, []byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load injects the transactions stored by Save back into the pool. They go through the same validation as
+// the new ones, so the transactions made obsolete by the current nonces and balances are dropped
+func (pool *TxPool) load() error {
+	var (
+		locals, remotes []*types.Transaction
+		arrivals        = make(map[common.Address]time.Time)
+	)
+	for _, bucket := range []string{dbutils.TxPoolPendingBucket, dbutils.TxPoolQueueBucket} {
+		if err := pool.chaindb.Walk(bucket, nil, 0, func(k, v []byte) (bool, error) {
+			var stored storedTx
+			if err := rlp.DecodeBytes(v, &stored); err != nil {
+				log.Warn("Failed to decode stored transaction", "key", fmt.Sprintf("%x", k), "err", err)
+				return true, nil
+			}
+			arrival := time.Unix(0, int64(stored.Time))
+			stored.Tx.SetTime(arrival)
+			if stored.Local && !pool.config.NoLocals {
+				locals = append(locals, stored.Tx)
+			} else {
+				remotes = append(remotes, stored.Tx)
+			}
+			from := common.BytesToAddress(k[:common.AddressLength])
+			if arrival.After(arrivals[from]) {
+				arrivals[from] = arrival
+			}
+			return true, nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	dropped := 0
+	for _, errs := range [][]error{pool.addTxs(locals, true, true), pool.addTxs(remotes, false, true)} {
+		for _, err := range errs {
+			if err != nil && err != ErrAlreadyKnown {
+				dropped++
+			}
+		}
+	}
+	// Restore the heartbeats, so the queued transactions are not kept around for longer than before the restart
+	pool.mu.Lock()
+	for addr, arrival := range arrivals {
+		if _, ok := pool.beats[addr]; ok {
+			pool.beats[addr] = arrival
+		}
+	}
+	pool.mu.Unlock()
+
+	log.Info("Loaded transaction pool from the database", "transactions", len(locals)+len(remotes), "dropped", dropped)
+	return nil
+}
@@ -47,6 +47,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.NoPersist = true
 	testTxPoolConfig.StartOnInit = true
 }
 
@@ -1685,6 +1686,247 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that the sender rules of the pool policy are applied to both the remote
+// and the local transactions.
+func TestTransactionPolicyAdmission(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	addrs := make([]common.Address, len(keys))
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	config := testTxPoolConfig
+	config.AllowSenders = []common.Address{addrs[0], addrs[1]}
+	config.DenySenders = []common.Address{addrs[1]}
+	config.SenderPriceLimits = map[common.Address]uint64{addrs[0]: 5}
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+	for _, addr := range addrs {
+		pool.currentState.AddBalance(addr, uint256.NewInt().SetUint64(1000000000))
+	}
+
+	if err := pool.AddRemote(pricedTransaction(0, 100000, uint256.NewInt().SetUint64(4), keys[0])); err != ErrSenderUnderpriced {
+		t.Fatalf("remote transaction under the sender price error mismatch: have %v, want %v", err, ErrSenderUnderpriced)
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100000, uint256.NewInt().SetUint64(4), keys[0])); err != ErrSenderUnderpriced {
+		t.Fatalf("local transaction under the sender price error mismatch: have %v, want %v", err, ErrSenderUnderpriced)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, uint256.NewInt().SetUint64(5), keys[0])); err != nil {
+		t.Fatalf("failed to add transaction of the allowed sender: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100000, u256.Num1, keys[1])); err != ErrSenderNotAllowed {
+		t.Fatalf("transaction of the denied sender error mismatch: have %v, want %v", err, ErrSenderNotAllowed)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, u256.Num1, keys[2])); err != ErrSenderNotAllowed {
+		t.Fatalf("transaction of the sender out of the allow list error mismatch: have %v, want %v", err, ErrSenderNotAllowed)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool content mismatch: have %d pending and %d queued, want 1 and 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the FIFO policy replaces the transactions without a price bump and
+// rejects the new remote transactions once the pool is full, instead of evicting
+// the cheaper but earlier ones.
+func TestTransactionFIFOPolicy(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := testTxPoolConfig
+	config.Policy = FIFOPolicyName
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), uint256.NewInt().SetUint64(1000000000))
+	}
+	arrival := time.Now()
+	timedTransaction := func(nonce uint64, gasprice uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := pricedTransaction(nonce, 100000, uint256.NewInt().SetUint64(gasprice), key)
+		arrival = arrival.Add(time.Second)
+		tx.SetTime(arrival)
+		return tx
+	}
+
+	// Replacements only need to match the price
+	if err := pool.addRemoteSync(timedTransaction(0, 2, keys[0])); err != nil {
+		t.Fatalf("failed to add original pending transaction: %v", err)
+	}
+	if err := pool.AddRemote(timedTransaction(0, 1, keys[0])); err != ErrReplaceUnderpriced {
+		t.Fatalf("cheaper replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	replacement := pricedTransaction(0, 100001, u256.Num2, keys[0])
+	if err := pool.AddRemote(replacement); err != nil {
+		t.Fatalf("failed to replace pending transaction at the same price: %v", err)
+	}
+	if pool.Get(replacement.Hash()) == nil {
+		t.Fatalf("replacement transaction not pooled")
+	}
+
+	// Fill the pool up with cheap transactions, the expensive late ones are rejected
+	if err := pool.addRemoteSync(timedTransaction(1, 1, keys[0])); err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(timedTransaction(0, 1, keys[1])); err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	if err := pool.AddRemote(timedTransaction(2, 1, keys[1])); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	if err := pool.AddRemote(timedTransaction(0, 100, keys[2])); err != ErrUnderpriced {
+		t.Fatalf("late transaction in the full pool error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	// Local transactions still push out the latest remote ones
+	if err := pool.AddLocal(timedTransaction(0, 1, keys[2])); err != nil {
+		t.Fatalf("failed to add local transaction into the full pool: %v", err)
+	}
+	pending, queued := pool.Stats()
+	if pending != 4 || queued != 0 {
+		t.Fatalf("pool content mismatch: have %d pending and %d queued, want 4 and 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the transactions promoted and demoted by the pool reorganisations
+// follow the pool policy, and the pending ones are ordered by it.
+func TestTransactionPolicyReorg(t *testing.T) {
+	t.Run("price", func(t *testing.T) { testTransactionPolicyReorg(t, PricePolicyName) })
+	t.Run("fifo", func(t *testing.T) { testTransactionPolicyReorg(t, FIFOPolicyName) })
+}
+
+func testTransactionPolicyReorg(t *testing.T, policy string) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := testTxPoolConfig
+	config.Policy = policy
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	events := make(chan NewTxsEvent, 32)
+	sub := pool.txFeed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	// commit applies the state changes of a new block and resets the pool onto it
+	commit := func(change func(ibs *state.IntraBlockState)) {
+		ibs := state.New(state.NewPlainStateReader(db))
+		change(ibs)
+		if err := ibs.CommitBlock(context.Background(), state.NewPlainStateWriter(db, nil, 1)); err != nil {
+			t.Fatalf("committing state: %v", err)
+		}
+		pool.ResetHead(1000000000, 1)
+	}
+	keys := make([]*ecdsa.PrivateKey, 3)
+	addrs := make([]common.Address, len(keys))
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	commit(func(ibs *state.IntraBlockState) {
+		for _, addr := range addrs {
+			ibs.AddBalance(addr, uint256.NewInt().SetUint64(1000000000))
+		}
+	})
+	arrival := time.Now()
+	timedTransaction := func(nonce uint64, gasprice uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := pricedTransaction(nonce, 100000, uint256.NewInt().SetUint64(gasprice), key)
+		arrival = arrival.Add(time.Second)
+		tx.SetTime(arrival)
+		return tx
+	}
+
+	// The earlier senders pay less, the first one only has queued transactions
+	first := []*types.Transaction{timedTransaction(1, 1, keys[0]), timedTransaction(2, 1, keys[0])}
+	second := timedTransaction(0, 2, keys[1])
+	third := []*types.Transaction{timedTransaction(0, 3, keys[2]), timedTransaction(1, 3, keys[2])}
+	pool.AddRemotesSync(append(append(first, second), third...))
+	if pending, queued := pool.Stats(); pending != 3 || queued != 2 {
+		t.Fatalf("pool content mismatch: have %d pending and %d queued, want 3 and 2", pending, queued)
+	}
+	if err := validateEvents(events, 3); err != nil {
+		t.Fatalf("original event firing failed: %v", err)
+	}
+
+	// A block includes the missing transaction of the first sender, the reset promotes the queued ones
+	commit(func(ibs *state.IntraBlockState) { ibs.SetNonce(addrs[0], 1) })
+	if pending, queued := pool.Stats(); pending != 5 || queued != 0 {
+		t.Fatalf("pool content mismatch after promotion: have %d pending and %d queued, want 5 and 0", pending, queued)
+	}
+	if err := validateEvents(events, 2); err != nil {
+		t.Fatalf("promotion event firing failed: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+
+	// The pending transactions are offered by the policy
+	pending, _ := pool.Pending()
+	var ordered []*types.Transaction
+	for txs := pool.Order(pool.signer, pending); txs.Peek() != nil; txs.Shift() {
+		ordered = append(ordered, txs.Peek())
+	}
+	want := append(append(third, second), first...)
+	if policy == FIFOPolicyName {
+		want = append(append(first, second), third...)
+	}
+	if len(ordered) != len(want) {
+		t.Fatalf("ordered transactions mismatch: have %d, want %d", len(ordered), len(want))
+	}
+	for i, tx := range ordered {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("ordered transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+	}
+
+	// The third sender runs out of funds, the reset demotes its transactions
+	commit(func(ibs *state.IntraBlockState) { ibs.SetBalance(addrs[2], uint256.NewInt()) })
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pool content mismatch after demotion: have %d pending and %d queued, want 3 and 0", pending, queued)
+	}
+	if err := validateEvents(events, 0); err != nil {
+		t.Fatalf("demotion event firing failed: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1836,6 +2078,83 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	}
 }
 
+// Tests that the pending and queued transactions, remote ones included, are kept
+// in the database across pool restarts and revalidated against the current state.
+func TestTransactionPersistence(t *testing.T) {
+	t.Parallel()
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+
+	config := testTxPoolConfig
+	config.NoPersist = false
+
+	txCacher := NewTxSenderCacher(runtime.NumCPU())
+	pool := NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	defer func() {
+		txCacher.Close()
+		pool.Stop()
+	}()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	stateWriter := state.NewPlainStateWriter(db, nil, 1)
+	ibs := state.New(state.NewPlainStateReader(db))
+	ibs.AddBalance(crypto.PubkeyToAddress(local.PublicKey), uint256.NewInt().SetUint64(1000000000))
+	ibs.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), uint256.NewInt().SetUint64(1000000000))
+	if err := ibs.CommitBlock(context.Background(), stateWriter); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a local transaction, two pending and one queued remote transactions
+	if err := pool.AddLocal(pricedTransaction(0, 100000, u256.Num1, local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	first := pricedTransaction(1, 100000, u256.Num1, remote)
+	for _, tx := range []*types.Transaction{pricedTransaction(0, 100000, u256.Num1, remote), first, pricedTransaction(3, 100000, u256.Num1, remote)} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("transactions mismatched: have %d pending and %d queued, want %d and %d", pending, queued, 3, 1)
+	}
+	arrival := first.Time()
+
+	// Terminate the old pool, include the first remote transaction and ensure the rest survives the restart
+	txCacher.Close()
+	pool.Stop()
+
+	stateWriter = state.NewPlainStateWriter(db, nil, 1)
+	ibs = state.New(state.NewPlainStateReader(db))
+	ibs.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 1)
+	if err := ibs.CommitBlock(context.Background(), stateWriter); err != nil {
+		t.Fatal(err)
+	}
+
+	txCacher = NewTxSenderCacher(runtime.NumCPU())
+	pool = NewTxPool(config, params.TestChainConfig, db, txCacher)
+	if err := pool.Start(1000000000, 0); err != nil {
+		t.Fatalf("starting tx pool: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("transactions mismatched: have %d pending and %d queued, want %d and %d", pending, queued, 2, 1)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != crypto.PubkeyToAddress(local.PublicKey) {
+		t.Fatalf("local accounts mismatched: have %v", locals)
+	}
+	if tx := pool.Get(first.Hash()); tx == nil || !tx.Time().Equal(arrival) {
+		t.Fatalf("arrival time of the restored transaction mismatched: have %v, want %v", tx, arrival)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -188,6 +188,13 @@ func (tx *Transaction) Value() *uint256.Int { return new(uint256.Int).Set(&tx.da
 func (tx *Transaction) Nonce() uint64       { return tx.data.AccountNonce }
 func (tx *Transaction) CheckNonce() bool    { return true }
 
+// Time returns the time the transaction was first seen locally.
+func (tx *Transaction) Time() time.Time { return tx.time }
+
+// SetTime overrides the time the transaction was first seen locally, used when
+// restoring transactions seen before a restart.
+func (tx *Transaction) SetTime(t time.Time) { tx.time = t }
+
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
 func (tx *Transaction) To() *common.Address {
@@ -351,6 +358,37 @@ func (s *TxByPriceAndTime) Pop() interface{} {
 	return x
 }
 
+// TxByTime implements both the sort and the heap interface, ordering the transactions
+// by the time they were first seen locally.
+type TxByTime Transactions
+
+func (s TxByTime) Len() int           { return len(s) }
+func (s TxByTime) Less(i, j int) bool { return s[i].time.Before(s[j].time) }
+func (s TxByTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *TxByTime) Push(x interface{}) {
+	*s = append(*s, x.(*Transaction))
+}
+
+func (s *TxByTime) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
+
+// TransactionsStream is a set of transactions offered for the inclusion into a block
+// one by one, honouring the nonce order of every account.
+type TransactionsStream interface {
+	// Peek returns the next transaction, nil if there are no more.
+	Peek() *Transaction
+	// Shift replaces the next transaction with the following one from the same account.
+	Shift()
+	// Pop removes the next transaction together with the rest of the same account.
+	Pop()
+}
+
 // TransactionsByPriceAndNonce represents a set of transactions that can return
 // transactions in a profit-maximizing sorted order, while supporting removing
 // entire batches of transactions for non-executable accounts.
@@ -413,6 +451,65 @@ func (t *TransactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
 
+// TransactionsByTimeAndNonce represents a set of transactions that can return
+// transactions in the order of their arrival, while supporting removing entire
+// batches of transactions for non-executable accounts.
+type TransactionsByTimeAndNonce struct {
+	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	heads  TxByTime                        // Next transaction for each unique account (time heap)
+	signer Signer                          // Signer for the set of transactions
+}
+
+// NewTransactionsByTimeAndNonce creates a transaction set that can retrieve
+// arrival time sorted transactions in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByTimeAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByTimeAndNonce {
+	heads := make(TxByTime, 0, len(txs))
+	for from, accTxs := range txs {
+		heads = append(heads, accTxs[0])
+		// Ensure the sender address is from the signer
+		acc, _ := Sender(signer, accTxs[0])
+		txs[acc] = accTxs[1:]
+		if from != acc {
+			delete(txs, from)
+		}
+	}
+	heap.Init(&heads)
+
+	return &TransactionsByTimeAndNonce{
+		txs:    txs,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction by arrival time.
+func (t *TransactionsByTimeAndNonce) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift replaces the current first head with the next one from the same account.
+func (t *TransactionsByTimeAndNonce) Shift() {
+	acc, _ := Sender(t.signer, t.heads[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+		heap.Fix(&t.heads, 0)
+	} else {
+		heap.Pop(&t.heads)
+	}
+}
+
+// Pop removes the first transaction, *not* replacing it with the next one from
+// the same account.
+func (t *TransactionsByTimeAndNonce) Pop() {
+	heap.Pop(&t.heads)
+}
+
 // Message is a fully derived transaction and implements core.Message
 //
 // NOTE: In a future PR this will be removed.
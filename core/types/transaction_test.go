@@ -221,6 +221,42 @@ func TestTransactionTimeSort(t *testing.T) {
 	}
 }
 
+// Tests that the transactions are returned in the order of their arrival regardless
+// of the price, while the nonce order of every account is kept.
+func TestTransactionArrivalSort(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	// The later accounts pay more, but their transactions arrive later
+	groups := map[common.Address]Transactions{}
+	for start, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for i := 0; i < 3; i++ {
+			tx, _ := SignTx(NewTransaction(uint64(i), common.Address{}, uint256.NewInt().SetUint64(100), 100, uint256.NewInt().SetUint64(uint64(start+1)), nil), signer, key)
+			tx.time = time.Unix(0, int64(start+i*len(keys)))
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	txset := NewTransactionsByTimeAndNonce(signer, groups)
+
+	txs := Transactions{}
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		txs = append(txs, tx)
+		txset.Shift()
+	}
+	if len(txs) != 3*len(keys) {
+		t.Errorf("expected %d transactions, found %d", 3*len(keys), len(txs))
+	}
+	for i := 0; i+1 < len(txs); i++ {
+		if txs[i].time.After(txs[i+1].time) {
+			t.Errorf("invalid received time ordering: tx #%d (T=%v) > tx #%d (T=%v)", i, txs[i].time, i+1, txs[i+1].time)
+		}
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"github.com/ledgerwatch/turbo-geth/common"
 )
 
 // codeBitmap collects data locations in code.
@@ -44,3 +45,24 @@ func codeBitmap(code []byte) []uint64 {
 	}
 	return bits
 }
+
+// codeAnalysis returns the code bitmap of the code, or nil if the code contains no
+// JUMPDEST or BEGINSUB bytes within PUSH data. In the latter case any JUMPDEST or BEGINSUB
+// byte of the code is a valid destination and the bitmap does not need to be consulted.
+func codeAnalysis(code []byte) []uint64 {
+	bits := codeBitmap(code)
+	for pc, b := range code {
+		if (OpCode(b) == JUMPDEST || OpCode(b) == BEGINSUB) && !isCodeFromAnalysis(bits, uint64(pc)) {
+			return bits
+		}
+	}
+	return nil
+}
+
+// JumpDestCache keeps results of JUMPDEST analysis of contract code across transactions
+// and blocks. The analysis is keyed by code hash, a nil analysis means that every
+// JUMPDEST or BEGINSUB byte of the code is a valid destination.
+type JumpDestCache interface {
+	Get(codeHash common.Hash) (analysis []uint64, ok bool)
+	Put(codeHash common.Hash, analysis []uint64)
+}
@@ -51,6 +51,61 @@ func TestJumpDestAnalysis(t *testing.T) {
 	}
 }
 
+func TestCodeAnalysis(t *testing.T) {
+	tests := []struct {
+		code  []byte
+		isNil bool
+	}{
+		{[]byte{byte(PUSH1), 0x01, byte(JUMPDEST)}, true},
+		{[]byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}, false},
+		{[]byte{byte(PUSH2), 0x01, byte(BEGINSUB)}, false},
+		{[]byte{byte(PUSH32)}, true},
+	}
+	for i, test := range tests {
+		if analysis := codeAnalysis(test.code); (analysis == nil) != test.isNil {
+			t.Errorf("test %d: expected nil analysis %t, got %x", i, test.isNil, analysis)
+		}
+	}
+}
+
+type mapJumpDestCache struct {
+	analyses   map[common.Hash][]uint64
+	gets, puts int
+}
+
+func (c *mapJumpDestCache) Get(codeHash common.Hash) ([]uint64, bool) {
+	c.gets++
+	analysis, ok := c.analyses[codeHash]
+	return analysis, ok
+}
+
+func (c *mapJumpDestCache) Put(codeHash common.Hash, analysis []uint64) {
+	c.puts++
+	c.analyses[codeHash] = analysis
+}
+
+func TestJumpDestCache(t *testing.T) {
+	// PUSH1 JUMPDEST JUMPDEST: the first JUMPDEST is PUSH data, the second one is a valid destination
+	code := []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+	hash := crypto.Keccak256Hash(code)
+	cache := &mapJumpDestCache{analyses: make(map[common.Hash][]uint64)}
+	for i := 0; i < 2; i++ {
+		contract := NewContract(dummyContractRef{}, dummyContractRef{}, nil, 0, false /* skipAnalysis */)
+		contract.Code = code
+		contract.CodeHash = hash
+		contract.jumpDestCache = cache
+		if valid, _ := contract.validJumpdest(uint256.NewInt().SetUint64(1)); valid {
+			t.Errorf("run %d: jump into PUSH data must be invalid", i)
+		}
+		if valid, _ := contract.validJumpdest(uint256.NewInt().SetUint64(2)); !valid {
+			t.Errorf("run %d: jump to JUMPDEST must be valid", i)
+		}
+	}
+	if cache.gets != 2 || cache.puts != 1 {
+		t.Errorf("expected the analysis to be done once and then reused, got %d gets and %d puts", cache.gets, cache.puts)
+	}
+}
+
 func BenchmarkJumpdestAnalysisEmpty_1200k(bench *testing.B) {
 	// 1.4 ms
 	code := make([]byte, 1200000)
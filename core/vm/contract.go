@@ -51,6 +51,7 @@ type Contract struct {
 	jumpdests     map[common.Hash][]uint64 // Aggregated result of JUMPDEST analysis.
 	analysis      []uint64                 // Locally cached result of JUMPDEST analysis
 	skipAnalysis  bool
+	jumpDestCache JumpDestCache // Results of JUMPDEST analysis shared across transactions
 
 	Code     []byte
 	CodeHash common.Hash
@@ -129,14 +130,15 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
+			// Look it up in the shared cache or do the analysis, and save in parent context
 			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			analysis = c.sharedAnalysis()
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
 		c.analysis = analysis
-		return isCodeFromAnalysis(analysis, udest)
+		// No analysis means there are no JUMPDEST or BEGINSUB bytes inside PUSH data
+		return analysis == nil || isCodeFromAnalysis(analysis, udest)
 	}
 
 	// We don't have the code hash, most likely a piece of initcode not already
@@ -150,6 +152,20 @@ func (c *Contract) isCode(udest uint64) bool {
 	return isCodeFromAnalysis(c.analysis, udest)
 }
 
+// sharedAnalysis returns the analysis of the contract code from the JumpDestCache,
+// performing it and filling the cache if necessary
+func (c *Contract) sharedAnalysis() []uint64 {
+	if c.jumpDestCache == nil {
+		return codeAnalysis(c.Code)
+	}
+	if analysis, ok := c.jumpDestCache.Get(c.CodeHash); ok {
+		return analysis
+	}
+	analysis := codeAnalysis(c.Code)
+	c.jumpDestCache.Put(c.CodeHash, analysis)
+	return analysis
+}
+
 // AsDelegate sets the contract to be a delegate call and returns the current
 // contract (for chaining calls)
 func (c *Contract) AsDelegate() *Contract {
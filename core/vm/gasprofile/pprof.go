@@ -0,0 +1,189 @@
+package gasprofile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the messages defined in github.com/google/pprof/proto/profile.proto
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+	profileTimeNanos   = 9
+	profilePeriodType  = 11
+	profilePeriod      = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+type pprofBuilder struct {
+	strings   []string
+	stringIDs map[string]int64
+	functions map[string]uint64
+	locations map[locationKey]uint64
+	out       []byte
+}
+
+type locationKey struct {
+	fn   uint64
+	line uint64
+}
+
+func (b *pprofBuilder) str(s string) int64 {
+	if id, ok := b.stringIDs[s]; ok {
+		return id
+	}
+	id := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.stringIDs[s] = id
+	return id
+}
+
+func (b *pprofBuilder) function(name, filename string) uint64 {
+	if id, ok := b.functions[name]; ok {
+		return id
+	}
+	id := uint64(len(b.functions) + 1)
+	b.functions[name] = id
+	var msg []byte
+	msg = protowire.AppendTag(msg, functionID, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, id)
+	msg = protowire.AppendTag(msg, functionName, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(b.str(name)))
+	msg = protowire.AppendTag(msg, functionSystemName, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(b.str(name)))
+	msg = protowire.AppendTag(msg, functionFilename, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(b.str(filename)))
+	b.out = protowire.AppendTag(b.out, profileFunction, protowire.BytesType)
+	b.out = protowire.AppendBytes(b.out, msg)
+	return id
+}
+
+func (b *pprofBuilder) location(fn uint64, line uint64) uint64 {
+	k := locationKey{fn: fn, line: line}
+	if id, ok := b.locations[k]; ok {
+		return id
+	}
+	id := uint64(len(b.locations) + 1)
+	b.locations[k] = id
+	var l []byte
+	l = protowire.AppendTag(l, lineFunctionID, protowire.VarintType)
+	l = protowire.AppendVarint(l, fn)
+	l = protowire.AppendTag(l, lineLine, protowire.VarintType)
+	l = protowire.AppendVarint(l, line)
+	var msg []byte
+	msg = protowire.AppendTag(msg, locationID, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, id)
+	msg = protowire.AppendTag(msg, locationLine, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, l)
+	b.out = protowire.AppendTag(b.out, profileLocation, protowire.BytesType)
+	b.out = protowire.AppendBytes(b.out, msg)
+	return id
+}
+
+func (b *pprofBuilder) valueType(field protowire.Number, typ, unit string) {
+	var msg []byte
+	msg = protowire.AppendTag(msg, valueTypeType, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(b.str(typ)))
+	msg = protowire.AppendTag(msg, valueTypeUnit, protowire.VarintType)
+	msg = protowire.AppendVarint(msg, uint64(b.str(unit)))
+	b.out = protowire.AppendTag(b.out, field, protowire.BytesType)
+	b.out = protowire.AppendBytes(b.out, msg)
+}
+
+// WritePprof writes the profile as a gzip-compressed pprof protobuf, which can be
+// inspected with `go tool pprof`. Every sample carries three values: gas, time in
+// nanoseconds and the number of executions. Contracts are represented as source
+// files named after their code hash, with the program counter used as the line
+// number, so that `pprof -list` shows per-instruction costs of a contract.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	b := &pprofBuilder{
+		stringIDs: make(map[string]int64),
+		functions: make(map[string]uint64),
+		locations: make(map[locationKey]uint64),
+	}
+	b.str("") // string_table[0] must be the empty string
+	b.valueType(profileSampleType, "gas", "count")
+	b.valueType(profileSampleType, "time", "nanoseconds")
+	b.valueType(profileSampleType, "instructions", "count")
+	b.valueType(profilePeriodType, "gas", "count")
+	b.out = protowire.AppendTag(b.out, profilePeriod, protowire.VarintType)
+	b.out = protowire.AppendVarint(b.out, 1)
+	b.out = protowire.AppendTag(b.out, profileTimeNanos, protowire.VarintType)
+	b.out = protowire.AppendVarint(b.out, uint64(time.Now().UnixNano()))
+
+	keys := make([]sampleKey, 0, len(p.samples))
+	for k := range p.samples {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].node != keys[j].node {
+			return keys[i].node < keys[j].node
+		}
+		return keys[i].pc < keys[j].pc
+	})
+	for _, k := range keys {
+		s := p.samples[k]
+		n := p.nodes[k.node]
+		file := n.codeHash.Hex()
+		// Leaf location is the instruction itself, followed by the call sites of all enclosing frames
+		locs := []uint64{b.location(b.function(fmt.Sprintf("%s.%s", frameName(n.codeHash), k.op), file), k.pc)}
+		for id := k.node; id != 0; {
+			callPc := p.nodes[id].callPc
+			id = p.nodes[id].parent
+			if id == 0 {
+				break
+			}
+			parent := p.nodes[id]
+			locs = append(locs, b.location(b.function(frameName(parent.codeHash), parent.codeHash.Hex()), callPc))
+		}
+		var ids []byte
+		for _, l := range locs {
+			ids = protowire.AppendVarint(ids, l)
+		}
+		var values []byte
+		values = protowire.AppendVarint(values, s.Gas)
+		values = protowire.AppendVarint(values, uint64(s.Time))
+		values = protowire.AppendVarint(values, s.Count)
+		var msg []byte
+		msg = protowire.AppendTag(msg, sampleLocationID, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, ids)
+		msg = protowire.AppendTag(msg, sampleValue, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, values)
+		b.out = protowire.AppendTag(b.out, profileSample, protowire.BytesType)
+		b.out = protowire.AppendBytes(b.out, msg)
+	}
+	for _, s := range b.strings {
+		b.out = protowire.AppendTag(b.out, profileStringTable, protowire.BytesType)
+		b.out = protowire.AppendString(b.out, s)
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.out); err != nil {
+		return err
+	}
+	return zw.Close()
+}
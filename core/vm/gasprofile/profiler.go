@@ -0,0 +1,218 @@
+// Package gasprofile implements an EVM tracer that aggregates gas and time
+// spent per (contract code hash, pc, opcode) across many transactions and blocks.
+// The accumulated profile can be rendered as flamegraph-compatible folded stacks,
+// as a pprof protobuf profile or as a list of per-contract hotspots.
+package gasprofile
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/stack"
+)
+
+// Stat is the accumulated cost of a single profile entry
+type Stat struct {
+	Gas   uint64
+	Time  time.Duration
+	Count uint64
+}
+
+func (s *Stat) add(gas uint64, t time.Duration) {
+	s.Gas += gas
+	s.Time += t
+	s.Count++
+}
+
+// OpKey identifies an instruction within the code of a contract
+type OpKey struct {
+	CodeHash common.Hash
+	Pc       uint64
+	Op       vm.OpCode
+}
+
+// node is an element of the call tree. Nodes are interned, so that the same
+// sequence of call sites always maps to the same node id
+type node struct {
+	parent   uint32
+	codeHash common.Hash
+	callPc   uint64 // pc of the call instruction within the parent
+}
+
+type nodeKey node
+
+type sampleKey struct {
+	node uint32
+	pc   uint64
+	op   vm.OpCode
+}
+
+// frame is the tracking state of a single active call frame
+type frame struct {
+	node     uint32 // 0 until the first instruction of the frame is seen
+	callPc   uint64
+	startGas uint64
+	started  bool
+	pending  sampleKey
+	gas      uint64 // gas available before the pending instruction
+	start    time.Time
+	// gas and time consumed by the child frames of the pending instruction
+	childGas  uint64
+	childTime time.Duration
+}
+
+// Profiler is a vm.Tracer which accumulates the gas and wall time spent by
+// every executed instruction. The cost of an instruction that creates a new
+// call frame (CALL, CREATE and friends) does not include the cost of the
+// code executed in that frame, which is attributed to the callee instead.
+// A Profiler is not safe for concurrent use, but can be reused across many
+// transactions and blocks.
+type Profiler struct {
+	nodes   []node
+	nodeIDs map[nodeKey]uint32
+	samples map[sampleKey]*Stat
+	frames  []*frame
+	txs     uint64
+}
+
+// NewProfiler creates an empty profiler
+func NewProfiler() *Profiler {
+	return &Profiler{
+		nodes:   []node{{}}, // node 0 is the root
+		nodeIDs: make(map[nodeKey]uint32),
+		samples: make(map[sampleKey]*Stat),
+	}
+}
+
+// Transactions returns the number of top-level calls seen by the profiler
+func (p *Profiler) Transactions() uint64 {
+	return p.txs
+}
+
+func (p *Profiler) intern(parent uint32, codeHash common.Hash, callPc uint64) uint32 {
+	k := nodeKey{parent: parent, codeHash: codeHash, callPc: callPc}
+	if id, ok := p.nodeIDs[k]; ok {
+		return id
+	}
+	id := uint32(len(p.nodes))
+	p.nodes = append(p.nodes, node(k))
+	p.nodeIDs[k] = id
+	return id
+}
+
+func (p *Profiler) record(k sampleKey, gas uint64, t time.Duration) {
+	s, ok := p.samples[k]
+	if !ok {
+		s = &Stat{}
+		p.samples[k] = s
+	}
+	s.add(gas, t)
+}
+
+// flush attributes the cost of the pending instruction of the frame, given the gas left after it
+func (p *Profiler) flush(f *frame, gasLeft uint64, now time.Time) {
+	if !f.started {
+		return
+	}
+	var gas uint64
+	if f.gas > gasLeft {
+		gas = f.gas - gasLeft
+	}
+	if gas > f.childGas {
+		gas -= f.childGas
+	} else {
+		gas = 0
+	}
+	elapsed := now.Sub(f.start) - f.childTime
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	p.record(f.pending, gas, elapsed)
+	f.started = false
+	f.childGas = 0
+	f.childTime = 0
+}
+
+// CaptureStart implements vm.Tracer
+func (p *Profiler) CaptureStart(depth int, from common.Address, to common.Address, precompile bool, create bool, callType vm.CallType, input []byte, gas uint64, value *big.Int) error {
+	f := &frame{startGas: gas}
+	if len(p.frames) == 0 {
+		p.txs++
+	} else if parent := p.frames[len(p.frames)-1]; parent.started {
+		f.callPc = parent.pending.pc
+	}
+	p.frames = append(p.frames, f)
+	return nil
+}
+
+// CaptureState implements vm.Tracer
+func (p *Profiler) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	now := time.Now()
+	f := p.frames[len(p.frames)-1]
+	if f.node == 0 {
+		var parent uint32
+		if len(p.frames) > 1 {
+			parent = p.frames[len(p.frames)-2].node
+		}
+		f.node = p.intern(parent, contract.CodeHash, f.callPc)
+	}
+	if f.started && f.pending.pc == pc && f.pending.op == op && err != nil {
+		// The same instruction is reported again on fault, it has already been accounted for
+		return nil
+	}
+	p.flush(f, gas, now)
+	f.pending = sampleKey{node: f.node, pc: pc, op: op}
+	f.gas = gas
+	f.start = now
+	f.started = true
+	return nil
+}
+
+// CaptureFault implements vm.Tracer
+func (p *Profiler) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, st *stack.Stack, rStack *stack.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return p.CaptureState(env, pc, op, gas, cost, memory, st, rStack, nil, contract, depth, err)
+}
+
+// CaptureEnd implements vm.Tracer
+func (p *Profiler) CaptureEnd(depth int, output []byte, gasUsed uint64, t time.Duration, err error) error {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	now := time.Now()
+	f := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	if f.started {
+		var gasLeft uint64
+		if f.startGas > gasUsed {
+			gasLeft = f.startGas - gasUsed
+		}
+		p.flush(f, gasLeft, now)
+	}
+	if f.node == 0 || len(p.frames) == 0 {
+		// Frames that did not execute any code (precompiles, transfers) stay in the cost of the calling instruction
+		return nil
+	}
+	parent := p.frames[len(p.frames)-1]
+	parent.childGas += gasUsed
+	parent.childTime += t
+	return nil
+}
+
+// CaptureSelfDestruct implements vm.Tracer
+func (p *Profiler) CaptureSelfDestruct(from common.Address, to common.Address, value *big.Int) {
+}
+
+// CaptureAccountRead implements vm.Tracer
+func (p *Profiler) CaptureAccountRead(account common.Address) error {
+	return nil
+}
+
+// CaptureAccountWrite implements vm.Tracer
+func (p *Profiler) CaptureAccountWrite(account common.Address) error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package gasprofile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/core/vm/runtime"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+)
+
+func TestProfilerAttribution(t *testing.T) {
+	var (
+		callerAddr = common.HexToAddress("0xaa")
+		calleeAddr = common.HexToAddress("0xbb")
+		// PUSH1 0x2a PUSH1 0 MSTORE STOP
+		calleeCode = common.FromHex("602a60005200")
+		// CALL(GAS, callee, 0, 0, 0, 0, 0) POP STOP
+		callerCode = common.FromHex("6000600060006000600073" + common.Bytes2Hex(calleeAddr.Bytes()) + "5af15000")
+	)
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	ibs := state.New(state.NewTrieDbState(common.Hash{}, db, 0))
+	ibs.SetCode(callerAddr, callerCode)
+	ibs.SetCode(calleeAddr, calleeCode)
+
+	p := NewProfiler()
+	cfg := &runtime.Config{State: ibs, GasLimit: 1000000, EVMConfig: vm.Config{Debug: true, Tracer: p}}
+	for i := 0; i < 2; i++ {
+		_, leftOver, err := runtime.Call(callerAddr, nil, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if used := cfg.GasLimit - leftOver; p.Total().Gas != uint64(i+1)*used {
+			t.Errorf("total gas %d, expected %d", p.Total().Gas, uint64(i+1)*used)
+		}
+	}
+	if p.Transactions() != 2 {
+		t.Errorf("transactions %d, expected 2", p.Transactions())
+	}
+
+	calleeHash := crypto.Keccak256Hash(calleeCode)
+	callerHash := crypto.Keccak256Hash(callerCode)
+	hotspots := p.ContractHotspots(MetricCount, 0)
+	if len(hotspots) != 2 {
+		t.Fatalf("expected 2 contracts, got %d", len(hotspots))
+	}
+	for _, h := range hotspots {
+		switch h.CodeHash {
+		case calleeHash:
+			// PUSH1 + PUSH1 + MSTORE with one word of memory expansion, twice
+			if h.Gas != 2*12 || h.Count != 2*4 {
+				t.Errorf("callee gas %d count %d, expected 24 and 8", h.Gas, h.Count)
+			}
+		case callerHash:
+			if h.Count != 2*10 {
+				t.Errorf("caller count %d, expected 20", h.Count)
+			}
+		default:
+			t.Errorf("unexpected code hash %x", h.CodeHash)
+		}
+	}
+	ops := p.OpHotspots(MetricGas, 1)
+	if len(ops) != 1 || ops[0].Op != "CALL" || ops[0].CodeHash != callerHash {
+		t.Errorf("expected CALL of the caller to be the top instruction, got %+v", ops)
+	}
+
+	var folded bytes.Buffer
+	if err := p.WriteFolded(&folded, MetricGas); err != nil {
+		t.Fatal(err)
+	}
+	expected := frameName(callerHash) + ";" + frameName(calleeHash) + ";MSTORE@4 12"
+	if !strings.Contains(folded.String(), expected+"\n") {
+		t.Errorf("folded output does not contain %q:\n%s", expected, folded.String())
+	}
+
+	var pprof bytes.Buffer
+	if err := p.WritePprof(&pprof); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&pprof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(raw, []byte(calleeHash.Hex())) {
+		t.Errorf("pprof string table does not contain the callee code hash")
+	}
+}
@@ -0,0 +1,230 @@
+package gasprofile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+)
+
+// Metric selects which of the accumulated values is used as the weight of a profile entry
+type Metric int
+
+const (
+	MetricGas Metric = iota
+	MetricTime
+	MetricCount
+)
+
+// ParseMetric converts the name of a metric ("gas", "time" or "count") into a Metric
+func ParseMetric(name string) (Metric, error) {
+	switch name {
+	case "gas":
+		return MetricGas, nil
+	case "time":
+		return MetricTime, nil
+	case "count":
+		return MetricCount, nil
+	default:
+		return 0, fmt.Errorf("unknown profile metric %q, expected one of gas, time, count", name)
+	}
+}
+
+func (m Metric) value(s *Stat) uint64 {
+	switch m {
+	case MetricTime:
+		return uint64(s.Time)
+	case MetricCount:
+		return s.Count
+	default:
+		return s.Gas
+	}
+}
+
+// ContractHotspot is the aggregated cost of all instructions executed within the code of one contract
+type ContractHotspot struct {
+	CodeHash common.Hash `json:"codeHash"`
+	Gas      uint64      `json:"gas"`
+	Time     uint64      `json:"timeNs"`
+	Count    uint64      `json:"count"`
+}
+
+// OpHotspot is the aggregated cost of one instruction
+type OpHotspot struct {
+	CodeHash common.Hash `json:"codeHash"`
+	Pc       uint64      `json:"pc"`
+	Op       string      `json:"op"`
+	Gas      uint64      `json:"gas"`
+	Time     uint64      `json:"timeNs"`
+	Count    uint64      `json:"count"`
+}
+
+// Total returns the cost of all instructions recorded by the profiler
+func (p *Profiler) Total() Stat {
+	var total Stat
+	for _, s := range p.samples {
+		total.Gas += s.Gas
+		total.Time += s.Time
+		total.Count += s.Count
+	}
+	return total
+}
+
+// ByOp aggregates the profile per (code hash, pc, opcode), regardless of the call path
+func (p *Profiler) ByOp() map[OpKey]*Stat {
+	res := make(map[OpKey]*Stat)
+	for k, s := range p.samples {
+		ok := OpKey{CodeHash: p.nodes[k.node].codeHash, Pc: k.pc, Op: k.op}
+		agg, found := res[ok]
+		if !found {
+			agg = &Stat{}
+			res[ok] = agg
+		}
+		agg.Gas += s.Gas
+		agg.Time += s.Time
+		agg.Count += s.Count
+	}
+	return res
+}
+
+// ContractHotspots returns up to limit contracts (all of them if limit is 0) ordered by the given metric
+func (p *Profiler) ContractHotspots(m Metric, limit int) []ContractHotspot {
+	byCode := make(map[common.Hash]*Stat)
+	for k, s := range p.samples {
+		codeHash := p.nodes[k.node].codeHash
+		agg, ok := byCode[codeHash]
+		if !ok {
+			agg = &Stat{}
+			byCode[codeHash] = agg
+		}
+		agg.Gas += s.Gas
+		agg.Time += s.Time
+		agg.Count += s.Count
+	}
+	keys := make([]common.Hash, 0, len(byCode))
+	for codeHash := range byCode {
+		keys = append(keys, codeHash)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		vi, vj := m.value(byCode[keys[i]]), m.value(byCode[keys[j]])
+		if vi != vj {
+			return vi > vj
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	res := make([]ContractHotspot, len(keys))
+	for i, codeHash := range keys {
+		s := byCode[codeHash]
+		res[i] = ContractHotspot{CodeHash: codeHash, Gas: s.Gas, Time: uint64(s.Time), Count: s.Count}
+	}
+	return res
+}
+
+// OpHotspots returns up to limit instructions (all of them if limit is 0) ordered by the given metric
+func (p *Profiler) OpHotspots(m Metric, limit int) []OpHotspot {
+	byOp := p.ByOp()
+	keys := make([]OpKey, 0, len(byOp))
+	for k := range byOp {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		vi, vj := m.value(byOp[keys[i]]), m.value(byOp[keys[j]])
+		if vi != vj {
+			return vi > vj
+		}
+		if keys[i].CodeHash != keys[j].CodeHash {
+			return keys[i].CodeHash.String() < keys[j].CodeHash.String()
+		}
+		return keys[i].Pc < keys[j].Pc
+	})
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	res := make([]OpHotspot, len(keys))
+	for i, k := range keys {
+		s := byOp[k]
+		res[i] = OpHotspot{CodeHash: k.CodeHash, Pc: k.Pc, Op: k.Op.String(), Gas: s.Gas, Time: uint64(s.Time), Count: s.Count}
+	}
+	return res
+}
+
+// frameName is the label of a call frame in the folded stacks and pprof outputs
+func frameName(codeHash common.Hash) string {
+	return codeHash.Hex()[:18]
+}
+
+// stackOf returns the labels of the call path leading to the node, outermost frame first
+func (p *Profiler) stackOf(id uint32) []string {
+	var frames []string
+	for ; id != 0; id = p.nodes[id].parent {
+		frames = append(frames, frameName(p.nodes[id].codeHash))
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+// WriteFolded writes the profile in the folded stacks format understood by
+// flamegraph.pl and speedscope: one line per unique stack, frames separated
+// by semicolons, followed by the weight of the stack
+func (p *Profiler) WriteFolded(w io.Writer, m Metric) error {
+	lines := make([]string, 0, len(p.samples))
+	stacks := make(map[uint32]string)
+	for k, s := range p.samples {
+		v := m.value(s)
+		if v == 0 {
+			continue
+		}
+		prefix, ok := stacks[k.node]
+		if !ok {
+			prefix = strings.Join(p.stackOf(k.node), ";")
+			stacks[k.node] = prefix
+		}
+		lines = append(lines, fmt.Sprintf("%s;%s@%d %d", prefix, k.op, k.pc, v))
+	}
+	sort.Strings(lines)
+	bw := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteSummary writes a human readable report of the top contracts and instructions
+func (p *Profiler) WriteSummary(w io.Writer, m Metric, limit int) error {
+	total := p.Total()
+	if _, err := fmt.Fprintf(w, "Transactions: %d, instructions: %d, gas: %d, time: %s\n",
+		p.txs, total.Count, total.Gas, total.Time); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\nTop contracts:\n%-68s %14s %14s %12s\n", "code hash", "gas", "time", "count"); err != nil {
+		return err
+	}
+	for _, h := range p.ContractHotspots(m, limit) {
+		if _, err := fmt.Fprintf(w, "%-68s %14d %14s %12d\n", h.CodeHash.Hex(), h.Gas, time.Duration(h.Time), h.Count); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\nTop instructions:\n%-68s %8s %-14s %14s %14s %12s\n", "code hash", "pc", "op", "gas", "time", "count"); err != nil {
+		return err
+	}
+	for _, h := range p.OpHotspots(m, limit) {
+		if _, err := fmt.Fprintf(w, "%-68s %8d %-14s %14d %14s %12d\n", h.CodeHash.Hex(), h.Pc, h.Op, h.Gas, time.Duration(h.Time), h.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -28,14 +28,15 @@ import (
 
 // Config are the configuration options for the Interpreter
 type Config struct {
-	Debug                   bool   // Enables debugging
-	Tracer                  Tracer // Opcode logger
-	NoRecursion             bool   // Disables call, callcode, delegate call and create
-	EnablePreimageRecording bool   // Enables recording of SHA3/keccak preimages
-	SkipAnalysis            bool   // Whether we can skip jumpdest analysis based on the checked history
-	TraceJumpDest           bool   // Print transaction hashes where jumpdest analysis was useful
-	NoReceipts              bool   // Do not calculate receipts
-	ReadOnly                bool   // Do no perform any block finalisation
+	Debug                   bool          // Enables debugging
+	Tracer                  Tracer        // Opcode logger
+	NoRecursion             bool          // Disables call, callcode, delegate call and create
+	EnablePreimageRecording bool          // Enables recording of SHA3/keccak preimages
+	SkipAnalysis            bool          // Whether we can skip jumpdest analysis based on the checked history
+	JumpDestCache           JumpDestCache // Results of jumpdest analysis shared across transactions
+	TraceJumpDest           bool          // Print transaction hashes where jumpdest analysis was useful
+	NoReceipts              bool          // Do not calculate receipts
+	ReadOnly                bool          // Do no perform any block finalisation
 
 	EWASMInterpreter string // External EWASM interpreter options
 	EVMInterpreter   string // External EVM interpreter options
@@ -191,6 +192,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		stack.ReturnRStack(returns)
 	}()
 	contract.Input = input
+	contract.jumpDestCache = in.cfg.JumpDestCache
 
 	if in.cfg.Debug {
 		defer func() {
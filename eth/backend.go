@@ -55,6 +55,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/eth/downloader"
 	"github.com/ledgerwatch/turbo-geth/eth/filters"
 	"github.com/ledgerwatch/turbo-geth/eth/gasprice"
+	"github.com/ledgerwatch/turbo-geth/eth/snap"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/ethdb/remote/remotedbserver"
@@ -105,6 +106,7 @@ type Ethereum struct {
 	txPoolStarted bool
 
 	torrentClient *bittorrent.Client
+	snapSyncer    *snap.Syncer
 
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
@@ -294,6 +296,12 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		p2pServer:      stack.Server(),
 		torrentClient:  torrentClient,
 	}
+	// Nodes seeding the snapshots or keeping the history are dialed preferentially, the seeders are also given to the torrent client
+	var seedsSnapshots func(*enode.Node) bool
+	if config.SnapshotMode != (snapshotsync.SnapshotMode{}) {
+		seedsSnapshots = snapshotsync.SeedsSnapshots(config.SnapshotMode)
+	}
+	eth.p2pServer.PreferNode = anyNode(seedsSnapshots, eth.historyPeers())
 
 	log.Info("Initialising Ethereum protocol", "versions", ProtocolVersions, "network", config.NetworkID)
 
@@ -375,6 +383,12 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			stagedSync.Notifier = remoteEvents
 		}
 	}
+	if config.SnapSync {
+		eth.snapSyncer = snap.NewSyncer()
+		if stagedSync.StateSyncer == nil {
+			stagedSync.StateSyncer = eth.snapSyncer
+		}
+	}
 
 	if stack.Config().PrivateApiAddr != "" {
 		if stack.Config().TLSConnection {
@@ -538,8 +552,13 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 		log.Warn("Ethash used in shared mode")
 		return ethash.NewShared()
 	default:
+		// The caches are persisted in the data directory, kept in memory only if there is none
+		cacheDir, _ := stack.ResolvePath(config.CacheDir)
 		engine := ethash.New(ethash.Config{
+			CacheDir:         cacheDir,
 			CachesInMem:      config.CachesInMem,
+			CachesOnDisk:     config.CachesOnDisk,
+			CachesAhead:      config.CachesAhead,
 			CachesLockMmap:   config.CachesLockMmap,
 			DatasetDir:       config.DatasetDir,
 			DatasetsInMem:    config.DatasetsInMem,
@@ -794,7 +813,7 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	protos := make([]p2p.Protocol, len(ProtocolVersions))
 	for i, vsn := range ProtocolVersions {
 		protos[i] = s.protocolManager.makeProtocol(vsn)
-		protos[i].Attributes = []enr.Entry{s.currentEthEntry()}
+		protos[i].Attributes = s.enrEntries()
 		protos[i].DialCandidates = s.dialCandidates
 	}
 
@@ -803,13 +822,36 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 		protos = append(protos, s.protocolManager.makeDebugProtocol())
 	}
 
+	if s.config.EnableSnapProtocol || s.snapSyncer != nil {
+		var server *snap.Server
+		if s.config.EnableSnapProtocol {
+			server = snap.NewServer(s.chainDb)
+		}
+		protos = append(protos, snap.MakeProtocols(server, s.snapSyncer)...)
+	}
+
 	return protos
 }
 
+// enrEntries returns the ENR entries advertising the eth protocol, the data kept by the node
+// and the snapshots it seeds
+func (s *Ethereum) enrEntries() []enr.Entry {
+	entries := []enr.Entry{s.currentEthEntry(), s.currentStorageEntry()}
+	if s.torrentClient != nil && s.config.SnapshotSeeding {
+		entries = append(entries, snapshotsync.NewSnapshotsEntry(s.config.SnapshotMode, bittorrent.SnapshotBlock, s.torrentClient.Cli.LocalPort()))
+	}
+	return entries
+}
+
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
 	s.startEthEntryUpdate(s.p2pServer.LocalNode())
+	if s.torrentClient != nil {
+		if seeders := s.p2pServer.PreferredNodes(); seeders != nil {
+			go s.torrentClient.AddPeersFromNodes(seeders, s.config.NetworkID)
+		}
+	}
 
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
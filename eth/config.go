@@ -56,7 +56,10 @@ var DefaultLightGPOConfig = gasprice.Config{
 var DefaultConfig = Config{
 	SyncMode: downloader.StagedSync,
 	Ethash: ethash.Config{
+		CacheDir:         "ethash",
 		CachesInMem:      2,
+		CachesOnDisk:     3,
+		CachesAhead:      1,
 		CachesLockMmap:   false,
 		DatasetsInMem:    1,
 		DatasetsOnDisk:   2,
@@ -191,6 +194,12 @@ type Config struct {
 	// Enables the dbg protocol
 	EnableDebugProtocol bool
 
+	// Enables serving the state snapshots via snap protocol
+	EnableSnapProtocol bool
+
+	// Enables downloading the state of a recent block via snap protocol instead of executing all the blocks
+	SnapSync bool
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -19,6 +19,9 @@ package eth
 import (
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/forkid"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/p2p"
 	"github.com/ledgerwatch/turbo-geth/p2p/dnsdisc"
 	"github.com/ledgerwatch/turbo-geth/p2p/enode"
@@ -39,6 +42,101 @@ func (e ethEntry) ENRKey() string {
 	return "eth"
 }
 
+// storageEntry is the "tgstore" ENR entry which advertises the data the node keeps,
+// so the peers needing the history can find the nodes serving it.
+type storageEntry struct {
+	Mode          string // Storage mode, in the format of ethdb.StorageMode.ToString
+	HistoryFrom   uint64 // First block with the history, non-zero if the state was downloaded by snap sync
+	PruneDistance uint64 // Number of the recent blocks the history is kept for, 0 for the archive nodes
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e storageEntry) ENRKey() string {
+	return "tgstore"
+}
+
+func (eth *Ethereum) currentStorageEntry() *storageEntry {
+	entry := &storageEntry{Mode: eth.config.StorageMode.ToString()}
+	if !eth.ArchiveMode() {
+		entry.PruneDistance = eth.config.BlocksBeforePruning
+	}
+	pivot, err := stages.GetStageProgress(eth.chainDb, stages.StateSync)
+	if err != nil {
+		log.Warn("Could not read the block the state was synced at", "error", err)
+	}
+	entry.HistoryFrom = pivot
+	return entry
+}
+
+// ServesHistory returns the filter of the discovered nodes, passing the ones which keep all the data
+// of the given storage mode starting from the given block, at the given head of the chain
+func ServesHistory(mode ethdb.StorageMode, from uint64, head uint64) func(*enode.Node) bool {
+	return func(n *enode.Node) bool {
+		var entry storageEntry
+		if n.Load(&entry) != nil {
+			return false
+		}
+		kept, err := ethdb.StorageModeFromString(entry.Mode)
+		if err != nil {
+			return false
+		}
+		if (mode.History && !kept.History) || (mode.Receipts && !kept.Receipts) ||
+			(mode.TxIndex && !kept.TxIndex) || (mode.CallTraces && !kept.CallTraces) {
+			return false
+		}
+		if entry.HistoryFrom > from {
+			return false
+		}
+		return entry.PruneDistance == 0 || head < entry.PruneDistance || head-entry.PruneDistance <= from
+	}
+}
+
+// historyPeers returns the filter of the discovered nodes keeping the history this node keeps itself, for the blocks
+// within its pruning horizon, so that the peers able to serve the same data are dialed preferentially.
+// Nil is returned if the node keeps no history.
+func (eth *Ethereum) historyPeers() func(*enode.Node) bool {
+	mode := eth.config.StorageMode
+	if !mode.History && !mode.Receipts && !mode.TxIndex && !mode.CallTraces {
+		return nil
+	}
+	return func(n *enode.Node) bool {
+		head, err := stages.GetStageProgress(eth.chainDb, stages.Headers)
+		if err != nil {
+			return false
+		}
+		var from uint64
+		if !eth.ArchiveMode() && head > eth.config.BlocksBeforePruning {
+			from = head - eth.config.BlocksBeforePruning
+		}
+		return ServesHistory(mode, from, head)(n)
+	}
+}
+
+// anyNode combines the filters of the discovered nodes, passing the nodes passed by any of them.
+// The nil filters are skipped, nil is returned if there are none left.
+func anyNode(filters ...func(*enode.Node) bool) func(*enode.Node) bool {
+	var set []func(*enode.Node) bool
+	for _, f := range filters {
+		if f != nil {
+			set = append(set, f)
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return func(n *enode.Node) bool {
+		for _, f := range set {
+			if f(n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // startEthEntryUpdate starts the ENR updater loop.
 func (eth *Ethereum) startEthEntryUpdate(ln *enode.LocalNode) {
 	var newHead = make(chan core.ChainHeadEvent, 10)
@@ -50,6 +148,7 @@ func (eth *Ethereum) startEthEntryUpdate(ln *enode.LocalNode) {
 			select {
 			case <-newHead:
 				ln.Set(eth.currentEthEntry())
+				ln.Set(eth.currentStorageEntry())
 			case <-sub.Err():
 				// Would be nice to sync with eth.Stop, but there is no
 				// good way to do that.
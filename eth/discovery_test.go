@@ -0,0 +1,74 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/p2p/enr"
+)
+
+func TestServesHistory(t *testing.T) {
+	node := func(entry *storageEntry) *enode.Node {
+		var r enr.Record
+		if entry != nil {
+			r.Set(entry)
+		}
+		return enode.SignNull(&r, enode.ID{})
+	}
+	archive := node(&storageEntry{Mode: "hrtc"})
+	snapSynced := node(&storageEntry{Mode: "hrt", HistoryFrom: 1000})
+	pruned := node(&storageEntry{Mode: "hr", PruneDistance: 100})
+	unknown := node(nil)
+
+	full := ethdb.StorageMode{History: true, Receipts: true}
+	tests := []struct {
+		name       string
+		mode       ethdb.StorageMode
+		from, head uint64
+		want       []bool // archive, snap synced, pruned, unknown
+	}{
+		{"from genesis", full, 0, 10000, []bool{true, false, false, false}},
+		{"after the snap sync", full, 2000, 10000, []bool{true, true, false, false}},
+		{"recent blocks", full, 9950, 10000, []bool{true, true, true, false}},
+		{"call traces", ethdb.StorageMode{CallTraces: true}, 9950, 10000, []bool{true, false, false, false}},
+	}
+	for _, tt := range tests {
+		filter := ServesHistory(tt.mode, tt.from, tt.head)
+		for i, n := range []*enode.Node{archive, snapSynced, pruned, unknown} {
+			if got := filter(n); got != tt.want[i] {
+				t.Errorf("%s: node %d: got %t, want %t", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHistoryPeers(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	if err := stages.SaveStageProgress(db, stages.Headers, 10000); err != nil {
+		t.Fatal(err)
+	}
+	node := func(entry *storageEntry) *enode.Node {
+		var r enr.Record
+		r.Set(entry)
+		return enode.SignNull(&r, enode.ID{})
+	}
+	archive := node(&storageEntry{Mode: "hrtc"})
+	pruned := node(&storageEntry{Mode: "hr", PruneDistance: 100})
+
+	eth := &Ethereum{chainDb: db, config: &Config{StorageMode: ethdb.StorageMode{History: true, Receipts: true}, Pruning: true, BlocksBeforePruning: 50}}
+	prefer := anyNode(nil, eth.historyPeers())
+	if !prefer(archive) || !prefer(pruned) {
+		t.Errorf("peers keeping the history of the last 50 blocks are not preferred")
+	}
+	eth.config.Pruning = false
+	if prefer = anyNode(eth.historyPeers()); !prefer(archive) || prefer(pruned) {
+		t.Errorf("only the archive peers are expected to be preferred by the archive node")
+	}
+	eth.config.StorageMode = ethdb.StorageMode{}
+	if anyNode(nil, eth.historyPeers()) != nil {
+		t.Errorf("peers preferred by the node keeping no history")
+	}
+}
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,6 +31,8 @@ import (
 	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/forkid"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/crypto"
 	"github.com/ledgerwatch/turbo-geth/eth/downloader"
@@ -524,6 +527,17 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	}
 	defer msg.Discard()
 
+	// Since eth/66 the requests and responses are prefixed with the request ID
+	var requestID uint64
+	if p.version >= eth66 && IsRequestOrResponse(msg.Code) {
+		var packet Packet66
+		if err := msg.Decode(&packet); err != nil {
+			return errResp(ErrDecode, "%v: %v", msg, err)
+		}
+		requestID = packet.RequestID
+		msg.Payload, msg.Size = bytes.NewReader(packet.Packet), uint32(len(packet.Packet))
+	}
+
 	// Handle the message depending on its contents
 	switch {
 	case msg.Code == StatusMsg:
@@ -616,7 +630,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				query.Origin.Number += query.Skip + 1
 			}
 		}
-		return p.SendBlockHeaders(headers)
+		return p.SendBlockHeaders(requestID, headers)
 
 	case msg.Code == BlockHeadersMsg:
 		// A batch of headers arrived to one of our previous requests
@@ -707,7 +721,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				bytes += len(data)
 			}
 		}
-		return p.SendBlockBodiesRLP(bodies)
+		return p.SendBlockBodiesRLP(requestID, bodies)
 
 	case msg.Code == BlockBodiesMsg:
 		// A batch of block bodies arrived to one of our previous requests
@@ -742,16 +756,14 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			return err
 		}
 
-		// Obtain the TrieDbState
-		if pm.mode == downloader.StagedSync {
-			return fmt.Errorf("staged sync mode, no support for GetNodeData")
-		}
-		tds, err := pm.blockchain.GetTrieDbState()
+		// Obtain the TrieDbState. There are no trie nodes in the staged sync and download-only modes,
+		// so the request is answered with the empty response, meaning that none of the data is available
+		tds, err := pm.trieDbState()
 		if err != nil {
 			return err
 		}
 		if tds == nil {
-			return fmt.Errorf("download-only mode, no support for GetNodeData")
+			return p.SendNodeData(requestID, nil)
 		}
 
 		// Gather state data until the fetch or network limits is reached
@@ -785,7 +797,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				data = append(data, nil)
 			}
 		}
-		return p.SendNodeData(data)
+		return p.SendNodeData(requestID, data)
 
 	case p.version >= eth64 && msg.Code == GetReceiptsMsg:
 		// Decode the retrieval message
@@ -807,21 +819,18 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				return errResp(ErrDecode, "msg %v: %v", msg, err)
 			}
 			// Retrieve the requested block's receipts, skipping if unknown to us
-			results := pm.blockchain.GetReceiptsByHash(hash)
-			if results == nil {
-				if header := pm.blockchain.GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
-					continue
-				}
+			encoded, err := rawdb.ReadCanonicalReceiptsRLP(pm.chaindb, hash)
+			if err != nil {
+				log.Error("Failed to encode receipts", "hash", hash, "err", err)
+				continue
 			}
-			// If known, encode and queue for response packet
-			if encoded, err := rlp.EncodeToBytes(results); err != nil {
-				log.Error("Failed to encode receipt", "err", err)
-			} else {
-				receipts = append(receipts, encoded)
-				bytes += len(encoded)
+			if encoded == nil {
+				continue
 			}
+			receipts = append(receipts, encoded)
+			bytes += len(encoded)
 		}
-		return p.SendReceiptsRLP(receipts)
+		return p.SendReceiptsRLP(requestID, receipts)
 
 	case p.version >= eth64 && msg.Code == ReceiptsMsg:
 		// A batch of receipts arrived to one of our previous requests
@@ -954,7 +963,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				bytes += len(encoded)
 			}
 		}
-		return p.SendPooledTransactionsRLP(hashes, txs)
+		return p.SendPooledTransactionsRLP(requestID, hashes, txs)
 
 	case msg.Code == TransactionMsg || (msg.Code == PooledTransactionsMsg && p.version >= eth65):
 		if pm.txFetcher == nil {
@@ -1155,6 +1164,19 @@ func (pm *ProtocolManager) txsSubErr() <-chan error {
 	return pm.txsSub.Err()
 }
 
+// servesNodeData reports whether there are trie nodes to answer GetNodeData with
+func (pm *ProtocolManager) servesNodeData() bool {
+	return pm.mode != downloader.StagedSync && !pm.blockchain.DownloadOnly()
+}
+
+// trieDbState returns the state to serve GetNodeData from, or nil if the trie nodes are not available
+func (pm *ProtocolManager) trieDbState() (*state.TrieDbState, error) {
+	if !pm.servesNodeData() {
+		return nil, nil
+	}
+	return pm.blockchain.GetTrieDbState()
+}
+
 // NodeInfo represents a short summary of the Ethereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -1163,6 +1185,7 @@ type NodeInfo struct {
 	Genesis    common.Hash         `json:"genesis"`    // SHA3 hash of the host's genesis block
 	Config     *params.ChainConfig `json:"config"`     // Chain configuration for the fork rules
 	Head       common.Hash         `json:"head"`       // SHA3 hash of the host's best owned block
+	NodeData   bool                `json:"nodeData"`   // Whether the trie nodes are served in response to GetNodeData
 }
 
 // NodeInfo retrieves some protocol metadata about the running host node.
@@ -1174,5 +1197,6 @@ func (pm *ProtocolManager) NodeInfo() *NodeInfo {
 		Genesis:    pm.blockchain.Genesis().Hash(),
 		Config:     pm.chainConfig,
 		Head:       currentBlock.Hash(),
+		NodeData:   pm.servesNodeData(),
 	}
 }
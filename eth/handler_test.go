@@ -49,6 +49,7 @@ import (
 // Tests that block headers can be retrieved from a remote chain based on user queries.
 func TestGetBlockHeaders64(t *testing.T) { testGetBlockHeaders(t, 64) }
 func TestGetBlockHeaders65(t *testing.T) { testGetBlockHeaders(t, 65) }
+func TestGetBlockHeaders66(t *testing.T) { testGetBlockHeaders(t, 66) }
 
 func testGetBlockHeaders(t *testing.T, protocol int) {
 	pm, clear := newTestProtocolManagerMust(t, downloader.StagedSync, downloader.MaxHashFetch+15, nil, nil)
@@ -191,10 +192,10 @@ func testGetBlockHeaders(t *testing.T, protocol int) {
 				headers = append(headers, pm.blockchain.GetBlockByHash(hash).Header())
 			}
 			// Send the hash request and verify the response
-			if err := p2p.Send(peer.app, 0x03, tt.query); err != nil {
+			if err := peer.sendPacket(0x03, uint64(i), tt.query); err != nil {
 				t.Error(err)
 			}
-			if err := p2p.ExpectMsg(peer.app, 0x04, headers); err != nil {
+			if err := peer.expectPacket(0x04, uint64(i), headers); err != nil {
 				t.Errorf("test %d: headers mismatch: %v", i, err)
 			}
 			// If the test used number origins, repeat with hashes as the too
@@ -202,11 +203,11 @@ func testGetBlockHeaders(t *testing.T, protocol int) {
 				if origin := pm.blockchain.GetBlockByNumber(tt.query.Origin.Number); origin != nil {
 					tt.query.Origin.Hash, tt.query.Origin.Number = origin.Hash(), 0
 
-					if err := p2p.Send(peer.app, 0x03, tt.query); err != nil {
+					if err := peer.sendPacket(0x03, uint64(i), tt.query); err != nil {
 						t.Error(err)
 					}
 
-					if err := p2p.ExpectMsg(peer.app, 0x04, headers); err != nil {
+					if err := peer.expectPacket(0x04, uint64(i), headers); err != nil {
 						t.Errorf("test %d: headers mismatch: %v", i, err)
 					}
 				}
@@ -219,6 +220,7 @@ func testGetBlockHeaders(t *testing.T, protocol int) {
 // Tests that block contents can be retrieved from a remote chain based on their hashes.
 func TestGetBlockBodies64(t *testing.T) { testGetBlockBodies(t, 64) }
 func TestGetBlockBodies65(t *testing.T) { testGetBlockBodies(t, 65) }
+func TestGetBlockBodies66(t *testing.T) { testGetBlockBodies(t, 66) }
 
 func testGetBlockBodies(t *testing.T, protocol int) {
 	pm, clear := newTestProtocolManagerMust(t, downloader.StagedSync, downloader.MaxBlockFetch+15, nil, nil)
@@ -282,8 +284,8 @@ func testGetBlockBodies(t *testing.T, protocol int) {
 			}
 		}
 		// Send the hash request and verify the response
-		p2p.Send(peer.app, 0x05, hashes)
-		if err := p2p.ExpectMsg(peer.app, 0x06, bodies); err != nil {
+		peer.sendPacket(0x05, uint64(i), hashes) //nolint:errcheck
+		if err := peer.expectPacket(0x06, uint64(i), bodies); err != nil {
 			t.Errorf("test %d: bodies mismatch: %v", i, err)
 		}
 	}
@@ -348,6 +350,7 @@ func testGetNodeData(t *testing.T, protocol int) {
 // Tests that the transaction receipts can be retrieved based on hashes.
 func TestGetReceipt64(t *testing.T) { testGetReceipt(t, 64) }
 func TestGetReceipt65(t *testing.T) { testGetReceipt(t, 65) }
+func TestGetReceipt66(t *testing.T) { testGetReceipt(t, 66) }
 
 func testGetReceipt(t *testing.T, protocol int) {
 	// Define two accounts to simulate transactions with
@@ -400,12 +403,77 @@ func testGetReceipt(t *testing.T, protocol int) {
 		receipts = append(receipts, pm.blockchain.GetReceiptsByHash(block.Hash()))
 	}
 	// Send the hash request and verify the response
-	p2p.Send(peer.app, 0x0f, hashes)
-	if err := p2p.ExpectMsg(peer.app, 0x10, receipts); err != nil {
+	peer.sendPacket(0x0f, 42, hashes) //nolint:errcheck
+	if err := peer.expectPacket(0x10, 42, receipts); err != nil {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
 
+// Tests that the served receipts carry the blooms and match the receipt roots of the headers,
+// and that the blocks not on the canonical chain are skipped.
+func TestGetReceiptRoots(t *testing.T) {
+	generator := func(i int, block *core.BlockGen) {
+		// Contract creation with the init code emitting a log: PUSH1 0 PUSH1 0 LOG0
+		tx, _ := types.SignTx(types.NewContractCreation(block.TxNonce(testBank), uint256.NewInt(), 100000, nil, common.FromHex("0x60006000a0")), types.HomesteadSigner{}, testBankKey)
+		block.AddTx(tx)
+	}
+	pm, clear := newTestProtocolManagerMust(t, downloader.StagedSync, 2, generator, nil)
+	defer clear()
+	peer, _ := newTestPeer("peer", 65, pm, true)
+	defer peer.close()
+
+	hashes := []common.Hash{common.HexToHash("0xdeadbeef")}
+	var headers []*types.Header
+	for i := uint64(0); i <= pm.blockchain.CurrentBlock().NumberU64(); i++ {
+		header := pm.blockchain.GetHeaderByNumber(i)
+		hashes = append(hashes, header.Hash())
+		headers = append(headers, header)
+	}
+	if err := p2p.Send(peer.app, GetReceiptsMsg, hashes); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := peer.app.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Code != ReceiptsMsg {
+		t.Fatalf("unexpected message code %d", msg.Code)
+	}
+	var receipts []types.Receipts
+	if err := msg.Decode(&receipts); err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != len(headers) {
+		t.Fatalf("expected receipts of %d blocks, got %d", len(headers), len(receipts))
+	}
+	for i, header := range headers {
+		if root := types.DeriveSha(receipts[i]); root != header.ReceiptHash {
+			t.Errorf("block %d: receipt root mismatch: have %x, want %x", i, root, header.ReceiptHash)
+		}
+		if i > 0 && (len(receipts[i]) != 1 || receipts[i][0].Bloom == (types.Bloom{})) {
+			t.Errorf("block %d: expected one receipt with non-empty bloom", i)
+		}
+	}
+}
+
+// Tests that without the trie nodes, GetNodeData is answered with the empty response instead of disconnecting the peer.
+func TestGetNodeDataStaged(t *testing.T) {
+	pm, clear := newTestProtocolManagerMust(t, downloader.StagedSync, 1, nil, nil)
+	defer clear()
+	peer, _ := newTestPeer("peer", 66, pm, true)
+	defer peer.close()
+
+	if pm.NodeInfo().NodeData {
+		t.Errorf("expected node info to advertise that node data is not served")
+	}
+	if err := peer.sendPacket(GetNodeDataMsg, 7, []common.Hash{pm.blockchain.CurrentBlock().Root()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := peer.expectPacket(NodeDataMsg, 7, [][]byte{}); err != nil {
+		t.Errorf("node data mismatch: %v", err)
+	}
+}
+
 // Tests that post eth protocol handshake, clients perform a mutual checkpoint
 // challenge to validate each other's chains. Hash mismatches, or missing ones
 // during a fast sync should lead to the peer getting dropped.
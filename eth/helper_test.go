@@ -232,17 +232,33 @@ func newTestPeer(name string, version int, pm *ProtocolManager, shake bool) (*te
 		tp.handshake(nil, td, head.Hash(), genesis.Hash(), forkID, forkid.NewFilter(pm.blockchain.Config(), genesis.Hash(), head.Number.Uint64()))
 
 		// Newly connected peer will query the header that was announced during the handshake
-		if err := p2p.ExpectMsg(tp.app, 0x03, &GetBlockHeadersData{Origin: HashOrNumber{Hash: pm.blockchain.CurrentBlock().Hash()}, Amount: 1}); err != nil {
+		if err := tp.expectPacket(0x03, 1, &GetBlockHeadersData{Origin: HashOrNumber{Hash: pm.blockchain.CurrentBlock().Hash()}, Amount: 1}); err != nil {
 			fmt.Printf("ExpectMsg error: %v\n", err)
 			panic(err)
 		}
-		if err := p2p.Send(tp.app, 0x04, []*types.Header{pm.blockchain.CurrentBlock().Header()}); err != nil {
+		if err := tp.sendPacket(0x04, 1, []*types.Header{pm.blockchain.CurrentBlock().Header()}); err != nil {
 			panic(err)
 		}
 	}
 	return tp, errc
 }
 
+// sendPacket sends a packet to the protocol manager, prefixed with the request ID since eth/66.
+func (p *testPeer) sendPacket(msgcode uint64, requestID uint64, data interface{}) error {
+	if p.version >= eth66 && IsRequestOrResponse(msgcode) {
+		return p2p.Send(p.app, msgcode, []interface{}{requestID, data})
+	}
+	return p2p.Send(p.app, msgcode, data)
+}
+
+// expectPacket reads a packet from the protocol manager, expecting it to be prefixed with the request ID since eth/66.
+func (p *testPeer) expectPacket(msgcode uint64, requestID uint64, data interface{}) error {
+	if p.version >= eth66 && IsRequestOrResponse(msgcode) {
+		return p2p.ExpectMsg(p.app, msgcode, []interface{}{requestID, data})
+	}
+	return p2p.ExpectMsg(p.app, msgcode, data)
+}
+
 func newFirehoseTestPeer(name string, pm *ProtocolManager) (*testFirehosePeer, <-chan error) {
 	// Create a message pipe to communicate through
 	app, net := p2p.MsgPipe()
@@ -33,16 +33,24 @@ import (
 const (
 	eth64 = 64
 	eth65 = 65
+	eth66 = 66
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 const ProtocolName = "eth"
 
 // ProtocolVersions are the supported versions of the eth protocol (first is primary).
-var ProtocolVersions = []uint{eth65, eth64}
+var ProtocolVersions = []uint{eth66, eth65, eth64}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{eth65: 17, eth64: 17}
+var ProtocolLengths = map[uint]uint64{eth66: 17, eth65: 17, eth64: 17}
+
+// Protocol versions for the code speaking the eth protocol outside of the ProtocolManager
+const (
+	ETH64 = eth64
+	ETH65 = eth65
+	ETH66 = eth66
+)
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -71,6 +79,38 @@ const (
 	PooledTransactionsMsg         = 0x0a
 )
 
+// IsRequestOrResponse reports whether the packets of the message are prefixed with the request ID under eth/66
+func IsRequestOrResponse(msgCode uint64) bool {
+	switch msgCode {
+	case GetBlockHeadersMsg, BlockHeadersMsg, GetBlockBodiesMsg, BlockBodiesMsg,
+		GetNodeDataMsg, NodeDataMsg, GetReceiptsMsg, ReceiptsMsg,
+		GetPooledTransactionsMsg, PooledTransactionsMsg:
+		return true
+	}
+	return false
+}
+
+// Packet66 is the envelope of the request and response packets under eth/66 and later.
+// Responses carry the ID of the request they answer.
+type Packet66 struct {
+	RequestID uint64
+	Packet    rlp.RawValue
+}
+
+// EncodePacket66 wraps the RLP encoded packet into the eth/66 envelope
+func EncodePacket66(requestID uint64, packet []byte) ([]byte, error) {
+	return rlp.EncodeToBytes(&Packet66{RequestID: requestID, Packet: packet})
+}
+
+// DecodePacket66 unwraps the eth/66 envelope, returning the request ID and the RLP encoded packet
+func DecodePacket66(data []byte) (uint64, []byte, error) {
+	var packet Packet66
+	if err := rlp.DecodeBytes(data, &packet); err != nil {
+		return 0, nil, err
+	}
+	return packet.RequestID, packet.Packet, nil
+}
+
 type errCode int
 
 const (
@@ -0,0 +1,233 @@
+package snap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/p2p"
+)
+
+// MakeProtocols constructs the P2P protocol definitions for `snap` and its companion plain keys protocol.
+// Requests are answered with the given server, or with empty responses if it is nil. Responses are
+// delivered to the given syncer, if it is nil, the responses are considered unsolicited.
+func MakeProtocols(server *Server, syncer *Syncer) []p2p.Protocol {
+	protocols := make([]p2p.Protocol, 0, len(ProtocolVersions)+len(PlainProtocolVersions))
+	for _, version := range ProtocolVersions {
+		version := version // Closure
+
+		protocols = append(protocols, p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return handle(server, syncer, newPeer(version, p, rw))
+			},
+		})
+	}
+	for _, version := range PlainProtocolVersions {
+		version := version // Closure
+
+		protocols = append(protocols, p2p.Protocol{
+			Name:    PlainProtocolName,
+			Version: version,
+			Length:  plainProtocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return handlePlain(server, syncer, newPeer(version, p, rw))
+			},
+		})
+	}
+	return protocols
+}
+
+// handle is the callback invoked to manage the life cycle of a `snap` peer.
+// When this function terminates, the peer is disconnected.
+func handle(server *Server, syncer *Syncer, peer *Peer) error {
+	peer.Log().Debug("Snapshot peer connected", "name", peer.Name())
+	defer peer.Log().Debug("Snapshot peer disconnected")
+	if syncer != nil {
+		syncer.register(syncer.peers, peer)
+		defer syncer.unregister(syncer.peers, peer)
+	}
+	for {
+		if err := handleMessage(server, syncer, peer); err != nil {
+			peer.Log().Debug("Message handling failed in `snap`", "err", err)
+			return err
+		}
+	}
+}
+
+// handleMessage is invoked whenever an inbound message is received from a
+// remote peer on the `snap` protocol. The remote connection is torn down upon
+// returning any error.
+func handleMessage(server *Server, syncer *Syncer, peer *Peer) error {
+	// Read the next message from the remote peer, and ensure it's fully consumed
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+	ctx := context.Background()
+
+	// Handle the message depending on its contents. Failures to read the database
+	// are not the fault of the peer, so the empty responses are sent in such cases
+	switch msg.Code {
+	case GetAccountRangeMsg:
+		var req GetAccountRangePacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		resp := &AccountRangePacket{ID: req.ID}
+		if server != nil {
+			if resp, err = server.AccountRange(ctx, &req); err != nil {
+				peer.Log().Warn("Failed to serve account range", "err", err)
+			}
+		}
+		return p2p.Send(peer.rw, AccountRangeMsg, resp)
+
+	case GetStorageRangesMsg:
+		var req GetStorageRangesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		resp := &StorageRangesPacket{ID: req.ID}
+		if server != nil {
+			if resp, err = server.StorageRanges(ctx, &req); err != nil {
+				peer.Log().Warn("Failed to serve storage ranges", "err", err)
+			}
+		}
+		return p2p.Send(peer.rw, StorageRangesMsg, resp)
+
+	case GetByteCodesMsg:
+		var req GetByteCodesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		resp := &ByteCodesPacket{ID: req.ID}
+		if server != nil {
+			if resp, err = server.ByteCodes(ctx, &req); err != nil {
+				peer.Log().Warn("Failed to serve byte codes", "err", err)
+			}
+		}
+		return p2p.Send(peer.rw, ByteCodesMsg, resp)
+
+	case GetTrieNodesMsg:
+		var req GetTrieNodesPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		resp := &TrieNodesPacket{ID: req.ID}
+		if server != nil {
+			if resp, err = server.TrieNodes(ctx, &req); err != nil {
+				if errors.Is(err, errBadRequest) {
+					return err
+				}
+				peer.Log().Warn("Failed to serve trie nodes", "err", err)
+			}
+		}
+		return p2p.Send(peer.rw, TrieNodesMsg, resp)
+
+	case AccountRangeMsg:
+		res := new(AccountRangePacket)
+		if err := decodeResponse(syncer, msg, res); err != nil {
+			return err
+		}
+		syncer.deliver(peer, res.ID, res)
+		return nil
+
+	case StorageRangesMsg:
+		res := new(StorageRangesPacket)
+		if err := decodeResponse(syncer, msg, res); err != nil {
+			return err
+		}
+		syncer.deliver(peer, res.ID, res)
+		return nil
+
+	case ByteCodesMsg:
+		res := new(ByteCodesPacket)
+		if err := decodeResponse(syncer, msg, res); err != nil {
+			return err
+		}
+		syncer.deliver(peer, res.ID, res)
+		return nil
+
+	case TrieNodesMsg:
+		res := new(TrieNodesPacket)
+		if err := decodeResponse(syncer, msg, res); err != nil {
+			return err
+		}
+		syncer.deliver(peer, res.ID, res)
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+// handlePlain is the callback invoked to manage the life cycle of a peer of the plain keys protocol.
+func handlePlain(server *Server, syncer *Syncer, peer *Peer) error {
+	if syncer != nil {
+		syncer.register(syncer.plainPeers, peer)
+		defer syncer.unregister(syncer.plainPeers, peer)
+	}
+	for {
+		if err := handlePlainMessage(server, syncer, peer); err != nil {
+			peer.Log().Debug("Message handling failed in `tgsnap`", "err", err)
+			return err
+		}
+	}
+}
+
+// handlePlainMessage is invoked whenever an inbound message is received from a
+// remote peer on the plain keys protocol.
+func handlePlainMessage(server *Server, syncer *Syncer, peer *Peer) error {
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+
+	switch msg.Code {
+	case GetPlainKeysMsg:
+		var req GetPlainKeysPacket
+		if err := msg.Decode(&req); err != nil {
+			return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+		}
+		resp := &PlainKeysPacket{ID: req.ID}
+		if server != nil {
+			if resp, err = server.PlainKeys(context.Background(), &req); err != nil {
+				peer.Log().Warn("Failed to serve plain keys", "err", err)
+			}
+		}
+		return p2p.Send(peer.rw, PlainKeysMsg, resp)
+
+	case PlainKeysMsg:
+		res := new(PlainKeysPacket)
+		if err := decodeResponse(syncer, msg, res); err != nil {
+			return err
+		}
+		syncer.deliver(peer, res.ID, res)
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+// decodeResponse decodes the response message, which can be accepted only if the syncer is set
+func decodeResponse(syncer *Syncer, msg p2p.Msg, res interface{}) error {
+	if syncer == nil {
+		// This node does not send the requests, so any response is unsolicited
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+	if err := msg.Decode(res); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	return nil
+}
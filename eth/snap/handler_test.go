@@ -0,0 +1,356 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/node"
+	"github.com/ledgerwatch/turbo-geth/p2p"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/p2p/simulations"
+	"github.com/ledgerwatch/turbo-geth/p2p/simulations/adapters"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+// testState describes the state generated for the tests
+type testState struct {
+	root      common.Hash
+	accounts  map[common.Hash]*accounts.Account
+	contracts []common.Hash // Hashes of the contract addresses, sorted
+	storage   map[common.Hash][]common.Hash
+	codes     map[common.Hash][]byte
+}
+
+// newTestState fills the hashed and the plain state and the intermediate hashes, and makes the state
+// the current one by writing the header and the stage progresses
+func newTestState(t *testing.T, db ethdb.Database, accountCount, slotCount int) *testState {
+	s := &testState{
+		accounts: make(map[common.Hash]*accounts.Account),
+		storage:  make(map[common.Hash][]common.Hash),
+		codes:    make(map[common.Hash][]byte),
+	}
+	for i := 0; i < accountCount; i++ {
+		var addr common.Address
+		binary.BigEndian.PutUint64(addr[12:], uint64(i))
+		addrHash := crypto.Keccak256Hash(addr[:])
+		a := accounts.NewAccount()
+		a.Nonce = uint64(i)
+		a.Balance.SetUint64(uint64(i) * 1000)
+		if i%10 == 0 {
+			code := []byte{0x60, byte(i), 0x60, 0x00, 0x55}
+			a.Incarnation = 1
+			a.CodeHash = crypto.Keccak256Hash(code)
+			s.codes[a.CodeHash] = code
+			require.NoError(t, db.Put(dbutils.CodeBucket, a.CodeHash[:], code))
+			require.NoError(t, db.Put(dbutils.ContractCodeBucket, dbutils.GenerateStoragePrefix(addrHash[:], a.Incarnation), a.CodeHash[:]))
+			require.NoError(t, db.Put(dbutils.PlainContractCodeBucket, dbutils.PlainGenerateStoragePrefix(addr[:], a.Incarnation), a.CodeHash[:]))
+			for j := 0; j < slotCount; j++ {
+				var loc common.Hash
+				binary.BigEndian.PutUint64(loc[24:], uint64(j))
+				locHash := crypto.Keccak256Hash(loc[:])
+				s.storage[addrHash] = append(s.storage[addrHash], locHash)
+				v := uint256.NewInt().SetUint64(uint64(i*1000 + j + 1))
+				require.NoError(t, db.Put(dbutils.CurrentStateBucket, dbutils.GenerateCompositeStorageKey(addrHash, a.Incarnation, locHash), v.Bytes()))
+				require.NoError(t, db.Put(dbutils.PlainStateBucket, dbutils.PlainGenerateCompositeStorageKey(addr[:], a.Incarnation, loc[:]), v.Bytes()))
+			}
+			s.contracts = append(s.contracts, addrHash)
+		}
+		v := make([]byte, a.EncodingLengthForStorage())
+		a.EncodeForStorage(v)
+		require.NoError(t, db.Put(dbutils.CurrentStateBucket, addrHash[:], v))
+		require.NoError(t, db.Put(dbutils.PlainStateBucket, addr[:], v))
+		s.accounts[addrHash] = &a
+	}
+	for _, slots := range s.storage {
+		sortHashes(slots)
+	}
+	sortHashes(s.contracts)
+
+	var ihs [][2][]byte
+	hc := func(keyHex []byte, hash []byte) error {
+		if len(keyHex) == 0 {
+			return nil
+		}
+		if len(keyHex) > trie.IHDupKeyLen {
+			ihs = append(ihs, [2][]byte{common.CopyBytes(keyHex[:trie.IHDupKeyLen]), append(common.CopyBytes(keyHex[trie.IHDupKeyLen:]), hash...)})
+			return nil
+		}
+		ihs = append(ihs, [2][]byte{common.CopyBytes(keyHex), common.CopyBytes(hash)})
+		return nil
+	}
+	loader := trie.NewFlatDBTrieLoader("test", dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
+	require.NoError(t, loader.Reset(trie.NewRetainList(0), hc, false))
+	root, err := loader.CalcTrieRoot(db, nil)
+	require.NoError(t, err)
+	for _, ih := range ihs {
+		require.NoError(t, db.Put(dbutils.IntermediateTrieHashBucket, ih[0], ih[1]))
+	}
+	s.root = root
+
+	header := &types.Header{Number: big.NewInt(1), Root: root, Difficulty: big.NewInt(1)}
+	rawdb.WriteHeader(context.Background(), db, header)
+	require.NoError(t, rawdb.WriteCanonicalHash(db, header.Hash(), 1))
+	require.NoError(t, stages.SaveStageProgress(db, stages.Execution, 1))
+	require.NoError(t, stages.SaveStageProgress(db, stages.HashState, 1))
+	require.NoError(t, stages.SaveStageProgress(db, stages.IntermediateHashes, 1))
+	return s
+}
+
+func sortHashes(hashes []common.Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+}
+
+// testLifecycle is the no-op node lifecycle, the work is done by the protocols
+type testLifecycle struct{}
+
+func (testLifecycle) Start() error { return nil }
+func (testLifecycle) Stop() error  { return nil }
+
+// newTestNetwork starts the simulated network of two nodes: the one serving the `snap` protocol
+// from the given database and the one handing over its `snap` peers to the test
+func newTestNetwork(t *testing.T, db ethdb.Database) (*simulations.Network, *Peer) {
+	peers := make(chan *Peer, 1)
+	done := make(chan struct{})
+	adapter := adapters.NewSimAdapter(adapters.LifecycleConstructors{
+		"snap-server": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			stack.RegisterProtocols(MakeProtocols(NewServer(db), nil))
+			return testLifecycle{}, nil
+		},
+		"snap-client": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			stack.RegisterProtocols([]p2p.Protocol{{
+				Name:    ProtocolName,
+				Version: snap1,
+				Length:  protocolLengths[snap1],
+				Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+					peers <- newPeer(snap1, p, rw)
+					<-done
+					return nil
+				},
+			}})
+			return testLifecycle{}, nil
+		},
+	})
+	network := simulations.NewNetwork(adapter, &simulations.NetworkConfig{DefaultService: "snap-server"})
+	t.Cleanup(func() {
+		close(done)
+		network.Shutdown()
+	})
+
+	var ids []enode.ID
+	for _, service := range []string{"snap-server", "snap-client"} {
+		conf := adapters.RandomNodeConfig()
+		conf.Lifecycles = []string{service}
+		n, err := network.NewNodeWithConfig(conf)
+		require.NoError(t, err)
+		require.NoError(t, network.Start(n.ID()))
+		ids = append(ids, n.ID())
+	}
+	require.NoError(t, network.Connect(ids[1], ids[0]))
+	select {
+	case peer := <-peers:
+		return network, peer
+	case <-time.After(10 * time.Second):
+		t.Fatal("snap peer is not connected")
+	}
+	return nil, nil
+}
+
+// expectMsg reads the next message from the peer and decodes it
+func expectMsg(t *testing.T, peer *Peer, code uint64, packet interface{}) {
+	msg, err := peer.rw.ReadMsg()
+	require.NoError(t, err)
+	defer msg.Discard()
+	require.Equal(t, code, msg.Code)
+	require.NoError(t, msg.Decode(packet))
+}
+
+func TestAccountRange(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	state := newTestState(t, db, 1000, 20)
+	_, peer := newTestNetwork(t, db)
+
+	var origin common.Hash
+	var all []*AccountData
+	for i := 0; ; i++ {
+		require.NoError(t, peer.RequestAccountRange(uint64(i), state.root, origin, maxHash, 4096))
+		var resp AccountRangePacket
+		expectMsg(t, peer, AccountRangeMsg, &resp)
+		assert.Equal(t, uint64(i), resp.ID)
+		require.NotEmpty(t, resp.Accounts)
+		require.NotEmpty(t, resp.Proof)
+
+		// Both boundaries must be proven
+		val, err := trie.VerifyProof(state.root, origin[:], resp.Proof)
+		require.NoError(t, err)
+		if origin == (common.Hash{}) {
+			assert.Nil(t, val)
+		}
+		last := resp.Accounts[len(resp.Accounts)-1]
+		val, err = trie.VerifyProof(state.root, last.Hash[:], resp.Proof)
+		require.NoError(t, err)
+		acc, err := DecodeSlimAccount(last.Body)
+		require.NoError(t, err)
+		enc := make([]byte, acc.EncodingLengthForHashing())
+		acc.EncodeForHashing(enc)
+		assert.Equal(t, enc, val)
+
+		all = append(all, resp.Accounts...)
+		next, ok := dbutils.NextSubtree(last.Hash[:])
+		if !ok {
+			break
+		}
+		origin = common.BytesToHash(next)
+		if len(all) >= len(state.accounts) {
+			// Range beyond the last account is proven by the absence proof
+			require.NoError(t, peer.RequestAccountRange(uint64(i+1), state.root, origin, maxHash, 4096))
+			expectMsg(t, peer, AccountRangeMsg, &resp)
+			assert.Empty(t, resp.Accounts)
+			val, err = trie.VerifyProof(state.root, origin[:], resp.Proof)
+			require.NoError(t, err)
+			assert.Nil(t, val)
+			break
+		}
+	}
+
+	require.Equal(t, len(state.accounts), len(all))
+	for i, data := range all {
+		if i > 0 {
+			assert.Equal(t, -1, bytes.Compare(all[i-1].Hash[:], data.Hash[:]))
+		}
+		acc, err := DecodeSlimAccount(data.Body)
+		require.NoError(t, err)
+		expected := state.accounts[data.Hash]
+		require.NotNil(t, expected)
+		assert.Equal(t, expected.Nonce, acc.Nonce)
+		assert.Equal(t, expected.Balance.Uint64(), acc.Balance.Uint64())
+		assert.Equal(t, expected.CodeHash, acc.CodeHash)
+		assert.Equal(t, len(state.storage[data.Hash]) > 0, acc.Root != trie.EmptyRoot)
+	}
+
+	// Unknown root is answered with the empty response
+	require.NoError(t, peer.RequestAccountRange(100, common.Hash{1}, common.Hash{}, maxHash, 4096))
+	var resp AccountRangePacket
+	expectMsg(t, peer, AccountRangeMsg, &resp)
+	assert.Equal(t, uint64(100), resp.ID)
+	assert.Empty(t, resp.Accounts)
+	assert.Empty(t, resp.Proof)
+}
+
+func TestStorageRanges(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	state := newTestState(t, db, 100, 50)
+	_, peer := newTestNetwork(t, db)
+
+	// Storage roots of the contracts come with the accounts
+	require.NoError(t, peer.RequestAccountRange(0, state.root, common.Hash{}, maxHash, softResponseLimit))
+	var accResp AccountRangePacket
+	expectMsg(t, peer, AccountRangeMsg, &accResp)
+	require.Len(t, accResp.Accounts, len(state.accounts))
+	roots := make(map[common.Hash]common.Hash)
+	for _, data := range accResp.Accounts {
+		acc, err := DecodeSlimAccount(data.Body)
+		require.NoError(t, err)
+		roots[data.Hash] = acc.Root
+	}
+
+	// Small contracts are served entirely, without proofs
+	require.NoError(t, peer.RequestStorageRanges(1, state.root, state.contracts, nil, nil, softResponseLimit))
+	var resp StorageRangesPacket
+	expectMsg(t, peer, StorageRangesMsg, &resp)
+	require.Len(t, resp.Slots, len(state.contracts))
+	assert.Empty(t, resp.Proof)
+	for i, slots := range resp.Slots {
+		contract := state.contracts[i]
+		require.Len(t, slots, len(state.storage[contract]))
+		st := trie.New(common.Hash{})
+		for j, slot := range slots {
+			assert.Equal(t, state.storage[contract][j], slot.Hash)
+			var v []byte
+			require.NoError(t, rlp.DecodeBytes(slot.Body, &v))
+			st.Update(slot.Hash[:], v)
+		}
+		assert.Equal(t, roots[contract], st.Hash())
+	}
+
+	// Large contract mode: the range starts in the middle and is capped by the size
+	contract := state.contracts[3]
+	origin := state.storage[contract][10]
+	require.NoError(t, peer.RequestStorageRanges(2, state.root, []common.Hash{contract}, origin[:], nil, 500))
+	expectMsg(t, peer, StorageRangesMsg, &resp)
+	require.Len(t, resp.Slots, 1)
+	slots := resp.Slots[0]
+	require.NotEmpty(t, slots)
+	assert.Less(t, len(slots), 40)
+	assert.Equal(t, origin, slots[0].Hash)
+	require.NotEmpty(t, resp.Proof)
+	for _, key := range []common.Hash{origin, slots[len(slots)-1].Hash} {
+		val, err := trie.VerifyProof(roots[contract], key[:], resp.Proof)
+		require.NoError(t, err)
+		assert.NotNil(t, val)
+	}
+}
+
+func TestByteCodesAndTrieNodes(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	state := newTestState(t, db, 100, 5)
+	_, peer := newTestNetwork(t, db)
+
+	var hashes []common.Hash
+	for hash := range state.codes {
+		hashes = append(hashes, hash)
+	}
+	require.NoError(t, peer.RequestByteCodes(1, append(hashes, trie.EmptyCodeHash), softResponseLimit))
+	var codes ByteCodesPacket
+	expectMsg(t, peer, ByteCodesMsg, &codes)
+	require.Len(t, codes.Codes, len(hashes)+1)
+	for i, hash := range hashes {
+		assert.Equal(t, state.codes[hash], codes.Codes[i])
+	}
+	assert.Empty(t, codes.Codes[len(hashes)])
+
+	// Account trie root, its first child, and the storage trie root of a contract
+	contract := state.contracts[0]
+	require.NoError(t, peer.RequestAccountRange(2, state.root, contract, contract, 0))
+	var accResp AccountRangePacket
+	expectMsg(t, peer, AccountRangeMsg, &accResp)
+	require.NotEmpty(t, accResp.Accounts)
+	require.Equal(t, contract, accResp.Accounts[0].Hash)
+	acc, err := DecodeSlimAccount(accResp.Accounts[0].Body)
+	require.NoError(t, err)
+
+	paths := []TrieNodePathSet{
+		{{0x00}},
+		{{0x10 | contract[0]>>4}},
+		{contract[:], {0x00}},
+	}
+	require.NoError(t, peer.RequestTrieNodes(3, state.root, paths, softResponseLimit))
+	var nodes TrieNodesPacket
+	expectMsg(t, peer, TrieNodesMsg, &nodes)
+	require.Len(t, nodes.Nodes, 3)
+	assert.Equal(t, state.root, crypto.Keccak256Hash(nodes.Nodes[0]))
+	assert.Contains(t, string(nodes.Nodes[0]), string(crypto.Keccak256(nodes.Nodes[1])))
+	assert.Equal(t, acc.Root, crypto.Keccak256Hash(nodes.Nodes[2]))
+}
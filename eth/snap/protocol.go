@@ -0,0 +1,216 @@
+package snap
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	snap1   = 1
+	tgsnap1 = 1
+)
+
+// ProtocolName is the official short name of the `snap` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "snap"
+
+// ProtocolVersions are the supported versions of the `snap` protocol (first
+// is primary).
+var ProtocolVersions = []uint{snap1}
+
+// protocolLengths are the number of implemented message corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{snap1: 8}
+
+// PlainProtocolName is the name of the companion protocol of `snap`, serving the keys of the plain
+// state. `snap` serves only the hashed keys, which are not enough to restore the plain state kept by
+// turbo-geth.
+const PlainProtocolName = "tgsnap"
+
+// PlainProtocolVersions are the supported versions of the plain keys protocol.
+var PlainProtocolVersions = []uint{tgsnap1}
+
+// plainProtocolLengths are the number of implemented message corresponding to
+// different versions of the plain keys protocol.
+var plainProtocolLengths = map[uint]uint64{tgsnap1: 2}
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 10 * 1024 * 1024
+
+// snap protocol message codes
+const (
+	GetAccountRangeMsg  = 0x00
+	AccountRangeMsg     = 0x01
+	GetStorageRangesMsg = 0x02
+	StorageRangesMsg    = 0x03
+	GetByteCodesMsg     = 0x04
+	ByteCodesMsg        = 0x05
+	GetTrieNodesMsg     = 0x06
+	TrieNodesMsg        = 0x07
+)
+
+// plain keys protocol message codes
+const (
+	GetPlainKeysMsg = 0x00
+	PlainKeysMsg    = 0x01
+)
+
+var (
+	errMsgTooLarge    = errors.New("message too long")
+	errDecode         = errors.New("invalid message")
+	errInvalidMsgCode = errors.New("invalid message code")
+	errBadRequest     = errors.New("bad request")
+)
+
+// GetAccountRangePacket represents an account query.
+type GetAccountRangePacket struct {
+	ID     uint64      // Request ID to match up responses with
+	Root   common.Hash // Root hash of the account trie to serve
+	Origin common.Hash // Hash of the first account to retrieve
+	Limit  common.Hash // Hash of the last account to retrieve
+	Bytes  uint64      // Soft limit at which to stop returning data
+}
+
+// AccountRangePacket represents an account query response.
+type AccountRangePacket struct {
+	ID       uint64         // ID of the request this is a response for
+	Accounts []*AccountData // List of consecutive accounts from the trie
+	Proof    [][]byte       // List of trie nodes proving the account range
+}
+
+// AccountData represents a single account in a query response.
+type AccountData struct {
+	Hash common.Hash  // Hash of the account
+	Body rlp.RawValue // Account body in slim format
+}
+
+// GetStorageRangesPacket represents an storage slot query.
+type GetStorageRangesPacket struct {
+	ID       uint64        // Request ID to match up responses with
+	Root     common.Hash   // Root hash of the account trie to serve
+	Accounts []common.Hash // Account hashes of the storage tries to serve
+	Origin   []byte        // Hash of the first storage slot to retrieve (large contract mode)
+	Limit    []byte        // Hash of the last storage slot to retrieve (large contract mode)
+	Bytes    uint64        // Soft limit at which to stop returning data
+}
+
+// StorageRangesPacket represents a storage slot query response.
+type StorageRangesPacket struct {
+	ID    uint64           // ID of the request this is a response for
+	Slots [][]*StorageData // Lists of consecutive storage slots for the requested accounts
+	Proof [][]byte         // Merkle proofs for the *last* slot range, if it's incomplete
+}
+
+// StorageData represents a single storage slot in a query response.
+type StorageData struct {
+	Hash common.Hash // Hash of the storage slot
+	Body []byte      // Data content of the slot
+}
+
+// GetByteCodesPacket represents a contract bytecode query.
+type GetByteCodesPacket struct {
+	ID     uint64        // Request ID to match up responses with
+	Hashes []common.Hash // Code hashes to retrieve the code for
+	Bytes  uint64        // Soft limit at which to stop returning data
+}
+
+// ByteCodesPacket represents a contract bytecode query response.
+type ByteCodesPacket struct {
+	ID    uint64   // ID of the request this is a response for
+	Codes [][]byte // Requested contract bytecodes
+}
+
+// GetTrieNodesPacket represents a state trie node query.
+type GetTrieNodesPacket struct {
+	ID    uint64            // Request ID to match up responses with
+	Root  common.Hash       // Root hash of the account trie to serve
+	Paths []TrieNodePathSet // Trie node hashes to retrieve the nodes for
+	Bytes uint64            // Soft limit at which to stop returning data
+}
+
+// TrieNodePathSet is a list of trie node paths to retrieve. A naive way to
+// represent trie nodes would be a simple list of `account || storage` path
+// segments concatenated, but that would be very wasteful on the network.
+//
+// Instead, this array special cases the first element as the path in the
+// account trie and the remaining elements as paths in the storage trie. To
+// address an account node, the slice should have a length of 1 consisting
+// of only the account path. There's no need to be able to address both an
+// account node and a storage node in the same request as it cannot happen
+// that a slot is accessed before the account path is fully expanded.
+type TrieNodePathSet [][]byte
+
+// TrieNodesPacket represents a state trie node query response.
+type TrieNodesPacket struct {
+	ID    uint64   // ID of the request this is a response for
+	Nodes [][]byte // Requested state trie nodes
+}
+
+// GetPlainKeysPacket represents a plain state keys query.
+type GetPlainKeysPacket struct {
+	ID     uint64      // Request ID to match up responses with
+	Root   common.Hash // Root hash of the account trie of the state to serve
+	Origin []byte      // First plain key to retrieve
+	Bytes  uint64      // Soft limit at which to stop returning data
+}
+
+// PlainKeysPacket represents a plain state keys query response.
+type PlainKeysPacket struct {
+	ID   uint64   // ID of the request this is a response for
+	Keys [][]byte // Consecutive keys of the plain state (accounts and storage)
+	Last bool     // Whether the keys reach the end of the state
+}
+
+// slimAccount is the account encoding used by the `snap` protocol: empty storage root
+// and empty code hash are omitted
+type slimAccount struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     []byte
+	CodeHash []byte
+}
+
+// encodeSlimAccount converts account into the slim format
+func encodeSlimAccount(acc *accounts.Account) (rlp.RawValue, error) {
+	slim := slimAccount{
+		Nonce:   acc.Nonce,
+		Balance: acc.Balance.ToBig(),
+	}
+	if acc.Root != trie.EmptyRoot {
+		slim.Root = acc.Root[:]
+	}
+	if acc.CodeHash != trie.EmptyCodeHash {
+		slim.CodeHash = acc.CodeHash[:]
+	}
+	return rlp.EncodeToBytes(slim)
+}
+
+// DecodeSlimAccount parses the account in the slim format, restoring omitted storage root and code hash
+func DecodeSlimAccount(body []byte) (*accounts.Account, error) {
+	var slim slimAccount
+	if err := rlp.DecodeBytes(body, &slim); err != nil {
+		return nil, err
+	}
+	acc := accounts.NewAccount()
+	acc.Nonce = slim.Nonce
+	if slim.Balance != nil {
+		if overflow := acc.Balance.SetFromBig(slim.Balance); overflow {
+			return nil, errors.New("balance overflow")
+		}
+	}
+	acc.Root = trie.EmptyRoot
+	if len(slim.Root) > 0 {
+		acc.Root = common.BytesToHash(slim.Root)
+	}
+	acc.CodeHash = trie.EmptyCodeHash
+	if len(slim.CodeHash) > 0 {
+		acc.CodeHash = common.BytesToHash(slim.CodeHash)
+	}
+	return &acc, nil
+}
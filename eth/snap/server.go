@@ -0,0 +1,536 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+const (
+	// softResponseLimit is the target maximum size of replies to data retrievals.
+	softResponseLimit = 2 * 1024 * 1024
+
+	// maxCodeLookups is the maximum number of bytecodes to serve. This number is
+	// there to limit the number of disk lookups.
+	maxCodeLookups = 1024
+
+	// stateLookupSlack defines the ratio by how much a state response can exceed
+	// the requested limit in order to try and avoid breaking up contracts into
+	// multiple packages and proving them.
+	stateLookupSlack = 0.1
+
+	// maxTrieNodeLookups is the maximum number of state trie nodes to serve. This
+	// number is there to limit the number of disk lookups.
+	maxTrieNodeLookups = 1024
+)
+
+var maxHash = common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+
+// Server answers `snap` requests from the flat state (CurrentStateBucket), intermediate hashes
+// (IntermediateTrieHashBucket) and the contract codes (CodeBucket). Only the state at the
+// block reached by the IntermediateHashes stage can be served, requests for any other root
+// receive empty responses.
+type Server struct {
+	db ethdb.Database
+}
+
+func NewServer(db ethdb.Database) *Server {
+	return &Server{db: db}
+}
+
+// stateRoot returns the root of the state which can be served. Hashed state and intermediate hashes
+// are consistent with each other only when both stages reached the same block, otherwise
+// empty hash is returned
+func stateRoot(db ethdb.Getter) (common.Hash, error) {
+	hashStateProgress, err := stages.GetStageProgress(db, stages.HashState)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	ihProgress, err := stages.GetStageProgress(db, stages.IntermediateHashes)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if hashStateProgress != ihProgress {
+		return common.Hash{}, nil
+	}
+	hash, err := rawdb.ReadCanonicalHash(db, ihProgress)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := rawdb.ReadHeader(db, hash, ihProgress)
+	if header == nil {
+		return common.Hash{}, nil
+	}
+	return header.Root, nil
+}
+
+// loadSubTrie builds the trie with the nodes on the paths to the retained keys and checks
+// that its root matches the expected one
+func loadSubTrie(tx ethdb.Tx, rl *trie.RetainList, root common.Hash) (*trie.Trie, error) {
+	loader := trie.NewFlatDBTrieLoader("snap", dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
+	if err := loader.Reset(rl, nil, false); err != nil {
+		return nil, err
+	}
+	t, err := loader.CalcSubTrie(tx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if hash := t.Hash(); hash != root {
+		return nil, fmt.Errorf("state root mismatch: calculated %x, expected %x", hash, root)
+	}
+	return t, nil
+}
+
+// readAccount reads the account from the hashed state
+func readAccount(tx ethdb.Tx, addrHash common.Hash) (*accounts.Account, error) {
+	enc, err := tx.GetOne(dbutils.CurrentStateBucket, addrHash[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	var acc accounts.Account
+	if err = acc.DecodeForStorage(enc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+// proofSet collects the proof nodes, skipping duplicates
+type proofSet struct {
+	nodes [][]byte
+	seen  map[string]struct{}
+}
+
+func (ps *proofSet) add(proof [][]byte) {
+	if ps.seen == nil {
+		ps.seen = make(map[string]struct{})
+	}
+	for _, n := range proof {
+		if _, ok := ps.seen[string(n)]; ok {
+			continue
+		}
+		ps.seen[string(n)] = struct{}{}
+		ps.nodes = append(ps.nodes, n)
+	}
+}
+
+// AccountRange serves the range of the accounts starting from the origin, together with
+// the proofs of the origin and of the last returned account
+func (s *Server) AccountRange(ctx context.Context, req *GetAccountRangePacket) (*AccountRangePacket, error) {
+	resp := &AccountRangePacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	tx, err := s.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+	root, err := stateRoot(tx)
+	if err != nil {
+		return resp, err
+	}
+	if root != req.Root {
+		return resp, nil
+	}
+
+	rl := trie.NewRetainList(0)
+	rl.AddKey(req.Origin[:])
+	var keys []common.Hash
+	var size uint64
+	c := tx.(ethdb.HasTx).Tx().Cursor(dbutils.CurrentStateBucket)
+	defer c.Close()
+	k, v, err := c.Seek(req.Origin[:])
+	for k != nil {
+		if err != nil {
+			return resp, err
+		}
+		if len(k) > common.HashLength {
+			// Storage of the previous account, skip to the next one
+			next, ok := dbutils.NextSubtree(k[:common.HashLength])
+			if !ok {
+				break
+			}
+			k, v, err = c.Seek(next)
+			continue
+		}
+		key := common.BytesToHash(k)
+		keys = append(keys, key)
+		rl.AddKey(k)
+		// Storage root is not known yet, assume it is there for the contracts
+		size += uint64(common.HashLength + len(v))
+		if bytes.Compare(k, req.Limit[:]) >= 0 || size >= req.Bytes {
+			break
+		}
+		k, v, err = c.Next()
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	t, err := loadSubTrie(tx.(ethdb.HasTx).Tx(), rl, root)
+	if err != nil {
+		return resp, err
+	}
+	for _, key := range keys {
+		acc, ok := t.GetAccount(key[:])
+		if !ok {
+			return &AccountRangePacket{ID: req.ID}, fmt.Errorf("account %x is not found in the trie", key)
+		}
+		body, err := encodeSlimAccount(acc)
+		if err != nil {
+			return &AccountRangePacket{ID: req.ID}, err
+		}
+		resp.Accounts = append(resp.Accounts, &AccountData{Hash: key, Body: body})
+	}
+
+	var proofs proofSet
+	proof, err := t.Prove(req.Origin[:], 0, false)
+	if err != nil {
+		return &AccountRangePacket{ID: req.ID}, err
+	}
+	proofs.add(proof)
+	if len(keys) > 0 {
+		if proof, err = t.Prove(keys[len(keys)-1][:], 0, false); err != nil {
+			return &AccountRangePacket{ID: req.ID}, err
+		}
+		proofs.add(proof)
+	}
+	resp.Proof = proofs.nodes
+	return resp, nil
+}
+
+// StorageRanges serves the storage of the requested accounts. If the storage of an account
+// cannot be served entirely (because of the origin, limit or the response size), the proofs
+// for its boundaries are added and the rest of the accounts is not served
+func (s *Server) StorageRanges(ctx context.Context, req *GetStorageRangesPacket) (*StorageRangesPacket, error) {
+	resp := &StorageRangesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	// Calculate the hard limit at which to abort, even if mid storage trie
+	hardLimit := uint64(float64(req.Bytes) * (1 + stateLookupSlack))
+
+	tx, err := s.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+	root, err := stateRoot(tx)
+	if err != nil {
+		return resp, err
+	}
+	if root != req.Root {
+		return resp, nil
+	}
+	c := tx.(ethdb.HasTx).Tx().Cursor(dbutils.CurrentStateBucket)
+	defer c.Close()
+
+	var size uint64
+	for _, account := range req.Accounts {
+		// If we've exceeded the requested data limit, abort without opening
+		// a new storage range (that we'd need to prove due to exceeded size)
+		if size >= req.Bytes {
+			break
+		}
+		// The first account might start from a different origin and end sooner
+		var origin common.Hash
+		if len(req.Origin) > 0 {
+			origin, req.Origin = common.BytesToHash(req.Origin), nil
+		}
+		var limit = maxHash
+		if len(req.Limit) > 0 {
+			limit, req.Limit = common.BytesToHash(req.Limit), nil
+		}
+		acc, err := readAccount(tx.(ethdb.HasTx).Tx(), account)
+		if err != nil {
+			return &StorageRangesPacket{ID: req.ID}, err
+		}
+		var incarnation uint64
+		if acc != nil {
+			incarnation = acc.Incarnation
+		}
+		prefix := dbutils.GenerateStoragePrefix(account[:], incarnation)
+		var (
+			storage []*StorageData
+			last    common.Hash
+			abort   bool
+		)
+		k, v, err := c.Seek(dbutils.GenerateCompositeStorageKey(account, incarnation, origin))
+		for ; k != nil && acc != nil && bytes.HasPrefix(k, prefix); k, v, err = c.Next() {
+			if err != nil {
+				return &StorageRangesPacket{ID: req.ID}, err
+			}
+			if size >= hardLimit {
+				abort = true
+				break
+			}
+			hash := common.BytesToHash(k[len(prefix):])
+			body, err := rlp.EncodeToBytes(v)
+			if err != nil {
+				return &StorageRangesPacket{ID: req.ID}, err
+			}
+			size += uint64(common.HashLength + len(body))
+			storage = append(storage, &StorageData{Hash: hash, Body: body})
+			last = hash
+			if bytes.Compare(hash[:], limit[:]) >= 0 {
+				break
+			}
+		}
+		if err != nil {
+			return &StorageRangesPacket{ID: req.ID}, err
+		}
+		resp.Slots = append(resp.Slots, storage)
+
+		// If the request started at a non-zero hash or was capped prematurely, add
+		// the endpoint Merkle proofs
+		if acc != nil && (origin != (common.Hash{}) || abort) {
+			rl := trie.NewRetainList(0)
+			rl.AddKey(dbutils.GenerateCompositeStorageKey(account, incarnation, origin))
+			if len(storage) > 0 {
+				rl.AddKey(dbutils.GenerateCompositeStorageKey(account, incarnation, last))
+			}
+			t, err := loadSubTrie(tx.(ethdb.HasTx).Tx(), rl, root)
+			if err != nil {
+				return &StorageRangesPacket{ID: req.ID}, err
+			}
+			var proofs proofSet
+			proof, err := t.Prove(dbutils.GenerateCompositeTrieKey(account, origin), 2*common.HashLength, true)
+			if err != nil {
+				return &StorageRangesPacket{ID: req.ID}, err
+			}
+			proofs.add(proof)
+			if len(storage) > 0 {
+				if proof, err = t.Prove(dbutils.GenerateCompositeTrieKey(account, last), 2*common.HashLength, true); err != nil {
+					return &StorageRangesPacket{ID: req.ID}, err
+				}
+				proofs.add(proof)
+			}
+			resp.Proof = proofs.nodes
+			// Proof terminates the reply as proofs are only added if a node
+			// refuses to serve more data
+			break
+		}
+	}
+	return resp, nil
+}
+
+// ByteCodes serves the contract codes by their hashes
+func (s *Server) ByteCodes(ctx context.Context, req *GetByteCodesPacket) (*ByteCodesPacket, error) {
+	resp := &ByteCodesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	if len(req.Hashes) > maxCodeLookups {
+		req.Hashes = req.Hashes[:maxCodeLookups]
+	}
+	tx, err := s.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+
+	var size uint64
+	for _, hash := range req.Hashes {
+		if hash == trie.EmptyCodeHash {
+			// Peers should not request the empty code, but if they do, at
+			// least sent them back a correct response without db lookups
+			resp.Codes = append(resp.Codes, []byte{})
+			continue
+		}
+		code, err := tx.(ethdb.HasTx).Tx().GetOne(dbutils.CodeBucket, hash[:])
+		if err != nil {
+			return &ByteCodesPacket{ID: req.ID}, err
+		}
+		if len(code) == 0 {
+			continue
+		}
+		resp.Codes = append(resp.Codes, common.CopyBytes(code))
+		size += uint64(len(code))
+		if size > req.Bytes {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// TrieNodes serves the nodes of the account and storage tries by their paths. Serving stops
+// at the first node which cannot be found
+func (s *Server) TrieNodes(ctx context.Context, req *GetTrieNodesPacket) (*TrieNodesPacket, error) {
+	resp := &TrieNodesPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	tx, err := s.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+	root, err := stateRoot(tx)
+	if err != nil {
+		return resp, err
+	}
+	if root != req.Root {
+		return resp, nil
+	}
+
+	// Collect the paths of the requested nodes (as seen by the trie) and the keys to retain
+	// (as seen by the hashed state, which also includes incarnations)
+	rl := trie.NewRetainList(0)
+	var paths [][]byte
+	var lookups int
+collect:
+	for _, pathset := range req.Paths {
+		switch len(pathset) {
+		case 0:
+			return resp, fmt.Errorf("%w: zero-item pathset requested", errBadRequest)
+		case 1:
+			// If we're only retrieving an account trie node
+			hex := trie.CompactToHex(pathset[0])
+			rl.AddHex(hex)
+			paths = append(paths, hex)
+			lookups++
+			if lookups >= maxTrieNodeLookups {
+				break collect
+			}
+		default:
+			// Storage slots requested, the account must exist
+			addrHash := common.BytesToHash(pathset[0])
+			acc, err := readAccount(tx.(ethdb.HasTx).Tx(), addrHash)
+			if err != nil {
+				return resp, err
+			}
+			if acc == nil {
+				break collect
+			}
+			var accHex, prefixHex []byte
+			trie.DecompressNibbles(addrHash[:], &accHex)
+			trie.DecompressNibbles(dbutils.GenerateStoragePrefix(addrHash[:], acc.Incarnation), &prefixHex)
+			for _, path := range pathset[1:] {
+				hex := trie.CompactToHex(path)
+				rl.AddHex(append(common.CopyBytes(prefixHex), hex...))
+				paths = append(paths, append(common.CopyBytes(accHex), hex...))
+				lookups++
+				if lookups >= maxTrieNodeLookups {
+					break collect
+				}
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return resp, nil
+	}
+
+	t, err := loadSubTrie(tx.(ethdb.HasTx).Tx(), rl, root)
+	if err != nil {
+		return resp, err
+	}
+	var size uint64
+	for _, path := range paths {
+		enc, err := t.NodeRLP(path)
+		if err != nil {
+			return &TrieNodesPacket{ID: req.ID}, err
+		}
+		if enc == nil {
+			break
+		}
+		resp.Nodes = append(resp.Nodes, enc)
+		size += uint64(len(enc))
+		if size > req.Bytes {
+			break
+		}
+	}
+	return resp, nil
+}
+
+// PlainKeys serves the keys of the plain state (accounts and the storage of their current incarnations),
+// starting from the origin. Values are not served, the client takes them from the hashed state
+// downloaded via `snap`. The plain state is served only when it is at the same block as the hashed one
+func (s *Server) PlainKeys(ctx context.Context, req *GetPlainKeysPacket) (*PlainKeysPacket, error) {
+	resp := &PlainKeysPacket{ID: req.ID}
+	if req.Bytes > softResponseLimit {
+		req.Bytes = softResponseLimit
+	}
+	tx, err := s.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return resp, err
+	}
+	defer tx.Rollback()
+	root, err := stateRoot(tx)
+	if err != nil {
+		return resp, err
+	}
+	if root != req.Root {
+		return resp, nil
+	}
+	executionProgress, err := stages.GetStageProgress(tx, stages.Execution)
+	if err != nil {
+		return resp, err
+	}
+	ihProgress, err := stages.GetStageProgress(tx, stages.IntermediateHashes)
+	if err != nil {
+		return resp, err
+	}
+	if executionProgress != ihProgress {
+		return resp, nil
+	}
+
+	var (
+		address     []byte
+		incarnation uint64
+		size        uint64
+	)
+	c := tx.(ethdb.HasTx).Tx().Cursor(dbutils.PlainStateBucket)
+	defer c.Close()
+	for k, v, err := c.Seek(req.Origin); k != nil; k, v, err = c.Next() {
+		if err != nil {
+			return &PlainKeysPacket{ID: req.ID}, err
+		}
+		if size >= req.Bytes {
+			return resp, nil
+		}
+		if len(k) == common.AddressLength {
+			var acc accounts.Account
+			if err = acc.DecodeForStorage(v); err != nil {
+				return &PlainKeysPacket{ID: req.ID}, err
+			}
+			address, incarnation = common.CopyBytes(k), acc.Incarnation
+		} else {
+			if !bytes.Equal(k[:common.AddressLength], address) {
+				// The origin is in the middle of the storage, so the account is not visited yet
+				address, incarnation = common.CopyBytes(k[:common.AddressLength]), 0
+				enc, err := tx.(ethdb.HasTx).Tx().GetOne(dbutils.PlainStateBucket, address)
+				if err != nil {
+					return &PlainKeysPacket{ID: req.ID}, err
+				}
+				if len(enc) > 0 {
+					var acc accounts.Account
+					if err = acc.DecodeForStorage(enc); err != nil {
+						return &PlainKeysPacket{ID: req.ID}, err
+					}
+					incarnation = acc.Incarnation
+				}
+			}
+			if binary.BigEndian.Uint64(k[common.AddressLength:]) != incarnation {
+				// Storage of the previous incarnations is not a part of the state
+				continue
+			}
+		}
+		resp.Keys = append(resp.Keys, common.CopyBytes(k))
+		size += uint64(len(k))
+	}
+	resp.Last = true
+	return resp, nil
+}
@@ -0,0 +1,829 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"github.com/ledgerwatch/turbo-geth/turbo/trie"
+)
+
+const (
+	// maxRequestSize is the maximum number of bytes to request from a remote peer.
+	maxRequestSize = 512 * 1024
+
+	// maxStorageRequestCount is the maximum number of contracts to request the
+	// storage for in a single query.
+	maxStorageRequestCount = maxRequestSize / 1024
+
+	// maxCodeRequestCount is the maximum number of bytecode blobs to request in a
+	// single query.
+	maxCodeRequestCount = maxRequestSize / (24 * 1024) * 4
+
+	// requestTimeout is the maximum time a peer is allowed to spend on serving a request.
+	requestTimeout = 10 * time.Second
+
+	// peerWaitTimeout is the maximum time to wait for the peers to connect.
+	peerWaitTimeout = time.Minute
+
+	// pivotSearchDepth is the number of the most recent blocks checked when looking for
+	// the state served by the peers.
+	pivotSearchDepth = 128
+)
+
+// Progress of the state download is kept in the SyncStageProgress bucket under these keys
+var (
+	pivotKey  = stages.SyncStage("snap_pivot")  // Number of the block which state is downloaded
+	phaseKey  = stages.SyncStage("snap_phase")  // Current phase of the download
+	cursorKey = stages.SyncStage("snap_cursor") // Next account hash or plain key to request in the current phase
+)
+
+// Phases of the state download
+const (
+	accountsPhase  = iota // Accounts with their storage and codes are downloaded into the hashed state
+	plainKeysPhase        // Plain keys are downloaded, the plain state is filled from the hashed one
+	donePhase             // The state is downloaded
+)
+
+var (
+	errTimeout    = errors.New("request timed out")
+	errNotServed  = errors.New("state is not served")
+	errStalePivot = errors.New("state of the pivot block is not served anymore")
+	errNoPeers    = errors.New("no snap peers")
+	errNoPivot    = errors.New("no recent state is served by the peers")
+)
+
+// Syncer downloads the state of a recent block from the `snap` peers and the plain state keys from
+// the peers of the companion plain keys protocol. Every range of accounts and storage is verified
+// against the state root from the block header with the range proofs.
+//
+// Peers serve only the state at the head of their sync, so the download has to complete before
+// all of them move on. There is no healing of the state downloaded for an older pivot block: once
+// its state is not served anymore, the downloaded state is dropped and the download starts over.
+type Syncer struct {
+	lock       sync.Mutex
+	peers      map[string]*Peer           // Peers of the `snap` protocol
+	plainPeers map[string]*Peer           // Peers of the plain keys protocol
+	pending    map[uint64]*pendingRequest // Requests waiting for the responses, by request ID
+	nextID     uint64
+}
+
+type pendingRequest struct {
+	peer string
+	resp chan interface{}
+}
+
+func NewSyncer() *Syncer {
+	return &Syncer{
+		peers:      make(map[string]*Peer),
+		plainPeers: make(map[string]*Peer),
+		pending:    make(map[uint64]*pendingRequest),
+		nextID:     rand.Uint64(), //nolint:gosec
+	}
+}
+
+func (s *Syncer) register(peers map[string]*Peer, peer *Peer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	peers[peer.id] = peer
+}
+
+func (s *Syncer) unregister(peers map[string]*Peer, peer *Peer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(peers, peer.id)
+}
+
+// deliver hands the response over to the request waiting for it. Responses to the timed out
+// or unknown requests are dropped
+func (s *Syncer) deliver(peer *Peer, id uint64, resp interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	req, ok := s.pending[id]
+	if !ok || req.peer != peer.id {
+		peer.Log().Debug("Dropping unsolicited response", "reqid", id)
+		return
+	}
+	delete(s.pending, id)
+	req.resp <- resp
+}
+
+// request sends the request with a new ID and waits for the response
+func (s *Syncer) request(peer *Peer, send func(id uint64) error, quit <-chan struct{}) (interface{}, error) {
+	resp := make(chan interface{}, 1)
+	s.lock.Lock()
+	id := s.nextID
+	s.nextID++
+	s.pending[id] = &pendingRequest{peer: peer.id, resp: resp}
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.pending, id)
+		s.lock.Unlock()
+	}()
+
+	if err := send(id); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(requestTimeout)
+	defer timeout.Stop()
+	select {
+	case res := <-resp:
+		return res, nil
+	case <-timeout.C:
+		return nil, errTimeout
+	case <-quit:
+		return nil, common.ErrStopped
+	}
+}
+
+// SyncState downloads the state of one of the recent blocks not higher than `to` into the hashed
+// and the plain state buckets, and returns the number of this block. The intermediate hashes are
+// not generated. The download is resumed from the progress saved in the database
+func (s *Syncer) SyncState(db ethdb.Database, to uint64, quit <-chan struct{}) (uint64, error) {
+	pivot, err := stages.GetStageProgress(db, pivotKey)
+	if err != nil {
+		return 0, err
+	}
+	phase, err := stages.GetStageProgress(db, phaseKey)
+	if err != nil {
+		return 0, err
+	}
+	if pivot > 0 && phase == donePhase {
+		return pivot, nil
+	}
+	if pivot == 0 {
+		if pivot, err = s.findPivot(db, to, quit); err != nil {
+			return 0, err
+		}
+		if err = resetState(db, pivot); err != nil {
+			return 0, err
+		}
+	}
+	root, err := headerRoot(db, pivot)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("Downloading the state", "pivot", pivot, "root", root)
+	ss := s.newStateSync(db, root, quit)
+	if err = ss.run(); err != nil {
+		if errors.Is(err, errStalePivot) {
+			// Peers could have failed for other reasons, drop the state only if it is not served indeed
+			if served, err1 := s.newStateSync(db, root, quit).served(); err1 == nil && !served {
+				log.Warn("Dropping the state of the stale pivot block", "pivot", pivot)
+				if err1 = resetState(db, 0); err1 != nil {
+					return 0, err1
+				}
+			}
+		}
+		return 0, err
+	}
+	return pivot, nil
+}
+
+// findPivot looks for the most recent block not higher than `to`, which state is served by the peers
+func (s *Syncer) findPivot(db ethdb.Database, to uint64, quit <-chan struct{}) (uint64, error) {
+	for n := to; n > 0 && to-n < pivotSearchDepth; n-- {
+		root, err := headerRoot(db, n)
+		if err != nil {
+			return 0, err
+		}
+		served, err := s.newStateSync(db, root, quit).served()
+		if err != nil {
+			return 0, err
+		}
+		if served {
+			return n, nil
+		}
+	}
+	return 0, errNoPivot
+}
+
+func headerRoot(db ethdb.Getter, number uint64) (common.Hash, error) {
+	hash, err := rawdb.ReadCanonicalHash(db, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := rawdb.ReadHeader(db, hash, number)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("header %d not found", number)
+	}
+	return header.Root, nil
+}
+
+// resetState drops the state (genesis or the partially downloaded one) and starts the download
+// of the state of the given block. Zero block means the pivot is to be chosen
+func resetState(db ethdb.Database, pivot uint64) error {
+	tx, err := db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = tx.(ethdb.BucketsMigrator).ClearBuckets(
+		dbutils.CurrentStateBucket,
+		dbutils.ContractCodeBucket,
+		dbutils.PlainStateBucket,
+		dbutils.PlainContractCodeBucket,
+	); err != nil {
+		return err
+	}
+	if err = stages.SaveStageProgress(tx, pivotKey, pivot); err != nil {
+		return err
+	}
+	if err = stages.SaveStageProgress(tx, phaseKey, accountsPhase); err != nil {
+		return err
+	}
+	if err = tx.Delete(dbutils.SyncStageProgress, cursorKey, nil); err != nil {
+		return err
+	}
+	_, err = tx.Commit()
+	return err
+}
+
+// stateSync is the download of the state with the given root
+type stateSync struct {
+	*Syncer
+	db     ethdb.Database
+	root   common.Hash
+	quit   <-chan struct{}
+	failed map[string]struct{} // Peers which failed to serve the state
+}
+
+func (s *Syncer) newStateSync(db ethdb.Database, root common.Hash, quit <-chan struct{}) *stateSync {
+	return &stateSync{Syncer: s, db: db, root: root, quit: quit, failed: make(map[string]struct{})}
+}
+
+// livePeers returns the peers which have not failed yet, in random order. If no peers are connected,
+// it waits for them for a while
+func (ss *stateSync) livePeers(plain bool) ([]*Peer, error) {
+	deadline := time.Now().Add(peerWaitTimeout)
+	for {
+		var live []*Peer
+		ss.lock.Lock()
+		peers := ss.peers
+		if plain {
+			peers = ss.plainPeers
+		}
+		for id, peer := range peers {
+			if _, ok := ss.failed[id]; !ok {
+				live = append(live, peer)
+			}
+		}
+		total := len(peers)
+		ss.lock.Unlock()
+		if len(live) > 0 {
+			rand.Shuffle(len(live), func(i, j int) { live[i], live[j] = live[j], live[i] })
+			return live, nil
+		}
+		if total > 0 {
+			return nil, errStalePivot
+		}
+		if time.Now().After(deadline) {
+			return nil, errNoPeers
+		}
+		select {
+		case <-ss.quit:
+			return nil, common.ErrStopped
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// withPeer runs the request against the peers until one of them succeeds. Peers failing the request
+// (including the responses which don't pass the verification) are not used for this state anymore
+func (ss *stateSync) withPeer(plain bool, f func(peer *Peer) error) error {
+	for {
+		peers, err := ss.livePeers(plain)
+		if err != nil {
+			return err
+		}
+		for _, peer := range peers {
+			err := f(peer)
+			if err == nil {
+				return nil
+			}
+			if errors.Is(err, common.ErrStopped) {
+				return err
+			}
+			peer.Log().Debug("State request failed", "root", ss.root, "err", err)
+			ss.failed[peer.id] = struct{}{}
+		}
+	}
+}
+
+// served checks whether any of the peers serves the state
+func (ss *stateSync) served() (bool, error) {
+	err := ss.withPeer(false, func(peer *Peer) error {
+		resp, err := ss.request(peer, func(id uint64) error {
+			return peer.RequestAccountRange(id, ss.root, common.Hash{}, maxHash, 1)
+		}, ss.quit)
+		if err != nil {
+			return err
+		}
+		if res := resp.(*AccountRangePacket); len(res.Accounts) == 0 && len(res.Proof) == 0 {
+			return errNotServed
+		}
+		return nil
+	})
+	if errors.Is(err, errStalePivot) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (ss *stateSync) run() error {
+	phase, err := stages.GetStageProgress(ss.db, phaseKey)
+	if err != nil {
+		return err
+	}
+	cursor, err := ss.db.Get(dbutils.SyncStageProgress, cursorKey)
+	if err != nil && !errors.Is(err, ethdb.ErrKeyNotFound) {
+		return err
+	}
+	if phase == accountsPhase {
+		if err = ss.syncAccounts(common.BytesToHash(cursor)); err != nil {
+			return err
+		}
+		cursor = nil
+	}
+	return ss.syncPlainKeys(cursor)
+}
+
+// syncAccounts downloads the accounts starting from the origin, together with their storage and codes.
+// Every range of the accounts is written in its own transaction, together with the progress
+func (ss *stateSync) syncAccounts(origin common.Hash) error {
+	logEvery := time.NewTicker(30 * time.Second)
+	defer logEvery.Stop()
+	for {
+		var (
+			hashes []common.Hash
+			accs   []*accounts.Account
+			more   bool
+		)
+		if err := ss.withPeer(false, func(peer *Peer) error {
+			resp, err := ss.request(peer, func(id uint64) error {
+				return peer.RequestAccountRange(id, ss.root, origin, maxHash, maxRequestSize)
+			}, ss.quit)
+			if err != nil {
+				return err
+			}
+			res := resp.(*AccountRangePacket)
+			if len(res.Accounts) == 0 && len(res.Proof) == 0 {
+				return errNotServed
+			}
+			hashes, accs, more, err = verifyAccountRange(ss.root, origin, res)
+			return err
+		}); err != nil {
+			return err
+		}
+		var next common.Hash
+		if more {
+			n, ok := dbutils.NextSubtree(hashes[len(hashes)-1][:])
+			if !ok {
+				return fmt.Errorf("account range proof claims more accounts after %x", hashes[len(hashes)-1])
+			}
+			next = common.BytesToHash(n)
+		}
+		if err := ss.writeAccounts(hashes, accs, next, more); err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+		origin = next
+
+		select {
+		default:
+		case <-logEvery.C:
+			log.Info("Downloading the state accounts", "progress", fmt.Sprintf("%.2f%%", float64(origin[0])*100/256))
+		}
+	}
+}
+
+// verifyAccountRange checks the range of the accounts against the state root, returning the decoded
+// accounts and whether there are more accounts after them
+func verifyAccountRange(root common.Hash, origin common.Hash, res *AccountRangePacket) ([]common.Hash, []*accounts.Account, bool, error) {
+	hashes := make([]common.Hash, len(res.Accounts))
+	accs := make([]*accounts.Account, len(res.Accounts))
+	keys := make([][]byte, len(res.Accounts))
+	values := make([][]byte, len(res.Accounts))
+	for i, data := range res.Accounts {
+		acc, err := DecodeSlimAccount(data.Body)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		hashes[i], accs[i], keys[i] = data.Hash, acc, data.Hash[:]
+		values[i] = make([]byte, acc.EncodingLengthForHashing())
+		acc.EncodeForHashing(values[i])
+	}
+	more, err := trie.VerifyRangeProof(root, origin[:], keys, values, res.Proof)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return hashes, accs, more, nil
+}
+
+// writeAccounts downloads the storage and the codes of the accounts and writes everything into
+// the hashed state, together with the next account to download
+func (ss *stateSync) writeAccounts(hashes []common.Hash, accs []*accounts.Account, next common.Hash, more bool) error {
+	tx, err := ss.db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		contracts []common.Hash
+		roots     []common.Hash
+		codes     []common.Hash
+		seenCodes = make(map[common.Hash]struct{})
+	)
+	for i, acc := range accs {
+		if !acc.IsEmptyRoot() || !acc.IsEmptyCodeHash() {
+			// Snap peers don't know about incarnations, so all the contracts start with the first one
+			acc.Incarnation = state.FirstContractIncarnation
+		}
+		if !acc.IsEmptyRoot() {
+			contracts = append(contracts, hashes[i])
+			roots = append(roots, acc.Root)
+		}
+		if !acc.IsEmptyCodeHash() {
+			if _, ok := seenCodes[acc.CodeHash]; !ok {
+				seenCodes[acc.CodeHash] = struct{}{}
+				codes = append(codes, acc.CodeHash)
+			}
+			if err = tx.Put(dbutils.ContractCodeBucket, dbutils.GenerateStoragePrefix(hashes[i][:], acc.Incarnation), acc.CodeHash[:]); err != nil {
+				return err
+			}
+		}
+		value := make([]byte, acc.EncodingLengthForStorage())
+		acc.EncodeForStorage(value)
+		if err = tx.Put(dbutils.CurrentStateBucket, hashes[i][:], value); err != nil {
+			return err
+		}
+	}
+	if err = ss.syncStorage(tx, contracts, roots); err != nil {
+		return err
+	}
+	if err = ss.syncCodes(tx, codes); err != nil {
+		return err
+	}
+
+	if more {
+		err = tx.Put(dbutils.SyncStageProgress, cursorKey, next[:])
+	} else {
+		if err = stages.SaveStageProgress(tx, phaseKey, plainKeysPhase); err != nil {
+			return err
+		}
+		err = tx.Delete(dbutils.SyncStageProgress, cursorKey, nil)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Commit()
+	return err
+}
+
+// syncStorage downloads the storage of the given accounts, writing it into the hashed state
+func (ss *stateSync) syncStorage(tx ethdb.Putter, accountHashes []common.Hash, roots []common.Hash) error {
+	for len(accountHashes) > 0 {
+		batch := accountHashes
+		if len(batch) > maxStorageRequestCount {
+			batch = batch[:maxStorageRequestCount]
+		}
+		var (
+			slots [][]*StorageData
+			more  bool
+		)
+		if err := ss.withPeer(false, func(peer *Peer) error {
+			resp, err := ss.request(peer, func(id uint64) error {
+				return peer.RequestStorageRanges(id, ss.root, batch, nil, nil, maxRequestSize)
+			}, ss.quit)
+			if err != nil {
+				return err
+			}
+			res := resp.(*StorageRangesPacket)
+			if len(res.Slots) == 0 {
+				return errNotServed
+			}
+			if len(res.Slots) > len(batch) {
+				return fmt.Errorf("storage of %d accounts received, %d requested", len(res.Slots), len(batch))
+			}
+			// Only the last range can be incomplete, so only it has the proof
+			for i, storage := range res.Slots {
+				var proof [][]byte
+				if i == len(res.Slots)-1 {
+					proof = res.Proof
+				}
+				if more, err = verifyStorageRange(roots[i], common.Hash{}, storage, proof); err != nil {
+					return err
+				}
+			}
+			slots = res.Slots
+			return nil
+		}); err != nil {
+			return err
+		}
+		for i, storage := range slots {
+			if err := writeStorage(tx, accountHashes[i], storage); err != nil {
+				return err
+			}
+		}
+		if more {
+			// The storage of the last account is too large, continue downloading it alone
+			last := len(slots) - 1
+			if err := ss.syncLargeStorage(tx, accountHashes[last], roots[last], slots[last][len(slots[last])-1].Hash); err != nil {
+				return err
+			}
+		}
+		accountHashes, roots = accountHashes[len(slots):], roots[len(slots):]
+	}
+	return nil
+}
+
+// syncLargeStorage downloads the storage of the single account, following the given slot
+func (ss *stateSync) syncLargeStorage(tx ethdb.Putter, accountHash common.Hash, root common.Hash, last common.Hash) error {
+	for {
+		n, ok := dbutils.NextSubtree(last[:])
+		if !ok {
+			return fmt.Errorf("storage range proof claims more slots after %x", last)
+		}
+		origin := common.BytesToHash(n)
+		var (
+			storage []*StorageData
+			more    bool
+		)
+		if err := ss.withPeer(false, func(peer *Peer) error {
+			resp, err := ss.request(peer, func(id uint64) error {
+				return peer.RequestStorageRanges(id, ss.root, []common.Hash{accountHash}, origin[:], maxHash[:], maxRequestSize)
+			}, ss.quit)
+			if err != nil {
+				return err
+			}
+			res := resp.(*StorageRangesPacket)
+			if len(res.Slots) == 0 {
+				return errNotServed
+			}
+			if len(res.Slots) > 1 {
+				return fmt.Errorf("storage of %d accounts received, 1 requested", len(res.Slots))
+			}
+			if more, err = verifyStorageRange(root, origin, res.Slots[0], res.Proof); err != nil {
+				return err
+			}
+			storage = res.Slots[0]
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := writeStorage(tx, accountHash, storage); err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+		last = storage[len(storage)-1].Hash
+	}
+}
+
+// verifyStorageRange checks the range of the storage slots against the storage root, returning
+// whether there are more slots after them
+func verifyStorageRange(root common.Hash, origin common.Hash, storage []*StorageData, proof [][]byte) (bool, error) {
+	keys := make([][]byte, len(storage))
+	values := make([][]byte, len(storage))
+	for i, slot := range storage {
+		keys[i], values[i] = slot.Hash[:], slot.Body
+	}
+	more, err := trie.VerifyRangeProof(root, origin[:], keys, values, proof)
+	if err != nil {
+		return false, err
+	}
+	if more && len(storage) == 0 {
+		return false, errors.New("empty storage range")
+	}
+	return more, nil
+}
+
+func writeStorage(tx ethdb.Putter, accountHash common.Hash, storage []*StorageData) error {
+	for _, slot := range storage {
+		kind, value, _, err := rlp.Split(slot.Body)
+		if err != nil {
+			return err
+		}
+		if kind == rlp.List {
+			return fmt.Errorf("invalid storage value %x", slot.Body)
+		}
+		if err = tx.Put(dbutils.CurrentStateBucket, dbutils.GenerateCompositeStorageKey(accountHash, state.FirstContractIncarnation, slot.Hash), common.CopyBytes(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCodes downloads the contract codes by their hashes
+func (ss *stateSync) syncCodes(tx ethdb.Putter, hashes []common.Hash) error {
+	for len(hashes) > 0 {
+		batch := hashes
+		if len(batch) > maxCodeRequestCount {
+			batch = batch[:maxCodeRequestCount]
+		}
+		received := make(map[common.Hash][]byte)
+		if err := ss.withPeer(false, func(peer *Peer) error {
+			resp, err := ss.request(peer, func(id uint64) error {
+				return peer.RequestByteCodes(id, batch, maxRequestSize)
+			}, ss.quit)
+			if err != nil {
+				return err
+			}
+			res := resp.(*ByteCodesPacket)
+			if len(res.Codes) == 0 {
+				return errNotServed
+			}
+			for _, code := range res.Codes {
+				received[crypto.Keccak256Hash(code)] = code
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		// Codes which were not requested are ignored, the missing ones are requested again
+		var missing []common.Hash
+		for _, hash := range batch {
+			code, ok := received[hash]
+			if !ok {
+				missing = append(missing, hash)
+				continue
+			}
+			if err := tx.Put(dbutils.CodeBucket, hash[:], code); err != nil {
+				return err
+			}
+		}
+		hashes = append(missing, hashes[len(batch):]...)
+	}
+	return nil
+}
+
+// syncPlainKeys downloads the plain state keys starting from the origin and fills the plain
+// state from the hashed state downloaded before
+func (ss *stateSync) syncPlainKeys(origin []byte) error {
+	for {
+		var res *PlainKeysPacket
+		if err := ss.withPeer(true, func(peer *Peer) error {
+			resp, err := ss.request(peer, func(id uint64) error {
+				return peer.RequestPlainKeys(id, ss.root, origin, maxRequestSize)
+			}, ss.quit)
+			if err != nil {
+				return err
+			}
+			res = resp.(*PlainKeysPacket)
+			if len(res.Keys) == 0 && !res.Last {
+				return errNotServed
+			}
+			for i, key := range res.Keys {
+				if len(key) != common.AddressLength && len(key) != common.AddressLength+common.IncarnationLength+common.HashLength {
+					return fmt.Errorf("invalid plain key %x", key)
+				}
+				if (i == 0 && bytes.Compare(key, origin) < 0) || (i > 0 && bytes.Compare(res.Keys[i-1], key) >= 0) {
+					return errors.New("plain keys are not in ascending order")
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := ss.writePlainKeys(res.Keys, res.Last); err != nil {
+			return err
+		}
+		if res.Last {
+			return nil
+		}
+		origin = append(common.CopyBytes(res.Keys[len(res.Keys)-1]), 0)
+	}
+}
+
+// writePlainKeys copies the state entries with the given plain keys from the hashed state. The keys
+// not found there are skipped, it is detected at the end by the different sizes of the plain and
+// the hashed state
+func (ss *stateSync) writePlainKeys(keys [][]byte, last bool) error {
+	tx, err := ss.db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, key := range keys {
+		if err = copyPlainState(tx, key); err != nil {
+			return err
+		}
+	}
+	if !last {
+		if err = tx.Put(dbutils.SyncStageProgress, cursorKey, append(common.CopyBytes(keys[len(keys)-1]), 0)); err != nil {
+			return err
+		}
+		_, err = tx.Commit()
+		return err
+	}
+
+	hashedCount, err := countEntries(tx, dbutils.CurrentStateBucket)
+	if err != nil {
+		return err
+	}
+	plainCount, err := countEntries(tx, dbutils.PlainStateBucket)
+	if err != nil {
+		return err
+	}
+	if hashedCount != plainCount {
+		// Some keys were withheld, start this phase over
+		tx.Rollback()
+		if err = resetPlainState(ss.db); err != nil {
+			return err
+		}
+		return fmt.Errorf("plain state is incomplete: %d entries, %d in the hashed state", plainCount, hashedCount)
+	}
+	if err = stages.SaveStageProgress(tx, phaseKey, donePhase); err != nil {
+		return err
+	}
+	if err = tx.Delete(dbutils.SyncStageProgress, cursorKey, nil); err != nil {
+		return err
+	}
+	_, err = tx.Commit()
+	return err
+}
+
+// copyPlainState copies the account or the storage slot from the hashed state into the plain state
+func copyPlainState(tx ethdb.Database, key []byte) error {
+	address := key[:common.AddressLength]
+	addrHash := crypto.Keccak256Hash(address)
+	if len(key) == common.AddressLength {
+		enc, err := tx.Get(dbutils.CurrentStateBucket, addrHash[:])
+		if errors.Is(err, ethdb.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var acc accounts.Account
+		if err = acc.DecodeForStorage(enc); err != nil {
+			return err
+		}
+		if err = tx.Put(dbutils.PlainStateBucket, address, common.CopyBytes(enc)); err != nil {
+			return err
+		}
+		if acc.IsEmptyCodeHash() {
+			return nil
+		}
+		return tx.Put(dbutils.PlainContractCodeBucket, dbutils.PlainGenerateStoragePrefix(address, acc.Incarnation), acc.CodeHash[:])
+	}
+	location := key[common.AddressLength+common.IncarnationLength:]
+	value, err := tx.Get(dbutils.CurrentStateBucket, dbutils.GenerateCompositeStorageKey(addrHash, state.FirstContractIncarnation, crypto.Keccak256Hash(location)))
+	if errors.Is(err, ethdb.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Put(dbutils.PlainStateBucket, dbutils.PlainGenerateCompositeStorageKey(address, state.FirstContractIncarnation, location), common.CopyBytes(value))
+}
+
+func countEntries(tx ethdb.Database, bucket string) (uint64, error) {
+	c := tx.(ethdb.HasTx).Tx().Cursor(bucket)
+	defer c.Close()
+	var count uint64
+	for k, _, err := c.First(); k != nil; k, _, err = c.Next() {
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// resetPlainState drops the plain state and starts the plain keys phase over
+func resetPlainState(db ethdb.Database) error {
+	tx, err := db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = tx.(ethdb.BucketsMigrator).ClearBuckets(dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket); err != nil {
+		return err
+	}
+	if err = tx.Delete(dbutils.SyncStageProgress, cursorKey, nil); err != nil {
+		return err
+	}
+	_, err = tx.Commit()
+	return err
+}
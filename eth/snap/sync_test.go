@@ -0,0 +1,145 @@
+package snap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/node"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/p2p/simulations"
+	"github.com/ledgerwatch/turbo-geth/p2p/simulations/adapters"
+)
+
+// newSyncNetwork starts the simulated network of the node serving the state from the given database
+// and the node with the given syncer, and waits until the syncer gets both kinds of peers
+func newSyncNetwork(t *testing.T, db ethdb.Database, syncer *Syncer) {
+	adapter := adapters.NewSimAdapter(adapters.LifecycleConstructors{
+		"snap-server": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			stack.RegisterProtocols(MakeProtocols(NewServer(db), nil))
+			return testLifecycle{}, nil
+		},
+		"snap-syncer": func(ctx *adapters.ServiceContext, stack *node.Node) (node.Lifecycle, error) {
+			stack.RegisterProtocols(MakeProtocols(nil, syncer))
+			return testLifecycle{}, nil
+		},
+	})
+	network := simulations.NewNetwork(adapter, &simulations.NetworkConfig{DefaultService: "snap-server"})
+	t.Cleanup(network.Shutdown)
+
+	var ids []enode.ID
+	for _, service := range []string{"snap-server", "snap-syncer"} {
+		conf := adapters.RandomNodeConfig()
+		conf.Lifecycles = []string{service}
+		n, err := network.NewNodeWithConfig(conf)
+		require.NoError(t, err)
+		require.NoError(t, network.Start(n.ID()))
+		ids = append(ids, n.ID())
+	}
+	require.NoError(t, network.Connect(ids[1], ids[0]))
+	require.Eventually(t, func() bool {
+		syncer.lock.Lock()
+		defer syncer.lock.Unlock()
+		return len(syncer.peers) > 0 && len(syncer.plainPeers) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func bucketContents(t *testing.T, db ethdb.Database, bucket string) map[string]string {
+	contents := make(map[string]string)
+	require.NoError(t, db.Walk(bucket, nil, 0, func(k, v []byte) (bool, error) {
+		contents[string(k)] = string(v)
+		return true, nil
+	}))
+	return contents
+}
+
+func TestSyncState(t *testing.T) {
+	serverDb := ethdb.NewMemDatabase()
+	defer serverDb.Close()
+	state := newTestState(t, serverDb, 300, 100)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	// The genesis state is replaced by the downloaded one
+	require.NoError(t, db.Put(dbutils.PlainStateBucket, []byte("genesis account  key"), []byte{1}))
+	for number, root := range []common.Hash{{}, state.root, {2}} {
+		header := &types.Header{Number: big.NewInt(int64(number)), Root: root, Difficulty: big.NewInt(1)}
+		rawdb.WriteHeader(context.Background(), db, header)
+		require.NoError(t, rawdb.WriteCanonicalHash(db, header.Hash(), uint64(number)))
+	}
+
+	syncer := NewSyncer()
+	newSyncNetwork(t, serverDb, syncer)
+	quit := make(chan struct{})
+	defer close(quit)
+
+	// The state of the block 2 is not served, so the block 1 is chosen
+	pivot, err := syncer.SyncState(db, 2, quit)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), pivot)
+	for _, bucket := range []string{
+		dbutils.CurrentStateBucket,
+		dbutils.PlainStateBucket,
+		dbutils.CodeBucket,
+		dbutils.ContractCodeBucket,
+		dbutils.PlainContractCodeBucket,
+	} {
+		assert.Equal(t, bucketContents(t, serverDb, bucket), bucketContents(t, db, bucket), bucket)
+	}
+
+	// Completed download is not repeated
+	pivot, err = syncer.SyncState(db, 2, quit)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), pivot)
+}
+
+func TestSyncStateResume(t *testing.T) {
+	serverDb := ethdb.NewMemDatabase()
+	defer serverDb.Close()
+	state := newTestState(t, serverDb, 500, 10)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	header := &types.Header{Number: big.NewInt(1), Root: state.root, Difficulty: big.NewInt(1)}
+	rawdb.WriteHeader(context.Background(), db, header)
+	require.NoError(t, rawdb.WriteCanonicalHash(db, header.Hash(), 1))
+
+	syncer := NewSyncer()
+	newSyncNetwork(t, serverDb, syncer)
+	quit := make(chan struct{})
+	defer close(quit)
+	_, err := syncer.SyncState(db, 1, quit)
+	require.NoError(t, err)
+
+	// Roll the download back to the middle of the accounts, as if it was interrupted there
+	cursor := state.contracts[len(state.contracts)/2]
+	var hashedKeys [][]byte
+	require.NoError(t, db.Walk(dbutils.CurrentStateBucket, cursor[:], 0, func(k, _ []byte) (bool, error) {
+		hashedKeys = append(hashedKeys, common.CopyBytes(k))
+		return true, nil
+	}))
+	require.NotEmpty(t, hashedKeys)
+	for _, k := range hashedKeys {
+		require.NoError(t, db.Delete(dbutils.CurrentStateBucket, k, nil))
+	}
+	require.NoError(t, resetPlainState(db))
+	require.NoError(t, stages.SaveStageProgress(db, phaseKey, accountsPhase))
+	require.NoError(t, db.Put(dbutils.SyncStageProgress, cursorKey, cursor[:]))
+
+	pivot, err := syncer.SyncState(db, 1, quit)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), pivot)
+	for _, bucket := range []string{dbutils.CurrentStateBucket, dbutils.PlainStateBucket, dbutils.PlainContractCodeBucket} {
+		assert.Equal(t, bucketContents(t, serverDb, bucket), bucketContents(t, db, bucket), bucket)
+	}
+}
@@ -90,13 +90,16 @@ func createStageBuilders(blocks []*types.Block, blockNum uint64, checkRoot bool)
 							world.chainConfig, world.chainContext, world.vmConfig,
 							world.QuitCh,
 							ExecuteBlockStageParams{
-								WriteReceipts:         world.storageMode.Receipts,
-								CacheSize:             world.cacheSize,
-								BatchSize:             world.batchSize,
-								ChangeSetHook:         world.changeSetHook,
-								ReaderBuilder:         world.stateReaderBuilder,
-								WriterBuilder:         world.stateWriterBuilder,
-								SilkwormExecutionFunc: world.silkwormExecutionFunc,
+								WriteReceipts:           world.storageMode.Receipts,
+								CacheSize:               world.cacheSize,
+								BatchSize:               world.batchSize,
+								ChangeSetHook:           world.changeSetHook,
+								ReaderBuilder:           world.stateReaderBuilder,
+								WriterBuilder:           world.stateWriterBuilder,
+								SilkwormExecutionFunc:   world.silkwormExecutionFunc,
+								AnalysisCache:           world.analysisCache,
+								ParallelExecution:       world.parallelExecution,
+								VerifyParallelExecution: world.verifyParallel,
 							})
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
@@ -284,12 +287,7 @@ func createStageBuilders(blocks []*types.Block, blockNum uint64, checkRoot bool)
 						return s.DoneAndUpdate(world.TX, executionAt)
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
-						var executionAt uint64
-						var err error
-						if executionAt, err = s.ExecutionAt(world.TX); err != nil {
-							return err
-						}
-						return s.DoneAndUpdate(world.TX, executionAt)
+						return u.Done(world.TX)
 					},
 				}
 			},
@@ -438,3 +436,40 @@ func InsertBlocksInStages(db ethdb.Database, storageMode ethdb.StorageMode, conf
 func InsertBlockInStages(db ethdb.Database, config *params.ChainConfig, vmConfig *vm.Config, engine consensus.Engine, block *types.Block, checkRoot bool) (bool, error) {
 	return InsertBlocksInStages(db, ethdb.DefaultStorageMode, config, vmConfig, engine, []*types.Block{block}, checkRoot)
 }
+
+// MineBlockInStages produces a block on top of the executed head of the chain, see MiningStages. All the changes
+// made by the stages are discarded, the produced block is left in cfg.Block and, once sealed, sent to cfg.SealResults.
+// The sealing reads the chain from db
+func MineBlockInStages(db ethdb.Database, config *params.ChainConfig, vmConfig *vm.Config, engine consensus.Engine, txPool *core.TxPool, cfg *MiningCfg, quit <-chan struct{}) error {
+	tx, err := db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return fmt.Errorf("starting transaction for mining: %v", err)
+	}
+	defer tx.Rollback()
+	cc := &core.TinyChainContext{}
+	cc.SetDB(tx)
+	cc.SetEngine(engine)
+	stagedSync := New(MiningStages(cfg), MiningUnwindOrder(), OptionalParameters{})
+	syncState, err := stagedSync.Prepare(
+		nil,
+		config,
+		cc,
+		vmConfig,
+		db,
+		tx,
+		"",
+		ethdb.DefaultStorageMode,
+		"",
+		0,
+		0,
+		quit,
+		nil,
+		txPool,
+		nil,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	return syncState.Run(tx, tx)
+}
@@ -50,6 +50,12 @@ type ExecuteBlockStageParams struct {
 	ReaderBuilder         StateReaderBuilder
 	WriterBuilder         StateWriterBuilder
 	SilkwormExecutionFunc unsafe.Pointer
+	AnalysisCache         *core.AnalysisCache // not setting this param means JUMPDEST analysis is done per transaction
+	// ParallelExecution is the number of workers speculatively executing transactions of a block, experimental.
+	// Not setting this param means sequential execution
+	ParallelExecution int
+	// VerifyParallelExecution executes every block sequentially as well and fails on any difference in the results
+	VerifyParallelExecution bool
 }
 
 func readBlock(blockNum uint64, tx ethdb.Database) (*types.Block, error) {
@@ -92,7 +98,16 @@ func executeBlockWithGo(block *types.Block, tx ethdb.DbWithPendingMutations, cac
 	engine := chainContext.Engine()
 
 	// where the magic happens
-	receipts, err := core.ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter)
+	var receipts types.Receipts
+	var err error
+	switch {
+	case params.VerifyParallelExecution:
+		receipts, err = core.VerifyParallelExecution(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter, params.ParallelExecution)
+	case params.ParallelExecution > 0:
+		receipts, err = core.ExecuteBlockParallel(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter, params.ParallelExecution)
+	default:
+		receipts, err = core.ExecuteBlockEphemerally(chainConfig, vmConfig, chainContext, engine, block, stateReader, stateWriter)
+	}
 	if err != nil {
 		return err
 	}
@@ -164,6 +179,12 @@ func SpawnExecuteBlocksStage(s *StageState, stateDB ethdb.Database, chainConfig
 
 	chainContext.SetDB(tx)
 
+	if params.AnalysisCache != nil && !useSilkworm {
+		cfg := *vmConfig
+		cfg.JumpDestCache = params.AnalysisCache.View(batch, batch)
+		vmConfig = &cfg
+	}
+
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 	stageProgress := s.BlockNumber
@@ -32,6 +32,11 @@ type ChainReader struct {
 	db     ethdb.Database
 }
 
+// NewChainReader returns the ChainReader over the headers of the database or a transaction
+func NewChainReader(config *params.ChainConfig, db ethdb.Database) ChainReader {
+	return ChainReader{config: config, db: db}
+}
+
 // Config retrieves the blockchain's chain configuration.
 func (cr ChainReader) Config() *params.ChainConfig {
 	return cr.config
@@ -41,6 +46,9 @@ func (cr ChainReader) Config() *params.ChainConfig {
 func (cr ChainReader) CurrentHeader() *types.Header {
 	hash := rawdb.ReadHeadHeaderHash(cr.db)
 	number := rawdb.ReadHeaderNumber(cr.db, hash)
+	if number == nil {
+		return nil
+	}
 	return rawdb.ReadHeader(cr.db, hash, *number)
 }
 
@@ -62,6 +70,9 @@ func (cr ChainReader) GetHeaderByNumber(number uint64) *types.Header {
 // GetHeaderByHash retrieves a block header from the database by its hash.
 func (cr ChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
 	number := rawdb.ReadHeaderNumber(cr.db, hash)
+	if number == nil {
+		return nil
+	}
 	return rawdb.ReadHeader(cr.db, hash, *number)
 }
 
@@ -60,7 +60,7 @@ func SpawnIntermediateHashesStage(s *StageState, db ethdb.Database, checkRoot bo
 			return err
 		}
 	} else {
-		if err := incrementIntermediateHashes(logPrefix, s, tx, to, checkRoot, tmpdir, expectedRootHash, quit); err != nil {
+		if _, err := incrementIntermediateHashes(logPrefix, s, tx, to, checkRoot, tmpdir, expectedRootHash, quit); err != nil {
 			return err
 		}
 	}
@@ -78,16 +78,43 @@ func SpawnIntermediateHashesStage(s *StageState, db ethdb.Database, checkRoot bo
 	return nil
 }
 
+// SpawnMiningIntermediateHashesStage computes the state root of the block being mined. Unlike in the
+// IntermediateHashes stage, there is no root in the header to check against: the root is set into the header
+func SpawnMiningIntermediateHashesStage(s *StageState, db ethdb.Database, current *MiningBlock, tmpdir string, quit <-chan struct{}) error {
+	to, err := s.ExecutionAt(db)
+	if err != nil {
+		return err
+	}
+	logPrefix := s.state.LogPrefix()
+	var root common.Hash
+	if s.BlockNumber == 0 {
+		root, err = regenerateIntermediateHashes(logPrefix, db, false /* checkRoot */, tmpdir, common.Hash{}, quit)
+	} else {
+		root, err = incrementIntermediateHashes(logPrefix, s, db, to, false /* checkRoot */, tmpdir, common.Hash{}, quit)
+	}
+	if err != nil {
+		return err
+	}
+	current.Header.Root = root
+	return s.DoneAndUpdate(db, to)
+}
+
 func RegenerateIntermediateHashes(logPrefix string, db ethdb.Database, checkRoot bool, tmpdir string, expectedRootHash common.Hash, quit <-chan struct{}) error {
+	_, err := regenerateIntermediateHashes(logPrefix, db, checkRoot, tmpdir, expectedRootHash, quit)
+	return err
+}
+
+// regenerateIntermediateHashes rebuilds the intermediate hashes from scratch and returns the state root
+func regenerateIntermediateHashes(logPrefix string, db ethdb.Database, checkRoot bool, tmpdir string, expectedRootHash common.Hash, quit <-chan struct{}) (common.Hash, error) {
 	log.Info(fmt.Sprintf("[%s] Regeneration intermediate hashes started", logPrefix))
 	// Clear IH bucket
 	c := db.(ethdb.HasTx).Tx().Cursor(dbutils.IntermediateTrieHashBucket)
 	for k, v, err := c.First(); k != nil; k, v, err = c.Next() {
 		if err != nil {
-			return err
+			return common.Hash{}, err
 		}
 		if err = c.Delete(k, v); err != nil {
-			return err
+			return common.Hash{}, err
 		}
 	}
 	c.Close()
@@ -106,29 +133,29 @@ func RegenerateIntermediateHashes(logPrefix string, db ethdb.Database, checkRoot
 	}
 	loader := trie.NewFlatDBTrieLoader(logPrefix, dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
 	if err := loader.Reset(trie.NewRetainList(0), hashCollector /* HashCollector */, false); err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	t := time.Now()
-	if hash, err := loader.CalcTrieRoot(db, quit); err == nil {
-		generationIHTook := time.Since(t)
-		if checkRoot && hash != expectedRootHash {
-			return fmt.Errorf("%s: wrong trie root: %x, expected (from header): %x", logPrefix, hash, expectedRootHash)
-		}
-		log.Debug("Collection finished",
-			"root hash", hash.Hex(),
-			"gen IH", generationIHTook,
-		)
-	} else {
-		return err
+	hash, err := loader.CalcTrieRoot(db, quit)
+	if err != nil {
+		return common.Hash{}, err
 	}
+	generationIHTook := time.Since(t)
+	if checkRoot && hash != expectedRootHash {
+		return common.Hash{}, fmt.Errorf("%s: wrong trie root: %x, expected (from header): %x", logPrefix, hash, expectedRootHash)
+	}
+	log.Debug("Collection finished",
+		"root hash", hash.Hex(),
+		"gen IH", generationIHTook,
+	)
 	if err := collector.Load(logPrefix, db, dbutils.IntermediateTrieHashBucket, etl.IdentityLoadFunc, etl.TransformArgs{
 		Quit:       quit,
 		Comparator: comparator,
 	}); err != nil {
-		return fmt.Errorf("%s: fail load data to bucket: %w", logPrefix, err)
+		return common.Hash{}, fmt.Errorf("%s: fail load data to bucket: %w", logPrefix, err)
 	}
 	log.Info(fmt.Sprintf("[%s] Regeneration ended", logPrefix))
-	return nil
+	return hash, nil
 }
 
 type HashPromoter struct {
@@ -234,7 +261,7 @@ func (p *HashPromoter) Unwind(logPrefix string, s *StageState, u *UnwindState, s
 	return nil
 }
 
-func incrementIntermediateHashes(logPrefix string, s *StageState, db ethdb.Database, to uint64, checkRoot bool, tmpdir string, expectedRootHash common.Hash, quit <-chan struct{}) error {
+func incrementIntermediateHashes(logPrefix string, s *StageState, db ethdb.Database, to uint64, checkRoot bool, tmpdir string, expectedRootHash common.Hash, quit <-chan struct{}) (common.Hash, error) {
 	p := NewHashPromoter(db, quit)
 	p.TempDir = tmpdir
 	var exclude [][]byte
@@ -246,10 +273,10 @@ func incrementIntermediateHashes(logPrefix string, s *StageState, db ethdb.Datab
 	}
 
 	if err := p.Promote(logPrefix, s, s.BlockNumber, to, false /* storage */, collect); err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	if err := p.Promote(logPrefix, s, s.BlockNumber, to, true /* storage */, collect); err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	sort.Slice(exclude, func(i, j int) bool { return bytes.Compare(exclude[i], exclude[j]) < 0 })
 	unfurl := trie.NewRetainList(0)
@@ -274,16 +301,16 @@ func incrementIntermediateHashes(logPrefix string, s *StageState, db ethdb.Datab
 	loader := trie.NewFlatDBTrieLoader(logPrefix, dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
 	// hashCollector in the line below will collect deletes
 	if err := loader.Reset(unfurl, hashCollector, false); err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	t := time.Now()
 	hash, err := loader.CalcTrieRoot(db, quit)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 	generationIHTook := time.Since(t)
 	if checkRoot && hash != expectedRootHash {
-		return fmt.Errorf("%s: wrong trie root: %x, expected (from header): %x", logPrefix, hash, expectedRootHash)
+		return common.Hash{}, fmt.Errorf("%s: wrong trie root: %x, expected (from header): %x", logPrefix, hash, expectedRootHash)
 	}
 	log.Info(fmt.Sprintf("[%s] Collection finished", logPrefix),
 		"root hash", hash.Hex(),
@@ -297,9 +324,9 @@ func incrementIntermediateHashes(logPrefix string, s *StageState, db ethdb.Datab
 			Comparator: comparator,
 		},
 	); err != nil {
-		return err
+		return common.Hash{}, err
 	}
-	return nil
+	return hash, nil
 }
 
 func UnwindIntermediateHashesStage(u *UnwindState, s *StageState, db ethdb.Database, tmpdir string, quit <-chan struct{}) error {
@@ -0,0 +1,134 @@
+package stagedsync
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// MiningBlock is the block being produced, passed from one mining stage to the next
+type MiningBlock struct {
+	Header   *types.Header
+	Uncles   []*types.Header
+	Txs      []*types.Transaction
+	Receipts types.Receipts
+
+	// The pending transactions of the pool, the local ones are included first
+	LocalTxs  types.TransactionsStream
+	RemoteTxs types.TransactionsStream
+}
+
+// Block assembles the block from the header and the body produced so far
+func (mb *MiningBlock) Block() *types.Block {
+	return types.NewBlockWithHeader(mb.Header).WithBody(mb.Txs, mb.Uncles)
+}
+
+// MiningCfg is the input and the output of one run of the mining stages
+type MiningCfg struct {
+	Etherbase common.Address // Receives the fees and the rewards of the block
+	ExtraData []byte
+	GasFloor  uint64 // Target gas floor of the block
+	GasCeil   uint64 // Target gas ceiling of the block
+	Timestamp uint64 // Time of the block, moved one second past the parent if it is not later
+
+	// Interrupt, if set, is checked before every transaction. Once it returns true, no more transactions
+	// are included and the block is assembled from the ones included so far
+	Interrupt func() bool
+
+	// PendingState, if set, receives the state changes of the block as well. Unlike the changes made
+	// by the stages, they are not discarded after the run
+	PendingState ethdb.Database
+
+	// Block is the block being produced. The uncles to include can be set before the run
+	Block MiningBlock
+
+	// SealResults receives the sealed block. If it is nil, the block is only assembled, not sealed,
+	// which is enough for the pending block
+	SealResults chan<- consensus.ResultWithContext
+	// SealCancel aborts the sealing
+	SealCancel consensus.Cancel
+	// BeforeSeal, if set, is called with the assembled block. The block is not sealed if it returns false
+	BeforeSeal func(current *MiningBlock) bool
+}
+
+// SpawnMiningCreateBlockStage prepares the header of the block on top of the executed head of the chain,
+// and orders the pending transactions of the pool to be included into the block
+func SpawnMiningCreateBlockStage(s *StageState, tx ethdb.Database, cfg *MiningCfg, chainConfig *params.ChainConfig, engine consensus.Engine, txPool *core.TxPool) error {
+	logPrefix := s.state.LogPrefix()
+	executionAt, err := s.ExecutionAt(tx)
+	if err != nil {
+		return fmt.Errorf("%s: getting last executed block: %w", logPrefix, err)
+	}
+	parentHash, err := rawdb.ReadCanonicalHash(tx, executionAt)
+	if err != nil {
+		return err
+	}
+	parent := rawdb.ReadHeader(tx, parentHash, executionAt)
+	if parent == nil {
+		return fmt.Errorf("%s: header of the last executed block %d not found", logPrefix, executionAt)
+	}
+
+	timestamp := cfg.Timestamp
+	if parent.Time >= timestamp {
+		timestamp = parent.Time + 1
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   core.CalcGasLimit(types.NewBlockWithHeader(parent), cfg.GasFloor, cfg.GasCeil),
+		Extra:      cfg.ExtraData,
+		Time:       timestamp,
+		Coinbase:   cfg.Etherbase,
+	}
+	if err = engine.Prepare(ChainReader{chainConfig, tx}, header); err != nil {
+		return fmt.Errorf("%s: preparing header %d: %w", logPrefix, header.Number, err)
+	}
+
+	// If we are care about TheDAO hard-fork check whether to override the extra-data or not
+	if daoBlock := chainConfig.DAOForkBlock; daoBlock != nil {
+		// Check whether the block is among the fork extra-override range
+		limit := new(big.Int).Add(daoBlock, params.DAOForkExtraRange)
+		if header.Number.Cmp(daoBlock) >= 0 && header.Number.Cmp(limit) < 0 {
+			// Depending whether we support or oppose the fork, override differently
+			if chainConfig.DAOForkSupport {
+				header.Extra = common.CopyBytes(params.DAOForkBlockExtra)
+			} else if bytes.Equal(header.Extra, params.DAOForkBlockExtra) {
+				header.Extra = []byte{} // If miner opposes, don't let it use the reserved extra-data
+			}
+		}
+	}
+
+	current := &cfg.Block
+	current.Header = header
+	current.Txs, current.Receipts = nil, nil
+	current.LocalTxs, current.RemoteTxs = nil, nil
+	if txPool != nil {
+		pending, err := txPool.Pending()
+		if err != nil {
+			return fmt.Errorf("%s: fetching pending transactions: %w", logPrefix, err)
+		}
+		// Split the pending transactions into locals and remotes
+		signer := types.NewEIP155Signer(chainConfig.ChainID)
+		localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
+		for _, account := range txPool.Locals() {
+			if txs := remoteTxs[account]; len(txs) > 0 {
+				delete(remoteTxs, account)
+				localTxs[account] = txs
+			}
+		}
+		current.LocalTxs = txPool.Order(signer, localTxs)
+		current.RemoteTxs = txPool.Order(signer, remoteTxs)
+	}
+	log.Info(fmt.Sprintf("[%s] Start mining", logPrefix), "number", header.Number, "parent", parent.Hash())
+	s.Done()
+	return nil
+}
@@ -0,0 +1,144 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus/misc"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// SpawnMiningExecStage executes the pending transactions into the block being mined, as long as they fit,
+// and writes the resulting state over the plain state, together with the changesets of the block.
+// The execution progress is moved to the new block, so the hashing stages pick it up
+func SpawnMiningExecStage(s *StageState, tx ethdb.Database, cfg *MiningCfg, chainConfig *params.ChainConfig, vmConfig *vm.Config, cc *core.TinyChainContext, quit <-chan struct{}) error {
+	logPrefix := s.state.LogPrefix()
+	current := &cfg.Block
+	header := current.Header
+	blockNum := header.Number.Uint64()
+
+	ibs := state.New(state.NewPlainStateReader(tx))
+	stateWriter := state.NewPlainStateWriter(tx, tx, blockNum)
+	if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
+		misc.ApplyDAOHardFork(ibs)
+	}
+
+	gasPool := new(core.GasPool).AddGas(header.GasLimit)
+	for _, txs := range []types.TransactionsStream{current.LocalTxs, current.RemoteTxs} {
+		if txs == nil {
+			continue
+		}
+		if err := addTransactionsToMiningBlock(current, txs, cfg.Etherbase, gasPool, ibs, chainConfig, vmConfig, cc, cfg.Interrupt, quit); err != nil {
+			return fmt.Errorf("%s: %w", logPrefix, err)
+		}
+	}
+
+	// Apply the block rewards and fill in the hashes of the body
+	block, err := cc.Engine().FinalizeAndAssemble(chainConfig, header, ibs, current.Txs, current.Uncles, current.Receipts)
+	if err != nil {
+		return fmt.Errorf("%s: finalizing block %d: %w", logPrefix, blockNum, err)
+	}
+	current.Header = block.Header()
+	current.Uncles = block.Uncles()
+
+	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
+	if err = ibs.CommitBlock(ctx, stateWriter); err != nil {
+		return fmt.Errorf("%s: committing block %d failed: %w", logPrefix, blockNum, err)
+	}
+	if cfg.PendingState != nil {
+		if err = ibs.CommitBlock(ctx, state.NewPlainStateWriter(cfg.PendingState, nil, blockNum)); err != nil {
+			return fmt.Errorf("%s: committing pending state of block %d failed: %w", logPrefix, blockNum, err)
+		}
+	}
+	if err = stateWriter.WriteChangeSets(); err != nil {
+		return fmt.Errorf("%s: writing changesets for block %d failed: %w", logPrefix, blockNum, err)
+	}
+	if err = stages.SaveStageProgress(tx, stages.Execution, blockNum); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[%s] Block executed", logPrefix), "number", blockNum, "txs", len(current.Txs), "gas", current.Header.GasUsed)
+	s.Done()
+	return nil
+}
+
+// addTransactionsToMiningBlock executes the transactions in the order of the pool policy, skipping
+// the ones that cannot be included, until the block runs out of gas or the mining is interrupted
+func addTransactionsToMiningBlock(current *MiningBlock, txs types.TransactionsStream, coinbase common.Address, gasPool *core.GasPool, ibs *state.IntraBlockState, chainConfig *params.ChainConfig, vmConfig *vm.Config, cc *core.TinyChainContext, interrupt func() bool, quit <-chan struct{}) error {
+	header := current.Header
+	signer := types.NewEIP155Signer(chainConfig.ChainID)
+	noop := state.NewNoopWriter()
+	for {
+		if err := common.Stopped(quit); err != nil {
+			return err
+		}
+		if interrupt != nil && interrupt() {
+			log.Trace("Mining interrupted", "number", header.Number, "txs", len(current.Txs))
+			return nil
+		}
+		// If we don't have enough gas for any further transactions then we're done
+		if gasPool.Gas() < params.TxGas {
+			log.Trace("Not enough gas for further transactions", "have", gasPool, "want", params.TxGas)
+			return nil
+		}
+		// Retrieve the next transaction and abort if all done
+		txn := txs.Peek()
+		if txn == nil {
+			return nil
+		}
+		// Error may be ignored here. The error has already been checked
+		// during transaction acceptance is the transaction pool.
+		//
+		// We use the eip155 signer regardless of the current hf.
+		from, _ := types.Sender(signer, txn)
+		// Check whether the tx is replay protected. If we're not in the EIP155 hf
+		// phase, start ignoring the sender until we do.
+		if txn.Protected() && !chainConfig.IsEIP155(header.Number) {
+			log.Trace("Ignoring reply protected transaction", "hash", txn.Hash(), "eip155", chainConfig.EIP155Block)
+			txs.Pop()
+			continue
+		}
+		// Start executing the transaction
+		ibs.Prepare(txn.Hash(), common.Hash{}, len(current.Txs))
+		snap := ibs.Snapshot()
+		receipt, err := core.ApplyTransaction(chainConfig, cc, &coinbase, gasPool, ibs, noop, header, txn, &header.GasUsed, *vmConfig)
+		if err != nil {
+			ibs.RevertToSnapshot(snap)
+		}
+		switch err {
+		case core.ErrGasLimitReached:
+			// Pop the current out-of-gas transaction without shifting in the next from the account
+			log.Trace("Gas limit exceeded for current block", "sender", from)
+			txs.Pop()
+
+		case core.ErrNonceTooLow:
+			// New head notification data race between the transaction pool and miner, shift
+			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", txn.Nonce())
+			txs.Shift()
+
+		case core.ErrNonceTooHigh:
+			// Reorg notification data race between the transaction pool and miner, skip account =
+			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", txn.Nonce())
+			txs.Pop()
+
+		case nil:
+			// Everything ok, collect the receipt and shift in the next transaction from the same account
+			current.Txs = append(current.Txs, txn)
+			current.Receipts = append(current.Receipts, receipt)
+			txs.Shift()
+
+		default:
+			// Strange error, discard the transaction and get the next in line (note, the
+			// nonce-too-high clause will prevent us from executing in vain).
+			log.Debug("Transaction failed, account skipped", "hash", txn.Hash(), "err", err)
+			txs.Shift()
+		}
+	}
+}
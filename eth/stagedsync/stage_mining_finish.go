@@ -0,0 +1,33 @@
+package stagedsync
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+// SpawnMiningFinishStage hands the assembled block over to the consensus engine for sealing. The sealing
+// goes on in the background, the sealed block is sent to cfg.SealResults. The engine reads the chain from
+// chainDb, as the transaction of the stages does not outlive the run
+func SpawnMiningFinishStage(s *StageState, chainDb ethdb.Database, cfg *MiningCfg, chainConfig *params.ChainConfig, engine consensus.Engine) error {
+	logPrefix := s.state.LogPrefix()
+	if cfg.SealResults == nil {
+		s.Done()
+		return nil
+	}
+	if cfg.BeforeSeal != nil && !cfg.BeforeSeal(&cfg.Block) {
+		s.Done()
+		return nil
+	}
+	block := cfg.Block.Block()
+	log.Info(fmt.Sprintf("[%s] Start sealing", logPrefix), "number", block.Number(), "sealhash", engine.SealHash(block.Header()),
+		"uncles", len(block.Uncles()), "txs", len(block.Transactions()), "gas", block.GasUsed())
+	if err := engine.Seal(cfg.SealCancel, ChainReader{chainConfig, chainDb}, block, cfg.SealResults, cfg.SealCancel.Done()); err != nil {
+		return fmt.Errorf("%s: sealing block %d: %w", logPrefix, block.NumberU64(), err)
+	}
+	s.Done()
+	return nil
+}
@@ -0,0 +1,93 @@
+package stagedsync
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/ethash"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+)
+
+func TestMineBlockInStages(t *testing.T) {
+	var (
+		db          = ethdb.NewMemDatabase()
+		key, _      = crypto.GenerateKey()
+		address     = crypto.PubkeyToAddress(key.PublicKey)
+		recipient   = common.Address{1}
+		etherbase   = common.Address{2}
+		chainConfig = params.AllEthashProtocolChanges
+		engine      = ethash.NewFaker()
+		gspec       = &core.Genesis{Config: chainConfig, Alloc: core.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}}}
+	)
+	defer db.Close()
+	genesis := gspec.MustCommit(db)
+
+	txCacher := core.NewTxSenderCacher(1)
+	defer txCacher.Close()
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+	txPool := core.NewTxPool(poolConfig, chainConfig, db, txCacher)
+	if err := txPool.Start(genesis.GasLimit(), 0); err != nil {
+		t.Fatalf("start pool: %v", err)
+	}
+	defer txPool.Stop()
+	signer := types.NewEIP155Signer(chainConfig.ChainID)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, err := types.SignTx(types.NewTransaction(nonce, recipient, uint256.NewInt().SetUint64(1000), params.TxGas, uint256.NewInt().SetUint64(1), nil), signer, key)
+		if err != nil {
+			t.Fatalf("sign transaction: %v", err)
+		}
+		if err = txPool.AddLocal(tx); err != nil {
+			t.Fatalf("add transaction: %v", err)
+		}
+	}
+
+	// The first block is mined over the state regenerated from scratch, the second one incrementally
+	for number, txCount := range []int{2, 0} {
+		results := make(chan consensus.ResultWithContext, 1)
+		pendingState := db.NewBatch()
+		cfg := &MiningCfg{
+			Etherbase:    etherbase,
+			GasFloor:     genesis.GasLimit(),
+			GasCeil:      genesis.GasLimit(),
+			PendingState: pendingState,
+			SealResults:  results,
+			SealCancel:   consensus.NewCancel(),
+		}
+		if err := MineBlockInStages(db, chainConfig, &vm.Config{}, engine, txPool, cfg, nil); err != nil {
+			t.Fatalf("mine block %d: %v", number+1, err)
+		}
+		// Nothing is left behind by the mining stages
+		if executionAt, err := stages.GetStageProgress(db, stages.Execution); err != nil || executionAt != uint64(number) {
+			t.Fatalf("execution progress after mining block %d: %d, %v", number+1, executionAt, err)
+		}
+		// The state of the mined block is kept aside
+		if balance := state.New(state.NewPlainStateReader(pendingState)).GetBalance(recipient); balance.Uint64() != 2000 {
+			t.Fatalf("wrong pending balance of the recipient after mining block %d: %d", number+1, balance)
+		}
+		block := (<-results).Block
+		if block.NumberU64() != uint64(number+1) || len(block.Transactions()) != txCount || block.Coinbase() != etherbase {
+			t.Fatalf("wrong block mined: number %d, %d txs, coinbase %x", block.NumberU64(), len(block.Transactions()), block.Coinbase())
+		}
+		// The stages importing the block check its state root
+		if _, err := InsertBlockInStages(db, chainConfig, &vm.Config{}, engine, block, true /* checkRoot */); err != nil {
+			t.Fatalf("import block %d: %v", number+1, err)
+		}
+	}
+
+	account, err := state.NewPlainStateReader(db).ReadAccountData(recipient)
+	if err != nil || account == nil || account.Balance.Uint64() != 2000 {
+		t.Errorf("wrong balance of the recipient: %v, %v", account, err)
+	}
+}
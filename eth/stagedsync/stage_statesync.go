@@ -0,0 +1,95 @@
+package stagedsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// StateSyncer downloads the state of a recent block instead of executing all the blocks up to it
+type StateSyncer interface {
+	// SyncState downloads the state of one of the blocks not higher than `to` into the hashed and the plain
+	// state, and returns the number of this block. Zero means that the state was not downloaded and the blocks
+	// have to be executed from genesis
+	SyncState(db ethdb.Database, to uint64, quit <-chan struct{}) (uint64, error)
+}
+
+// stagesAfterStateSync are the stages which are considered done up to the block the state is downloaded for
+var stagesAfterStateSync = []stages.SyncStage{
+	stages.Senders,
+	stages.Execution,
+	stages.HashState,
+	stages.IntermediateHashes,
+	stages.AccountHistoryIndex,
+	stages.StorageHistoryIndex,
+	stages.LogIndex,
+	stages.CallTraces,
+}
+
+// SpawnStateSyncStage downloads the state of a recent block when the node starts from scratch. Then the
+// intermediate hashes are generated and checked against the state root, and the stages up to the intermediate
+// hashes are moved to this block, so the sync continues from it. The blocks before it are never executed,
+// so there are no receipts, history and senders for them.
+func SpawnStateSyncStage(s *StageState, db ethdb.Database, syncer StateSyncer, tmpdir string, quit <-chan struct{}) error {
+	executionAt, err := s.ExecutionAt(db)
+	if err != nil {
+		return err
+	}
+	if s.BlockNumber > 0 || executionAt > 0 {
+		// The state is either downloaded already or built by the execution
+		s.Done()
+		return nil
+	}
+	to, err := stages.GetStageProgress(db, stages.Bodies)
+	if err != nil {
+		return err
+	}
+	logPrefix := s.state.LogPrefix()
+	pivot, err := syncer.SyncState(db, to, quit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logPrefix, err)
+	}
+	if pivot == 0 {
+		s.Done()
+		return nil
+	}
+
+	hash, err := rawdb.ReadCanonicalHash(db, pivot)
+	if err != nil {
+		return err
+	}
+	header := rawdb.ReadHeader(db, hash, pivot)
+	if header == nil {
+		return fmt.Errorf("%s: header %d not found", logPrefix, pivot)
+	}
+	tx, err := db.Begin(context.Background(), ethdb.RW)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = RegenerateIntermediateHashes(logPrefix, tx, true /* checkRoot */, tmpdir, header.Root, quit); err != nil {
+		return err
+	}
+	for _, stage := range stagesAfterStateSync {
+		if err = stages.SaveStageProgress(tx, stage, pivot); err != nil {
+			return err
+		}
+	}
+	if err = s.DoneAndUpdate(tx, pivot); err != nil {
+		return err
+	}
+	if _, err = tx.Commit(); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[%s] State is downloaded", logPrefix), "block", pivot, "root", header.Root)
+	return nil
+}
+
+// UnwindStateSyncStage fails, because the history of the state before the downloaded one is not available
+func UnwindStateSyncStage(u *UnwindState, s *StageState) error {
+	return fmt.Errorf("%s: cannot unwind to block %d, the state was downloaded for block %d", s.state.LogPrefix(), u.UnwindPoint, s.BlockNumber)
+}
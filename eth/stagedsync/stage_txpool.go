@@ -43,6 +43,11 @@ func spawnTxPool(s *StageState, db ethdb.Database, pool *core.TxPool, poolStart
 		pending, queued := pool.Stats()
 		log.Info(fmt.Sprintf("[%s] Transaction stats", logPrefix), "pending", pending, "queued", queued)
 	}
+	if pool != nil && pool.IsStarted() {
+		if err := pool.Save(db); err != nil {
+			return fmt.Errorf("%s: %w", logPrefix, err)
+		}
+	}
 	return s.DoneAndUpdate(db, to)
 }
 
@@ -119,6 +124,10 @@ func unwindTxPool(u *UnwindState, s *StageState, db ethdb.Database, pool *core.T
 		}
 		pending, queued := pool.Stats()
 		log.Info(fmt.Sprintf("[%s] Transaction stats", logPrefix), "pending", pending, "queued", queued)
+		// The transactions of the unwound blocks are back in the pool, keep the stored content in line
+		if err := pool.Save(db); err != nil {
+			return fmt.Errorf("%s: %w", logPrefix, err)
+		}
 	}
 	if err := u.Done(db); err != nil {
 		return fmt.Errorf("%s: reset: %w", logPrefix, err)
@@ -18,6 +18,8 @@ import (
 
 type ChainEventNotifier interface {
 	OnNewHeader(*types.Header)
+	// OnUnwind is called when an unwind of all the stages to the given block is scheduled
+	OnUnwind(uint64)
 }
 
 // StageParameters contains the stage that stages receives at runtime when initializes.
@@ -47,7 +49,11 @@ type StageParameters struct {
 	stateReaderBuilder    StateReaderBuilder
 	stateWriterBuilder    StateWriterBuilder
 	notifier              ChainEventNotifier
+	stateSyncer           StateSyncer
 	silkwormExecutionFunc unsafe.Pointer
+	analysisCache         *core.AnalysisCache
+	parallelExecution     int
+	verifyParallel        bool
 }
 
 // StageBuilder represent an object to create a single stage for staged sync
@@ -141,6 +147,23 @@ func DefaultStages() StageBuilders {
 				}
 			},
 		},
+		{
+			ID: stages.StateSync,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:                  stages.StateSync,
+					Description:         "Download the state of a recent block",
+					Disabled:            world.stateSyncer == nil,
+					DisabledDescription: "Enable by --snap.sync",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnStateSyncStage(s, world.db, world.stateSyncer, world.tmpdir, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return UnwindStateSyncStage(u, s)
+					},
+				}
+			},
+		},
 		{
 			ID: stages.Senders,
 			Build: func(world StageParameters) *Stage {
@@ -179,13 +202,16 @@ func DefaultStages() StageBuilders {
 							world.chainConfig, world.chainContext, world.vmConfig,
 							world.QuitCh,
 							ExecuteBlockStageParams{
-								WriteReceipts:         world.storageMode.Receipts,
-								CacheSize:             world.cacheSize,
-								BatchSize:             world.batchSize,
-								ChangeSetHook:         world.changeSetHook,
-								ReaderBuilder:         world.stateReaderBuilder,
-								WriterBuilder:         world.stateWriterBuilder,
-								SilkwormExecutionFunc: world.silkwormExecutionFunc,
+								WriteReceipts:           world.storageMode.Receipts,
+								CacheSize:               world.cacheSize,
+								BatchSize:               world.batchSize,
+								ChangeSetHook:           world.changeSetHook,
+								ReaderBuilder:           world.stateReaderBuilder,
+								WriterBuilder:           world.stateWriterBuilder,
+								SilkwormExecutionFunc:   world.silkwormExecutionFunc,
+								AnalysisCache:           world.analysisCache,
+								ParallelExecution:       world.parallelExecution,
+								VerifyParallelExecution: world.verifyParallel,
 							})
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
@@ -355,12 +381,7 @@ func DefaultStages() StageBuilders {
 						return s.DoneAndUpdate(world.TX, executionAt)
 					},
 					UnwindFunc: func(u *UnwindState, s *StageState) error {
-						var executionAt uint64
-						var err error
-						if executionAt, err = s.ExecutionAt(world.TX); err != nil {
-							return err
-						}
-						return s.DoneAndUpdate(world.TX, executionAt)
+						return u.Done(world.TX)
 					},
 				}
 			},
@@ -382,10 +403,101 @@ func DefaultUnwindOrder() UnwindOrder {
 		0, 1, 2,
 		// Unwinding of tx pool (reinjecting transactions into the pool needs to happen after unwinding execution)
 		// also tx pool is before senders because senders unwind is inside cycle transaction
-		12,
-		3, 4,
+		13,
+		4, 5,
 		// Unwinding of IHashes needs to happen after unwinding HashState
-		6, 5,
-		7, 8, 9, 10, 11,
+		7, 6,
+		8, 9, 10, 11, 12,
+		// Unwinding below the downloaded state is impossible, so it is checked before anything is unwound
+		3,
 	}
 }
+
+// MiningStages are the stages producing a block on top of the executed head of the chain. They reuse
+// the hashing stages to compute the state root of the block, so they have to run in a transaction
+// that is rolled back afterwards, see MineBlockInStages
+func MiningStages(cfg *MiningCfg) StageBuilders {
+	return []StageBuilder{
+		{
+			ID: stages.MiningCreateBlock,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningCreateBlock,
+					Description: "Mining: create the block from the pending transactions",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningCreateBlockStage(s, world.TX, cfg, world.chainConfig, world.chainContext.Engine(), world.txPool)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return u.Done(world.TX)
+					},
+				}
+			},
+		},
+		{
+			ID: stages.MiningExecution,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningExecution,
+					Description: "Mining: execute the transactions of the block",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningExecStage(s, world.TX, cfg, world.chainConfig, world.vmConfig, world.chainContext, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return u.Done(world.TX)
+					},
+				}
+			},
+		},
+		{
+			ID: stages.HashState,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.HashState,
+					Description: "Hash the key in the state",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnHashStateStage(s, world.TX, world.tmpdir, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return UnwindHashStateStage(u, s, world.TX, world.tmpdir, world.QuitCh)
+					},
+				}
+			},
+		},
+		{
+			ID: stages.IntermediateHashes,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.IntermediateHashes,
+					Description: "Generate intermediate hashes and computing state root",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningIntermediateHashesStage(s, world.TX, &cfg.Block, world.tmpdir, world.QuitCh)
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return UnwindIntermediateHashesStage(u, s, world.TX, world.tmpdir, world.QuitCh)
+					},
+				}
+			},
+		},
+		{
+			ID: stages.MiningFinish,
+			Build: func(world StageParameters) *Stage {
+				return &Stage{
+					ID:          stages.MiningFinish,
+					Description: "Mining: seal the block",
+					ExecFunc: func(s *StageState, u Unwinder) error {
+						return SpawnMiningFinishStage(s, world.db, cfg, world.chainConfig, world.chainContext.Engine())
+					},
+					UnwindFunc: func(u *UnwindState, s *StageState) error {
+						return u.Done(world.TX)
+					},
+				}
+			},
+		},
+	}
+}
+
+// MiningUnwindOrder contains the unwind order for `MiningStages()`. The mining stages are never
+// unwound, their transaction is rolled back instead
+func MiningUnwindOrder() UnwindOrder {
+	return []int{0, 1, 3, 2, 4}
+}
@@ -17,6 +17,7 @@ type StagedSync struct {
 	unwindOrder      UnwindOrder
 	params           OptionalParameters
 	Notifier         ChainEventNotifier
+	StateSyncer      StateSyncer
 }
 
 // OptionalParameters contains any non-necessary parateres you can specify to fine-tune
@@ -33,10 +34,29 @@ type OptionalParameters struct {
 	// Notifier allows sending some data when new headers or new blocks are added
 	Notifier ChainEventNotifier
 
+	// StateSyncer downloads the state of a recent block when the node starts from scratch.
+	// If not set, all the blocks are executed from genesis.
+	StateSyncer StateSyncer
+
 	SilkwormExecutionFunc unsafe.Pointer
+
+	// AnalysisCache keeps results of JUMPDEST analysis across blocks for the execution stage.
+	// If not set, a cache of the default size is created.
+	AnalysisCache *core.AnalysisCache
+
+	// ParallelExecution is the number of workers speculatively executing transactions of a block
+	// in the execution stage, experimental. 0 means sequential execution.
+	ParallelExecution int
+
+	// VerifyParallelExecution makes the execution stage execute every block sequentially as well,
+	// failing on any difference from the results of the parallel execution.
+	VerifyParallelExecution bool
 }
 
 func New(stages StageBuilders, unwindOrder UnwindOrder, params OptionalParameters) *StagedSync {
+	if params.AnalysisCache == nil {
+		params.AnalysisCache = core.NewAnalysisCache(core.DefaultAnalysisCacheSize)
+	}
 	return &StagedSync{
 		PrefetchedBlocks: NewPrefetchedBlocks(),
 		stageBuilders:    stages,
@@ -77,6 +97,10 @@ func (stagedSync *StagedSync) Prepare(
 		stagedSync.Notifier = stagedSync.params.Notifier
 	}
 
+	if stagedSync.params.StateSyncer != nil {
+		stagedSync.StateSyncer = stagedSync.params.StateSyncer
+	}
+
 	stages := stagedSync.stageBuilders.Build(
 		StageParameters{
 			d:                     d,
@@ -99,10 +123,15 @@ func (stagedSync *StagedSync) Prepare(
 			stateReaderBuilder:    readerBuilder,
 			stateWriterBuilder:    writerBuilder,
 			notifier:              stagedSync.Notifier,
+			stateSyncer:           stagedSync.StateSyncer,
 			silkwormExecutionFunc: stagedSync.params.SilkwormExecutionFunc,
+			analysisCache:         stagedSync.params.AnalysisCache,
+			parallelExecution:     stagedSync.params.ParallelExecution,
+			verifyParallel:        stagedSync.params.VerifyParallelExecution,
 		},
 	)
 	state := NewState(stages)
+	state.notifier = stagedSync.Notifier
 
 	state.unwindOrder = make([]*Stage, len(stagedSync.unwindOrder))
 
@@ -34,6 +34,7 @@ var (
 	Headers             SyncStage = []byte("Headers")             // Headers are downloaded, their Proof-Of-Work validity and chaining is verified
 	BlockHashes         SyncStage = []byte("BlockHashes")         // Headers Number are written, fills blockHash => number bucket
 	Bodies              SyncStage = []byte("Bodies")              // Block bodies are downloaded, TxHash and UncleHash are getting verified
+	StateSync           SyncStage = []byte("StateSync")           // State of a recent block is downloaded from the peers instead of executing the blocks
 	Senders             SyncStage = []byte("Senders")             // "From" recovered from signatures, bodies re-written
 	Execution           SyncStage = []byte("Execution")           // Executing each block w/o buildinf a trie
 	IntermediateHashes  SyncStage = []byte("IntermediateHashes")  // Generate intermediate hashes, calculate the state root hash
@@ -45,12 +46,17 @@ var (
 	TxLookup            SyncStage = []byte("TxLookup")            // Generating transactions lookup index
 	TxPool              SyncStage = []byte("TxPool")              // Starts Backend
 	Finish              SyncStage = []byte("Finish")              // Nominal stage after all other stages
+
+	MiningCreateBlock SyncStage = []byte("MiningCreateBlock") // Create the block to mine from the pending transactions
+	MiningExecution   SyncStage = []byte("MiningExecution")   // Execute the transactions of the block being mined
+	MiningFinish      SyncStage = []byte("MiningFinish")      // Seal the mined block
 )
 
 var AllStages = []SyncStage{
 	Headers,
 	BlockHashes,
 	Bodies,
+	StateSync,
 	Senders,
 	Execution,
 	IntermediateHashes,
@@ -17,6 +17,7 @@ type State struct {
 	stages       []*Stage
 	unwindOrder  []*Stage
 	currentStage uint
+	notifier     ChainEventNotifier
 
 	beforeStageRun    map[string]func() error
 	onBeforeUnwind    func(stages.SyncStage) error
@@ -83,6 +84,9 @@ func (s *State) UnwindTo(blockNumber uint64, db ethdb.Database) error {
 			return err
 		}
 	}
+	if s.notifier != nil {
+		s.notifier.OnUnwind(blockNumber)
+	}
 	return nil
 }
 
@@ -29,11 +29,15 @@ import (
 //go:generate protoc --go_out=. "./remote/kv.proto" -I=. -I=./../build/include/google
 //go:generate protoc --go_out=. "./remote/db.proto" -I=. -I=./../build/include/google
 //go:generate protoc --go_out=. "./remote/ethbackend.proto" -I=. -I=./../build/include/google
+//go:generate protoc --go_out=. "./remote/statediff.proto" -I=. -I=./../build/include/google
+//go:generate protoc --go_out=. "./remote/blocks.proto" -I=. -I=./../build/include/google
 
 // generate the services
 //go:generate protoc --go-grpc_out=. "./remote/kv.proto" -I=. -I=./../build/include/google
 //go:generate protoc --go-grpc_out=. "./remote/db.proto" -I=. -I=./../build/include/google
 //go:generate protoc --go-grpc_out=. "./remote/ethbackend.proto" -I=. -I=./../build/include/google
+//go:generate protoc --go-grpc_out=. "./remote/statediff.proto" -I=. -I=./../build/include/google
+//go:generate protoc --go-grpc_out=. "./remote/blocks.proto" -I=. -I=./../build/include/google
 
 type remoteOpts struct {
 	DialAddress string
@@ -99,6 +103,7 @@ func (opts remoteOpts) InMem(listener *bufconn.Listener) remoteOpts {
 type RemoteBackend struct {
 	opts             remoteOpts
 	remoteEthBackend remote.ETHBACKENDClient
+	remoteTxPool     remote.TXPOOLClient
 	conn             *grpc.ClientConn
 	log              log.Logger
 }
@@ -177,6 +182,7 @@ func (opts remoteOpts) Open(certFile, keyFile, caCert string) (KV, Backend, erro
 	eth := &RemoteBackend{
 		opts:             opts,
 		remoteEthBackend: remote.NewETHBACKENDClient(conn),
+		remoteTxPool:     remote.NewTXPOOLClient(conn),
 		conn:             conn,
 		log:              log.New("remote_db", opts.DialAddress),
 	}
@@ -691,6 +697,11 @@ func (c *remoteCursorDupFixed) PutMulti(key []byte, page []byte, stride int) err
 	panic("not supported")
 }
 
+// TxPool is the client of the transaction pool of the core, served over the same connection
+func (back *RemoteBackend) TxPool() remote.TXPOOLClient {
+	return back.remoteTxPool
+}
+
 func (back *RemoteBackend) AddLocal(signedTx []byte) ([]byte, error) {
 	res, err := back.remoteEthBackend.Add(context.Background(), &remote.TxRequest{Signedtx: signedTx})
 	if err != nil {
@@ -0,0 +1,750 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.14.0
+// source: remote/blocks.proto
+
+package remote
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type BlocksRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromBlock uint64 `protobuf:"varint,1,opt,name=from_block,json=fromBlock,proto3" json:"from_block,omitempty"` // the first block to stream, used when from_hash is not set
+	FromHash  []byte `protobuf:"bytes,2,opt,name=from_hash,json=fromHash,proto3" json:"from_hash,omitempty"`     // the last received block, the stream starts with an unwind if it is not canonical anymore
+}
+
+func (x *BlocksRequest) Reset() {
+	*x = BlocksRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlocksRequest) ProtoMessage() {}
+
+func (x *BlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlocksRequest.ProtoReflect.Descriptor instead.
+func (*BlocksRequest) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BlocksRequest) GetFromBlock() uint64 {
+	if x != nil {
+		return x.FromBlock
+	}
+	return 0
+}
+
+func (x *BlocksRequest) GetFromHash() []byte {
+	if x != nil {
+		return x.FromHash
+	}
+	return nil
+}
+
+type BlocksReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*BlocksReply_Block
+	//	*BlocksReply_Unwind
+	Event isBlocksReply_Event `protobuf_oneof:"event"`
+}
+
+func (x *BlocksReply) Reset() {
+	*x = BlocksReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlocksReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlocksReply) ProtoMessage() {}
+
+func (x *BlocksReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlocksReply.ProtoReflect.Descriptor instead.
+func (*BlocksReply) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *BlocksReply) GetEvent() isBlocksReply_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *BlocksReply) GetBlock() *StreamedBlock {
+	if x, ok := x.GetEvent().(*BlocksReply_Block); ok {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *BlocksReply) GetUnwind() *UnwindBlocks {
+	if x, ok := x.GetEvent().(*BlocksReply_Unwind); ok {
+		return x.Unwind
+	}
+	return nil
+}
+
+type isBlocksReply_Event interface {
+	isBlocksReply_Event()
+}
+
+type BlocksReply_Block struct {
+	Block *StreamedBlock `protobuf:"bytes,1,opt,name=block,proto3,oneof"`
+}
+
+type BlocksReply_Unwind struct {
+	Unwind *UnwindBlocks `protobuf:"bytes,2,opt,name=unwind,proto3,oneof"`
+}
+
+func (*BlocksReply_Block) isBlocksReply_Event() {}
+
+func (*BlocksReply_Unwind) isBlocksReply_Event() {}
+
+// UnwindBlocks is sent when the blocks above unwind_to, which were already streamed, are not canonical anymore.
+type UnwindBlocks struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UnwindTo  uint64 `protobuf:"varint,1,opt,name=unwind_to,json=unwindTo,proto3" json:"unwind_to,omitempty"`
+	BlockHash []byte `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"` // hash of the block unwind_to
+}
+
+func (x *UnwindBlocks) Reset() {
+	*x = UnwindBlocks{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnwindBlocks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnwindBlocks) ProtoMessage() {}
+
+func (x *UnwindBlocks) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnwindBlocks.ProtoReflect.Descriptor instead.
+func (*UnwindBlocks) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UnwindBlocks) GetUnwindTo() uint64 {
+	if x != nil {
+		return x.UnwindTo
+	}
+	return 0
+}
+
+func (x *UnwindBlocks) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+type StreamedBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number       uint64                 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Hash         []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	ParentHash   []byte                 `protobuf:"bytes,3,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
+	Timestamp    uint64                 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Header       []byte                 `protobuf:"bytes,5,opt,name=header,proto3" json:"header,omitempty"` // RLP encoded header
+	Transactions []*StreamedTransaction `protobuf:"bytes,6,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (x *StreamedBlock) Reset() {
+	*x = StreamedBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamedBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamedBlock) ProtoMessage() {}
+
+func (x *StreamedBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamedBlock.ProtoReflect.Descriptor instead.
+func (*StreamedBlock) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamedBlock) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *StreamedBlock) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *StreamedBlock) GetParentHash() []byte {
+	if x != nil {
+		return x.ParentHash
+	}
+	return nil
+}
+
+func (x *StreamedBlock) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *StreamedBlock) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *StreamedBlock) GetTransactions() []*StreamedTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type StreamedTransaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash    []byte           `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Data    []byte           `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"` // RLP encoded transaction
+	Sender  []byte           `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"`
+	Receipt *StreamedReceipt `protobuf:"bytes,4,opt,name=receipt,proto3" json:"receipt,omitempty"` // not set if the receipts are not stored
+}
+
+func (x *StreamedTransaction) Reset() {
+	*x = StreamedTransaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamedTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamedTransaction) ProtoMessage() {}
+
+func (x *StreamedTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamedTransaction.ProtoReflect.Descriptor instead.
+func (*StreamedTransaction) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamedTransaction) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *StreamedTransaction) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *StreamedTransaction) GetSender() []byte {
+	if x != nil {
+		return x.Sender
+	}
+	return nil
+}
+
+func (x *StreamedTransaction) GetReceipt() *StreamedReceipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+type StreamedReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status            uint64         `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	CumulativeGasUsed uint64         `protobuf:"varint,2,opt,name=cumulative_gas_used,json=cumulativeGasUsed,proto3" json:"cumulative_gas_used,omitempty"`
+	GasUsed           uint64         `protobuf:"varint,3,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	ContractAddress   []byte         `protobuf:"bytes,4,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"` // set for contract creations only
+	Logs              []*StreamedLog `protobuf:"bytes,5,rep,name=logs,proto3" json:"logs,omitempty"`
+}
+
+func (x *StreamedReceipt) Reset() {
+	*x = StreamedReceipt{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamedReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamedReceipt) ProtoMessage() {}
+
+func (x *StreamedReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamedReceipt.ProtoReflect.Descriptor instead.
+func (*StreamedReceipt) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamedReceipt) GetStatus() uint64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *StreamedReceipt) GetCumulativeGasUsed() uint64 {
+	if x != nil {
+		return x.CumulativeGasUsed
+	}
+	return 0
+}
+
+func (x *StreamedReceipt) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *StreamedReceipt) GetContractAddress() []byte {
+	if x != nil {
+		return x.ContractAddress
+	}
+	return nil
+}
+
+func (x *StreamedReceipt) GetLogs() []*StreamedLog {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+type StreamedLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Topics  [][]byte `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data    []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Index   uint64   `protobuf:"varint,4,opt,name=index,proto3" json:"index,omitempty"` // index of the log in the block
+}
+
+func (x *StreamedLog) Reset() {
+	*x = StreamedLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_blocks_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamedLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamedLog) ProtoMessage() {}
+
+func (x *StreamedLog) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_blocks_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamedLog.ProtoReflect.Descriptor instead.
+func (*StreamedLog) Descriptor() ([]byte, []int) {
+	return file_remote_blocks_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StreamedLog) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *StreamedLog) GetTopics() [][]byte {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *StreamedLog) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *StreamedLog) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+var File_remote_blocks_proto protoreflect.FileDescriptor
+
+var file_remote_blocks_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x22, 0x4b, 0x0a,
+	0x0d, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1b, 0x0a,
+	0x09, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x48, 0x61, 0x73, 0x68, 0x22, 0x75, 0x0a, 0x0b, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x05, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x48,
+	0x00, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2e, 0x0a, 0x06, 0x75, 0x6e, 0x77, 0x69,
+	0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x55, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x48, 0x00,
+	0x52, 0x06, 0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x22, 0x4a, 0x0a, 0x0c, 0x55, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x12, 0x1b, 0x0a, 0x09, 0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x74, 0x6f, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x12, 0x1d,
+	0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x22, 0xd3, 0x01,
+	0x0a, 0x0d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12,
+	0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x70,
+	0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x3f, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x22, 0x88, 0x01, 0x0a, 0x13, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68,
+	0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x07, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64, 0x52, 0x65,
+	0x63, 0x65, 0x69, 0x70, 0x74, 0x52, 0x07, 0x72, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x22, 0xc8,
+	0x01, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x70, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x63, 0x75,
+	0x6d, 0x75, 0x6c, 0x61, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x67, 0x61, 0x73, 0x5f, 0x75, 0x73, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x63, 0x75, 0x6d, 0x75, 0x6c, 0x61, 0x74,
+	0x69, 0x76, 0x65, 0x47, 0x61, 0x73, 0x55, 0x73, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x61,
+	0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x67, 0x61,
+	0x73, 0x55, 0x73, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x27, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x65, 0x64,
+	0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x22, 0x69, 0x0a, 0x0b, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x65, 0x64, 0x4c, 0x6f, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0c, 0x52, 0x06, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x32, 0x40, 0x0a, 0x06, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x53, 0x12, 0x36,
+	0x0a, 0x06, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x15, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x30, 0x01, 0x42, 0x2d, 0x0a, 0x10, 0x69, 0x6f, 0x2e, 0x74, 0x75, 0x72,
+	0x62, 0x6f, 0x2d, 0x67, 0x65, 0x74, 0x68, 0x2e, 0x64, 0x62, 0x42, 0x06, 0x42, 0x4c, 0x4f, 0x43,
+	0x4b, 0x53, 0x50, 0x01, 0x5a, 0x0f, 0x2e, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x3b, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_remote_blocks_proto_rawDescOnce sync.Once
+	file_remote_blocks_proto_rawDescData = file_remote_blocks_proto_rawDesc
+)
+
+func file_remote_blocks_proto_rawDescGZIP() []byte {
+	file_remote_blocks_proto_rawDescOnce.Do(func() {
+		file_remote_blocks_proto_rawDescData = protoimpl.X.CompressGZIP(file_remote_blocks_proto_rawDescData)
+	})
+	return file_remote_blocks_proto_rawDescData
+}
+
+var file_remote_blocks_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_remote_blocks_proto_goTypes = []interface{}{
+	(*BlocksRequest)(nil),       // 0: remote.BlocksRequest
+	(*BlocksReply)(nil),         // 1: remote.BlocksReply
+	(*UnwindBlocks)(nil),        // 2: remote.UnwindBlocks
+	(*StreamedBlock)(nil),       // 3: remote.StreamedBlock
+	(*StreamedTransaction)(nil), // 4: remote.StreamedTransaction
+	(*StreamedReceipt)(nil),     // 5: remote.StreamedReceipt
+	(*StreamedLog)(nil),         // 6: remote.StreamedLog
+}
+var file_remote_blocks_proto_depIdxs = []int32{
+	3, // 0: remote.BlocksReply.block:type_name -> remote.StreamedBlock
+	2, // 1: remote.BlocksReply.unwind:type_name -> remote.UnwindBlocks
+	4, // 2: remote.StreamedBlock.transactions:type_name -> remote.StreamedTransaction
+	5, // 3: remote.StreamedTransaction.receipt:type_name -> remote.StreamedReceipt
+	6, // 4: remote.StreamedReceipt.logs:type_name -> remote.StreamedLog
+	0, // 5: remote.BLOCKS.Blocks:input_type -> remote.BlocksRequest
+	1, // 6: remote.BLOCKS.Blocks:output_type -> remote.BlocksReply
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_remote_blocks_proto_init() }
+func file_remote_blocks_proto_init() {
+	if File_remote_blocks_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_remote_blocks_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlocksRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlocksReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnwindBlocks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamedBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamedTransaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamedReceipt); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_blocks_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamedLog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_remote_blocks_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*BlocksReply_Block)(nil),
+		(*BlocksReply_Unwind)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_remote_blocks_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remote_blocks_proto_goTypes,
+		DependencyIndexes: file_remote_blocks_proto_depIdxs,
+		MessageInfos:      file_remote_blocks_proto_msgTypes,
+	}.Build()
+	File_remote_blocks_proto = out.File
+	file_remote_blocks_proto_rawDesc = nil
+	file_remote_blocks_proto_goTypes = nil
+	file_remote_blocks_proto_depIdxs = nil
+}
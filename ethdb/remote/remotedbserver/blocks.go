@@ -0,0 +1,117 @@
+package remotedbserver
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// BlocksServer streams the canonical blocks with their receipts, see remote/blocks.proto
+type BlocksServer struct {
+	remote.UnimplementedBLOCKSServer // must be embedded to have forward compatible implementations.
+
+	db     ethdb.Database
+	events *Events
+}
+
+func NewBlocksServer(kv ethdb.KV, events *Events) *BlocksServer {
+	return &BlocksServer{db: ethdb.NewObjectDatabase(kv), events: events}
+}
+
+func (s *BlocksServer) Blocks(req *remote.BlocksRequest, stream remote.BLOCKS_BlocksServer) error {
+	log.Debug("establishing blocks stream", "from", req.FromBlock, "fromHash", common.BytesToHash(req.FromHash))
+	cs, err := s.newStream(stream, req)
+	if err != nil {
+		return err
+	}
+	return cs.follow(stream.Context(), s.events)
+}
+
+func (s *BlocksServer) newStream(stream remote.BLOCKS_BlocksServer, req *remote.BlocksRequest) (*chainStream, error) {
+	streamed := streamedBlocks{next: req.FromBlock}
+	if len(req.FromHash) > 0 {
+		var err error
+		if streamed, err = streamedBlocksAfter(s.db, common.BytesToHash(req.FromHash)); err != nil {
+			return nil, err
+		}
+	}
+	return &chainStream{
+		db:       s.db,
+		streamed: streamed,
+		sendUnwind: func(unwindTo uint64, hash common.Hash) error {
+			return stream.Send(&remote.BlocksReply{Event: &remote.BlocksReply_Unwind{Unwind: &remote.UnwindBlocks{UnwindTo: unwindTo, BlockHash: hash.Bytes()}}})
+		},
+		sendBlock: func(tx ethdb.Database, blockNum uint64) (common.Hash, error) {
+			block, err := streamedBlock(tx, blockNum)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return common.BytesToHash(block.Hash), stream.Send(&remote.BlocksReply{Event: &remote.BlocksReply_Block{Block: block}})
+		},
+	}, nil
+}
+
+// streamedBlock reads the canonical block blockNum with its senders and receipts
+func streamedBlock(db ethdb.Database, blockNum uint64) (*remote.StreamedBlock, error) {
+	hash, err := rawdb.ReadCanonicalHash(db, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	block := rawdb.ReadBlock(db, hash, blockNum)
+	if block == nil {
+		return nil, fmt.Errorf("canonical block %d not found", blockNum)
+	}
+	header, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	senders := rawdb.ReadSenders(db, hash, blockNum)
+	receipts := rawdb.ReadReceipts(db, hash, blockNum)
+	out := &remote.StreamedBlock{
+		Number:     blockNum,
+		Hash:       hash.Bytes(),
+		ParentHash: block.ParentHash().Bytes(),
+		Timestamp:  block.Time(),
+		Header:     header,
+	}
+	for i, txn := range block.Transactions() {
+		data, err := rlp.EncodeToBytes(txn)
+		if err != nil {
+			return nil, err
+		}
+		streamedTx := &remote.StreamedTransaction{Hash: txn.Hash().Bytes(), Data: data}
+		if i < len(senders) {
+			streamedTx.Sender = senders[i].Bytes()
+		}
+		if i < len(receipts) {
+			streamedTx.Receipt = streamedReceipt(receipts[i])
+		}
+		out.Transactions = append(out.Transactions, streamedTx)
+	}
+	return out, nil
+}
+
+func streamedReceipt(receipt *types.Receipt) *remote.StreamedReceipt {
+	out := &remote.StreamedReceipt{
+		Status:            receipt.Status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		GasUsed:           receipt.GasUsed,
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		out.ContractAddress = receipt.ContractAddress.Bytes()
+	}
+	for _, l := range receipt.Logs {
+		streamedLog := &remote.StreamedLog{Address: l.Address.Bytes(), Data: l.Data, Index: uint64(l.Index)}
+		for _, topic := range l.Topics {
+			streamedLog.Topics = append(streamedLog.Topics, topic.Bytes())
+		}
+		out.Logs = append(out.Logs, streamedLog)
+	}
+	return out
+}
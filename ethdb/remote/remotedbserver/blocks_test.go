@@ -0,0 +1,187 @@
+package remotedbserver
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+	"google.golang.org/grpc"
+)
+
+type blocksStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	replies []*remote.BlocksReply
+}
+
+func (s *blocksStream) Context() context.Context { return s.ctx }
+
+func (s *blocksStream) Send(reply *remote.BlocksReply) error {
+	s.replies = append(s.replies, reply)
+	return nil
+}
+
+func (s *blocksStream) take() []*remote.BlocksReply {
+	replies := s.replies
+	s.replies = nil
+	return replies
+}
+
+func TestBlocksStream(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	ctx := context.Background()
+
+	var (
+		sender = common.HexToAddress("0x01")
+		topic  = common.HexToHash("0x02")
+	)
+	writeBlock := func(parent *types.Header, extra byte) *types.Header {
+		number := uint64(0)
+		var parentHash common.Hash
+		if parent != nil {
+			number = parent.Number.Uint64() + 1
+			parentHash = parent.Hash()
+		}
+		header := &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: parentHash, Extra: []byte{extra}}
+		txn := types.NewTransaction(number, common.HexToAddress("0xdead"), uint256.NewInt(), 21000, uint256.NewInt(), nil)
+		receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{{Address: sender, Topics: []common.Hash{topic}}}}
+		block := types.NewBlock(header, []*types.Transaction{txn}, nil, []*types.Receipt{receipt})
+		if err := rawdb.WriteBlock(ctx, db, block); err != nil {
+			t.Fatal(err)
+		}
+		rawdb.WriteSenders(ctx, db, block.Hash(), number, []common.Address{sender})
+		if err := rawdb.WriteReceipts(db, number, types.Receipts{receipt}); err != nil {
+			t.Fatal(err)
+		}
+		if err := rawdb.WriteCanonicalHash(db, block.Hash(), number); err != nil {
+			t.Fatal(err)
+		}
+		if err := stages.SaveStageProgress(db, stages.Finish, number); err != nil {
+			t.Fatal(err)
+		}
+		return block.Header()
+	}
+	expectBlocks := func(replies []*remote.BlocksReply, headers ...*types.Header) {
+		t.Helper()
+		if len(replies) != len(headers) {
+			t.Fatalf("expected %d blocks, got %v", len(headers), replies)
+		}
+		for i, header := range headers {
+			block := replies[i].GetBlock()
+			if block == nil || block.Number != header.Number.Uint64() || !bytes.Equal(block.Hash, header.Hash().Bytes()) {
+				t.Fatalf("expected block %d %x, got %v", header.Number, header.Hash(), replies[i])
+			}
+		}
+	}
+	expectUnwind := func(reply *remote.BlocksReply, unwindTo uint64) {
+		t.Helper()
+		if unwind := reply.GetUnwind(); unwind == nil || unwind.UnwindTo != unwindTo {
+			t.Fatalf("expected an unwind to %d, got %v", unwindTo, reply)
+		}
+	}
+
+	genesis := writeBlock(nil, 0)
+	header1 := writeBlock(genesis, 0)
+	header2 := writeBlock(header1, 0)
+
+	srv := NewBlocksServer(db.KV(), nil)
+	stream := &blocksStream{ctx: ctx}
+	cs, err := srv.newStream(stream, &remote.BlocksRequest{FromBlock: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	replies := stream.take()
+	expectBlocks(replies, header1, header2)
+	block := replies[0].GetBlock()
+	var decoded types.Header
+	if err := rlp.DecodeBytes(block.Header, &decoded); err != nil || decoded.Hash() != header1.Hash() {
+		t.Errorf("unexpected header %x: %v", block.Header, err)
+	}
+	if len(block.Transactions) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(block.Transactions))
+	}
+	txn := block.Transactions[0]
+	if !bytes.Equal(txn.Sender, sender.Bytes()) || txn.Receipt == nil || txn.Receipt.GasUsed != 21000 {
+		t.Errorf("unexpected transaction %v", txn)
+	}
+	if logs := txn.Receipt.Logs; len(logs) != 1 || !bytes.Equal(logs[0].Address, sender.Bytes()) || len(logs[0].Topics) != 1 || !bytes.Equal(logs[0].Topics[0], topic.Bytes()) {
+		t.Errorf("unexpected logs %v", logs)
+	}
+
+	// reorg replacing block 2
+	reorg2 := writeBlock(header1, 1)
+	reorg3 := writeBlock(reorg2, 1)
+	if err := cs.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	replies = stream.take()
+	expectUnwind(replies[0], 1)
+	expectBlocks(replies[1:], reorg2, reorg3)
+
+	// resuming after the block which is not canonical anymore
+	resumed, err := srv.newStream(stream, &remote.BlocksRequest{FromHash: header2.Hash().Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	replies = stream.take()
+	expectUnwind(replies[0], 1)
+	expectBlocks(replies[1:], reorg2, reorg3)
+
+	// unwind scheduled by the staged sync
+	events := NewEvents()
+	listener, remove := events.AddChainListener()
+	defer remove()
+	events.OnUnwind(2)
+	events.OnUnwind(1)
+	events.OnUnwind(2)
+	unwindTo, ok := listener.Unwind()
+	if !ok || unwindTo != 1 {
+		t.Fatalf("expected an unwind to 1, got %d %t", unwindTo, ok)
+	}
+	if _, ok := listener.Unwind(); ok {
+		t.Fatal("unwind is reported twice")
+	}
+	if err := stages.SaveStageUnwind(db, stages.Finish, unwindTo); err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.send(ctx, unwindTo); err != nil {
+		t.Fatal(err)
+	}
+	replies = stream.take()
+	expectUnwind(replies[0], 1)
+	expectBlocks(replies[1:])
+
+	// nothing above the unwind point is sent until the unwind of the Finish stage is done
+	if err := resumed.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	expectBlocks(stream.take())
+	if err := stages.SaveStageProgress(db, stages.Finish, unwindTo); err != nil {
+		t.Fatal(err)
+	}
+	if err := stages.SaveStageUnwind(db, stages.Finish, 0); err != nil {
+		t.Fatal(err)
+	}
+	fork2 := writeBlock(header1, 2)
+	if err := resumed.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	expectBlocks(stream.take(), fork2)
+}
@@ -0,0 +1,190 @@
+package remotedbserver
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// StreamReorgDepth is how many of the last streamed blocks are remembered to find the common ancestor after a reorg
+	StreamReorgDepth = 1024
+	// streamPollInterval is how often the head is re-checked when no chain event is received
+	streamPollInterval = 5 * time.Second
+)
+
+// chainStream sends the canonical blocks in order and an unwind when the blocks it has sent stop being canonical.
+// Only the blocks up to the progress of the Finish stage are sent, so that all the indices are available for them.
+// While an unwind of the Finish stage is pending, the blocks above its unwind point are not sent: the canonical
+// hashes may already be replaced there, but the progress of the Finish stage still refers to the old blocks.
+type chainStream struct {
+	db         ethdb.Database
+	streamed   streamedBlocks
+	sendUnwind func(unwindTo uint64, hash common.Hash) error
+	sendBlock  func(tx ethdb.Database, blockNum uint64) (common.Hash, error)
+}
+
+// follow sends the blocks until the context is cancelled, waking up on the chain events when events is not nil
+func (cs *chainStream) follow(ctx context.Context, events *Events) error {
+	var changed <-chan struct{}
+	var listener *ChainListener
+	if events != nil {
+		var remove func()
+		listener, remove = events.AddChainListener()
+		defer remove()
+		changed = listener.C()
+	}
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		keep := uint64(math.MaxUint64)
+		if listener != nil {
+			if unwindTo, ok := listener.Unwind(); ok {
+				keep = unwindTo
+			}
+		}
+		if err := cs.send(ctx, keep); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// send unwinds the streamed blocks above keep, or not canonical anymore, and then sends all the blocks up to the head,
+// which is the progress of the Finish stage or its pending unwind point, whichever is lower
+func (cs *chainStream) send(ctx context.Context, keep uint64) error {
+	tx, err := cs.db.Begin(ctx, ethdb.RO)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	head, err := stages.GetStageProgress(tx, stages.Finish)
+	if err != nil {
+		return err
+	}
+	unwindPoint, err := stages.GetStageUnwind(tx, stages.Finish)
+	if err != nil {
+		return err
+	}
+	if unwindPoint > 0 && head > unwindPoint {
+		head = unwindPoint
+	}
+	if keep > head {
+		keep = head
+	}
+	unwound, err := cs.streamed.unwind(tx, keep)
+	if err != nil {
+		return err
+	}
+	if unwound {
+		unwindTo := cs.streamed.next - 1
+		hash, err := rawdb.ReadCanonicalHash(tx, unwindTo)
+		if err != nil {
+			return err
+		}
+		if err := cs.sendUnwind(unwindTo, hash); err != nil {
+			return err
+		}
+	}
+	for ; cs.streamed.next <= head; cs.streamed.next++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		hash, err := cs.sendBlock(tx, cs.streamed.next)
+		if err != nil {
+			return err
+		}
+		cs.streamed.add(hash)
+	}
+	return nil
+}
+
+// streamedBlocks remembers the hashes of the last streamed blocks, hashes[i] belongs to the block next-len(hashes)+i
+type streamedBlocks struct {
+	next      uint64
+	hashes    []common.Hash
+	truncated bool // hashes of blocks streamed earlier were forgotten
+}
+
+// streamedBlocksAfter starts the stream after the block with the given hash. If it is not canonical, its ancestors
+// are remembered down to the canonical one, so that the stream begins with an unwind to the common ancestor.
+func streamedBlocksAfter(db ethdb.Database, hash common.Hash) (streamedBlocks, error) {
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		return streamedBlocks{}, status.Errorf(codes.NotFound, "block %x not found", hash)
+	}
+	sb := streamedBlocks{next: *number + 1, hashes: []common.Hash{hash}}
+	for blockNum := *number; ; blockNum-- {
+		canonical, err := rawdb.ReadCanonicalHash(db, blockNum)
+		if err != nil {
+			return streamedBlocks{}, err
+		}
+		if canonical == hash {
+			break
+		}
+		if blockNum == 0 || len(sb.hashes) == StreamReorgDepth {
+			return streamedBlocks{}, status.Errorf(codes.DataLoss, "block %x is not canonical and its common ancestor is more than %d blocks below it", hash, StreamReorgDepth)
+		}
+		header := rawdb.ReadHeader(db, hash, blockNum)
+		if header == nil {
+			return streamedBlocks{}, status.Errorf(codes.NotFound, "header %x of block %d not found", hash, blockNum)
+		}
+		hash = header.ParentHash
+		sb.hashes = append([]common.Hash{hash}, sb.hashes...)
+	}
+	return sb, nil
+}
+
+func (sb *streamedBlocks) add(hash common.Hash) {
+	if len(sb.hashes) == StreamReorgDepth {
+		copy(sb.hashes, sb.hashes[1:])
+		sb.hashes = sb.hashes[:len(sb.hashes)-1]
+		sb.truncated = true
+	}
+	sb.hashes = append(sb.hashes, hash)
+}
+
+// unwind forgets the streamed blocks which are above keep or not canonical anymore, and reports whether there were any
+func (sb *streamedBlocks) unwind(db ethdb.Getter, keep uint64) (bool, error) {
+	var unwound bool
+	for len(sb.hashes) > 0 {
+		last := sb.next - 1
+		if last <= keep {
+			hash, err := rawdb.ReadCanonicalHash(db, last)
+			if err != nil {
+				return false, err
+			}
+			if hash == sb.hashes[len(sb.hashes)-1] {
+				break
+			}
+		}
+		sb.hashes = sb.hashes[:len(sb.hashes)-1]
+		sb.next--
+		unwound = true
+	}
+	if unwound && len(sb.hashes) == 0 {
+		if sb.truncated {
+			return false, status.Errorf(codes.DataLoss, "reorg is deeper than %d blocks, resume the stream from an earlier block", StreamReorgDepth)
+		}
+		if sb.next == 0 {
+			return false, status.Errorf(codes.DataLoss, "genesis block is not canonical anymore")
+		}
+	}
+	return unwound, nil
+}
@@ -1,6 +1,8 @@
 package remotedbserver
 
 import (
+	"sync"
+
 	"github.com/ledgerwatch/turbo-geth/core/types"
 )
 
@@ -14,6 +16,10 @@ type HeaderSubscription func(*types.Header) error
 
 type Events struct {
 	headerSubscription HeaderSubscription
+
+	lock           sync.Mutex
+	chainListeners map[uint64]*ChainListener
+	nextListenerID uint64
 }
 
 func NewEvents() *Events {
@@ -24,7 +30,26 @@ func (e *Events) AddHeaderSubscription(s HeaderSubscription) {
 	e.headerSubscription = s
 }
 
+// AddChainListener registers a listener of new headers and unwinds, and returns a function removing it.
+func (e *Events) AddChainListener() (*ChainListener, func()) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	id := e.nextListenerID
+	e.nextListenerID++
+	l := &ChainListener{ch: make(chan struct{}, 1)}
+	if e.chainListeners == nil {
+		e.chainListeners = map[uint64]*ChainListener{}
+	}
+	e.chainListeners[id] = l
+	return l, func() {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		delete(e.chainListeners, id)
+	}
+}
+
 func (e *Events) OnNewHeader(newHeader *types.Header) {
+	e.notifyChainListeners(func(l *ChainListener) { l.signal() })
 	if e.headerSubscription == nil {
 		return
 	}
@@ -33,3 +58,55 @@ func (e *Events) OnNewHeader(newHeader *types.Header) {
 		e.headerSubscription = nil
 	}
 }
+
+// OnUnwind is called when the staged sync schedules an unwind to the block unwindTo
+func (e *Events) OnUnwind(unwindTo uint64) {
+	e.notifyChainListeners(func(l *ChainListener) { l.onUnwind(unwindTo) })
+}
+
+func (e *Events) notifyChainListeners(notify func(l *ChainListener)) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, l := range e.chainListeners {
+		notify(l)
+	}
+}
+
+// ChainListener is signalled about the chain changes without blocking the sync
+type ChainListener struct {
+	ch chan struct{}
+
+	lock     sync.Mutex
+	unwound  bool
+	unwindTo uint64
+}
+
+// C is signalled when a new header is announced or an unwind is scheduled
+func (l *ChainListener) C() <-chan struct{} {
+	return l.ch
+}
+
+// Unwind returns the lowest block the chain was unwound to since the previous call, if any
+func (l *ChainListener) Unwind() (uint64, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	unwindTo, unwound := l.unwindTo, l.unwound
+	l.unwindTo, l.unwound = 0, false
+	return unwindTo, unwound
+}
+
+func (l *ChainListener) onUnwind(unwindTo uint64) {
+	l.lock.Lock()
+	if !l.unwound || unwindTo < l.unwindTo {
+		l.unwindTo, l.unwound = unwindTo, true
+	}
+	l.lock.Unlock()
+	l.signal()
+}
+
+func (l *ChainListener) signal() {
+	select {
+	case l.ch <- struct{}{}:
+	default:
+	}
+}
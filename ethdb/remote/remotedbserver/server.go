@@ -39,6 +39,9 @@ func StartGrpc(kv ethdb.KV, eth core.Backend, addr string, creds *credentials.Tr
 	kv2Srv := NewKvServer(kv)
 	dbSrv := NewDBServer(kv)
 	ethBackendSrv := NewEthBackendServer(eth, events)
+	stateDiffSrv := NewStateDiffServer(kv, events)
+	blocksSrv := NewBlocksServer(kv, events)
+	txPoolSrv := NewTxPoolServer(eth.TxPool())
 	var (
 		streamInterceptors []grpc.StreamServerInterceptor
 		unaryInterceptors  []grpc.UnaryServerInterceptor
@@ -74,6 +77,9 @@ func StartGrpc(kv ethdb.KV, eth core.Backend, addr string, creds *credentials.Tr
 	remote.RegisterDBServer(grpcServer, dbSrv)
 	remote.RegisterETHBACKENDServer(grpcServer, ethBackendSrv)
 	remote.RegisterKVServer(grpcServer, kv2Srv)
+	remote.RegisterSTATEDIFFServer(grpcServer, stateDiffSrv)
+	remote.RegisterBLOCKSServer(grpcServer, blocksSrv)
+	remote.RegisterTXPOOLServer(grpcServer, txPoolSrv)
 
 	if metrics.Enabled {
 		grpc_prometheus.Register(grpcServer)
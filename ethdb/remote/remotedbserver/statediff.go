@@ -0,0 +1,128 @@
+package remotedbserver
+
+import (
+	"fmt"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/changeset"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/log"
+)
+
+// StateDiffServer streams the diffs recorded in the plain change sets, see remote/statediff.proto.
+// The new values are read through the history indices.
+type StateDiffServer struct {
+	remote.UnimplementedSTATEDIFFServer // must be embedded to have forward compatible implementations.
+
+	db     ethdb.Database
+	events *Events
+}
+
+func NewStateDiffServer(kv ethdb.KV, events *Events) *StateDiffServer {
+	return &StateDiffServer{db: ethdb.NewObjectDatabase(kv), events: events}
+}
+
+func (s *StateDiffServer) Diffs(req *remote.DiffsRequest, stream remote.STATEDIFF_DiffsServer) error {
+	log.Debug("establishing state diff stream", "from", req.FromBlock)
+	return s.newStream(stream, req.FromBlock).follow(stream.Context(), s.events)
+}
+
+func (s *StateDiffServer) newStream(stream remote.STATEDIFF_DiffsServer, fromBlock uint64) *chainStream {
+	return &chainStream{
+		db:       s.db,
+		streamed: streamedBlocks{next: fromBlock},
+		sendUnwind: func(unwindTo uint64, hash common.Hash) error {
+			return stream.Send(&remote.DiffsReply{Event: &remote.DiffsReply_Unwind{Unwind: &remote.UnwindDiff{UnwindTo: unwindTo, BlockHash: hash.Bytes()}}})
+		},
+		sendBlock: func(tx ethdb.Database, blockNum uint64) (common.Hash, error) {
+			diff, err := blockStateDiff(tx, blockNum)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return common.BytesToHash(diff.BlockHash), stream.Send(&remote.DiffsReply{Event: &remote.DiffsReply_Block{Block: diff}})
+		},
+	}
+}
+
+// blockStateDiff reads the accounts and storage changed by the canonical block blockNum with their values before and after it
+func blockStateDiff(db ethdb.Database, blockNum uint64) (*remote.BlockDiff, error) {
+	hash, err := rawdb.ReadCanonicalHash(db, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if hash == (common.Hash{}) {
+		return nil, fmt.Errorf("canonical hash of block %d not found", blockNum)
+	}
+	diff := &remote.BlockDiff{BlockNumber: blockNum, BlockHash: hash.Bytes()}
+
+	var before *state.PlainDBState
+	if blockNum > 0 {
+		before = state.NewPlainDBState(db, blockNum-1)
+	}
+	after := state.NewPlainDBState(db, blockNum)
+	startKey := dbutils.EncodeBlockNumber(blockNum)
+	if err := changeset.Walk(db, dbutils.PlainAccountChangeSetBucket, startKey, 8*8, func(_ uint64, k, _ []byte) (bool, error) {
+		address := common.BytesToAddress(k)
+		var original *accounts.Account
+		if before != nil {
+			var err error
+			if original, err = before.ReadAccountData(address); err != nil {
+				return false, err
+			}
+		}
+		account, err := after.ReadAccountData(address)
+		if err != nil {
+			return false, err
+		}
+		accountDiff := &remote.AccountDiff{
+			Address:  address.Bytes(),
+			OldValue: accountState(original),
+			NewValue: accountState(account),
+		}
+		if account != nil && !account.IsEmptyCodeHash() && (original == nil || original.CodeHash != account.CodeHash) {
+			if accountDiff.Code, err = after.ReadAccountCode(address, account.Incarnation, account.CodeHash); err != nil {
+				return false, err
+			}
+		}
+		diff.Accounts = append(diff.Accounts, accountDiff)
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("walking account changes of block %d: %w", blockNum, err)
+	}
+
+	if err := changeset.Walk(db, dbutils.PlainStorageChangeSetBucket, startKey, 8*8, func(_ uint64, k, v []byte) (bool, error) {
+		address, incarnation, location := dbutils.PlainParseCompositeStorageKey(k)
+		value, err := after.ReadAccountStorage(address, incarnation, &location)
+		if err != nil {
+			return false, err
+		}
+		diff.Storage = append(diff.Storage, &remote.StorageDiff{
+			Address:     address.Bytes(),
+			Incarnation: incarnation,
+			Location:    location.Bytes(),
+			OldValue:    common.CopyBytes(v),
+			NewValue:    value,
+		})
+		return true, nil
+	}); err != nil {
+		return nil, fmt.Errorf("walking storage changes of block %d: %w", blockNum, err)
+	}
+	return diff, nil
+}
+
+func accountState(account *accounts.Account) *remote.AccountState {
+	if account == nil {
+		return nil
+	}
+	return &remote.AccountState{
+		Nonce:       account.Nonce,
+		Balance:     account.Balance.Bytes(),
+		CodeHash:    account.CodeHash.Bytes(),
+		Incarnation: account.Incarnation,
+	}
+}
@@ -0,0 +1,145 @@
+package remotedbserver
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"google.golang.org/grpc"
+)
+
+type diffsStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	replies []*remote.DiffsReply
+}
+
+func (s *diffsStream) Context() context.Context { return s.ctx }
+
+func (s *diffsStream) Send(reply *remote.DiffsReply) error {
+	s.replies = append(s.replies, reply)
+	return nil
+}
+
+func TestStateDiffs(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	ctx := context.Background()
+
+	var (
+		eoa      = common.HexToAddress("0x01")
+		contract = common.HexToAddress("0x02")
+		location = common.HexToHash("0x03")
+		empty    = accounts.NewAccount()
+	)
+	writeBlock := func(blockNum uint64, hash common.Hash, write func(w *state.PlainStateWriter) error) {
+		w := state.NewPlainStateWriter(db, db, blockNum)
+		if err := write(w); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteChangeSets(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteHistory(); err != nil {
+			t.Fatal(err)
+		}
+		if err := rawdb.WriteCanonicalHash(db, hash, blockNum); err != nil {
+			t.Fatal(err)
+		}
+		if err := stages.SaveStageProgress(db, stages.Finish, blockNum); err != nil {
+			t.Fatal(err)
+		}
+	}
+	account := func(balance uint64, incarnation uint64) *accounts.Account {
+		acc := accounts.NewAccount()
+		acc.Initialised = true
+		acc.Balance.SetUint64(balance)
+		acc.Incarnation = incarnation
+		return &acc
+	}
+
+	writeBlock(0, common.HexToHash("0xb0"), func(w *state.PlainStateWriter) error {
+		return w.UpdateAccountData(ctx, eoa, &empty, account(1, 0))
+	})
+	writeBlock(1, common.HexToHash("0xb1"), func(w *state.PlainStateWriter) error {
+		if err := w.UpdateAccountData(ctx, eoa, account(1, 0), account(2, 0)); err != nil {
+			return err
+		}
+		if err := w.CreateContract(contract); err != nil {
+			return err
+		}
+		if err := w.WriteAccountStorage(ctx, contract, 1, &location, uint256.NewInt(), uint256.NewInt().SetUint64(5)); err != nil {
+			return err
+		}
+		return w.UpdateAccountData(ctx, contract, &empty, account(0, 1))
+	})
+	writeBlock(2, common.HexToHash("0xb2"), func(w *state.PlainStateWriter) error {
+		return w.WriteAccountStorage(ctx, contract, 1, &location, uint256.NewInt().SetUint64(5), uint256.NewInt())
+	})
+
+	diff, err := blockStateDiff(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Accounts) != 2 || len(diff.Storage) != 1 {
+		t.Fatalf("expected 2 account and 1 storage diffs, got %d and %d", len(diff.Accounts), len(diff.Storage))
+	}
+	if a := diff.Accounts[0]; !bytes.Equal(a.Address, eoa.Bytes()) || !bytes.Equal(a.OldValue.Balance, []byte{1}) || !bytes.Equal(a.NewValue.Balance, []byte{2}) {
+		t.Errorf("unexpected diff of %x: %v", eoa, a)
+	}
+	if a := diff.Accounts[1]; !bytes.Equal(a.Address, contract.Bytes()) || a.OldValue != nil || a.NewValue.Incarnation != 1 {
+		t.Errorf("unexpected diff of %x: %v", contract, a)
+	}
+	if s := diff.Storage[0]; s.Incarnation != 1 || !bytes.Equal(s.Location, location.Bytes()) || len(s.OldValue) != 0 || !bytes.Equal(s.NewValue, []byte{5}) {
+		t.Errorf("unexpected storage diff: %v", s)
+	}
+
+	srv := NewStateDiffServer(db.KV(), nil)
+	stream := &diffsStream{ctx: ctx}
+	cs := srv.newStream(stream, 1)
+	if err := cs.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.replies) != 2 || stream.replies[0].GetBlock().GetBlockNumber() != 1 || stream.replies[1].GetBlock().GetBlockNumber() != 2 {
+		t.Fatalf("expected the diffs of blocks 1 and 2, got %v", stream.replies)
+	}
+	if s := stream.replies[1].GetBlock().Storage; len(s) != 1 || !bytes.Equal(s[0].OldValue, []byte{5}) || len(s[0].NewValue) != 0 {
+		t.Errorf("unexpected storage diff of block 2: %v", s)
+	}
+
+	// replace block 2
+	reorgHash := common.HexToHash("0xb2b2")
+	if err := rawdb.WriteCanonicalHash(db, reorgHash, 2); err != nil {
+		t.Fatal(err)
+	}
+	stream.replies = nil
+	if err := cs.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.replies) != 2 {
+		t.Fatalf("expected an unwind and a diff, got %v", stream.replies)
+	}
+	if unwind := stream.replies[0].GetUnwind(); unwind == nil || unwind.UnwindTo != 1 {
+		t.Errorf("expected an unwind to block 1, got %v", stream.replies[0])
+	}
+	if block := stream.replies[1].GetBlock(); block == nil || block.BlockNumber != 2 || !bytes.Equal(block.BlockHash, reorgHash.Bytes()) {
+		t.Errorf("expected the diff of the new block 2, got %v", stream.replies[1])
+	}
+
+	stream.replies = nil
+	if err := cs.send(ctx, math.MaxUint64); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.replies) != 0 {
+		t.Errorf("expected no replies at the head, got %v", stream.replies)
+	}
+}
@@ -0,0 +1,117 @@
+package remotedbserver
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/ethdb/remote"
+	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// TxPoolServer exposes the transaction pool of the core, see remote/txpool.proto
+type TxPoolServer struct {
+	remote.UnimplementedTXPOOLServer // must be embedded to have forward compatible implementations.
+
+	txPool *core.TxPool
+}
+
+func NewTxPoolServer(txPool *core.TxPool) *TxPoolServer {
+	return &TxPoolServer{txPool: txPool}
+}
+
+func (s *TxPoolServer) Content(_ context.Context, _ *remote.ContentRequest) (*remote.ContentReply, error) {
+	pending, queued := s.txPool.Content()
+	pendingTxs, err := senderTxs(pending)
+	if err != nil {
+		return nil, err
+	}
+	queuedTxs, err := senderTxs(queued)
+	if err != nil {
+		return nil, err
+	}
+	return &remote.ContentReply{Pending: pendingTxs, Queued: queuedTxs}, nil
+}
+
+func (s *TxPoolServer) Status(_ context.Context, _ *remote.StatusRequest) (*remote.StatusReply, error) {
+	pending, queued := s.txPool.Stats()
+	return &remote.StatusReply{Pending: uint64(pending), Queued: uint64(queued)}, nil
+}
+
+func (s *TxPoolServer) Get(_ context.Context, in *remote.GetRequest) (*remote.GetReply, error) {
+	reply := &remote.GetReply{Txs: make([][]byte, len(in.Hashes))}
+	for i, hash := range in.Hashes {
+		tx := s.txPool.Get(common.BytesToHash(hash))
+		if tx == nil {
+			continue
+		}
+		data, err := rlp.EncodeToBytes(tx)
+		if err != nil {
+			return nil, err
+		}
+		reply.Txs[i] = data
+	}
+	return reply, nil
+}
+
+func (s *TxPoolServer) Nonce(_ context.Context, in *remote.NonceRequest) (*remote.NonceReply, error) {
+	return &remote.NonceReply{Nonce: s.txPool.Nonce(common.BytesToAddress(in.Address))}, nil
+}
+
+func (s *TxPoolServer) OnPending(_ *remote.OnPendingRequest, stream remote.TXPOOL_OnPendingServer) error {
+	log.Debug("establishing pending transactions stream")
+	txsCh := make(chan core.NewTxsEvent, txChanSize)
+	sub := s.txPool.SubscribeNewTxsEvent(txsCh)
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case event := <-txsCh:
+			reply := &remote.OnPendingReply{Txs: make([][]byte, len(event.Txs))}
+			for i, tx := range event.Txs {
+				data, err := rlp.EncodeToBytes(tx)
+				if err != nil {
+					return err
+				}
+				reply.Txs[i] = data
+			}
+			if err := stream.Send(reply); err != nil {
+				log.Debug("pending transactions stream was closed", "reason", err)
+				return err
+			}
+		case err := <-sub.Err():
+			return err
+		case <-stream.Context().Done():
+			log.Debug("pending transactions stream closed")
+			return stream.Context().Err()
+		}
+	}
+}
+
+// txChanSize is the size of the channel listening to the pool, the pending transactions arrive in batches
+const txChanSize = 4096
+
+// senderTxs encodes the transactions of every sender, the senders are ordered to keep the replies stable
+func senderTxs(txs map[common.Address]types.Transactions) ([]*remote.SenderTxs, error) {
+	senders := make([]common.Address, 0, len(txs))
+	for sender := range txs {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool { return bytes.Compare(senders[i][:], senders[j][:]) < 0 })
+	result := make([]*remote.SenderTxs, len(senders))
+	for i, sender := range senders {
+		encoded := make([][]byte, len(txs[sender]))
+		for j, tx := range txs[sender] {
+			data, err := rlp.EncodeToBytes(tx)
+			if err != nil {
+				return nil, err
+			}
+			encoded[j] = data
+		}
+		result[i] = &remote.SenderTxs{Sender: sender.Bytes(), Txs: encoded}
+	}
+	return result, nil
+}
@@ -0,0 +1,724 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.14.0
+// source: remote/statediff.proto
+
+package remote
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type DiffsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromBlock uint64 `protobuf:"varint,1,opt,name=from_block,json=fromBlock,proto3" json:"from_block,omitempty"` // the first block to stream, pass the last received block + 1 to resume
+}
+
+func (x *DiffsRequest) Reset() {
+	*x = DiffsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffsRequest) ProtoMessage() {}
+
+func (x *DiffsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffsRequest.ProtoReflect.Descriptor instead.
+func (*DiffsRequest) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DiffsRequest) GetFromBlock() uint64 {
+	if x != nil {
+		return x.FromBlock
+	}
+	return 0
+}
+
+type DiffsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*DiffsReply_Block
+	//	*DiffsReply_Unwind
+	Event isDiffsReply_Event `protobuf_oneof:"event"`
+}
+
+func (x *DiffsReply) Reset() {
+	*x = DiffsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffsReply) ProtoMessage() {}
+
+func (x *DiffsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffsReply.ProtoReflect.Descriptor instead.
+func (*DiffsReply) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *DiffsReply) GetEvent() isDiffsReply_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *DiffsReply) GetBlock() *BlockDiff {
+	if x, ok := x.GetEvent().(*DiffsReply_Block); ok {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *DiffsReply) GetUnwind() *UnwindDiff {
+	if x, ok := x.GetEvent().(*DiffsReply_Unwind); ok {
+		return x.Unwind
+	}
+	return nil
+}
+
+type isDiffsReply_Event interface {
+	isDiffsReply_Event()
+}
+
+type DiffsReply_Block struct {
+	Block *BlockDiff `protobuf:"bytes,1,opt,name=block,proto3,oneof"`
+}
+
+type DiffsReply_Unwind struct {
+	Unwind *UnwindDiff `protobuf:"bytes,2,opt,name=unwind,proto3,oneof"`
+}
+
+func (*DiffsReply_Block) isDiffsReply_Event() {}
+
+func (*DiffsReply_Unwind) isDiffsReply_Event() {}
+
+type BlockDiff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockNumber uint64         `protobuf:"varint,1,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	BlockHash   []byte         `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Accounts    []*AccountDiff `protobuf:"bytes,3,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	Storage     []*StorageDiff `protobuf:"bytes,4,rep,name=storage,proto3" json:"storage,omitempty"`
+}
+
+func (x *BlockDiff) Reset() {
+	*x = BlockDiff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockDiff) ProtoMessage() {}
+
+func (x *BlockDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockDiff.ProtoReflect.Descriptor instead.
+func (*BlockDiff) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BlockDiff) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *BlockDiff) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *BlockDiff) GetAccounts() []*AccountDiff {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *BlockDiff) GetStorage() []*StorageDiff {
+	if x != nil {
+		return x.Storage
+	}
+	return nil
+}
+
+// UnwindDiff is sent when the blocks above unwind_to, which were already streamed, are not canonical anymore.
+// Their diffs have to be reverted by the receiver.
+type UnwindDiff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UnwindTo  uint64 `protobuf:"varint,1,opt,name=unwind_to,json=unwindTo,proto3" json:"unwind_to,omitempty"`
+	BlockHash []byte `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"` // hash of the block unwind_to
+}
+
+func (x *UnwindDiff) Reset() {
+	*x = UnwindDiff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnwindDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnwindDiff) ProtoMessage() {}
+
+func (x *UnwindDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnwindDiff.ProtoReflect.Descriptor instead.
+func (*UnwindDiff) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UnwindDiff) GetUnwindTo() uint64 {
+	if x != nil {
+		return x.UnwindTo
+	}
+	return 0
+}
+
+func (x *UnwindDiff) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+type AccountState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nonce       uint64 `protobuf:"varint,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Balance     []byte `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"` // big endian
+	CodeHash    []byte `protobuf:"bytes,3,opt,name=code_hash,json=codeHash,proto3" json:"code_hash,omitempty"`
+	Incarnation uint64 `protobuf:"varint,4,opt,name=incarnation,proto3" json:"incarnation,omitempty"`
+}
+
+func (x *AccountState) Reset() {
+	*x = AccountState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccountState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountState) ProtoMessage() {}
+
+func (x *AccountState) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountState.ProtoReflect.Descriptor instead.
+func (*AccountState) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AccountState) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *AccountState) GetBalance() []byte {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+func (x *AccountState) GetCodeHash() []byte {
+	if x != nil {
+		return x.CodeHash
+	}
+	return nil
+}
+
+func (x *AccountState) GetIncarnation() uint64 {
+	if x != nil {
+		return x.Incarnation
+	}
+	return 0
+}
+
+type AccountDiff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address  []byte        `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	OldValue *AccountState `protobuf:"bytes,2,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"` // not set if the account did not exist before the block
+	NewValue *AccountState `protobuf:"bytes,3,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"` // not set if the account does not exist after the block
+	Code     []byte        `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`                         // set when the code hash has changed
+}
+
+func (x *AccountDiff) Reset() {
+	*x = AccountDiff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccountDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountDiff) ProtoMessage() {}
+
+func (x *AccountDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountDiff.ProtoReflect.Descriptor instead.
+func (*AccountDiff) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AccountDiff) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *AccountDiff) GetOldValue() *AccountState {
+	if x != nil {
+		return x.OldValue
+	}
+	return nil
+}
+
+func (x *AccountDiff) GetNewValue() *AccountState {
+	if x != nil {
+		return x.NewValue
+	}
+	return nil
+}
+
+func (x *AccountDiff) GetCode() []byte {
+	if x != nil {
+		return x.Code
+	}
+	return nil
+}
+
+type StorageDiff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address     []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Incarnation uint64 `protobuf:"varint,2,opt,name=incarnation,proto3" json:"incarnation,omitempty"`
+	Location    []byte `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	OldValue    []byte `protobuf:"bytes,4,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"` // empty if the slot was not set
+	NewValue    []byte `protobuf:"bytes,5,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"` // empty if the slot was cleared
+}
+
+func (x *StorageDiff) Reset() {
+	*x = StorageDiff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_remote_statediff_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageDiff) ProtoMessage() {}
+
+func (x *StorageDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_remote_statediff_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageDiff.ProtoReflect.Descriptor instead.
+func (*StorageDiff) Descriptor() ([]byte, []int) {
+	return file_remote_statediff_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StorageDiff) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *StorageDiff) GetIncarnation() uint64 {
+	if x != nil {
+		return x.Incarnation
+	}
+	return 0
+}
+
+func (x *StorageDiff) GetLocation() []byte {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *StorageDiff) GetOldValue() []byte {
+	if x != nil {
+		return x.OldValue
+	}
+	return nil
+}
+
+func (x *StorageDiff) GetNewValue() []byte {
+	if x != nil {
+		return x.NewValue
+	}
+	return nil
+}
+
+var File_remote_statediff_proto protoreflect.FileDescriptor
+
+var file_remote_statediff_proto_rawDesc = []byte{
+	0x0a, 0x16, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x64, 0x69,
+	0x66, 0x66, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x22, 0x2d, 0x0a, 0x0c, 0x44, 0x69, 0x66, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22,
+	0x6e, 0x0a, 0x0a, 0x44, 0x69, 0x66, 0x66, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x29, 0x0a,
+	0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x69, 0x66, 0x66, 0x48,
+	0x00, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x06, 0x75, 0x6e, 0x77, 0x69,
+	0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x55, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x44, 0x69, 0x66, 0x66, 0x48, 0x00, 0x52, 0x06,
+	0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22,
+	0xad, 0x01, 0x0a, 0x09, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x44, 0x69, 0x66, 0x66, 0x12, 0x21, 0x0a,
+	0x0c, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x2f, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x44, 0x69, 0x66, 0x66, 0x52, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x12, 0x2d, 0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x44, 0x69, 0x66, 0x66, 0x52, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x22,
+	0x48, 0x0a, 0x0a, 0x55, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x44, 0x69, 0x66, 0x66, 0x12, 0x1b, 0x0a,
+	0x09, 0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x5f, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x75, 0x6e, 0x77, 0x69, 0x6e, 0x64, 0x54, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x22, 0x7d, 0x0a, 0x0c, 0x41, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6f, 0x64,
+	0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x63, 0x6f,
+	0x64, 0x65, 0x48, 0x61, 0x73, 0x68, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x6e, 0x63, 0x61, 0x72, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x69, 0x6e, 0x63,
+	0x61, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa1, 0x01, 0x0a, 0x0b, 0x41, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x44, 0x69, 0x66, 0x66, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x31, 0x0a, 0x09, 0x6f, 0x6c, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x41,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08, 0x6f, 0x6c, 0x64,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x31, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08,
+	0x6e, 0x65, 0x77, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x9f, 0x01, 0x0a,
+	0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x44, 0x69, 0x66, 0x66, 0x12, 0x18, 0x0a, 0x07,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x6e, 0x63, 0x61, 0x72, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x69, 0x6e, 0x63,
+	0x61, 0x72, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6c, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6f, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x32, 0x40,
+	0x0a, 0x09, 0x53, 0x54, 0x41, 0x54, 0x45, 0x44, 0x49, 0x46, 0x46, 0x12, 0x33, 0x0a, 0x05, 0x44,
+	0x69, 0x66, 0x66, 0x73, 0x12, 0x14, 0x2e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x2e, 0x44, 0x69,
+	0x66, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x72, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x30, 0x01,
+	0x42, 0x30, 0x0a, 0x10, 0x69, 0x6f, 0x2e, 0x74, 0x75, 0x72, 0x62, 0x6f, 0x2d, 0x67, 0x65, 0x74,
+	0x68, 0x2e, 0x64, 0x62, 0x42, 0x09, 0x53, 0x54, 0x41, 0x54, 0x45, 0x44, 0x49, 0x46, 0x46, 0x50,
+	0x01, 0x5a, 0x0f, 0x2e, 0x2f, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x3b, 0x72, 0x65, 0x6d, 0x6f,
+	0x74, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_remote_statediff_proto_rawDescOnce sync.Once
+	file_remote_statediff_proto_rawDescData = file_remote_statediff_proto_rawDesc
+)
+
+func file_remote_statediff_proto_rawDescGZIP() []byte {
+	file_remote_statediff_proto_rawDescOnce.Do(func() {
+		file_remote_statediff_proto_rawDescData = protoimpl.X.CompressGZIP(file_remote_statediff_proto_rawDescData)
+	})
+	return file_remote_statediff_proto_rawDescData
+}
+
+var file_remote_statediff_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_remote_statediff_proto_goTypes = []interface{}{
+	(*DiffsRequest)(nil), // 0: remote.DiffsRequest
+	(*DiffsReply)(nil),   // 1: remote.DiffsReply
+	(*BlockDiff)(nil),    // 2: remote.BlockDiff
+	(*UnwindDiff)(nil),   // 3: remote.UnwindDiff
+	(*AccountState)(nil), // 4: remote.AccountState
+	(*AccountDiff)(nil),  // 5: remote.AccountDiff
+	(*StorageDiff)(nil),  // 6: remote.StorageDiff
+}
+var file_remote_statediff_proto_depIdxs = []int32{
+	2, // 0: remote.DiffsReply.block:type_name -> remote.BlockDiff
+	3, // 1: remote.DiffsReply.unwind:type_name -> remote.UnwindDiff
+	5, // 2: remote.BlockDiff.accounts:type_name -> remote.AccountDiff
+	6, // 3: remote.BlockDiff.storage:type_name -> remote.StorageDiff
+	4, // 4: remote.AccountDiff.old_value:type_name -> remote.AccountState
+	4, // 5: remote.AccountDiff.new_value:type_name -> remote.AccountState
+	0, // 6: remote.STATEDIFF.Diffs:input_type -> remote.DiffsRequest
+	1, // 7: remote.STATEDIFF.Diffs:output_type -> remote.DiffsReply
+	7, // [7:8] is the sub-list for method output_type
+	6, // [6:7] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_remote_statediff_proto_init() }
+func file_remote_statediff_proto_init() {
+	if File_remote_statediff_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_remote_statediff_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DiffsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DiffsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlockDiff); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnwindDiff); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccountState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccountDiff); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_remote_statediff_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageDiff); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_remote_statediff_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*DiffsReply_Block)(nil),
+		(*DiffsReply_Unwind)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_remote_statediff_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remote_statediff_proto_goTypes,
+		DependencyIndexes: file_remote_statediff_proto_depIdxs,
+		MessageInfos:      file_remote_statediff_proto_msgTypes,
+	}.Build()
+	File_remote_statediff_proto = out.File
+	file_remote_statediff_proto_rawDesc = nil
+	file_remote_statediff_proto_goTypes = nil
+	file_remote_statediff_proto_depIdxs = nil
+}
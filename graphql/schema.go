@@ -16,7 +16,8 @@
 
 package graphql
 
-const schema string = `
+// Schema is the EIP-1767 GraphQL schema, shared by the node and the rpcdaemon
+const Schema string = `
     # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
     scalar Bytes32
     # Address is a 20 byte Ethereum address, represented as 0x-prefixed hexadecimal.
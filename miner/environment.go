@@ -8,28 +8,18 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/consensus"
-	"github.com/ledgerwatch/turbo-geth/core"
-	"github.com/ledgerwatch/turbo-geth/core/state"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 )
 
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
-	signer types.Signer
-
-	state     *state.IntraBlockState // apply state changes here
-	tds       *state.TrieDbState
-	ancestors mapset.Set    // ancestor set (used for checking uncle parent validity)
-	family    mapset.Set    // family set (used for checking uncle invalidity)
-	uncles    mapset.Set    // uncle set
-	tcount    int           // tx count in cycle
-	gasPool   *core.GasPool // available gas used to pack transactions
+	ancestors mapset.Set // ancestor set (used for checking uncle parent validity)
+	family    mapset.Set // family set (used for checking uncle invalidity)
+	uncles    mapset.Set // uncle set
 
 	*sync.RWMutex
-	header   *types.Header
-	txs      []*types.Transaction
-	receipts []*types.Receipt
-	ctx      consensus.Cancel
+	header *types.Header
+	ctx    consensus.Cancel
 }
 
 func (e *environment) Number() *big.Int {
@@ -182,7 +182,7 @@ func (miner *Miner) SetRecommitInterval(interval time.Duration) {
 }
 
 // Pending returns the currently pending block and associated state.
-func (miner *Miner) Pending() (*types.Block, *state.IntraBlockState, *state.TrieDbState) {
+func (miner *Miner) Pending() (*types.Block, *state.IntraBlockState) {
 	return miner.worker.pending()
 }
 
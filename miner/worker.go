@@ -17,10 +17,7 @@
 package miner
 
 import (
-	"bytes"
-	"context"
 	"errors"
-	"fmt"
 	"math/big"
 	"math/rand"
 	"sync"
@@ -30,14 +27,13 @@ import (
 	mapset "github.com/deckarep/golang-set"
 
 	"github.com/ledgerwatch/turbo-geth/common"
-	"github.com/ledgerwatch/turbo-geth/common/debug"
 	"github.com/ledgerwatch/turbo-geth/consensus"
-	"github.com/ledgerwatch/turbo-geth/consensus/misc"
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/core/state"
 	"github.com/ledgerwatch/turbo-geth/core/types"
 	"github.com/ledgerwatch/turbo-geth/core/vm"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/event"
 	"github.com/ledgerwatch/turbo-geth/log"
 	"github.com/ledgerwatch/turbo-geth/params"
@@ -83,11 +79,10 @@ const (
 	staleThreshold = 7
 )
 
-// task contains all information for consensus engine sealing and result submitting.
+// task contains all information of the block produced by the mining stages.
 type task struct {
 	receipts  []*types.Receipt
 	state     *state.IntraBlockState
-	tds       *state.TrieDbState
 	block     *types.Block
 	createdAt time.Time
 	ctx       consensus.Cancel
@@ -136,7 +131,7 @@ type worker struct {
 
 	// Channels
 	newWorkCh          chan *newWorkReq
-	taskCh             chan *task
+	resultCh           chan consensus.ResultWithContext
 	startCh            chan struct{}
 	exitCh             chan struct{}
 	resubmitIntervalCh chan time.Duration
@@ -149,10 +144,11 @@ type worker struct {
 	coinbase common.Address
 	extra    []byte
 
-	snapshotMu    sync.RWMutex // The lock used to protect the block snapshot and state snapshot
+	snapshotMu    sync.RWMutex // The lock used to protect the block snapshot
 	snapshotBlock *types.Block
-	snapshotState *state.IntraBlockState
-	snapshotTds   *state.TrieDbState
+	snapshotState ethdb.Database // The state changes of the snapshot block over the head state
+
+	sealCancel consensus.Cancel // Aborts the sealing of the latest block, superseded by the next one
 
 	// atomic status counters
 	running int32 // The indicator whether the consensus engine is running or not.
@@ -194,7 +190,8 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		hooks:              h,
 		uncles:             newUncles(),
 		newWorkCh:          make(chan *newWorkReq, 1),
-		taskCh:             make(chan *task, 1),
+		resultCh:           make(chan consensus.ResultWithContext, resultQueueSize),
+		sealCancel:         consensus.StabCancel(),
 		exitCh:             make(chan struct{}),
 		startCh:            make(chan struct{}, 1),
 		resubmitIntervalCh: make(chan time.Duration),
@@ -242,16 +239,16 @@ func (w *worker) enablePreseal() {
 	atomic.StoreUint32(&w.noempty, 0)
 }
 
-// pending returns the pending state and corresponding block.
-func (w *worker) pending() (*types.Block, *state.IntraBlockState, *state.TrieDbState) {
+// pending returns the pending block and the state after it.
+func (w *worker) pending() (*types.Block, *state.IntraBlockState) {
 	// return a snapshot to avoid contention on currentMu mutex
 	w.snapshotMu.RLock()
 	defer w.snapshotMu.RUnlock()
-	if w.snapshotState == nil {
-		return nil, nil, nil
+	if w.snapshotBlock == nil {
+		return nil, nil
 	}
 
-	return w.snapshotBlock, w.snapshotState, w.snapshotTds.Copy()
+	return w.snapshotBlock, state.New(state.NewPlainStateReader(w.snapshotState))
 }
 
 // pendingBlock returns pending block.
@@ -288,7 +285,7 @@ func (w *worker) init() {
 		go w.mainLoop()
 		go w.newWorkLoop(recommit)
 		go w.chainEvents(timestamp, commit)
-		go w.taskLoop()
+		go w.resultLoop()
 	})
 }
 
@@ -393,14 +390,13 @@ func (w *worker) getCommit() (func(ctx consensus.Cancel, noempty bool, s int32),
 	timestamp := new(int64) // timestamp for each round of mining.
 
 	return func(ctx consensus.Cancel, noempty bool, s int32) {
+		// Interrupt the previous work, the new one gets its own signal
 		if v := interrupt.Load(); v != nil {
 			stored := v.(*int32)
 			atomic.StoreInt32(stored, s)
-		} else {
-			interrupt.Store(new(int32))
 		}
-
-		v := interrupt.Load().(*int32)
+		v := new(int32)
+		interrupt.Store(v)
 
 		w.newWorkCh <- &newWorkReq{interrupt: v, noempty: noempty, timestamp: atomic.LoadInt64(timestamp), cancel: consensus.NewCancel()}
 		atomic.StoreInt32(&w.newTxs, 0)
@@ -418,35 +414,9 @@ func (w *worker) mainLoop() {
 			w.commitNewWork(req.cancel, req.interrupt, req.noempty, req.timestamp)
 
 		case ev := <-w.txsCh:
-			//fixme can be removed?
-
-			// Apply transactions to the pending state if we're not mining.
-			//
-			// Note all transactions received may not be continuous with transactions
-			// already included in the current mining block. These transactions will
-			// be automatically eliminated.
-			if !w.isRunning() && w.current != nil {
-				// If block is already full, abort
-				if gp := w.current.gasPool; gp != nil && gp.Gas() < params.TxGas {
-					continue
-				}
-				w.mu.RLock()
-				coinbase := w.coinbase
-				w.mu.RUnlock()
-
-				txs := make(map[common.Address]types.Transactions)
-				for _, tx := range ev.Txs {
-					acc, _ := types.Sender(w.current.signer, tx)
-					txs[acc] = append(txs[acc], tx)
-				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
-				tcount := w.current.tcount
-				w.commitTransactions(txset, coinbase, nil)
-				// Only update the snapshot if any new transactons were added
-				// to the pending block
-				if tcount != w.current.tcount {
-					w.updateSnapshot()
-				}
+			// Rebuild the pending block with the new transactions if we're not mining.
+			if !w.isRunning() {
+				w.commitNewWork(consensus.StabCancel(), nil, true, time.Now().Unix())
 			} else {
 				// Special case, if the consensus engine is 0 period clique(dev mode),
 				// submit mining work here since all empty submission will be rejected
@@ -528,35 +498,10 @@ func (w *worker) chainEvents(timestamp *int64, commit func(ctx consensus.Cancel,
 					w.uncles.setRemote(ev.Block)
 				}
 
-				// fixme can be removed
 				// If our mining block contains less than 2 uncle blocks,
-				// add the new uncle block if valid and regenerate a mining block.
+				// regenerate the mining block to include the new uncle block.
 				if w.isRunning() && w.current != nil && w.current.uncles.Cardinality() < 2 {
-					start := time.Now()
-					if err := w.commitUncle(w.current, ev.Block.Header()); err != nil {
-						ctx.CancelFunc()
-						log.Debug("cannot commit uncle", "err", err)
-						return
-					}
-
-					var uncles []*types.Header
-					w.current.uncles.Each(func(item interface{}) bool {
-						hash, ok := item.(common.Hash)
-						if !ok {
-							return false
-						}
-						uncle, exist := w.uncles.get(hash)
-						if !exist {
-							return false
-						}
-						uncles = append(uncles, uncle.Header())
-						return false
-					})
-
-					if err := w.commit(ctx, uncles, nil, true, start); err != nil {
-						ctx.CancelFunc()
-						log.Debug("cannot commit a block", "err", err)
-					}
+					commit(ctx, false, commitInterruptResubmit)
 				}
 			}(w.getCanonicalChainContext(), ev)
 
@@ -571,44 +516,20 @@ func (w *worker) chainEvents(timestamp *int64, commit func(ctx consensus.Cancel,
 	}
 }
 
-// taskLoop is a standalone goroutine to fetch sealing task from the generator and
-// push them to consensus engine.
-func (w *worker) taskLoop() {
-	var prev common.Hash
-
+// resultLoop is a standalone goroutine to handle sealing result submitting
+// and flush relative data to the database.
+func (w *worker) resultLoop() {
 	for {
 		select {
-		case task := <-w.taskCh:
-			log.Warn("mining task", "number", task.block.NumberU64(), "hash", task.block.Hash().String())
-
-			if w.newTaskHook != nil {
-				w.newTaskHook(task)
-			}
-
-			// Reject duplicate sealing work due to resubmitting.
-			sealHash := w.engine.SealHash(task.block.Header())
-			if sealHash == prev {
-				continue
-			}
-			prev = sealHash
-
-			if w.skipSealHook != nil && w.skipSealHook(task) {
-				continue
-			}
-
-			resultCh := make(chan consensus.ResultWithContext, 1)
-			if err := w.engine.Seal(task.ctx, w.chain, task.block, resultCh, task.ctx.Done()); err != nil {
-				log.Warn("Block sealing failed", "err", err)
-			}
-
-			w.insertToChain(<-resultCh, task.createdAt, sealHash, task, false)
+		case result := <-w.resultCh:
+			w.insertToChain(result)
 		case <-w.exitCh:
 			return
 		}
 	}
 }
 
-func (w *worker) insertToChain(result consensus.ResultWithContext, createdAt time.Time, sealHash common.Hash, task *task, directInsert bool) {
+func (w *worker) insertToChain(result consensus.ResultWithContext) {
 	// Short circuit when receiving empty result.
 	if result.Block == nil {
 		return
@@ -621,46 +542,12 @@ func (w *worker) insertToChain(result consensus.ResultWithContext, createdAt tim
 		return
 	}
 
-	// Different block could share same sealhash, deep copy here to prevent write-write conflict.
-	if directInsert {
-		var (
-			receipts = make([]*types.Receipt, len(task.receipts))
-			logs     = make([]*types.Log, len(task.receipts))
-		)
-		hash := block.Hash()
-
-		for i, receipt := range task.receipts {
-			// add block location fields
-			receipt.BlockHash = hash
-			receipt.BlockNumber = block.Number()
-			receipt.TransactionIndex = uint(i)
-
-			receipts[i] = new(types.Receipt)
-			*receipts[i] = *receipt
-
-			// Update the block hash in all logs since it is now available and not when the
-			// receipt/log of individual transactions were created.
-			for _, log := range receipt.Logs {
-				log.BlockHash = hash
-			}
-			logs = append(logs, receipt.Logs...)
-		}
-
-		// Commit block and state to database.
-		_, err := w.chain.WriteBlockWithState(result.Cancel, block, receipts, logs, task.state, task.tds, true)
-		if err != nil {
-			log.Error("Failed writing block with state", "err", err)
-			return
-		}
-
-		log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealHash, "hash", block.Hash(),
-			"elapsed", common.PrettyDuration(time.Since(createdAt)), "difficulty", block.Difficulty())
-	} else {
-		if _, err := stagedsync.InsertBlockInStages(w.chain.ChainDb(), w.chain.Config(), &vm.Config{}, w.chain.Engine(), block, true /* checkRoot */); err != nil {
-			log.Error("Failed writing block to chain", "err", err)
-			return
-		}
+	if _, err := stagedsync.InsertBlockInStages(w.chain.ChainDb(), w.chain.Config(), &vm.Config{}, w.chain.Engine(), block, true /* checkRoot */); err != nil {
+		log.Error("Failed writing block to chain", "err", err)
+		return
 	}
+	log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()), "hash", block.Hash(),
+		"difficulty", block.Difficulty())
 
 	// Broadcast the block and announce chain insertion event
 	_ = w.mux.Post(core.NewMinedBlockEvent{Block: block})
@@ -674,15 +561,7 @@ func (w *worker) makeCurrent(ctx consensus.Cancel, parent *types.Block, header *
 	default:
 	}
 
-	stateV, tds, err := GetState(w.chain, parent)
-	if err != nil {
-		return err
-	}
-
 	env := &environment{
-		signer:    types.NewEIP155Signer(w.chainConfig.ChainID),
-		state:     stateV,
-		tds:       tds,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
@@ -700,9 +579,6 @@ func (w *worker) makeCurrent(ctx consensus.Cancel, parent *types.Block, header *
 		env.ancestors.Add(ancestor.Hash())
 	}
 
-	// Keep track of transactions which return errors so they can be removed
-	env.tcount = 0
-
 	if w.current == nil {
 		w.current = env
 	} else {
@@ -731,171 +607,16 @@ func (w *worker) commitUncle(env *environment, uncle *types.Header) error {
 }
 
 // updateSnapshot updates pending snapshot block and state.
-// Note this function assumes the current variable is thread safe.
-func (w *worker) updateSnapshot() {
+func (w *worker) updateSnapshot(block *types.Block, pendingState ethdb.Database) {
 	w.snapshotMu.Lock()
 	defer w.snapshotMu.Unlock()
 
-	var uncles []*types.Header
-	w.current.uncles.Each(func(item interface{}) bool {
-		hash, ok := item.(common.Hash)
-		if !ok {
-			return false
-		}
-		uncle, exist := w.uncles.get(hash)
-		if !exist {
-			return false
-		}
-		uncles = append(uncles, uncle.Header())
-		return false
-	})
-
-	w.snapshotBlock = types.NewBlock(
-		w.current.GetHeader(),
-		w.current.txs,
-		uncles,
-		w.current.receipts,
-	)
-
-	w.snapshotState = w.current.state
-	w.snapshotTds = w.current.tds.WithNewBuffer()
+	w.snapshotBlock = block
+	w.snapshotState = pendingState
 }
 
-func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
-	snap := w.current.state.Snapshot()
-
-	header := w.current.GetHeader()
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.tds.TrieStateWriter(), header, tx, &header.GasUsed, *w.chain.GetVMConfig())
-	if err != nil {
-		w.current.state.RevertToSnapshot(snap)
-		return nil, err
-	}
-
-	if !w.chainConfig.IsByzantium(w.current.Number()) {
-		w.current.tds.StartNewBuffer()
-	}
-	w.current.txs = append(w.current.txs, tx)
-	w.current.receipts = append(w.current.receipts, receipt)
-
-	return receipt.Logs, nil
-}
-
-func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
-	// Short circuit if current is nil
-	if w.current == nil {
-		return true
-	}
-
-	header := w.current.GetHeader()
-	if w.current.gasPool == nil {
-		w.current.gasPool = new(core.GasPool).AddGas(header.GasLimit)
-	}
-
-	w.current.tds.StartNewBuffer()
-	var coalescedLogs []*types.Log
-
-	for {
-		// In the following three cases, we will interrupt the execution of the transaction.
-		// (1) new head block event arrival, the interrupt signal is 1
-		// (2) worker start or restart, the interrupt signal is 1
-		// (3) worker recreate the mining block with any newly arrived transactions, the interrupt signal is 2.
-		// For the first two cases, the semi-finished work will be discarded.
-		// For the third case, the semi-finished work will be submitted to the consensus engine.
-		if interrupt != nil && atomic.LoadInt32(interrupt) != commitInterruptNone {
-			// Notify resubmit loop to increase resubmitting interval due to too frequent commits.
-			if atomic.LoadInt32(interrupt) == commitInterruptResubmit {
-				ratio := float64(header.GasLimit-w.current.gasPool.Gas()) / float64(header.GasLimit)
-				if ratio < 0.1 {
-					ratio = 0.1
-				}
-				w.resubmitAdjustCh <- &intervalAdjust{
-					ratio: ratio,
-					inc:   true,
-				}
-			}
-			return atomic.LoadInt32(interrupt) == commitInterruptNewHead
-		}
-		// If we don't have enough gas for any further transactions then we're done
-		if w.current.gasPool.Gas() < params.TxGas {
-			log.Trace("Not enough gas for further transactions", "have", w.current.gasPool, "want", params.TxGas)
-			break
-		}
-		// Retrieve the next transaction and abort if all done
-		tx := txs.Peek()
-		if tx == nil {
-			break
-		}
-		// Error may be ignored here. The error has already been checked
-		// during transaction acceptance is the transaction pool.
-		//
-		// We use the eip155 signer regardless of the current hf.
-		from, _ := types.Sender(w.current.signer, tx)
-		// Check whether the tx is replay protected. If we're not in the EIP155 hf
-		// phase, start ignoring the sender until we do.
-		if tx.Protected() && !w.chainConfig.IsEIP155(header.Number) {
-			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
-
-			txs.Pop()
-			continue
-		}
-		// Start executing the transaction
-		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
-
-		logs, err := w.commitTransaction(tx, coinbase)
-		switch err {
-		case core.ErrGasLimitReached:
-			// Pop the current out-of-gas transaction without shifting in the next from the account
-			log.Trace("Gas limit exceeded for current block", "sender", from)
-			txs.Pop()
-
-		case core.ErrNonceTooLow:
-			// New head notification data race between the transaction pool and miner, shift
-			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
-			txs.Shift()
-
-		case core.ErrNonceTooHigh:
-			// Reorg notification data race between the transaction pool and miner, skip account =
-			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
-			txs.Pop()
-
-		case nil:
-			// Everything ok, collect the logs and shift in the next transaction from the same account
-			coalescedLogs = append(coalescedLogs, logs...)
-			w.current.tcount++
-			txs.Shift()
-
-		default:
-			// Strange error, discard the transaction and get the next in line (note, the
-			// nonce-too-high clause will prevent us from executing in vain).
-			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
-			txs.Shift()
-		}
-	}
-
-	if !w.isRunning() && len(coalescedLogs) > 0 {
-		// We don't push the pendingLogsEvent while we are mining. The reason is that
-		// when we are mining, the worker will regenerate a mining block every 3 seconds.
-		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
-
-		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
-		// logs by filling in the block hash when the block was mined by the local miner. This can
-		// cause a race condition if a log was "upgraded" before the PendingLogsEvent is processed.
-		cpy := make([]*types.Log, len(coalescedLogs))
-		for i, l := range coalescedLogs {
-			cpy[i] = new(types.Log)
-			*cpy[i] = *l
-		}
-		w.pendingLogsFeed.Send(cpy)
-	}
-	// Notify resubmit loop to decrease resubmitting interval if current interval is larger
-	// than the user-specified one.
-	if interrupt != nil {
-		w.resubmitAdjustCh <- &intervalAdjust{inc: false}
-	}
-	return false
-}
-
-// commitNewWork generates several new sealing tasks based on the parent block.
+// commitNewWork produces a new block on top of the head of the chain through the mining stages.
+// The block is sealed if the worker is running, otherwise it only becomes the pending block.
 func (w *worker) commitNewWork(ctx consensus.Cancel, interrupt *int32, noempty bool, timestamp int64) {
 	select {
 	case <-ctx.Done():
@@ -904,74 +625,37 @@ func (w *worker) commitNewWork(ctx consensus.Cancel, interrupt *int32, noempty b
 	}
 
 	w.mu.RLock()
-	defer w.mu.RUnlock()
+	coinbase, extra := w.coinbase, w.extra
+	w.mu.RUnlock()
 
 	tstart := time.Now()
 	parent := w.chain.CurrentBlock()
-
-	if parent.Time() >= uint64(timestamp) {
-		timestamp = int64(parent.Time() + 1)
-	}
-
-	num := parent.Number()
-	header := &types.Header{
-		ParentHash: parent.Hash(),
-		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent, w.config.GasFloor, w.config.GasCeil),
-		Extra:      w.extra,
-		Time:       uint64(timestamp),
-	}
+	running := w.isRunning()
 
 	// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
-	if w.isRunning() {
-		if w.coinbase == (common.Address{}) {
+	if running {
+		if coinbase == (common.Address{}) {
 			log.Error("Refusing to mine without etherbase")
 			ctx.CancelFunc()
 			return
 		}
-		header.Coinbase = w.coinbase
-	}
-
-	if err := w.engine.Prepare(w.chain, header); err != nil {
-		log.Error("Failed to prepare header for mining",
-			"err", err,
-			"headerNumber", header.Number.Uint64(),
-			"headerRoot", header.Root.String(),
-			"headerParentHash", header.ParentHash.String(),
-			"parentNumber", parent.Number().Uint64(),
-			"parentHash", parent.Hash().String(),
-			"callers", debug.Callers(10))
-		ctx.CancelFunc()
-		return
+	} else {
+		coinbase = common.Address{}
 	}
 
-	// If we are care about TheDAO hard-fork check whether to override the extra-data or not
-	if daoBlock := w.chainConfig.DAOForkBlock; daoBlock != nil {
-		// Check whether the block is among the fork extra-override range
-		limit := new(big.Int).Add(daoBlock, params.DAOForkExtraRange)
-		if header.Number.Cmp(daoBlock) >= 0 && header.Number.Cmp(limit) < 0 {
-			// Depending whether we support or oppose the fork, override differently
-			if w.chainConfig.DAOForkSupport {
-				header.Extra = common.CopyBytes(params.DAOForkBlockExtra)
-			} else if bytes.Equal(header.Extra, params.DAOForkBlockExtra) {
-				header.Extra = []byte{} // If miner opposes, don't let it use the reserved extra-data
-			}
-		}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
 	}
 	// Could potentially happen if starting to mine in an odd state.
-	err := w.makeCurrent(ctx, parent, header)
-	if err != nil {
+	if err := w.makeCurrent(ctx, parent, header); err != nil {
 		log.Error("Failed to create mining context", "err", err)
 		ctx.CancelFunc()
 		return
 	}
 
-	// Create the current work task and check any fork transitions needed
-	env := w.current
-	if w.chainConfig.DAOForkSupport && w.chainConfig.DAOForkBlock != nil && w.chainConfig.DAOForkBlock.Cmp(header.Number) == 0 {
-		misc.ApplyDAOHardFork(env.state)
-	}
 	// Accumulate the miningUncles for the current block
+	env := w.current
 	uncles := make([]*types.Header, 0, 2)
 	commitUncles := func(u *miningUncles) {
 		u.Lock()
@@ -988,7 +672,7 @@ func (w *worker) commitNewWork(ctx consensus.Cancel, interrupt *int32, noempty b
 				if len(uncles) == 2 {
 					break
 				}
-				if err = w.commitUncle(env, uncle.Header()); err != nil {
+				if err := w.commitUncle(env, uncle.Header()); err != nil {
 					log.Trace("Possible uncle rejected", "hash", hash, "reason", err)
 				} else {
 					log.Debug("Committing new uncle to block", "hash", hash)
@@ -1001,102 +685,149 @@ func (w *worker) commitNewWork(ctx consensus.Cancel, interrupt *int32, noempty b
 	// Prefer to locally generated uncle
 	commitUncles(w.uncles)
 
-	// Create an empty block based on temporary copied state for
-	// sealing in advance without waiting block execution finished.
+	newCfg := func() *stagedsync.MiningCfg {
+		return &stagedsync.MiningCfg{
+			Etherbase: coinbase,
+			ExtraData: extra,
+			GasFloor:  w.config.GasFloor,
+			GasCeil:   w.config.GasCeil,
+			Timestamp: uint64(timestamp),
+			Block:     stagedsync.MiningBlock{Uncles: uncles},
+		}
+	}
+
+	// Create an empty block for sealing in advance
+	// without waiting block execution finished.
+	var empty *task
+	var emptyState ethdb.Database
 	if !noempty && atomic.LoadUint32(&w.noempty) == 0 {
 		now := time.Now()
-		if err = w.commit(ctx, uncles, nil, false, tstart); err != nil {
+		var err error
+		if empty, emptyState, err = w.mine(consensus.NewCancel(ctx), newCfg(), nil, nil, running); err != nil {
 			log.Error("Failed to commit empty block", "err", err)
 			ctx.CancelFunc()
+			return
 		}
 		log.Info("Commit an empty block", "number", header.Number, "duration", time.Since(now))
 	}
 
-	// Fill the block with all available pending transactions.
+	// Short circuit if there is no available pending transactions.
+	// But if we disable empty precommit already, ignore it. Since
+	// empty block is necessary to keep the liveness of the network.
 	pending, err := w.eth.TxPool().Pending()
 	if err != nil {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		ctx.CancelFunc()
 		return
 	}
-	// Short circuit if there is no available pending transactions.
-	// But if we disable empty precommit already, ignore it. Since
-	// empty block is necessary to keep the liveness of the network.
-	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
-		w.updateSnapshot()
+	if len(pending) == 0 && empty != nil {
+		w.updateSnapshot(empty.block, emptyState)
 		return
 	}
 
-	// Split the pending transactions into locals and remotes
-	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
-	for _, account := range w.eth.TxPool().Locals() {
-		if txs := remoteTxs[account]; len(txs) > 0 {
-			delete(remoteTxs, account)
-			localTxs[account] = txs
-		}
+	// Fill the block with all available pending transactions.
+	if running && w.fullTaskHook != nil {
+		w.fullTaskHook()
 	}
-	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
-			return
-		}
+	task, pendingState, err := w.mine(ctx, newCfg(), w.eth.TxPool(), interrupt, running)
+	if err != nil {
+		log.Error("Failed to mine block", "number", header.Number, "err", err)
+		ctx.CancelFunc()
+		return
 	}
-	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs)
-		if w.commitTransactions(txs, w.coinbase, interrupt) {
+	block := task.block
+	if interrupt != nil {
+		switch atomic.LoadInt32(interrupt) {
+		case commitInterruptNewHead:
+			// The block is superseded by the one on top of the new head
 			return
+		case commitInterruptResubmit:
+			// Notify resubmit loop to increase resubmitting interval due to too frequent commits.
+			ratio := float64(block.GasUsed()) / float64(block.GasLimit())
+			if ratio < 0.1 {
+				ratio = 0.1
+			}
+			w.resubmitAdjustCh <- &intervalAdjust{
+				ratio: ratio,
+				inc:   true,
+			}
+		default:
+			// Notify resubmit loop to decrease resubmitting interval if current interval is larger
+			// than the user-specified one.
+			w.resubmitAdjustCh <- &intervalAdjust{inc: false}
 		}
 	}
 
-	now := time.Now()
-	if err = w.commit(ctx, uncles, w.fullTaskHook, true, tstart); err != nil {
-		log.Error("Failed to commit block", "err", err)
-		ctx.CancelFunc()
+	w.current.SetHeader(block.Header())
+	if running {
+		log.Info("Commit new mining work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
+			"uncles", len(block.Uncles()), "txs", len(block.Transactions()),
+			"gas", block.GasUsed(), "fees", totalFees(block, task.receipts),
+			"elapsed", common.PrettyDuration(time.Since(tstart)))
+	} else {
+		// We don't push the pendingLogsEvent while we are mining. The reason is that
+		// when we are mining, the worker will regenerate a mining block every 3 seconds.
+		// In order to avoid pushing the repeated pendingLog, we disable the pending log pushing.
+		var logs []*types.Log
+		for _, receipt := range task.receipts {
+			logs = append(logs, receipt.Logs...)
+		}
+		if len(logs) > 0 {
+			w.pendingLogsFeed.Send(logs)
+		}
 	}
-	log.Info("Commit a block with transactions", "number", header.Number, "duration", time.Since(now))
-}
-
-// commit runs any post-transaction state modifications, assembles the final block
-// and commits new work if consensus engine is running.
-func (w *worker) commit(ctx consensus.Cancel, uncles []*types.Header, interval func(), update bool, start time.Time) error {
-	// Deep copy receipts here to avoid interaction between different tasks.
-	receipts := copyReceipts(w.current.receipts)
-
-	s := &(*w.current.state)
-
-	block, err := NewBlock(w.engine, s, w.current.tds, w.chain.Config(), w.current.GetHeader(), w.current.txs, uncles, w.current.receipts)
-	if err != nil {
-		return err
+	w.updateSnapshot(block, pendingState)
+}
+
+// mine produces a block through the mining stages, with the transactions of txPool if it is given, and returns
+// it together with its state changes. The execution stops including the transactions once the work is interrupted.
+// If the worker is running, the block supersedes the one being sealed, unless the work is interrupted by a new head.
+func (w *worker) mine(ctx consensus.Cancel, cfg *stagedsync.MiningCfg, txPool *core.TxPool, interrupt *int32, running bool) (*task, ethdb.Database, error) {
+	pendingState := w.chain.ChainDb().NewBatch()
+	newTask := func(current *stagedsync.MiningBlock) *task {
+		// Deep copy receipts here to avoid interaction between different tasks.
+		return &task{
+			receipts:  copyReceipts(current.Receipts),
+			state:     state.New(state.NewPlainStateReader(pendingState)),
+			block:     current.Block(),
+			createdAt: time.Now(),
+			ctx:       ctx,
+		}
 	}
 
-	w.current.SetHeader(block.Header())
-
-	if w.isRunning() {
-		if interval != nil {
-			interval()
+	var sealing *task
+	cfg.PendingState = pendingState
+	if interrupt != nil {
+		cfg.Interrupt = func() bool {
+			return atomic.LoadInt32(interrupt) != commitInterruptNone
 		}
-
-		select {
-		case w.taskCh <- &task{receipts: receipts, state: s, tds: w.current.tds, block: block, createdAt: time.Now(), ctx: ctx}:
-			log.Warn("mining: worker task event",
-				"number", block.NumberU64(),
-				"hash", block.Hash().String(),
-				"parentHash", block.ParentHash().String(),
-			)
-
-			log.Info("Commit new mining work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
-				"uncles", len(uncles), "txs", w.current.tcount,
-				"gas", block.GasUsed(), "fees", totalFees(block, receipts),
-				"elapsed", common.PrettyDuration(time.Since(start)))
-
-		case <-w.exitCh:
-			log.Info("Worker has exited")
+	}
+	if running {
+		cfg.SealResults, cfg.SealCancel = w.resultCh, ctx
+		cfg.BeforeSeal = func(current *stagedsync.MiningBlock) bool {
+			if interrupt != nil && atomic.LoadInt32(interrupt) == commitInterruptNewHead {
+				return false
+			}
+			sealing = newTask(current)
+			if w.newTaskHook != nil {
+				w.newTaskHook(sealing)
+			}
+			if w.skipSealHook != nil && w.skipSealHook(sealing) {
+				return false
+			}
+			// The new block supersedes the one being sealed
+			w.sealCancel.CancelFunc()
+			w.sealCancel = ctx
+			return true
 		}
 	}
-	if update {
-		w.updateSnapshot()
+	if err := stagedsync.MineBlockInStages(w.chain.ChainDb(), w.chainConfig, w.chain.GetVMConfig(), w.engine, txPool, cfg, ctx.Done()); err != nil {
+		return nil, nil, err
 	}
-	return nil
+	if sealing == nil {
+		sealing = newTask(&cfg.Block)
+	}
+	return sealing, pendingState, nil
 }
 
 // copyReceipts makes a deep copy of the given receipts.
@@ -1145,55 +876,3 @@ func totalFees(block *types.Block, receipts []*types.Receipt) *big.Float {
 	}
 	return new(big.Float).Quo(new(big.Float).SetInt(feesWei), new(big.Float).SetInt(big.NewInt(params.Ether)))
 }
-
-func NewBlock(engine consensus.Engine, s *state.IntraBlockState, tds *state.TrieDbState, chainConfig *params.ChainConfig, header *types.Header, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
-	block, err := engine.FinalizeAndAssemble(chainConfig, header, s, txs, uncles, receipts)
-	if err != nil {
-		return nil, err
-	}
-
-	ctx := chainConfig.WithEIPsFlags(context.Background(), header.Number)
-	if err = s.FinalizeTx(ctx, tds.TrieStateWriter()); err != nil {
-		return nil, err
-	}
-
-	if _, err = tds.ResolveStateTrie(false, false); err != nil {
-		return nil, fmt.Errorf("newBlock on %s: %w", header.Number.String(), err)
-	}
-
-	root, err := tds.CalcTrieRoots(false)
-	if err != nil {
-		return nil, err
-	}
-
-	header = block.Header()
-	header.Root = root
-
-	return types.NewBlock(header, txs, uncles, receipts), nil
-}
-
-func GetState(blockchain *core.BlockChain, parent *types.Block) (*state.IntraBlockState, *state.TrieDbState, error) {
-	current := blockchain.CurrentBlock()
-	if current.Number().Cmp(parent.Number()) != 0 || current.Root() != parent.Root() {
-		log.Error("mining not on a current chain",
-			"currentNumber", current.Number().Uint64(),
-			"parentNumber", parent.Number().Uint64(),
-			"currentRoot", current.Root().String(),
-			"parentRoot", parent.Root().String(),
-		)
-		return nil, nil, errors.New("mining in an odd state")
-	}
-
-	tds, err := blockchain.GetTrieDbState()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	tds = tds.WithNewBuffer()
-	tds.SetResolveReads(false)
-	tds.SetNoHistory(true)
-
-	statedb := state.New(tds)
-
-	return statedb, tds, nil
-}
@@ -125,6 +125,16 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// PeerFilter, if set, is asked whether the node may be connected. Rejected
+	// nodes are not dialed, and their inbound connections are dropped after
+	// the encryption handshake.
+	PeerFilter func(*enode.Node) bool `toml:"-"`
+
+	// PreferNode, if set, selects the nodes to be dialed preferentially, judging by
+	// their ENR records. The discv5 node discovery is started to find such nodes,
+	// and the matching nodes are mixed into the dial candidates besides the random ones.
+	PreferNode func(*enode.Node) bool `toml:"-"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -189,6 +199,7 @@ type Server struct {
 	localnode *enode.LocalNode
 	ntab      *discover.UDPv4
 	DiscV5    *discv5.Network
+	discENR   *discover.UDPv5 // Discovery of the preferred nodes by their ENR records
 	discmix   *enode.FairMix
 	dialsched *dialScheduler
 
@@ -318,6 +329,15 @@ func (srv *Server) SetP2PListenFunc(listenFunc func(network, addr string) (net.L
 	srv.listenFunc = listenFunc
 }
 
+// PreferredNodes returns the iterator of the discovered nodes preferred by PreferNode,
+// or nil if their discovery is not running.
+func (srv *Server) PreferredNodes() enode.Iterator {
+	if srv.discENR == nil {
+		return nil
+	}
+	return enode.Filter(srv.discENR.RandomNodes(), srv.PreferNode)
+}
+
 // PeerCount returns the number of connected peers.
 func (srv *Server) PeerCount() int {
 	var count int
@@ -584,8 +604,13 @@ func (srv *Server) setupDiscovery() error {
 	// Discovery V4
 	var unhandled chan discover.ReadPacket
 	var sconn *sharedUDPConn
+	withENR := srv.PreferNode != nil && !srv.NoDiscovery
+	if withENR && srv.DiscoveryV5 {
+		srv.log.Warn("Discovery of the preferred nodes is not available together with the topic discovery")
+		withENR = false
+	}
 	if !srv.NoDiscovery {
-		if srv.DiscoveryV5 {
+		if srv.DiscoveryV5 || withENR {
 			unhandled = make(chan discover.ReadPacket, 100)
 			sconn = &sharedUDPConn{conn, unhandled}
 		}
@@ -604,6 +629,22 @@ func (srv *Server) setupDiscovery() error {
 		srv.discmix.AddSource(ntab.RandomNodes())
 	}
 
+	// Discovery V5 exchanging the ENR records, to find the preferred nodes
+	if withENR {
+		cfg := discover.Config{
+			PrivateKey:  srv.PrivateKey,
+			NetRestrict: srv.NetRestrict,
+			Bootnodes:   srv.BootstrapNodes,
+			Log:         srv.log,
+		}
+		discENR, err := discover.ListenV5(sconn, srv.localnode, cfg)
+		if err != nil {
+			return err
+		}
+		srv.discENR = discENR
+		srv.discmix.AddSource(enode.Filter(discENR.RandomNodes(), srv.PreferNode))
+	}
+
 	// Discovery V5
 	if srv.DiscoveryV5 {
 		var ntab *discv5.Network
@@ -631,6 +672,7 @@ func (srv *Server) setupDialScheduler() {
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
+		filter:         srv.PeerFilter,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -802,6 +844,9 @@ running:
 	if srv.DiscV5 != nil {
 		srv.DiscV5.Close()
 	}
+	if srv.discENR != nil {
+		srv.discENR.Close()
+	}
 	// Disconnect all peers.
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
@@ -826,6 +871,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case srv.PeerFilter != nil && !srv.PeerFilter(c.node):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
@@ -11,10 +11,14 @@ var DefaultFlags = []cli.Flag{
 	utils.DataDirFlag,
 	utils.KeyStoreDirFlag,
 	utils.EthashDatasetDirFlag,
+	utils.EthashCacheDirFlag,
+	utils.EthashCachesOnDiskFlag,
+	utils.EthashCachesAheadFlag,
 	utils.TxPoolLocalsFlag,
 	utils.TxPoolNoLocalsFlag,
 	utils.TxPoolJournalFlag,
 	utils.TxPoolRejournalFlag,
+	utils.TxPoolNoPersistFlag,
 	utils.TxPoolPriceLimitFlag,
 	utils.TxPoolPriceBumpFlag,
 	utils.TxPoolAccountSlotsFlag,
@@ -22,11 +26,17 @@ var DefaultFlags = []cli.Flag{
 	utils.TxPoolAccountQueueFlag,
 	utils.TxPoolGlobalQueueFlag,
 	utils.TxPoolLifetimeFlag,
+	utils.TxPoolPolicyFlag,
+	utils.TxPoolAllowSendersFlag,
+	utils.TxPoolDenySendersFlag,
+	utils.TxPoolSenderPriceLimitsFlag,
 	utils.TxLookupLimitFlag,
 	StorageModeFlag,
 	SnapshotModeFlag,
 	SeedSnapshotsFlag,
 	ExternalSnapshotDownloaderAddrFlag,
+	SnapServerFlag,
+	SnapSyncFlag,
 	CacheSizeFlag,
 	BatchSizeFlag,
 	DatabaseFlag,
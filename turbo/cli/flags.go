@@ -66,6 +66,16 @@ var (
 		Usage: `Seed snapshot seeding(default: true)`,
 	}
 
+	SnapServerFlag = cli.BoolFlag{
+		Name:  "snap.server",
+		Usage: "Serve the state ranges to the peers via snap/1 protocol",
+	}
+
+	SnapSyncFlag = cli.BoolFlag{
+		Name:  "snap.sync",
+		Usage: "Download the state of a recent block from the peers via snap/1 protocol instead of executing all the blocks (only on the first sync)",
+	}
+
 	ExternalSnapshotDownloaderAddrFlag = cli.StringFlag{
 		Name:  "snapshot.downloader.addr",
 		Usage: `enable external snapshot downloader`,
@@ -122,6 +132,8 @@ func ApplyFlagsForEthConfig(ctx *cli.Context, cfg *eth.Config) {
 	}
 	cfg.SnapshotMode = snMode
 	cfg.SnapshotSeeding = ctx.GlobalBool(SeedSnapshotsFlag.Name)
+	cfg.EnableSnapProtocol = ctx.GlobalBool(SnapServerFlag.Name)
+	cfg.SnapSync = ctx.GlobalBool(SnapSyncFlag.Name)
 
 	if ctx.GlobalString(CacheSizeFlag.Name) != "" {
 		err := cfg.CacheSize.UnmarshalText([]byte(ctx.GlobalString(CacheSizeFlag.Name)))
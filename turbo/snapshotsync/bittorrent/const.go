@@ -3,6 +3,7 @@ package bittorrent
 import (
 	"errors"
 
+	"github.com/anacrolix/torrent"
 	"github.com/anacrolix/torrent/metainfo"
 	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/turbo/snapshotsync"
@@ -20,6 +21,9 @@ const (
 
 	SnapshotInfoHashPrefix  = "ih"
 	SnapshotInfoBytesPrefix = "ib"
+
+	// PeerSourceENR marks the peers found by their ENR records on the discovery network
+	PeerSourceENR torrent.PeerSource = "E"
 )
 
 var (
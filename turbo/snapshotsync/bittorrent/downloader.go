@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
 	"github.com/ledgerwatch/turbo-geth/turbo/snapshotsync"
 	"golang.org/x/sync/errgroup"
 )
@@ -219,6 +221,34 @@ func (cli *Client) Download() {
 	}
 }
 
+// AddPeersFromNodes adds the nodes advertising the snapshots in their ENR records as the peers of the
+// matching torrents, so the snapshots are downloaded from them preferentially. Returns when the iterator
+// is closed
+func (cli *Client) AddPeersFromNodes(it enode.Iterator, networkID uint64) {
+	defer it.Close()
+	for it.Next() {
+		node := it.Node()
+		entry := snapshotsync.NodeSnapshots(node)
+		if entry == nil || entry.TorrentPort == 0 || entry.Block != SnapshotBlock {
+			continue
+		}
+		mode, err := entry.SnapshotMode()
+		if err != nil {
+			continue
+		}
+		peer := torrent.Peer{
+			Addr:   &net.TCPAddr{IP: node.IP(), Port: int(entry.TorrentPort)},
+			Source: PeerSourceENR,
+		}
+		for _, snapshotType := range mode.ToSnapshotTypes() {
+			if t, ok := cli.Cli.Torrent(TorrentHashes[networkID][snapshotType]); ok {
+				t.AddPeers([]torrent.Peer{peer})
+			}
+		}
+		log.Debug("Added snapshot seeder", "id", node.ID(), "addr", peer.Addr, "snapshots", entry.Mode)
+	}
+}
+
 func (cli *Client) GetSnapshots(db ethdb.Database, networkID uint64) (map[snapshotsync.SnapshotType]*snapshotsync.SnapshotsInfo, error) {
 	mp := make(map[snapshotsync.SnapshotType]*snapshotsync.SnapshotsInfo)
 	networkIDBytes := make([]byte, 8)
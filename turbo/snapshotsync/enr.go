@@ -0,0 +1,59 @@
+package snapshotsync
+
+import (
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// SnapshotsEntry is the "tgsnap" ENR entry advertising the snapshots the node seeds over bittorrent,
+// so the nodes downloading them can find the seeders on the discovery network
+type SnapshotsEntry struct {
+	Mode        string // Types of the snapshots, in the format of SnapshotMode.ToString
+	Block       uint64 // Block the snapshots are made at
+	TorrentPort uint16 // Port of the bittorrent client, 0 if it is not reachable directly
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e SnapshotsEntry) ENRKey() string {
+	return "tgsnap"
+}
+
+func NewSnapshotsEntry(mode SnapshotMode, block uint64, torrentPort int) *SnapshotsEntry {
+	return &SnapshotsEntry{Mode: mode.ToString(), Block: block, TorrentPort: uint16(torrentPort)}
+}
+
+// SnapshotMode returns the types of the snapshots the node seeds
+func (e SnapshotsEntry) SnapshotMode() (SnapshotMode, error) {
+	return SnapshotModeFromString(e.Mode)
+}
+
+// Covers tells whether all the snapshots of the given mode are seeded
+func (e SnapshotsEntry) Covers(mode SnapshotMode) bool {
+	seeded, err := e.SnapshotMode()
+	if err != nil {
+		return false
+	}
+	return (!mode.Headers || seeded.Headers) && (!mode.Bodies || seeded.Bodies) &&
+		(!mode.State || seeded.State) && (!mode.Receipts || seeded.Receipts)
+}
+
+// NodeSnapshots returns the snapshots entry of the node, or nil if the node does not seed snapshots
+func NodeSnapshots(n *enode.Node) *SnapshotsEntry {
+	var entry SnapshotsEntry
+	if n.Load(&entry) != nil {
+		return nil
+	}
+	return &entry
+}
+
+// SeedsSnapshots returns the filter of the discovered nodes, passing the ones which seed all the
+// snapshots of the given mode
+func SeedsSnapshots(mode SnapshotMode) func(*enode.Node) bool {
+	return func(n *enode.Node) bool {
+		entry := NodeSnapshots(n)
+		return entry != nil && entry.Covers(mode)
+	}
+}
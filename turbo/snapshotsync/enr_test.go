@@ -0,0 +1,28 @@
+package snapshotsync
+
+import (
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/p2p/enode"
+	"github.com/ledgerwatch/turbo-geth/p2p/enr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedsSnapshots(t *testing.T) {
+	var r enr.Record
+	r.Set(NewSnapshotsEntry(SnapshotMode{Headers: true, Bodies: true}, 11_000_000, 42069))
+	seeder := enode.SignNull(&r, enode.ID{1})
+	other := enode.SignNull(new(enr.Record), enode.ID{2})
+
+	entry := NodeSnapshots(seeder)
+	require.NotNil(t, entry)
+	assert.Equal(t, uint64(11_000_000), entry.Block)
+	assert.Equal(t, uint16(42069), entry.TorrentPort)
+	assert.Nil(t, NodeSnapshots(other))
+
+	assert.True(t, SeedsSnapshots(SnapshotMode{Headers: true})(seeder))
+	assert.True(t, SeedsSnapshots(SnapshotMode{Headers: true, Bodies: true})(seeder))
+	assert.False(t, SeedsSnapshots(SnapshotMode{Headers: true, State: true})(seeder))
+	assert.False(t, SeedsSnapshots(SnapshotMode{Headers: true})(other))
+}
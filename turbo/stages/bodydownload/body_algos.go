@@ -43,6 +43,7 @@ func (bd *BodyDownload) UpdateFromDb(db ethdb.Database) error {
 	bd.requestQueue = list.New()
 	bd.RequestQueueTimer = time.NewTimer(time.Hour)
 	bd.requestedMap = make(map[DoubleHash]uint64)
+	bd.requests = make(map[uint64]*BodyRequest)
 	for i := 0; i < len(bd.deliveries); i++ {
 		bd.deliveries[i] = nil
 	}
@@ -144,10 +145,7 @@ func (bd *BodyDownload) RequestMoreBodies(db ethdb.Database) *BodyRequest {
 			if header != nil {
 				bd.deliveries[b-bd.requestedLow] = types.NewBlockWithHeader(header) // Block without uncles and transactions
 				if header.UncleHash != types.EmptyUncleHash || header.TxHash != types.EmptyRootHash {
-					var doubleHash DoubleHash
-					copy(doubleHash[:], header.UncleHash.Bytes())
-					copy(doubleHash[common.HashLength:], header.TxHash.Bytes())
-					bd.requestedMap[doubleHash] = b
+					bd.requestedMap[headerDoubleHash(header)] = b
 				}
 			}
 		}
@@ -165,7 +163,12 @@ func (bd *BodyDownload) RequestMoreBodies(db ethdb.Database) *BodyRequest {
 		}
 	}
 	if len(blockNums) > 0 {
-		bodyReq = &BodyRequest{BlockNums: blockNums, Hashes: hashes, requested: reqBitmap}
+		bd.lastRequestID++
+		bodyReq = &BodyRequest{ID: bd.lastRequestID, BlockNums: blockNums, Hashes: hashes, requested: reqBitmap}
+		bd.requests[bodyReq.ID] = bodyReq
+		if bd.lastRequestID > RequestIDWindow {
+			delete(bd.requests, bd.lastRequestID-RequestIDWindow)
+		}
 		bd.required.AndNot(reqBitmap)
 	}
 	if !empties.IsEmpty() {
@@ -190,28 +193,99 @@ func (bd *BodyDownload) resetRequestQueueTimer(prevTopTime, currentTime uint64)
 	bd.RequestQueueTimer = time.NewTimer(time.Duration(nextTopTime-currentTime) * time.Second)
 }
 
-// DeliverBody takes the block body received from a peer and adds it to the various data structures
-func (bd *BodyDownload) DeliverBody(body *eth.BlockBody) (uint64, bool) {
+func bodyDoubleHash(body *eth.BlockBody) DoubleHash {
 	uncleHash := types.CalcUncleHash(body.Uncles)
 	txHash := types.DeriveSha(types.Transactions(body.Transactions))
 	var doubleHash DoubleHash
 	copy(doubleHash[:], uncleHash.Bytes())
 	copy(doubleHash[common.HashLength:], txHash.Bytes())
+	return doubleHash
+}
+
+func headerDoubleHash(header *types.Header) DoubleHash {
+	var doubleHash DoubleHash
+	copy(doubleHash[:], header.UncleHash.Bytes())
+	copy(doubleHash[common.HashLength:], header.TxHash.Bytes())
+	return doubleHash
+}
+
+// DeliverBody takes the block body received from a peer and adds it to the various data structures
+func (bd *BodyDownload) DeliverBody(body *eth.BlockBody) (uint64, bool) {
+	doubleHash := bodyDoubleHash(body)
 	bd.lock.Lock()
 	defer bd.lock.Unlock()
 	// Block numbers are added to the bd.delivered bitmap here, only for blocks for which the body has been received, and their double hashes are present in the bd.requesredMap
 	// Also, block numbers can be added to bd.delivered for empty blocks, above
 	if blockNum, ok := bd.requestedMap[doubleHash]; ok {
-		bd.delivered.Add(blockNum)
-		bd.requested.Remove(blockNum)
-		bd.required.Remove(blockNum) // This is not usually required, but helps deal with the situations when old request is cancelled just before blocks delivered that contained in that request
-		bd.deliveries[blockNum-bd.requestedLow] = bd.deliveries[blockNum-bd.requestedLow].WithBody(body.Transactions, body.Uncles)
-		delete(bd.requestedMap, doubleHash) // Delivered, cleaning up
+		bd.deliverBody(blockNum, doubleHash, body)
 		return blockNum, true
 	}
 	return 0, false
 }
 
+// DeliverBodies takes the block bodies received from a peer in response to the request with given ID.
+// The bodies come in the order of the request, possibly with some of them missing, so each body is matched to the
+// next requested block with the same uncle and transaction hashes. This way, the blocks with identical bodies are
+// told apart, and the response arriving after its request has timed out is still accepted.
+// Bodies with zero request ID (from peers before eth/66), or for the requests that fell out of the RequestIDWindow,
+// are matched by their hashes only, as in DeliverBody. Returns the numbers of the delivered blocks and the count of the
+// bodies that were not requested
+func (bd *BodyDownload) DeliverBodies(requestID uint64, bodies []*eth.BlockBody) ([]uint64, int) {
+	bd.lock.RLock()
+	bodyReq, ok := bd.requests[requestID]
+	bd.lock.RUnlock()
+	var delivered []uint64
+	var unrequested int
+	if !ok {
+		for _, body := range bodies {
+			if blockNum, ok1 := bd.DeliverBody(body); ok1 {
+				delivered = append(delivered, blockNum)
+			} else {
+				unrequested++
+			}
+		}
+		return delivered, unrequested
+	}
+	bd.lock.Lock()
+	defer bd.lock.Unlock()
+	delete(bd.requests, requestID) // Response received, cleaning up
+	i := 0
+	for _, body := range bodies {
+		doubleHash := bodyDoubleHash(body)
+		for ; i < len(bodyReq.BlockNums); i++ {
+			blockNum := bodyReq.BlockNums[i]
+			if blockNum < bd.requestedLow || bd.deliveries[blockNum-bd.requestedLow] == nil {
+				continue // Already delivered and inserted, or reset
+			}
+			if headerDoubleHash(bd.deliveries[blockNum-bd.requestedLow].Header()) == doubleHash {
+				break
+			}
+		}
+		if i == len(bodyReq.BlockNums) {
+			unrequested++
+			continue
+		}
+		blockNum := bodyReq.BlockNums[i]
+		i++
+		if bd.delivered.Contains(blockNum) {
+			continue // Duplicate delivery
+		}
+		bd.deliverBody(blockNum, doubleHash, body)
+		delivered = append(delivered, blockNum)
+	}
+	return delivered, unrequested
+}
+
+func (bd *BodyDownload) deliverBody(blockNum uint64, doubleHash DoubleHash, body *eth.BlockBody) {
+	bd.delivered.Add(blockNum)
+	bd.requested.Remove(blockNum)
+	bd.required.Remove(blockNum) // This is not usually required, but helps deal with the situations when old request is cancelled just before blocks delivered that contained in that request
+	bd.deliveries[blockNum-bd.requestedLow] = bd.deliveries[blockNum-bd.requestedLow].WithBody(body.Transactions, body.Uncles)
+	if bd.requestedMap[doubleHash] == blockNum {
+		delete(bd.requestedMap, doubleHash) // Delivered, cleaning up
+	}
+}
+
 func (bd *BodyDownload) FeedDeliveries() {
 	bd.lock.Lock()
 	defer bd.lock.Unlock()
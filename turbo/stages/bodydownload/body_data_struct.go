@@ -15,6 +15,9 @@ type DoubleHash [2 * common.HashLength]byte
 
 const MaxBodiesInRequest = 128
 
+// RequestIDWindow is how many most recent requests are remembered for matching their responses
+const RequestIDWindow = 1024
+
 // BodyDownload represents the state of body downloading process
 type BodyDownload struct {
 	lock              sync.RWMutex
@@ -28,6 +31,8 @@ type BodyDownload struct {
 	requestQueue      *list.List // Queue of items of type RequestQueueItem to deal with the request timeouts
 	RequestQueueTimer *time.Timer
 	blockChannel      chan *types.Block
+	lastRequestID     uint64
+	requests          map[uint64]*BodyRequest // Requests by request ID, within RequestIDWindow of lastRequestID, kept after the timeout to accept late responses
 }
 
 type RequestQueueItem struct {
@@ -37,6 +42,7 @@ type RequestQueueItem struct {
 
 // BodyRequest is a sketch of the request for block bodies, meaning that access to the database is required to convert it to the actual BlockBodies request (look up hashes of canonical blocks)
 type BodyRequest struct {
+	ID        uint64 // Request ID sent to the eth/66 peers, so that the response can be matched to the request
 	BlockNums []uint64
 	Hashes    []common.Hash
 	requested *roaring64.Bitmap
@@ -53,5 +59,6 @@ func NewBodyDownload(outstandingLimit int) *BodyDownload {
 		deliveries:        make([]*types.Block, outstandingLimit+MaxBodiesInRequest),
 		requestQueue:      list.New(),
 		RequestQueueTimer: time.NewTimer(time.Hour),
+		requests:          make(map[uint64]*BodyRequest),
 	}
 }
@@ -1,8 +1,14 @@
 package bodydownload
 
 import (
+	"context"
+	"math/big"
 	"testing"
 
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 )
 
@@ -14,3 +20,46 @@ func TestCreateBodyDownload(t *testing.T) {
 		t.Fatalf("update from db: %v", err)
 	}
 }
+
+func TestDeliverIdenticalBodies(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	// Three blocks with the identical bodies, which cannot be told apart by the uncle and transaction hashes
+	txs := types.Transactions{types.NewTransaction(0, [20]byte{1}, nil, 21000, nil, nil)}
+	for i := uint64(1); i <= 3; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), UncleHash: types.EmptyUncleHash, TxHash: types.DeriveSha(txs)}
+		rawdb.WriteHeader(context.Background(), db, header)
+		if err := rawdb.WriteCanonicalHash(db, header.Hash(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stages.SaveStageProgress(db, stages.Headers, 3); err != nil {
+		t.Fatal(err)
+	}
+	bd := NewBodyDownload(100)
+	if err := bd.UpdateFromDb(db); err != nil {
+		t.Fatalf("update from db: %v", err)
+	}
+	req := bd.RequestMoreBodies(db)
+	if req == nil || len(req.BlockNums) != 3 || req.ID == 0 {
+		t.Fatalf("expected request for 3 bodies with non-zero ID, got %+v", req)
+	}
+	bd.ApplyBodyRequest(0, 15, req)
+	body := &eth.BlockBody{Transactions: txs}
+	delivered, unrequested := bd.DeliverBodies(req.ID, []*eth.BlockBody{body, body, body, body})
+	if len(delivered) != 3 || unrequested != 1 {
+		t.Fatalf("expected 3 delivered and 1 unrequested, got %v and %d", delivered, unrequested)
+	}
+	// Repeated response to the same request delivers nothing
+	if delivered, _ = bd.DeliverBodies(req.ID, []*eth.BlockBody{body}); len(delivered) != 0 {
+		t.Errorf("expected no duplicate deliveries, got %v", delivered)
+	}
+	bd.FeedDeliveries()
+	ch := bd.PrepareStageData()
+	for i := uint64(1); i <= 3; i++ {
+		block := <-ch
+		if block.NumberU64() != i || len(block.Transactions()) != 1 {
+			t.Errorf("expected block %d with 1 transaction, got block %d with %d", i, block.NumberU64(), len(block.Transactions()))
+		}
+	}
+}
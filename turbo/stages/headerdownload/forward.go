@@ -10,11 +10,14 @@ import (
 
 	"github.com/ledgerwatch/turbo-geth/common"
 	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/core/rawdb"
 	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
 	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/log"
+	"github.com/ledgerwatch/turbo-geth/params"
 	"github.com/ledgerwatch/turbo-geth/rlp"
 )
 
@@ -22,8 +25,9 @@ const (
 	logInterval = 30 * time.Second
 )
 
-// Forward progresses Headers stage in the forward direction
-func Forward(logPrefix string, db ethdb.Database, files []string, buffer []byte) error {
+// Forward progresses Headers stage in the forward direction. Every header is verified by the consensus engine before
+// it is written. The seals are left to the header download, unless the engine (clique) verifies them anyway
+func Forward(logPrefix string, db ethdb.Database, files []string, buffer []byte, config *params.ChainConfig, engine consensus.Engine) error {
 	count := 0
 	var highest uint64
 	var headerProgress uint64
@@ -48,6 +52,8 @@ func Forward(logPrefix string, db ethdb.Database, files []string, buffer []byte)
 	}
 	batch := tx.NewBatch()
 	defer batch.Rollback()
+	// The parents of the headers are either already in the database or have been just written to the batch
+	chain := stagedsync.NewChainReader(config, batch)
 	logEvery := time.NewTicker(logInterval)
 	defer logEvery.Stop()
 	var logBlock uint64
@@ -82,6 +88,9 @@ func Forward(logPrefix string, db ethdb.Database, files []string, buffer []byte)
 		if blockHeight < prevHeight {
 			return fmt.Errorf("[%s] headers are unexpectedly unsorted, got %d after %d", logPrefix, blockHeight, prevHeight)
 		}
+		if err := engine.VerifyHeader(chain, header, false /* seal */); err != nil {
+			return fmt.Errorf("[%s] verification of header %d %x: %w", logPrefix, blockHeight, hash, err)
+		}
 		if forkNumber == 0 {
 			forkNumber = blockHeight
 			logBlock = blockHeight - 1
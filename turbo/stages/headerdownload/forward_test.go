@@ -0,0 +1,121 @@
+package headerdownload
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/consensus/clique"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync"
+	"github.com/ledgerwatch/turbo-geth/eth/stagedsync/stages"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/rpc"
+)
+
+func TestForwardClique(t *testing.T) {
+	const (
+		extraVanity = 32
+		extraSeal   = crypto.SignatureLength
+	)
+	// The genesis signer votes the second one in, the first one comes first in the order of the signers
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	signerA, signerB := crypto.PubkeyToAddress(keys[0].PublicKey), crypto.PubkeyToAddress(keys[1].PublicKey)
+
+	db := ethdb.NewMemDatabase()
+	defer db.Close()
+	config := params.AllCliqueProtocolChanges
+	gspec := &core.Genesis{
+		Config:    config,
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	copy(gspec.ExtraData[extraVanity:], signerA[:])
+	genesis := gspec.MustCommit(db)
+	snapshots := ethdb.NewMemDatabase()
+	defer snapshots.Close()
+	engine := clique.New(config.Clique, snapshots)
+
+	newHeader := func(parent *types.Header, key *ecdsa.PrivateKey, difficulty int64, vote *common.Address) *types.Header {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   parent.GasLimit,
+			Time:       parent.Time + 1,
+			Difficulty: big.NewInt(difficulty),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if vote != nil {
+			header.Coinbase = *vote
+			header.Nonce = types.EncodeNonce(^uint64(0)) // Vote to authorize
+		}
+		sig, _ := crypto.Sign(clique.SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		return header
+	}
+	forward := func(headers ...*types.Header) error {
+		var buffer []byte
+		for _, header := range headers {
+			buffer = AppendHeader(buffer, header)
+		}
+		return Forward("Headers", db, nil /* files */, buffer, config, engine)
+	}
+	signers := func(number uint64) []common.Address {
+		n := rpc.BlockNumber(number)
+		signers, err := clique.NewAPI(stagedsync.NewChainReader(config, db), engine).GetSigners(&n)
+		if err != nil {
+			t.Fatalf("signers at %d: %v", number, err)
+		}
+		return signers
+	}
+
+	// The first chain adds the second signer, which takes turns with the first one from then on
+	voted := make([]*types.Header, 4)
+	voted[0] = newHeader(genesis.Header(), keys[0], 2, &signerB)
+	voted[1] = newHeader(voted[0], keys[1], 1, nil)
+	voted[2] = newHeader(voted[1], keys[0], 1, nil)
+	voted[3] = newHeader(voted[2], keys[1], 1, nil)
+	if err := forward(voted...); err != nil {
+		t.Fatalf("forward voted chain: %v", err)
+	}
+	if have := signers(4); len(have) != 2 || have[0] != signerA || have[1] != signerB {
+		t.Errorf("wrong signers after the vote: %x", have)
+	}
+
+	// The heavier fork from the genesis without the vote replaces it, the second signer is not authorized there
+	fork := make([]*types.Header, 6)
+	parent := genesis.Header()
+	for i := range fork {
+		fork[i] = newHeader(parent, keys[0], 2, nil)
+		parent = fork[i]
+	}
+	if err := forward(fork...); err != nil {
+		t.Fatalf("forward fork: %v", err)
+	}
+	if hash, err := rawdb.ReadCanonicalHash(db, 4); err != nil || hash != fork[3].Hash() {
+		t.Errorf("fork is not canonical: %x, %v", hash, err)
+	}
+	if have := signers(6); len(have) != 1 || have[0] != signerA {
+		t.Errorf("wrong signers after the reorg: %x", have)
+	}
+	if err := forward(newHeader(fork[5], keys[1], 1, nil)); err == nil {
+		t.Errorf("header of the unauthorized signer accepted")
+	}
+	if progress, err := stages.GetStageProgress(db, stages.Headers); err != nil || progress != 6 {
+		t.Errorf("wrong Headers progress: %d, %v", progress, err)
+	}
+}
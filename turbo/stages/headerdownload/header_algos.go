@@ -13,8 +13,10 @@ import (
 	"math/big"
 	"os"
 	"path"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/holiman/uint256"
@@ -92,6 +94,9 @@ func (hd *HeaderDownload) childParentValid(child, parent *types.Header) (bool, P
 	if parent.Number.Uint64()+1 != child.Number.Uint64() {
 		return false, WrongChildBlockHeightPenalty
 	}
+	if hd.calcDifficultyFunc == nil {
+		return true, NoPenalty
+	}
 	childDifficulty := hd.calcDifficultyFunc(child.Time, parent.Time, parent.Difficulty, parent.Number, parent.Hash(), parent.UncleHash)
 	if child.Difficulty.Cmp(childDifficulty) != 0 {
 		return false, WrongChildDifficultyPenalty
@@ -224,11 +229,26 @@ func (hd *HeaderDownload) VerifySeals(segment *ChainSegment, anchorFound, tipFou
 			return 0, fmt.Errorf("verifySeals anchors were not found for %x", segment.Headers[start].Hash())
 		}
 	}
+	// The seals are verified in parallel first, then the results are taken in the order of the headers,
+	// so that the error is reported with the same powDepth as if the headers were verified one by one
+	var headers []*types.Header
+	depth := powDepth
 	for _, header := range segment.Headers[start:end] {
-		if !anchorFound || powDepth > 0 {
-			if err := hd.verifySealFunc(header); err != nil {
+		if (!anchorFound || depth > 0) && hd.verifySealFunc != nil {
+			headers = append(headers, header)
+		}
+		if anchorFound && depth > 0 {
+			depth--
+		}
+	}
+	errs := hd.verifySeals(headers)
+	verified := 0
+	for range segment.Headers[start:end] {
+		if (!anchorFound || powDepth > 0) && hd.verifySealFunc != nil {
+			if err := errs[verified]; err != nil {
 				return powDepth, err
 			}
+			verified++
 		}
 		if anchorFound && powDepth > 0 {
 			powDepth--
@@ -237,6 +257,63 @@ func (hd *HeaderDownload) VerifySeals(segment *ChainSegment, anchorFound, tipFou
 	return powDepth, nil
 }
 
+// VerifyBudget splits the memory budget of the seal verification, in the number of the ethash verification caches
+// held in memory at once, between the goroutines verifying the seals, the in-memory caches of the recent epochs and
+// the caches generated ahead for the upcoming epochs. Every goroutine can hold the cache of the epoch it verifies
+// after that cache has left the recent ones, so at most threads+cachesInMem+cachesAhead caches are held in memory,
+// which does not exceed the budget of at least MinVerifyCaches
+func VerifyBudget(caches int) (threads, cachesInMem, cachesAhead int) {
+	cachesInMem, cachesAhead = 1, 1 // The engine always generates at least one cache ahead
+	if caches >= 5 {
+		cachesInMem = 2 // The headers at the epoch boundaries need the caches of both epochs
+	}
+	threads = caches - cachesInMem - cachesAhead
+	if threads > runtime.GOMAXPROCS(0) {
+		threads = runtime.GOMAXPROCS(0)
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	return threads, cachesInMem, cachesAhead
+}
+
+// SetVerifyThreads sets the number of goroutines verifying the seals in parallel, see VerifyBudget
+func (hd *HeaderDownload) SetVerifyThreads(threads int) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	if threads < 1 {
+		threads = 1
+	}
+	hd.verifyThreads = threads
+}
+
+// verifySeals verifies the seals of the headers in parallel, on up to verifyThreads goroutines, and returns
+// the verification errors of the headers in the given order
+func (hd *HeaderDownload) verifySeals(headers []*types.Header) []error {
+	threads := hd.verifyThreads
+	if threads > len(headers) {
+		threads = len(headers)
+	}
+	errs := make([]error, len(headers))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range next {
+				errs[index] = hd.verifySealFunc(headers[index])
+			}
+		}()
+	}
+	for index := range headers {
+		next <- index
+	}
+	close(next)
+	wg.Wait()
+	return errs
+}
+
 // ExtendUp extends a working tree up from the tip, using given chain segment
 func (hd *HeaderDownload) ExtendUp(segment *ChainSegment, start, end int, currentTime uint64) error {
 	hd.lock.Lock()
@@ -482,10 +559,8 @@ func (hd *HeaderDownload) AddSegmentToBuffer(segment *ChainSegment, start, end i
 	if end > start {
 		fmt.Printf("Adding segment [%d-%d] to the buffer\n", segment.Headers[end-1].Number.Uint64(), segment.Headers[start].Number.Uint64())
 	}
-	var serBuffer [HeaderSerLength]byte
 	for _, header := range segment.Headers[start:end] {
-		SerialiseHeader(header, serBuffer[:])
-		hd.buffer = append(hd.buffer, serBuffer[:]...)
+		hd.buffer = AppendHeader(hd.buffer, header)
 	}
 }
 
@@ -493,9 +568,7 @@ func (hd *HeaderDownload) AddHeaderToBuffer(header *types.Header) {
 	hd.lock.Lock()
 	defer hd.lock.Unlock()
 	fmt.Printf("Adding header %d to the buffer\n", header.Number.Uint64())
-	var serBuffer [HeaderSerLength]byte
-	SerialiseHeader(header, serBuffer[:])
-	hd.buffer = append(hd.buffer, serBuffer[:]...)
+	hd.buffer = AppendHeader(hd.buffer, header)
 }
 
 func (hd *HeaderDownload) AnchorState() string {
@@ -616,7 +689,6 @@ func (hd *HeaderDownload) CheckFiles() error {
 	if err != nil {
 		return err
 	}
-	var buffer [HeaderSerLength]byte
 	var anchorBuf [AnchorSerLen]byte
 	for _, fileInfo := range fileInfos {
 		f, err1 := os.Open(path.Join(hd.filesDir, fileInfo.Name()))
@@ -624,6 +696,10 @@ func (hd *HeaderDownload) CheckFiles() error {
 			return fmt.Errorf("open file %s: %v", fileInfo.Name(), err1)
 		}
 		r := bufio.NewReader(f)
+		if err = ReadHeadersFileVersion(r); err != nil {
+			fmt.Printf("skipping file %s: %v\n", fileInfo.Name(), err)
+			continue
+		}
 		if _, err = io.ReadFull(r, anchorBuf[:8]); err != nil {
 			fmt.Printf("reading anchor sequence and count from file: %v\n", err)
 			continue
@@ -636,13 +712,12 @@ func (hd *HeaderDownload) CheckFiles() error {
 		}
 		for {
 			var header types.Header
-			if _, err = io.ReadFull(r, buffer[:]); err != nil {
+			if err = ReadHeader(r, &header); err != nil {
 				if !errors.Is(err, io.EOF) {
 					fmt.Printf("reading header from file: %v\n", err)
 				}
 				break
 			}
-			DeserialiseHeader(&header, buffer[:])
 			fmt.Printf("Read header %d from file %s\n", header.Number.Uint64(), fileInfo.Name())
 		}
 	}
@@ -654,15 +729,18 @@ func InitHardCodedTips(filename string) map[common.Hash]struct{} {
 	hardTips := make(map[common.Hash]struct{})
 	if _, err := os.Stat(filename); err == nil {
 		if f, err1 := os.Open(filename); err1 == nil {
-			var hBuffer [HeaderSerLength]byte
+			defer f.Close()
+			r := bufio.NewReader(f)
+			if err2 := ReadHeadersFileVersion(r); err2 != nil {
+				log.Error("Failed to read hard-coded headers", "file", filename, "error", err2)
+				return hardTips
+			}
 			for {
 				var h types.Header
-				if _, err2 := io.ReadFull(f, hBuffer[:]); err2 == nil {
-					DeserialiseHeader(&h, hBuffer[:])
-				} else if errors.Is(err2, io.EOF) {
-					break
-				} else {
-					log.Error("Failed to read hard coded header", "error", err2)
+				if err2 := ReadHeader(r, &h); err2 != nil {
+					if !errors.Is(err2, io.EOF) {
+						log.Error("Failed to read hard coded header", "error", err2)
+					}
 					break
 				}
 				hardTips[h.Hash()] = struct{}{}
@@ -697,6 +775,11 @@ func ReadFilesAndBuffer(files []string, headerBuf []byte, hf func(header *types.
 			return nil, 0, fmt.Errorf("open file %s: %v", filename, err1)
 		}
 		r := bufio.NewReader(f)
+		if err := ReadHeadersFileVersion(r); err != nil {
+			log.Warn("Skipping headers file", "file", filename, "error", err)
+			f.Close()
+			continue
+		}
 		if _, err := io.ReadFull(r, anchorBuf[:8]); err != nil {
 			fmt.Printf("reading anchor sequence and count from file: %v\n", err)
 			continue
@@ -732,7 +815,7 @@ func ReadFilesAndBuffer(files []string, headerBuf []byte, hf func(header *types.
 		rs = append(rs, r)
 	}
 	if headerBuf != nil {
-		sort.Sort(BufferSorter(headerBuf))
+		SortBuffer(headerBuf)
 		fs = append(fs, nil)
 		rs = append(rs, bytes.NewReader(headerBuf))
 	}
@@ -746,17 +829,15 @@ func ReadFilesAndBuffer(files []string, headerBuf []byte, hf func(header *types.
 	}()
 	h := &Heap{}
 	heap.Init(h)
-	var buffer [HeaderSerLength]byte
 	for i, f := range fs {
 		r := rs[i]
 		var header types.Header
-		if _, err := io.ReadFull(r, buffer[:]); err != nil {
+		if err := ReadHeader(r, &header); err != nil {
 			if !errors.Is(err, io.EOF) {
 				return nil, 0, fmt.Errorf("reading header from file: %w", err)
 			}
 			continue
 		}
-		DeserialiseHeader(&header, buffer[:])
 		he := HeapElem{file: f, reader: r, blockHeight: header.Number.Uint64(), hash: header.Hash(), header: &header}
 		heap.Push(h, he)
 	}
@@ -766,8 +847,7 @@ func ReadFilesAndBuffer(files []string, headerBuf []byte, hf func(header *types.
 			return nil, 0, err
 		}
 		var header types.Header
-		if _, err := io.ReadFull(he.reader, buffer[:]); err == nil {
-			DeserialiseHeader(&header, buffer[:])
+		if err := ReadHeader(he.reader, &header); err == nil {
 			he.blockHeight = header.Number.Uint64()
 			he.hash = header.Hash()
 			he.header = &header
@@ -956,7 +1036,9 @@ func (hd *HeaderDownload) RequestMoreHeaders(currentTime, timeout uint64) ([]*He
 		item := peek.Value.(RequestQueueItem)
 		if anchors, present := hd.anchors[item.anchorParent]; present {
 			// Anchor still exists after the timeout
-			requests = append(requests, &HeaderRequest{Hash: item.anchorParent, Number: anchors[0].blockHeight - 1, Length: 192})
+			request := &HeaderRequest{Hash: item.anchorParent, Number: anchors[0].blockHeight - 1, Length: 192}
+			hd.addRequest(request)
+			requests = append(requests, request)
 			hd.requestQueue.PushBack(RequestQueueItem{anchorParent: item.anchorParent, waitUntil: currentTime + timeout})
 		}
 	}
@@ -964,6 +1046,49 @@ func (hd *HeaderDownload) RequestMoreHeaders(currentTime, timeout uint64) ([]*He
 	return requests, hd.RequestQueueTimer
 }
 
+// AddHeaderRequest assigns the request ID to the request made outside of RequestMoreHeaders, e.g. in response to a block announcement
+func (hd *HeaderDownload) AddHeaderRequest(request *HeaderRequest) {
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	hd.addRequest(request)
+}
+
+func (hd *HeaderDownload) addRequest(request *HeaderRequest) {
+	hd.lastRequestID++
+	request.ID = hd.lastRequestID
+	hd.requests[request.ID] = request
+	if hd.lastRequestID > RequestIDWindow {
+		delete(hd.requests, hd.lastRequestID-RequestIDWindow)
+	}
+}
+
+// MatchHeaderResponse checks the headers against the request with the given ID, and forgets the request.
+// Responses with zero request ID (from peers before eth/66), or for the requests that fell out of the
+// RequestIDWindow, cannot be checked and are accepted as they are
+func (hd *HeaderDownload) MatchHeaderResponse(requestID uint64, headers []*types.Header) Penalty {
+	if requestID == 0 {
+		return NoPenalty
+	}
+	hd.lock.Lock()
+	defer hd.lock.Unlock()
+	request, ok := hd.requests[requestID]
+	if !ok {
+		if requestID > hd.lastRequestID {
+			// We have never sent the request with such ID
+			return UnrequestedHeadersPenalty
+		}
+		return NoPenalty
+	}
+	delete(hd.requests, requestID)
+	if len(headers) > request.Length {
+		return UnrequestedHeadersPenalty
+	}
+	if len(headers) > 0 && headers[0].Hash() != request.Hash {
+		return UnrequestedHeadersPenalty
+	}
+	return NoPenalty
+}
+
 func (hd *HeaderDownload) resetRequestQueueTimer(prevTopTime, currentTime uint64) {
 	var nextTopTime uint64
 	if hd.requestQueue.Len() > 0 {
@@ -988,9 +1113,13 @@ func (hd *HeaderDownload) FlushBuffer() error {
 		return nil
 	}
 	// Sort the buffer first
-	sort.Sort(BufferSorter(hd.buffer))
+	SortBuffer(hd.buffer)
 	if bufferFile, err := ioutil.TempFile(hd.filesDir, "headers-buf"); err == nil {
-		// First write the anchors
+		if err = WriteHeadersFileVersion(bufferFile); err != nil {
+			bufferFile.Close()
+			return err
+		}
+		// Then write the anchors
 		var buf [AnchorSerLen]byte
 		binary.BigEndian.PutUint32(buf[:], hd.anchorSequence)
 		anchorCount := 0
@@ -1073,6 +1202,9 @@ func (hd *HeaderDownload) childTipValid(child *types.Header, tipHash common.Hash
 	if tip.blockHeight+1 != child.Number.Uint64() {
 		return false, WrongChildBlockHeightPenalty
 	}
+	if hd.calcDifficultyFunc == nil {
+		return true, NoPenalty
+	}
 	childDifficulty := hd.calcDifficultyFunc(child.Time, tip.timestamp, tip.difficulty.ToBig(), big.NewInt(int64(tip.blockHeight)), tipHash, tip.uncleHash)
 	if child.Difficulty.Cmp(childDifficulty) != 0 {
 		return false, WrongChildDifficultyPenalty
@@ -1179,6 +1311,9 @@ func (hd *HeaderDownload) anchorParentValid(anchor *Anchor, parent *types.Header
 		fmt.Printf("anchor.blockHeight(%d) != parent.Number+1(%d)\n", anchor.blockHeight, parent.Number.Uint64()+1)
 		return false
 	}
+	if hd.calcDifficultyFunc == nil {
+		return true
+	}
 	childDifficulty := hd.calcDifficultyFunc(anchor.timestamp, parent.Time, parent.Difficulty, parent.Number, parent.Hash(), parent.UncleHash)
 	if anchor.difficulty.ToBig().Cmp(childDifficulty) != 0 {
 		fmt.Printf("anchor.difficulty (%s) != childDifficulty (%s)\n", anchor.difficulty.ToBig(), childDifficulty)
@@ -3,8 +3,11 @@ package headerdownload
 import (
 	"container/list"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -109,6 +112,7 @@ const (
 	InvalidSealPenalty
 	TooFarFuturePenalty
 	TooFarPastPenalty
+	UnrequestedHeadersPenalty
 )
 
 type PeerPenalty struct {
@@ -120,11 +124,24 @@ type PeerPenalty struct {
 
 // Request for chain segment starting with hash and going to its parent, etc, with length headers in total
 type HeaderRequest struct {
+	ID     uint64 // Request ID sent to the eth/66 peers, so that the response can be matched to the request
 	Hash   common.Hash
 	Number uint64
 	Length int
 }
 
+// RequestIDWindow is how many most recent requests are remembered for matching their responses
+const RequestIDWindow = 1024
+
+// DefaultVerifyCaches is the default memory budget of the seal verification, in the number of the ethash verification
+// caches held in memory at once, see VerifyBudget. MinVerifyCaches is the smallest budget it can keep to
+const (
+	DefaultVerifyCaches = 8
+	MinVerifyCaches     = 3
+)
+
+// VerifySealFunc and CalcDifficultyFunc are left nil for the engines (clique) which cannot verify the seals and the
+// difficulties without the snapshots of the chain, such headers are only verified by the engine in Forward
 type VerifySealFunc func(header *types.Header) error
 type CalcDifficultyFunc func(childTimestamp uint64, parentTime uint64, parentDifficulty, parentNumber *big.Int, parentHash, parentUncleHash common.Hash) *big.Int
 
@@ -151,10 +168,13 @@ type HeaderDownload struct {
 	requestQueue           *list.List
 	calcDifficultyFunc     CalcDifficultyFunc
 	verifySealFunc         VerifySealFunc
+	verifyThreads          int // Number of goroutines verifying the seals in parallel
 	RequestQueueTimer      *time.Timer
 	initialHash            common.Hash
 	stageReady             bool
 	stageReadyCh           chan struct{}
+	lastRequestID          uint64
+	requests               map[uint64]*HeaderRequest // Outstanding requests by request ID, within RequestIDWindow of lastRequestID
 }
 
 type TipQueueItem struct {
@@ -215,12 +235,14 @@ func NewHeaderDownload(
 		anchorTree:           llrb.New(),
 		calcDifficultyFunc:   calcDifficultyFunc,
 		verifySealFunc:       verifySealFunc,
+		verifyThreads:        1,
 		newAnchorFutureLimit: newAnchorFutureLimit,
 		newAnchorPastLimit:   newAnchorPastLimit,
 		hardTips:             make(map[common.Hash]struct{}),
 		tips:                 make(map[common.Hash]*Tip),
 		stageReadyCh:         make(chan struct{}),
 		RequestQueueTimer:    time.NewTimer(time.Hour),
+		requests:             make(map[uint64]*HeaderRequest),
 	}
 	return hd
 }
@@ -243,6 +265,8 @@ func (p Penalty) String() string {
 		return "TooFarFuture"
 	case TooFarPastPenalty:
 		return "TooFarPast"
+	case UnrequestedHeadersPenalty:
+		return "UnrequestedHeaders"
 	default:
 		return fmt.Sprintf("Unknown(%d)", p)
 	}
@@ -254,10 +278,39 @@ func (pp PeerPenalty) String() string {
 
 const HeaderPreBlockHeight = 32 /*ParentHash*/ + 32 /*UncleHash*/ + 20 /*Coinbase*/ + 32 /*Root*/ + 32 /*TxHash*/ + 32 /*ReceiptHash*/ +
 																			256 /*Bloom*/ + 16 /*Difficulty */
-const HeaderPostBlockHeight = 8 /*Number*/ + 8 /*GasLimit*/ + 8 /*GasUsed*/ + 8 /*Time*/ + 1 /*len(Extra)*/ + 32 /*Extra*/ + 32 /* MixDigest */ + 8 /*Nonce*/
+const HeaderPostBlockHeight = 8 /*Number*/ + 8 /*GasLimit*/ + 8 /*GasUsed*/ + 8 /*Time*/ + 4 /*len(Extra)*/ + 32 /* MixDigest */ + 8 /*Nonce*/ + 0 /*Extra, stored after the fixed size fields*/
 
+// HeaderSerLength is the length of the serialised header without the extra data, which follows it
 const HeaderSerLength = HeaderPreBlockHeight + HeaderPostBlockHeight
 
+// headersFileVersion starts the files of the serialised headers. The files written before the extra data was
+// serialised in full have no version, they are not read
+var headersFileVersion = [8]byte{'h', 'e', 'a', 'd', 'e', 'r', 's', 2}
+
+// WriteHeadersFileVersion starts a file of the serialised headers
+func WriteHeadersFileVersion(w io.Writer) error {
+	_, err := w.Write(headersFileVersion[:])
+	return err
+}
+
+// ReadHeadersFileVersion checks that the file of the serialised headers is of the current version
+func ReadHeadersFileVersion(r io.Reader) error {
+	var version [len(headersFileVersion)]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return fmt.Errorf("reading version of headers file: %w", err)
+	}
+	if version != headersFileVersion {
+		return fmt.Errorf("headers file of unsupported version %x, expected %x", version, headersFileVersion)
+	}
+	return nil
+}
+
+// SerialisedHeaderLength is the length of the serialised header, including its extra data
+func SerialisedHeaderLength(header *types.Header) int {
+	return HeaderSerLength + len(header.Extra)
+}
+
+// SerialiseHeader writes the header into the buffer, which must fit SerialisedHeaderLength(header) bytes
 func SerialiseHeader(header *types.Header, buffer []byte) {
 	pos := 0
 	copy(buffer[pos:pos+32], header.ParentHash[:])
@@ -290,15 +343,24 @@ func SerialiseHeader(header *types.Header, buffer []byte) {
 	pos += 8
 	binary.BigEndian.PutUint64(buffer[pos:pos+8], header.Time)
 	pos += 8
-	buffer[pos] = byte(len(header.Extra))
-	pos++
-	copy(buffer[pos:pos+32], header.Extra)
-	pos += 32
+	binary.BigEndian.PutUint32(buffer[pos:pos+4], uint32(len(header.Extra)))
+	pos += 4
 	copy(buffer[pos:pos+32], header.MixDigest[:])
 	pos += 32
 	binary.BigEndian.PutUint64(buffer[pos:pos+8], header.Nonce.Uint64())
+	pos += 8
+	copy(buffer[pos:pos+len(header.Extra)], header.Extra)
 }
 
+// AppendHeader appends the serialised header to the buffer
+func AppendHeader(buffer []byte, header *types.Header) []byte {
+	start := len(buffer)
+	buffer = append(buffer, make([]byte, SerialisedHeaderLength(header))...)
+	SerialiseHeader(header, buffer[start:])
+	return buffer
+}
+
+// DeserialiseHeader reads the header from the buffer holding it in full, see serialisedLength
 func DeserialiseHeader(header *types.Header, buffer []byte) {
 	pos := 0
 	copy(header.ParentHash[:], buffer[pos:pos+32])
@@ -331,32 +393,55 @@ func DeserialiseHeader(header *types.Header, buffer []byte) {
 	pos += 8
 	header.Time = binary.BigEndian.Uint64(buffer[pos : pos+8])
 	pos += 8
-	extraLen := int(buffer[pos])
-	pos++
-	header.Extra = header.Extra[:0]
-	header.Extra = append(header.Extra, buffer[pos:pos+extraLen]...)
-	pos += 32
+	extraLen := int(binary.BigEndian.Uint32(buffer[pos : pos+4]))
+	pos += 4
 	copy(header.MixDigest[:], buffer[pos:pos+32])
 	pos += 32
 	header.Nonce = types.EncodeNonce(binary.BigEndian.Uint64(buffer[pos : pos+8]))
+	pos += 8
+	header.Extra = header.Extra[:0]
+	header.Extra = append(header.Extra, buffer[pos:pos+extraLen]...)
 }
 
-// Wrapper for the header buffer to sort headers within by block height
-type BufferSorter []byte
-
-func (bs BufferSorter) Len() int {
-	return len(bs) / HeaderSerLength
+// serialisedLength is the length of the serialised header at the start of the buffer, which holds
+// at least HeaderSerLength bytes of it
+func serialisedLength(buffer []byte) int {
+	return HeaderSerLength + int(binary.BigEndian.Uint32(buffer[HeaderPreBlockHeight+32:]))
 }
 
-func (bs BufferSorter) Less(i, j int) bool {
-	hi := binary.BigEndian.Uint64(bs[i*HeaderSerLength+HeaderPreBlockHeight:])
-	hj := binary.BigEndian.Uint64(bs[j*HeaderSerLength+HeaderPreBlockHeight:])
-	return hi < hj
+// ReadHeader reads the next serialised header. It returns io.EOF if there are no more headers
+func ReadHeader(r io.Reader, header *types.Header) error {
+	var buffer [HeaderSerLength]byte
+	if _, err := io.ReadFull(r, buffer[:]); err != nil {
+		return err
+	}
+	full := make([]byte, serialisedLength(buffer[:]))
+	copy(full, buffer[:])
+	if _, err := io.ReadFull(r, full[HeaderSerLength:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	DeserialiseHeader(header, full)
+	return nil
 }
 
-func (bs BufferSorter) Swap(i, j int) {
-	var swapBuffer [HeaderSerLength]byte
-	copy(swapBuffer[:], bs[i*HeaderSerLength:])
-	copy(bs[i*HeaderSerLength:i*HeaderSerLength+HeaderSerLength], bs[j*HeaderSerLength:j*HeaderSerLength+HeaderSerLength])
-	copy(bs[j*HeaderSerLength:], swapBuffer[:])
+// SortBuffer sorts the serialised headers within the buffer by block height
+func SortBuffer(buffer []byte) {
+	var offsets []int
+	for pos := 0; pos < len(buffer); pos += serialisedLength(buffer[pos:]) {
+		offsets = append(offsets, pos)
+	}
+	height := func(pos int) uint64 {
+		return binary.BigEndian.Uint64(buffer[pos+HeaderPreBlockHeight:])
+	}
+	sort.SliceStable(offsets, func(i, j int) bool {
+		return height(offsets[i]) < height(offsets[j])
+	})
+	sorted := make([]byte, 0, len(buffer))
+	for _, pos := range offsets {
+		sorted = append(sorted, buffer[pos:pos+serialisedLength(buffer[pos:])]...)
+	}
+	copy(buffer, sorted)
 }
@@ -2,7 +2,11 @@ package headerdownload
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"math/big"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -439,13 +443,96 @@ func TestExtendDown(t *testing.T) {
 	}
 }
 
+func TestVerifySeals(t *testing.T) {
+	var verified int32
+	hd := NewHeaderDownload(common.Hash{}, "", TestBufferLimit, TestTipLimit, TestInitPowDepth, nil, func(header *types.Header) error {
+		atomic.AddInt32(&verified, 1)
+		if n := header.Number.Uint64(); n == 30 || n == 70 {
+			return fmt.Errorf("invalid seal %d", n)
+		}
+		return nil
+	}, 60, 60,
+	)
+	currentTime := uint64(time.Now().Unix())
+	// Segments are ordered from the highest block to the lowest one
+	segment := &ChainSegment{}
+	for n := 100; n > 0; n-- {
+		segment.Headers = append(segment.Headers, &types.Header{Number: big.NewInt(int64(n)), Time: currentTime})
+	}
+	if _, err := hd.VerifySeals(segment, false, false, 0, len(segment.Headers), currentTime); err == nil || err.Error() != "invalid seal 70" {
+		t.Errorf("expected the error of the first invalid header in the segment, got %v", err)
+	}
+	if verified != 100 {
+		t.Errorf("verified seals: have %d, want 100", verified)
+	}
+	if _, err := hd.VerifySeals(segment, false, false, 0, 30, currentTime); err != nil {
+		t.Errorf("verifying valid seals: %v", err)
+	}
+
+	// With the anchor found, only powDepth headers are verified, and the error comes with powDepth decremented
+	// for the headers before the invalid one, as if they were verified one by one
+	hd.anchors[segment.Headers[0].Hash()] = []*Anchor{{powDepth: 50}}
+	powDepth, err := hd.VerifySeals(segment, true, false, 0, len(segment.Headers), currentTime)
+	if err == nil || err.Error() != "invalid seal 70" || powDepth != 20 {
+		t.Errorf("expected the error of the first invalid header at powDepth 20, got %v at %d", err, powDepth)
+	}
+	if powDepth, err = hd.VerifySeals(segment, true, false, 0, 30, currentTime); err != nil || powDepth != 20 {
+		t.Errorf("verifying valid seals: %v at powDepth %d", err, powDepth)
+	}
+}
+
+func TestVerifyBudget(t *testing.T) {
+	for caches := MinVerifyCaches; caches <= 4*DefaultVerifyCaches; caches++ {
+		threads, cachesInMem, cachesAhead := VerifyBudget(caches)
+		if threads < 1 || cachesInMem < 1 || cachesAhead < 1 {
+			t.Errorf("budget of %d caches: %d threads, %d in memory, %d ahead", caches, threads, cachesInMem, cachesAhead)
+		}
+		if held := threads + cachesInMem + cachesAhead; held > caches {
+			t.Errorf("budget of %d caches exceeded: %d threads, %d in memory, %d ahead", caches, threads, cachesInMem, cachesAhead)
+		}
+	}
+
+	// No more seals are verified at once than the budget allows for
+	const threads = 3
+	var running, maxRunning int32
+	hd := NewHeaderDownload(common.Hash{}, "", TestBufferLimit, TestTipLimit, TestInitPowDepth, nil, func(header *types.Header) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			highest := atomic.LoadInt32(&maxRunning)
+			if n <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}, 60, 60,
+	)
+	hd.SetVerifyThreads(threads)
+	currentTime := uint64(time.Now().Unix())
+	segment := &ChainSegment{}
+	for n := 100; n > 0; n-- {
+		segment.Headers = append(segment.Headers, &types.Header{Number: big.NewInt(int64(n)), Time: currentTime})
+	}
+	if _, err := hd.VerifySeals(segment, false, false, 0, len(segment.Headers), currentTime); err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning < 1 || maxRunning > threads {
+		t.Errorf("seals verified at once: have %d, want at most %d", maxRunning, threads)
+	}
+}
+
 func TestHeaderSerialisation(t *testing.T) {
 	var header types.Header
 	header.Number = big.NewInt(4556)
 	header.Difficulty = big.NewInt(8594358439058439583)
 	header.Difficulty.Mul(header.Difficulty, big.NewInt(443598345394))
 	header.ParentHash = common.HexToHash("0xfd58493ad432269bcc3435abc5444")
-	header.Extra = common.FromHex("0x433423234324324323243243232423aaaaaabbbbbbbcccc")
+	// Extra data of a clique checkpoint with 10 signers
+	header.Extra = make([]byte, 32+10*common.AddressLength+65)
+	for i := range header.Extra {
+		header.Extra[i] = byte(i)
+	}
 	header.Nonce = types.EncodeNonce(34343232432)
 	header.GasLimit = 54330000004
 	header.GasUsed = 40000000000
@@ -458,13 +545,91 @@ func TestHeaderSerialisation(t *testing.T) {
 	}
 	header.ReceiptHash = common.HexToHash("0x5566778")
 	header.TxHash = common.HexToHash("0x894858473765654")
-	buffer := make([]byte, HeaderSerLength)
+	buffer := make([]byte, SerialisedHeaderLength(&header))
 	SerialiseHeader(&header, buffer)
 	var newHeader types.Header
 	DeserialiseHeader(&newHeader, buffer)
-	newBuffer := make([]byte, HeaderSerLength)
+	newBuffer := make([]byte, SerialisedHeaderLength(&newHeader))
 	SerialiseHeader(&newHeader, newBuffer)
 	if !bytes.Equal(buffer, newBuffer) {
 		t.Errorf("header serialistion must be the same")
 	}
+	if newHeader.Hash() != header.Hash() {
+		t.Errorf("deserialised header %x differs from %x", newHeader.Hash(), header.Hash())
+	}
+	// Headers read one by one from a stream
+	r := bytes.NewReader(append(buffer, newBuffer...))
+	for i := 0; i < 2; i++ {
+		var readHeader types.Header
+		if err := ReadHeader(r, &readHeader); err != nil || readHeader.Hash() != header.Hash() {
+			t.Errorf("read header %d: %x, %v", i, readHeader.Hash(), err)
+		}
+	}
+	if err := ReadHeader(r, &newHeader); !errors.Is(err, io.EOF) {
+		t.Errorf("expected end of stream, got %v", err)
+	}
+}
+
+func TestSortBuffer(t *testing.T) {
+	var buffer []byte
+	for _, number := range []int64{3, 1, 2} {
+		buffer = AppendHeader(buffer, &types.Header{Number: big.NewInt(number), Extra: make([]byte, number*100)})
+	}
+	SortBuffer(buffer)
+	r := bytes.NewReader(buffer)
+	for number := int64(1); number <= 3; number++ {
+		var header types.Header
+		if err := ReadHeader(r, &header); err != nil || header.Number.Int64() != number || len(header.Extra) != int(number*100) {
+			t.Fatalf("header %d expected, got %v with %d bytes of extra data, %v", number, header.Number, len(header.Extra), err)
+		}
+	}
+}
+
+func TestHeadersFileVersion(t *testing.T) {
+	var file bytes.Buffer
+	if err := WriteHeadersFileVersion(&file); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReadHeadersFileVersion(&file); err != nil {
+		t.Errorf("current version rejected: %v", err)
+	}
+	// The files without the version start with the sequence and the count of the anchors
+	if err := ReadHeadersFileVersion(bytes.NewReader(make([]byte, 8))); err == nil {
+		t.Errorf("file without version accepted")
+	}
+}
+
+func TestMatchHeaderResponse(t *testing.T) {
+	hd := NewHeaderDownload(common.Hash{}, "", TestBufferLimit, TestTipLimit, TestInitPowDepth, nil, nil, 60, 60)
+	var h1, h2 types.Header
+	h1.Number = big.NewInt(5)
+	h2.Number = big.NewInt(6)
+	req1 := &HeaderRequest{Hash: h1.Hash(), Number: 5, Length: 1}
+	req2 := &HeaderRequest{Hash: h2.Hash(), Number: 6, Length: 1}
+	hd.AddHeaderRequest(req1)
+	hd.AddHeaderRequest(req2)
+	if req1.ID == 0 || req1.ID == req2.ID {
+		t.Fatalf("expected distinct non-zero request IDs, got %d and %d", req1.ID, req2.ID)
+	}
+	// Responses arriving out of order are matched to their requests
+	if penalty := hd.MatchHeaderResponse(req2.ID, []*types.Header{&h2}); penalty != NoPenalty {
+		t.Errorf("unexpected penalty: %s", penalty)
+	}
+	if penalty := hd.MatchHeaderResponse(req1.ID, []*types.Header{&h1}); penalty != NoPenalty {
+		t.Errorf("unexpected penalty: %s", penalty)
+	}
+	// Response with the ID of another request
+	req3 := &HeaderRequest{Hash: h1.Hash(), Number: 5, Length: 1}
+	hd.AddHeaderRequest(req3)
+	if penalty := hd.MatchHeaderResponse(req3.ID, []*types.Header{&h2}); penalty != UnrequestedHeadersPenalty {
+		t.Errorf("expected UnrequestedHeaders penalty, got %s", penalty)
+	}
+	// Response to the request that has never been sent
+	if penalty := hd.MatchHeaderResponse(req3.ID+1, []*types.Header{&h1}); penalty != UnrequestedHeadersPenalty {
+		t.Errorf("expected UnrequestedHeaders penalty, got %s", penalty)
+	}
+	// Responses from peers before eth/66 are not checked
+	if penalty := hd.MatchHeaderResponse(0, []*types.Header{&h2}); penalty != NoPenalty {
+		t.Errorf("unexpected penalty: %s", penalty)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ledgerwatch/turbo-geth/consensus"
 	"github.com/ledgerwatch/turbo-geth/core"
 	"github.com/ledgerwatch/turbo-geth/ethdb"
 	"github.com/ledgerwatch/turbo-geth/log"
@@ -11,15 +12,17 @@ import (
 	"github.com/ledgerwatch/turbo-geth/turbo/stages/headerdownload"
 )
 
-// StageLoop runs the continuous loop of staged sync
-func StageLoop(ctx context.Context, db ethdb.Database, hd *headerdownload.HeaderDownload, bd *bodydownload.BodyDownload, bodyWakeUp chan struct{}) error {
-	if _, _, _, err := core.SetupGenesisBlock(db, core.DefaultGenesisBlock(), false, false /* overwrite */); err != nil {
+// StageLoop runs the continuous loop of staged sync, the headers are verified by the given consensus engine
+func StageLoop(ctx context.Context, db ethdb.Database, hd *headerdownload.HeaderDownload, bd *bodydownload.BodyDownload, engine consensus.Engine, bodyWakeUp chan struct{}) error {
+	// The chain of the genesis block in the database is synced, the main-net one if there is none yet
+	chainConfig, _, _, err := core.SetupGenesisBlock(db, nil, false /* history */, false /* overwrite */)
+	if err != nil {
 		return fmt.Errorf("setup genesis block: %w", err)
 	}
 	files, buffer := hd.PrepareStageData()
 	for {
 		if len(files) > 0 || len(buffer) > 0 {
-			if err := headerdownload.Forward("1/14 Headers", db, files, buffer); err != nil {
+			if err := headerdownload.Forward("1/14 Headers", db, files, buffer, chainConfig, engine); err != nil {
 				log.Error("header download forward failed", "error", err)
 			}
 			if err := bd.UpdateFromDb(db); err != nil {
@@ -0,0 +1,247 @@
+package transactions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/hexutil"
+	"github.com/ledgerwatch/turbo-geth/consensus"
+	"github.com/ledgerwatch/turbo-geth/consensus/misc"
+	"github.com/ledgerwatch/turbo-geth/core"
+	"github.com/ledgerwatch/turbo-geth/core/rawdb"
+	"github.com/ledgerwatch/turbo-geth/core/state"
+	"github.com/ledgerwatch/turbo-geth/core/types"
+	"github.com/ledgerwatch/turbo-geth/core/vm"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/params"
+	"github.com/ledgerwatch/turbo-geth/turbo/adapter"
+)
+
+// AccountOverride replaces the code, the balance or the storage slots of an account at the start of the replay
+type AccountOverride struct {
+	Code    *hexutil.Bytes              `json:"code"`
+	Balance *hexutil.Big                `json:"balance"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// StateOverrides are the overrides of the accounts applied at the start of the replay
+type StateOverrides map[common.Address]AccountOverride
+
+// ReplayOutcome is the result of a transaction in one of the executions
+type ReplayOutcome struct {
+	Status  uint64       `json:"status"`
+	GasUsed uint64       `json:"gasUsed"`
+	Logs    []*types.Log `json:"logs"`
+	Error   string       `json:"error,omitempty"` // The transaction could not be applied, e.g. its sender can not pay for the gas anymore
+}
+
+// ValueDiff is a value which differs between the canonical and the replayed execution
+type ValueDiff struct {
+	Canonical interface{} `json:"canonical"`
+	Replayed  interface{} `json:"replayed"`
+}
+
+// AccountDiff is the difference of the account between the executions after the transaction, only the accounts and
+// the storage slots written by the transaction in either of the executions are compared
+type AccountDiff struct {
+	Balance  *ValueDiff                 `json:"balance,omitempty"`
+	Nonce    *ValueDiff                 `json:"nonce,omitempty"`
+	CodeHash *ValueDiff                 `json:"codeHash,omitempty"`
+	Storage  map[common.Hash]*ValueDiff `json:"storage,omitempty"`
+}
+
+// TxReplay compares the replayed transaction with its canonical execution
+type TxReplay struct {
+	TxHash      common.Hash                     `json:"transactionHash"`
+	BlockNumber uint64                          `json:"blockNumber"`
+	TxIndex     uint64                          `json:"transactionIndex"`
+	Changed     bool                            `json:"changed"`
+	Canonical   *ReplayOutcome                  `json:"canonical"`
+	Replayed    *ReplayOutcome                  `json:"replayed"`
+	StateDiff   map[common.Address]*AccountDiff `json:"stateDiff"`
+}
+
+// ReplayWithOverrides re-executes the blocks from fromBlock to toBlock inclusive on top of the historical state,
+// the same way the transactions are traced, twice: as they were executed and with the overrides applied to the state
+// at the start of the first block. The transactions are compared one by one.
+// The blocks are read from db and the state from tx, the engine applies the block rewards.
+func ReplayWithOverrides(ctx context.Context, db ethdb.Database, tx ethdb.Tx, chainConfig *params.ChainConfig, chain core.ChainContext, engine consensus.Engine, fromBlock, toBlock uint64, overrides StateOverrides) ([]*TxReplay, error) {
+	if fromBlock == 0 || fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid block range %d-%d", fromBlock, toBlock)
+	}
+	canonical := state.New(adapter.NewStateReader(tx, fromBlock-1))
+	replayed := state.New(adapter.NewStateReader(tx, fromBlock-1))
+	for address, override := range overrides {
+		if override.Code != nil {
+			replayed.SetCode(address, *override.Code)
+		}
+		if override.Balance != nil {
+			balance, overflow := uint256.FromBig(override.Balance.ToInt())
+			if overflow {
+				return nil, fmt.Errorf("balance override of %x overflows 256 bits", address)
+			}
+			replayed.SetBalance(address, balance)
+		}
+		for key, value := range override.Storage {
+			key := key
+			replayed.SetState(address, &key, *uint256.NewInt().SetBytes(value[:]))
+		}
+	}
+	// The overrides are the starting point of the replay, not the changes of its first transaction
+	if err := replayed.FinalizeTx(context.Background(), state.NewNoopWriter()); err != nil {
+		return nil, err
+	}
+
+	var replays []*TxReplay
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := rawdb.ReadBlockByNumber(db, number)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		header := block.Header()
+		if chainConfig.DAOForkSupport && chainConfig.DAOForkBlock != nil && chainConfig.DAOForkBlock.Cmp(block.Number()) == 0 {
+			misc.ApplyDAOHardFork(canonical)
+			misc.ApplyDAOHardFork(replayed)
+		}
+		canonicalGas, replayedGas := new(core.GasPool).AddGas(block.GasLimit()), new(core.GasPool).AddGas(block.GasLimit())
+		canonicalUsed, replayedUsed := new(uint64), new(uint64)
+		for i, txn := range block.Transactions() {
+			select {
+			default:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			canonical.Prepare(txn.Hash(), block.Hash(), i)
+			canonicalWriter := state.NewChangeSetWriterPlain(nil, number)
+			receipt, err := core.ApplyTransaction(chainConfig, chain, nil, canonicalGas, canonical, canonicalWriter, header, txn, canonicalUsed, vm.Config{})
+			if err != nil {
+				return nil, fmt.Errorf("canonical execution of transaction %x failed: %v", txn.Hash(), err)
+			}
+			replay := &TxReplay{
+				TxHash:      txn.Hash(),
+				BlockNumber: number,
+				TxIndex:     uint64(i),
+				Canonical:   newReplayOutcome(receipt),
+			}
+
+			replayed.Prepare(txn.Hash(), block.Hash(), i)
+			replayedWriter := state.NewChangeSetWriterPlain(nil, number)
+			snapshot := replayed.Snapshot()
+			if receipt, err = core.ApplyTransaction(chainConfig, chain, nil, replayedGas, replayed, replayedWriter, header, txn, replayedUsed, vm.Config{}); err == nil {
+				replay.Replayed = newReplayOutcome(receipt)
+			} else {
+				replayed.RevertToSnapshot(snapshot)
+				replay.Replayed = &ReplayOutcome{Error: err.Error()}
+			}
+
+			if replay.StateDiff, err = diffWrites(canonical, replayed, canonicalWriter, replayedWriter); err != nil {
+				return nil, err
+			}
+			replay.Changed = len(replay.StateDiff) > 0 || !sameOutcome(replay.Canonical, replay.Replayed)
+			replays = append(replays, replay)
+		}
+
+		// The block rewards
+		ctx := chainConfig.WithEIPsFlags(ctx, header.Number)
+		for _, ibs := range []*state.IntraBlockState{canonical, replayed} {
+			engine.Finalize(chainConfig, types.CopyHeader(header), ibs, block.Transactions(), block.Uncles())
+			if err := ibs.FinalizeTx(ctx, state.NewNoopWriter()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return replays, nil
+}
+
+func newReplayOutcome(receipt *types.Receipt) *ReplayOutcome {
+	logs := receipt.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return &ReplayOutcome{Status: receipt.Status, GasUsed: receipt.GasUsed, Logs: logs}
+}
+
+func sameOutcome(a, b *ReplayOutcome) bool {
+	if a.Status != b.Status || a.GasUsed != b.GasUsed || a.Error != b.Error || len(a.Logs) != len(b.Logs) {
+		return false
+	}
+	for i := range a.Logs {
+		if a.Logs[i].Address != b.Logs[i].Address || !bytes.Equal(a.Logs[i].Data, b.Logs[i].Data) || len(a.Logs[i].Topics) != len(b.Logs[i].Topics) {
+			return false
+		}
+		for j := range a.Logs[i].Topics {
+			if a.Logs[i].Topics[j] != b.Logs[i].Topics[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// diffWrites compares the accounts and the storage slots written by the transaction in either of the executions
+func diffWrites(canonical, replayed *state.IntraBlockState, writers ...*state.ChangeSetWriter) (map[common.Address]*AccountDiff, error) {
+	addresses := make(map[common.Address]struct{})
+	slots := make(map[common.Address]map[common.Hash]struct{})
+	for _, w := range writers {
+		accountChanges, err := w.GetAccountChanges()
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range accountChanges.Changes {
+			addresses[common.BytesToAddress(change.Key)] = struct{}{}
+		}
+		storageChanges, err := w.GetStorageChanges()
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range storageChanges.Changes {
+			// Plain composite key: address + incarnation + slot
+			address := common.BytesToAddress(change.Key[:common.AddressLength])
+			if slots[address] == nil {
+				slots[address] = make(map[common.Hash]struct{})
+			}
+			slots[address][common.BytesToHash(change.Key[common.AddressLength+common.IncarnationLength:])] = struct{}{}
+		}
+	}
+
+	diffs := make(map[common.Address]*AccountDiff)
+	diff := func(address common.Address) *AccountDiff {
+		if diffs[address] == nil {
+			diffs[address] = &AccountDiff{}
+		}
+		return diffs[address]
+	}
+	for address := range addresses {
+		if a, b := canonical.GetBalance(address), replayed.GetBalance(address); !a.Eq(b) {
+			diff(address).Balance = &ValueDiff{(*hexutil.Big)(a.ToBig()), (*hexutil.Big)(b.ToBig())}
+		}
+		if a, b := canonical.GetNonce(address), replayed.GetNonce(address); a != b {
+			diff(address).Nonce = &ValueDiff{hexutil.Uint64(a), hexutil.Uint64(b)}
+		}
+		if a, b := canonical.GetCodeHash(address), replayed.GetCodeHash(address); a != b {
+			diff(address).CodeHash = &ValueDiff{a, b}
+		}
+	}
+	for address, keys := range slots {
+		for key := range keys {
+			key := key
+			var a, b uint256.Int
+			canonical.GetState(address, &key, &a)
+			replayed.GetState(address, &key, &b)
+			if a.Eq(&b) {
+				continue
+			}
+			if diff(address).Storage == nil {
+				diff(address).Storage = make(map[common.Hash]*ValueDiff)
+			}
+			diff(address).Storage[key] = &ValueDiff{common.Hash(a.Bytes32()), common.Hash(b.Bytes32())}
+		}
+	}
+	return diffs, nil
+}
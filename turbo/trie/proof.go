@@ -2,9 +2,12 @@ package trie
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/rlp"
 )
 
 // Prove constructs a merkle proof for key. The result contains all encoded nodes
@@ -95,3 +98,229 @@ func (t *Trie) Prove(key []byte, fromLevel int, storage bool) ([][]byte, error)
 	}
 	return proof, nil
 }
+
+// NodeRLP returns the RLP encoding of the node located at the given path (in HEX encoding, without terminator).
+// Paths longer than account keys continue into the storage trie of the account, so the node of the
+// storage trie is addressed by concatenation of the account key and the path inside the storage trie.
+// If there is no node at the given path, nil is returned.
+func (t *Trie) NodeRLP(hex []byte) ([]byte, error) {
+	hasher := newHasher(false)
+	defer returnHasherToPool(hasher)
+	tn := t.root
+	for tn != nil {
+		if n, ok := tn.(*accountNode); ok {
+			tn = n.storage
+			continue
+		}
+		if len(hex) == 0 {
+			break
+		}
+		switch n := tn.(type) {
+		case *shortNode:
+			nKey := n.Key
+			if nKey[len(nKey)-1] == 16 {
+				nKey = nKey[:len(nKey)-1]
+			}
+			if len(hex) < len(nKey) || !bytes.Equal(nKey, hex[:len(nKey)]) {
+				return nil, nil
+			}
+			tn = n.Val
+			hex = hex[len(nKey):]
+		case *duoNode:
+			i1, i2 := n.childrenIdx()
+			switch hex[0] {
+			case i1:
+				tn = n.child1
+			case i2:
+				tn = n.child2
+			default:
+				tn = nil
+			}
+			hex = hex[1:]
+		case *fullNode:
+			tn = n.Children[hex[0]]
+			hex = hex[1:]
+		case hashNode:
+			return nil, fmt.Errorf("encountered hashNode unexpectedly, remaining path %x", hex)
+		default:
+			return nil, nil
+		}
+	}
+	switch n := tn.(type) {
+	case *shortNode, *duoNode, *fullNode:
+		enc, err := hasher.hashChildren(n, 0)
+		if err != nil {
+			return nil, err
+		}
+		return common.CopyBytes(enc), nil
+	case hashNode:
+		return nil, fmt.Errorf("node at the path is not loaded")
+	}
+	return nil, nil
+}
+
+// CompactToHex converts compact encoded path into HEX encoding, dropping the terminator
+func CompactToHex(compact []byte) []byte {
+	hex := compactToHex(compact)
+	if hasTerm(hex) {
+		hex = hex[:len(hex)-1]
+	}
+	return hex
+}
+
+// VerifyProof checks merkle proofs. The given proof must contain the value for
+// key in a trie with the given root hash. VerifyProof returns an error if the
+// proof contains invalid trie nodes or the wrong value.
+// Nil value with nil error means the proof proves absence of the key.
+func VerifyProof(rootHash common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	nodes := make(map[common.Hash][]byte, len(proof))
+	for _, enc := range proof {
+		nodes[common.BytesToHash(crypto.Keccak256(enc))] = enc
+	}
+	key = keybytesToHex(key)
+	key = key[:len(key)-1] // Remove terminator
+	wantHash := rootHash
+	for i := 0; ; i++ {
+		buf, ok := nodes[wantHash]
+		if !ok {
+			return nil, fmt.Errorf("proof node %d (hash %064x) missing", i, wantHash)
+		}
+		n, err := decodeNode(buf)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %w", i, err)
+		}
+		var rest []byte
+		rest, n = getProofChild(n, key)
+		switch cld := n.(type) {
+		case nil:
+			// The trie doesn't contain the key.
+			return nil, nil
+		case hashNode:
+			key = rest
+			copy(wantHash[:], cld.hash)
+		case valueNode:
+			return cld, nil
+		}
+	}
+}
+
+// getProofChild walks decoded proof node (including embedded children) until it reaches
+// either the value, the reference to the next proof node, or the end of path
+func getProofChild(tn node, key []byte) ([]byte, node) {
+	for {
+		switch n := tn.(type) {
+		case *shortNode:
+			nKey := n.Key
+			if hasTerm(nKey) {
+				nKey = nKey[:len(nKey)-1]
+			}
+			if len(key) < len(nKey) || !bytes.Equal(nKey, key[:len(nKey)]) {
+				return nil, nil
+			}
+			tn = n.Val
+			key = key[len(nKey):]
+		case *fullNode:
+			if len(key) == 0 {
+				return key, n.Children[16]
+			}
+			tn = n.Children[key[0]]
+			key = key[1:]
+		case hashNode:
+			return key, n
+		case nil:
+			return key, nil
+		case valueNode:
+			if len(key) > 0 {
+				return nil, nil
+			}
+			return nil, n
+		default:
+			panic(fmt.Sprintf("%T: invalid node: %v", tn, tn))
+		}
+	}
+}
+
+var errDecode = errors.New("invalid encoded node")
+
+// decodeNode parses the RLP encoding of a trie node
+func decodeNode(buf []byte) (node, error) {
+	if len(buf) == 0 {
+		return nil, errDecode
+	}
+	elems, _, err := rlp.SplitList(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	switch c, _ := rlp.CountValues(elems); c {
+	case 2:
+		return decodeShort(elems)
+	case 17:
+		return decodeFull(elems)
+	default:
+		return nil, fmt.Errorf("invalid number of list elements: %v", c)
+	}
+}
+
+func decodeShort(elems []byte) (node, error) {
+	kbuf, rest, err := rlp.SplitString(elems)
+	if err != nil {
+		return nil, err
+	}
+	key := compactToHex(kbuf)
+	if hasTerm(key) {
+		// value node
+		val, _, err := rlp.SplitString(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value node: %w", err)
+		}
+		return &shortNode{Key: key, Val: valueNode(common.CopyBytes(val))}, nil
+	}
+	r, _, err := decodeRef(rest)
+	if err != nil {
+		return nil, err
+	}
+	return &shortNode{Key: key, Val: r}, nil
+}
+
+func decodeFull(elems []byte) (*fullNode, error) {
+	n := &fullNode{}
+	for i := 0; i < 16; i++ {
+		cld, rest, err := decodeRef(elems)
+		if err != nil {
+			return n, fmt.Errorf("child %d: %w", i, err)
+		}
+		n.Children[i], elems = cld, rest
+	}
+	val, _, err := rlp.SplitString(elems)
+	if err != nil {
+		return n, err
+	}
+	if len(val) > 0 {
+		n.Children[16] = valueNode(common.CopyBytes(val))
+	}
+	return n, nil
+}
+
+func decodeRef(buf []byte) (node, []byte, error) {
+	kind, val, rest, err := rlp.Split(buf)
+	if err != nil {
+		return nil, buf, err
+	}
+	switch {
+	case kind == rlp.List:
+		// 'embedded' node reference. The encoding must be smaller
+		// than a hash in order to be valid.
+		if size := len(buf) - len(rest); size > common.HashLength {
+			return nil, buf, fmt.Errorf("oversized embedded node (size is %d bytes, want size < %d)", size, common.HashLength)
+		}
+		n, err := decodeNode(buf)
+		return n, rest, err
+	case kind == rlp.String && len(val) == 0:
+		// empty node
+		return nil, rest, nil
+	case kind == rlp.String && len(val) == 32:
+		return hashNode{hash: common.CopyBytes(val)}, rest, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid RLP string size %d (want 0 or 32)", len(val))
+	}
+}
@@ -0,0 +1,131 @@
+package trie
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/common/dbutils"
+	"github.com/ledgerwatch/turbo-geth/core/types/accounts"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/ethdb"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+func TestCalcSubTrieProofs(t *testing.T) {
+	require, assert, db := require.New(t), assert.New(t), ethdb.NewMemDatabase()
+	defer db.Close()
+
+	var addrHashes []common.Hash
+	var locHashes []common.Hash
+	for i := 0; i < 8; i++ {
+		var loc common.Hash
+		binary.BigEndian.PutUint64(loc[24:], uint64(i))
+		locHashes = append(locHashes, crypto.Keccak256Hash(loc[:]))
+	}
+	for i := 0; i < 200; i++ {
+		var addr common.Address
+		binary.BigEndian.PutUint64(addr[12:], uint64(i))
+		addrHash := crypto.Keccak256Hash(addr[:])
+		addrHashes = append(addrHashes, addrHash)
+		a := accounts.NewAccount()
+		a.Nonce = uint64(i)
+		a.Balance.SetUint64(uint64(i) * 1000)
+		if i%10 == 0 {
+			a.Incarnation = 1
+			a.CodeHash = crypto.Keccak256Hash([]byte{byte(i)})
+			for j, locHash := range locHashes {
+				require.NoError(db.Put(dbutils.CurrentStateBucket, dbutils.GenerateCompositeStorageKey(addrHash, a.Incarnation, locHash), uint256.NewInt().SetUint64(uint64(i*100+j+1)).Bytes()))
+			}
+		}
+		v := make([]byte, a.EncodingLengthForStorage())
+		a.EncodeForStorage(v)
+		require.NoError(db.Put(dbutils.CurrentStateBucket, addrHash[:], v))
+	}
+
+	// Calculate the root, filling up the intermediate hashes
+	var ihs [][2][]byte
+	hc := func(keyHex []byte, hash []byte) error {
+		if len(keyHex) == 0 {
+			return nil
+		}
+		if len(keyHex) > IHDupKeyLen {
+			ihs = append(ihs, [2][]byte{common.CopyBytes(keyHex[:IHDupKeyLen]), append(common.CopyBytes(keyHex[IHDupKeyLen:]), hash...)})
+			return nil
+		}
+		ihs = append(ihs, [2][]byte{common.CopyBytes(keyHex), common.CopyBytes(hash)})
+		return nil
+	}
+	loader := NewFlatDBTrieLoader("test", dbutils.CurrentStateBucket, dbutils.IntermediateTrieHashBucket)
+	require.NoError(loader.Reset(NewRetainList(0), hc, false))
+	root, err := loader.CalcTrieRoot(db, nil)
+	require.NoError(err)
+	require.NotEmpty(ihs)
+	for _, ih := range ihs {
+		require.NoError(db.Put(dbutils.IntermediateTrieHashBucket, ih[0], ih[1]))
+	}
+
+	contract, eoa := addrHashes[50], addrHashes[51]
+	var missing common.Hash
+	rl := NewRetainList(0)
+	rl.AddKey(missing[:])
+	rl.AddKey(eoa[:])
+	rl.AddKey(dbutils.GenerateCompositeStorageKey(contract, 1, locHashes[3]))
+	tx, err := db.KV().Begin(context.Background(), nil, ethdb.RO)
+	require.NoError(err)
+	defer tx.Rollback()
+	require.NoError(loader.Reset(rl, nil, false))
+	tr, err := loader.CalcSubTrie(tx, nil)
+	require.NoError(err)
+	assert.Equal(root, tr.Hash())
+
+	// Intermediate hashes must be kept intact
+	count := 0
+	require.NoError(db.Walk(dbutils.IntermediateTrieHashBucket, nil, 0, func(k, v []byte) (bool, error) {
+		count++
+		return true, nil
+	}))
+	assert.Equal(len(ihs), count)
+
+	// Account proof
+	proof, err := tr.Prove(eoa[:], 0, false)
+	require.NoError(err)
+	val, err := VerifyProof(root, eoa[:], proof)
+	require.NoError(err)
+	acc, ok := tr.GetAccount(eoa[:])
+	require.True(ok)
+	enc := make([]byte, acc.EncodingLengthForHashing())
+	acc.EncodeForHashing(enc)
+	assert.Equal(enc, val)
+
+	// Storage proof
+	acc, ok = tr.GetAccount(contract[:])
+	require.True(ok)
+	proof, err = tr.Prove(append(common.CopyBytes(contract[:]), locHashes[3][:]...), 64, true)
+	require.NoError(err)
+	val, err = VerifyProof(acc.Root, locHashes[3][:], proof)
+	require.NoError(err)
+	expected, err := rlp.EncodeToBytes(uint256.NewInt().SetUint64(5004).Bytes())
+	require.NoError(err)
+	assert.Equal(expected, val)
+
+	// Absence proof
+	proof, err = tr.Prove(missing[:], 0, false)
+	require.NoError(err)
+	val, err = VerifyProof(root, missing[:], proof)
+	require.NoError(err)
+	assert.Nil(val)
+
+	// Root node by path
+	enc, err = tr.NodeRLP(nil)
+	require.NoError(err)
+	assert.Equal(root, crypto.Keccak256Hash(enc))
+	enc, err = tr.NodeRLP(append(keybytesToHex(contract[:])[:64], CompactToHex([]byte{0})...))
+	require.NoError(err)
+	assert.Equal(acc.Root, crypto.Keccak256Hash(enc))
+}
@@ -0,0 +1,223 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/turbo/rlphacks"
+)
+
+// VerifyRangeProof checks that the given keys (in ascending order) with their leaf values are all the
+// leaves of the trie with the given root, starting from the origin and up to the last key.
+// The proof consists of the nodes on the paths to the origin and to the last key. Subtries outside
+// of the range are taken as hashes from these nodes, then the trie is rebuilt from these hashes and
+// the given leaves (the same way the root is computed from the intermediate hashes and the flat state),
+// and its root is compared with the expected one.
+// The returned flag tells whether the trie has more leaves after the last key.
+// Empty proof means that the keys are the whole trie.
+func VerifyRangeProof(root common.Hash, origin []byte, keys [][]byte, values [][]byte, proof [][]byte) (bool, error) {
+	if len(keys) != len(values) {
+		return false, fmt.Errorf("inconsistent proof data, keys: %d, values: %d", len(keys), len(values))
+	}
+	for i := range keys {
+		if i == 0 && bytes.Compare(keys[i], origin) < 0 {
+			return false, fmt.Errorf("key %x is before the origin %x", keys[i], origin)
+		}
+		if i > 0 && bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			return false, errors.New("keys are not in ascending order")
+		}
+	}
+	v := &rangeVerifier{}
+	for i, key := range keys {
+		v.items = append(v.items, rangeItem{key: keybytesToHex(key), value: values[i]})
+	}
+	if len(proof) > 0 {
+		v.nodes = make(map[common.Hash][]byte, len(proof))
+		for _, enc := range proof {
+			v.nodes[common.BytesToHash(crypto.Keccak256(enc))] = enc
+		}
+		v.origin = keybytesToHex(origin)
+		v.origin = v.origin[:len(v.origin)-1]
+		v.last = v.origin
+		if len(keys) > 0 {
+			v.last = keybytesToHex(keys[len(keys)-1])
+			v.last = v.last[:len(v.last)-1]
+		}
+		if err := v.walk(hashNode{hash: root[:]}, nil); err != nil {
+			return false, err
+		}
+		if len(keys) == 0 && v.hasMore {
+			return false, errors.New("empty range while the trie has more leaves after the origin")
+		}
+		sort.Slice(v.items, func(i, j int) bool {
+			return bytes.Compare(v.items[i].key, v.items[j].key) < 0
+		})
+	}
+	hash, err := v.rootHash()
+	if err != nil {
+		return false, err
+	}
+	if hash != root {
+		return false, fmt.Errorf("root mismatch: calculated %x, expected %x", hash, root)
+	}
+	return v.hasMore, nil
+}
+
+// rangeItem is either a leaf or the hash of the subtrie outside of the range
+type rangeItem struct {
+	key   []byte // HEX path of the leaf (with terminator) or of the subtrie
+	value []byte // value of the leaf
+	hash  []byte // hash of the subtrie, nil for the leaves
+}
+
+type rangeVerifier struct {
+	nodes        map[common.Hash][]byte // proof nodes by their hashes
+	origin, last []byte                 // HEX paths of the range boundaries, without terminator
+	items        []rangeItem
+	hasMore      bool // whether there are subtries after the last key
+}
+
+const (
+	leftOfRange = iota
+	inRange
+	rightOfRange
+	onBoundary // prefix of one of the boundaries, nodes on such paths are expected in the proof
+)
+
+func (v *rangeVerifier) classify(path []byte) int {
+	if c := bytes.Compare(path, v.origin[:min(len(path), len(v.origin))]); c < 0 {
+		return leftOfRange
+	} else if c == 0 {
+		return onBoundary
+	}
+	if c := bytes.Compare(path, v.last[:min(len(path), len(v.last))]); c > 0 {
+		return rightOfRange
+	} else if c == 0 {
+		return onBoundary
+	}
+	return inRange
+}
+
+// walk follows the paths to the range boundaries, collecting the subtries outside of the range.
+// `ref` is either the hash of the node or the embedded node itself
+func (v *rangeVerifier) walk(ref node, path []byte) error {
+	n := ref
+	if h, ok := ref.(hashNode); ok {
+		enc, ok := v.nodes[common.BytesToHash(h.hash)]
+		if !ok {
+			return fmt.Errorf("proof node for path %x (hash %x) missing", path, h.hash)
+		}
+		var err error
+		if n, err = decodeNode(enc); err != nil {
+			return fmt.Errorf("bad proof node for path %x: %w", path, err)
+		}
+	}
+	switch n := n.(type) {
+	case *shortNode:
+		nKey := n.Key
+		if hasTerm(nKey) {
+			nKey = nKey[:len(nKey)-1]
+		}
+		full := append(common.CopyBytes(path), nKey...)
+		cls := v.classify(full)
+		if _, ok := n.Val.(valueNode); ok {
+			// The leaf on the boundary is the boundary key itself, which is within the range
+			if cls == leftOfRange || cls == rightOfRange {
+				return v.add(ref, path, cls)
+			}
+			return nil
+		}
+		switch cls {
+		case onBoundary:
+			return v.walk(n.Val, full)
+		case leftOfRange, rightOfRange:
+			return v.add(ref, path, cls)
+		}
+		return nil
+	case *fullNode:
+		for i, child := range n.Children[:16] {
+			if child == nil {
+				continue
+			}
+			childPath := append(common.CopyBytes(path), byte(i))
+			switch cls := v.classify(childPath); cls {
+			case onBoundary:
+				if err := v.walk(child, childPath); err != nil {
+					return err
+				}
+			case leftOfRange, rightOfRange:
+				if err := v.add(child, childPath, cls); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected node %T for path %x", n, path)
+	}
+}
+
+// add collects the subtrie outside of the range. Hashed subtries are added as hashes,
+// while the embedded ones are expanded into their leaves
+func (v *rangeVerifier) add(ref node, path []byte, cls int) error {
+	if cls == rightOfRange {
+		v.hasMore = true
+	}
+	switch n := ref.(type) {
+	case hashNode:
+		v.items = append(v.items, rangeItem{key: common.CopyBytes(path), hash: n.hash})
+	case *shortNode:
+		full := append(common.CopyBytes(path), n.Key...)
+		if val, ok := n.Val.(valueNode); ok {
+			v.items = append(v.items, rangeItem{key: full, value: val})
+			return nil
+		}
+		return v.add(n.Val, full, cls)
+	case *fullNode:
+		for i, child := range n.Children[:16] {
+			if child == nil {
+				continue
+			}
+			if err := v.add(child, append(common.CopyBytes(path), byte(i)), cls); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected node %T for path %x", n, path)
+	}
+	return nil
+}
+
+func (v *rangeVerifier) rootHash() (common.Hash, error) {
+	if len(v.items) == 0 {
+		return EmptyRoot, nil
+	}
+	if len(v.items) == 1 && v.items[0].hash != nil && len(v.items[0].key) == 0 {
+		// The whole trie is outside of the range
+		return common.BytesToHash(v.items[0].hash), nil
+	}
+	hb := NewHashBuilder(false)
+	retain := func(_ []byte) bool { return false }
+	var groups []uint16
+	for i, item := range v.items {
+		var succ []byte
+		if i+1 < len(v.items) {
+			succ = v.items[i+1].key
+		}
+		var data GenStructStepData
+		if item.hash != nil {
+			data = &GenStructStepHashData{Hash: common.BytesToHash(item.hash)}
+		} else {
+			data = &GenStructStepLeafData{Value: rlphacks.RlpEncodedBytes(item.value)}
+		}
+		var err error
+		if groups, err = GenStructStep(retain, item.key, succ, hb, nil /* hashCollector */, data, groups, false); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return hb.rootHash(), nil
+}
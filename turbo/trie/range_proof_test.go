@@ -0,0 +1,110 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ledgerwatch/turbo-geth/common"
+	"github.com/ledgerwatch/turbo-geth/crypto"
+	"github.com/ledgerwatch/turbo-geth/rlp"
+)
+
+// rangeTestTrie builds the trie with the given number of keys, returning the keys with
+// the leaf values (RLP encoded, as they are in the leaf nodes)
+func rangeTestTrie(t *testing.T, count int) (*Trie, [][]byte, [][]byte) {
+	var keys [][]byte
+	for i := 0; i < count; i++ {
+		var preimage [8]byte
+		binary.BigEndian.PutUint64(preimage[:], uint64(i))
+		key := crypto.Keccak256(preimage[:])
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	tr := New(common.Hash{})
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value := bytes.Repeat([]byte{byte(i%250 + 1)}, i%40+1)
+		tr.Update(key, value)
+		var err error
+		values[i], err = rlp.EncodeToBytes(value)
+		require.NoError(t, err)
+	}
+	return tr, keys, values
+}
+
+func rangeProof(t *testing.T, tr *Trie, origin []byte, last []byte) [][]byte {
+	proof, err := tr.Prove(origin, 0, false)
+	require.NoError(t, err)
+	if last != nil {
+		lastProof, err := tr.Prove(last, 0, false)
+		require.NoError(t, err)
+		proof = append(proof, lastProof...)
+	}
+	return proof
+}
+
+func TestVerifyRangeProof(t *testing.T) {
+	tr, keys, values := rangeTestTrie(t, 500)
+	root := tr.Hash()
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		start := rnd.Intn(len(keys))
+		end := start + 1 + rnd.Intn(len(keys)-start)
+		if i == 0 {
+			start, end = 0, len(keys)
+		}
+		hasMore, err := VerifyRangeProof(root, keys[start], keys[start:end], values[start:end], rangeProof(t, tr, keys[start], keys[end-1]))
+		require.NoError(t, err, "range [%d, %d)", start, end)
+		require.Equal(t, end < len(keys), hasMore, "range [%d, %d)", start, end)
+	}
+
+	// Whole trie without the proof
+	hasMore, err := VerifyRangeProof(root, make([]byte, common.HashLength), keys, values, nil)
+	require.NoError(t, err)
+	require.False(t, hasMore)
+	_, err = VerifyRangeProof(root, make([]byte, common.HashLength), keys[1:], values[1:], nil)
+	require.Error(t, err)
+
+	// Origin which is not in the trie
+	origin := common.CopyBytes(keys[100])
+	origin[common.HashLength-1]++
+	require.False(t, bytes.Equal(origin, keys[101]))
+	hasMore, err = VerifyRangeProof(root, origin, keys[101:200], values[101:200], rangeProof(t, tr, origin, keys[199]))
+	require.NoError(t, err)
+	require.True(t, hasMore)
+
+	// Missing key in the middle of the range
+	rangeKeys := append(append([][]byte{}, keys[100:150]...), keys[151:200]...)
+	rangeValues := append(append([][]byte{}, values[100:150]...), values[151:200]...)
+	_, err = VerifyRangeProof(root, keys[100], rangeKeys, rangeValues, rangeProof(t, tr, keys[100], keys[199]))
+	require.Error(t, err)
+
+	// Missing key at the beginning of the range
+	_, err = VerifyRangeProof(root, keys[100], keys[101:200], values[101:200], rangeProof(t, tr, keys[100], keys[199]))
+	require.Error(t, err)
+
+	// Wrong value
+	rangeValues = append([][]byte{}, values[100:200]...)
+	rangeValues[50] = []byte{0xff}
+	_, err = VerifyRangeProof(root, keys[100], keys[100:200], rangeValues, rangeProof(t, tr, keys[100], keys[199]))
+	require.Error(t, err)
+
+	// Proof of the different root
+	_, err = VerifyRangeProof(common.Hash{1}, keys[100], keys[100:200], values[100:200], rangeProof(t, tr, keys[100], keys[199]))
+	require.Error(t, err)
+
+	// Empty range after the last key
+	origin = bytes.Repeat([]byte{0xff}, common.HashLength)
+	hasMore, err = VerifyRangeProof(root, origin, nil, nil, rangeProof(t, tr, origin, nil))
+	require.NoError(t, err)
+	require.False(t, hasMore)
+
+	// Empty range while there are more keys
+	_, err = VerifyRangeProof(root, keys[100], nil, nil, rangeProof(t, tr, keys[100], nil))
+	require.Error(t, err)
+}
@@ -119,6 +119,8 @@ type RootHashAggregator struct {
 	a            accounts.Account
 	leafData     GenStructStepLeafData
 	accData      GenStructStepAccountData
+	rd           RetainDecider // if set - nodes on the paths to the retained keys are built, see CalcSubTrie
+	rootNode     node
 }
 
 func NewRootHashAggregator() *RootHashAggregator {
@@ -145,6 +147,7 @@ func (l *FlatDBTrieLoader) Reset(rd RetainDecider, hc HashCollector, trace bool)
 	l.rd = rd
 	l.kHex, l.vHex = nil, nil
 	l.itemPresent = false
+	l.itemType = NoItem
 	if l.trace {
 		fmt.Printf("----------\n")
 		fmt.Printf("CalcTrieRoot\n")
@@ -386,25 +389,61 @@ func (l *FlatDBTrieLoader) CalcTrieRoot(db ethdb.Database, quit <-chan struct{})
 		return !l.rd.Retain(k)
 	}
 	ih := IH(filter, tx.CursorDupSort(l.intermediateHashesBucket))
-	if err := l.iteration(c, ih, true /* first */); err != nil {
+	if err := l.stream(c, ih, quit); err != nil {
 		return EmptyRoot, err
 	}
+
+	if !useExternalTx {
+		_, err := txDB.Commit()
+		if err != nil {
+			return EmptyRoot, err
+		}
+	}
+
+	return l.receiver.Root(), nil
+}
+
+// CalcSubTrie - same as CalcTrieRoot, but doesn't modify IntermediateHashes (so can be used within read-only
+// transaction) and keeps in memory all nodes on the paths to the keys retained by RetainDecider.
+// Returned trie can be used to build Merkle proofs for the retained keys.
+func (l *FlatDBTrieLoader) CalcSubTrie(tx ethdb.Tx, quit <-chan struct{}) (*Trie, error) {
+	c := NewStateCursor(tx.Cursor(l.stateBucket))
+	var filter = func(k []byte) bool {
+		return !l.rd.Retain(k)
+	}
+	ih := &IHCursor{c: tx.CursorDupSort(l.intermediateHashesBucket), filter: filter, readOnly: true}
+	l.defaultReceiver.rd = l.rd
+	if err := l.stream(c, ih, quit); err != nil {
+		return nil, err
+	}
+	t := New(l.defaultReceiver.root)
+	if l.defaultReceiver.rootNode != nil {
+		t.root = l.defaultReceiver.rootNode
+	}
+	return t, nil
+}
+
+// stream moves both cursors till the end and feeds produced items to the receiver
+func (l *FlatDBTrieLoader) stream(c *StateCursor, ih *IHCursor, quit <-chan struct{}) error {
+	if err := l.iteration(c, ih, true /* first */); err != nil {
+		return err
+	}
 	logEvery := time.NewTicker(30 * time.Second)
 	defer logEvery.Stop()
 
 	for l.itemType != CutoffStreamItem {
 		if err := common.Stopped(quit); err != nil {
-			return EmptyRoot, err
+			return err
 		}
 
 		for !l.itemPresent {
 			if err := l.iteration(c, ih, false /* first */); err != nil {
-				return EmptyRoot, err
+				return err
 			}
 		}
 
 		if err := l.receiver.Receive(l.itemType, l.accountKey, l.storageKey, &l.accountValue, l.storageValue, l.hashValue, 0); err != nil {
-			return EmptyRoot, err
+			return err
 		}
 		l.itemPresent = false
 
@@ -414,15 +453,7 @@ func (l *FlatDBTrieLoader) CalcTrieRoot(db ethdb.Database, quit <-chan struct{})
 			l.logProgress()
 		}
 	}
-
-	if !useExternalTx {
-		_, err := txDB.Commit()
-		if err != nil {
-			return EmptyRoot, err
-		}
-	}
-
-	return l.receiver.Root(), nil
+	return nil
 }
 
 func (l *FlatDBTrieLoader) logProgress() {
@@ -441,6 +472,13 @@ func (r *RootHashAggregator) RetainNothing(_ []byte) bool {
 	return false
 }
 
+func (r *RootHashAggregator) retain(prefix []byte) bool {
+	if r.rd == nil {
+		return false
+	}
+	return r.rd.Retain(prefix)
+}
+
 func (r *RootHashAggregator) Reset(hc HashCollector, trace bool) {
 	r.hc = hc
 	r.curr.Reset()
@@ -455,6 +493,8 @@ func (r *RootHashAggregator) Reset(hc HashCollector, trace bool) {
 	r.valueStorage = nil
 	r.wasIHStorage = false
 	r.root = common.Hash{}
+	r.rd = nil
+	r.rootNode = nil
 	r.trace = trace
 	r.hb.trace = trace
 }
@@ -588,6 +628,7 @@ func (r *RootHashAggregator) Receive(itemType StreamItem,
 		}
 		if r.hb.hasRoot() {
 			r.root = r.hb.rootHash()
+			r.rootNode = r.hb.root()
 		} else {
 			r.root = EmptyRoot
 		}
@@ -643,7 +684,7 @@ func (r *RootHashAggregator) genStructStorage() error {
 		r.leafData.Value = rlphacks.RlpSerializableBytes(r.valueStorage)
 		data = &r.leafData
 	}
-	r.groups, err = GenStructStep(r.RetainNothing, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, r.hc, data, r.groups, r.trace)
+	r.groups, err = GenStructStep(r.retain, r.currStorage.Bytes(), r.succStorage.Bytes(), r.hb, r.hc, data, r.groups, r.trace)
 	if err != nil {
 		return err
 	}
@@ -702,7 +743,7 @@ func (r *RootHashAggregator) genStructAccount() error {
 	r.currStorage.Reset()
 	r.succStorage.Reset()
 	var err error
-	if r.groups, err = GenStructStep(r.RetainNothing, r.curr.Bytes(), r.succ.Bytes(), r.hb, r.hc, data, r.groups, r.trace); err != nil {
+	if r.groups, err = GenStructStep(r.retain, r.curr.Bytes(), r.succ.Bytes(), r.hb, r.hc, data, r.groups, r.trace); err != nil {
 		return err
 	}
 	r.accData.FieldSet = 0
@@ -733,8 +774,9 @@ const IHDupKeyLen = 2 * (common.HashLength + common.IncarnationLength)
 
 // IHCursor - holds logic related to iteration over IH bucket
 type IHCursor struct {
-	c      ethdb.CursorDupSort
-	filter Filter
+	c        ethdb.CursorDupSort
+	filter   Filter
+	readOnly bool // if true - records which didn't pass the filter are skipped instead of deleted
 }
 
 func IH(f Filter, c ethdb.CursorDupSort) *IHCursor {
@@ -773,9 +815,11 @@ func (c *IHCursor) _seek(seek []byte) (k, v []byte, err error) {
 		return k, v, nil
 	}
 
-	err = c.c.DeleteCurrent()
-	if err != nil {
-		return []byte{}, nil, err
+	if !c.readOnly {
+		err = c.c.DeleteCurrent()
+		if err != nil {
+			return []byte{}, nil, err
+		}
 	}
 
 	return c._next()
@@ -801,9 +845,11 @@ func (c *IHCursor) _next() (k, v []byte, err error) {
 			return k, v, nil
 		}
 
-		err = c.c.DeleteCurrent()
-		if err != nil {
-			return []byte{}, nil, err
+		if !c.readOnly {
+			err = c.c.DeleteCurrent()
+			if err != nil {
+				return []byte{}, nil, err
+			}
 		}
 
 		k, v, err = c.c.Next()